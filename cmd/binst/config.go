@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get or edit values of an InstallSpec config file",
+	Long: `Reads or edits an InstallSpec configuration file in place while preserving
+comments and key ordering, so automation can modify specs safely.
+
+Keys are dotted paths with optional list indexes, e.g. "asset.template" or
+"asset.rules[0].ext". Values are parsed as YAML, so booleans, numbers, lists
+and mappings can be given. For supported_platforms, "os/arch" is accepted as
+a shorthand.
+
+Examples:
+  binst config get asset.template
+  binst config set asset.template '${NAME}_${VERSION}_${OS}_${ARCH}${EXT}'
+  binst config set attestation.enabled true
+  binst config add supported_platforms linux/riscv64`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _, err := parseSpecAST()
+		if err != nil {
+			return err
+		}
+		node, err := spec.GetValue(file, args[0])
+		if err != nil {
+			return err
+		}
+		var v any
+		if err := yaml.NodeToValue(node, &v, yaml.UseOrderedMap()); err != nil {
+			return fmt.Errorf("failed to decode value of %s: %w", args[0], err)
+		}
+		switch v := v.(type) {
+		case yaml.MapSlice, []any:
+			out, err := yaml.Marshal(v)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(out))
+		default:
+			fmt.Println(v)
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set the value of a key",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editSpec(args[0], args[1], spec.SetValue)
+	},
+}
+
+var configAddCmd = &cobra.Command{
+	Use:   "add <key> <value>",
+	Short: "Append a value to a list",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editSpec(args[0], args[1], spec.AddValue)
+	},
+}
+
+// parseSpecAST parses the config file with comments preserved.
+func parseSpecAST() (*ast.File, string, error) {
+	cfgFile, err := resolveConfigFile(configFile)
+	if err != nil {
+		return nil, "", err
+	}
+	if cfgFile == "-" {
+		return nil, "", fmt.Errorf("config command does not support reading the spec from stdin")
+	}
+	file, err := parser.ParseFile(cfgFile, parser.ParseComments)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse install spec file %s: %w", cfgFile, err)
+	}
+	return file, cfgFile, nil
+}
+
+// editSpec applies edit to key with the parsed value and writes the spec back.
+func editSpec(key, rawValue string, edit func(*ast.File, string, any) error) error {
+	file, cfgFile, err := parseSpecAST()
+	if err != nil {
+		return err
+	}
+	value, err := spec.ParseValue(key, rawValue)
+	if err != nil {
+		return err
+	}
+	if err := edit(file, key, value); err != nil {
+		return fmt.Errorf("failed to update %s: %w", key, err)
+	}
+
+	// Make sure the result is still a valid InstallSpec before writing it.
+	out := file.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	installSpec, err := spec.Parse([]byte(out))
+	if err != nil {
+		return fmt.Errorf("updated spec is invalid: %w", err)
+	}
+	if strings.HasSuffix(key, "default_version") {
+		spec.AnnotateRenovate(file, installSpec)
+		out = file.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+	}
+
+	if dryRunSkip("update %s in %s", key, cfgFile) {
+		return nil
+	}
+	if err := os.WriteFile(cfgFile, []byte(out), 0644); err != nil {
+		return fmt.Errorf("failed to write install spec file %s: %w", cfgFile, err)
+	}
+	log.Infof("Updated %s in %s", key, cfgFile)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configAddCmd)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/internal/devserver"
+	"github.com/haya14busa/goinstaller/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for dev command
+	devAddr        string
+	devTemplateDir string
+)
+
+// devCmd represents the dev command
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Serve a live preview of the generated installer for template development",
+	Long: `Starts a local server that renders the InstallSpec config file with the
+installer template and shows the generated script in the browser with syntax
+highlighting and a diff to the last render.
+
+The spec file and the template directory (--template-dir, e.g. internal/shell
+in a binstaller checkout) are watched and the page reloads on every change.
+Without --template-dir the templates built into binst are used.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("Running dev command...")
+
+		cfgFile, err := resolveConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+
+		watch := []string{cfgFile}
+		if devTemplateDir != "" {
+			watch = append(watch, devTemplateDir)
+		}
+		srv := &devserver.Server{
+			Render: func() ([]byte, error) {
+				installSpec, err := loadInstallSpec(cfgFile)
+				if err != nil {
+					return nil, err
+				}
+				if devTemplateDir != "" {
+					return shell.GenerateFromDir(installSpec, devTemplateDir)
+				}
+				return shell.Generate(installSpec)
+			},
+			WatchPaths: watch,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return srv.Run(ctx, devAddr)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(devCmd)
+
+	// Flags specific to dev command
+	devCmd.Flags().StringVar(&devAddr, "addr", "localhost:8080", "Address to serve the preview on")
+	devCmd.Flags().StringVar(&devTemplateDir, "template-dir", "", "Directory containing template.tmpl.sh and shell function files to use instead of the built-in ones")
+}
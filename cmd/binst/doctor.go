@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/internal/httpclient"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for doctor command
+	doctorBinDir string
+)
+
+// doctorStatus is the result level of a single diagnostic check.
+type doctorStatus string
+
+const (
+	doctorOK   doctorStatus = "ok"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+)
+
+// doctorCheck holds the outcome of a single diagnostic check and a
+// suggested fix when the check did not pass.
+type doctorCheck struct {
+	Name   string
+	Status doctorStatus
+	Detail string
+	Fix    string
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the environment for running binstaller and generated installers",
+	Long: `Checks the local environment for the tools and settings that binst and the
+generated installer scripts rely on:
+- download and extraction tools (curl/wget, tar, unzip)
+- optional verification tools (gh, cosign)
+- GITHUB_TOKEN validity and GitHub API rate-limit status
+- write permission to the default bin directory
+- whether the bin directory is on PATH
+
+Each failed check is reported together with a suggested fix.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("Running doctor command...")
+
+		binDir := doctorBinDir
+		if binDir == "" {
+			binDir = defaultBinDir()
+		}
+
+		var checks []doctorCheck
+		checks = append(checks, checkDownloaders())
+		checks = append(checks, checkCommand("tar", true, "Install tar with your system package manager (e.g. apt install tar)"))
+		checks = append(checks, checkCommand("unzip", false, "Install unzip to extract .zip assets (e.g. apt install unzip)"))
+		checks = append(checks, checkCommand("gh", false, "Install the GitHub CLI (https://cli.github.com) to verify attestations"))
+		checks = append(checks, checkCommand("cosign", false, "Install cosign (https://docs.sigstore.dev) to verify signatures"))
+		checks = append(checks, checkGitHubToken())
+		checks = append(checks, checkBinDirWritable(binDir))
+		checks = append(checks, checkBinDirInPath(binDir))
+
+		failed := 0
+		for _, c := range checks {
+			fmt.Printf("[%-4s] %s: %s\n", c.Status, c.Name, c.Detail)
+			if c.Status != doctorOK && c.Fix != "" {
+				fmt.Printf("       fix: %s\n", c.Fix)
+			}
+			if c.Status == doctorFail {
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		log.Info("All required checks passed")
+		return nil
+	},
+}
+
+// defaultBinDir mirrors the default bin dir of generated installer scripts.
+func defaultBinDir() string {
+	if dir := os.Getenv("BINSTALLER_BIN"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".local", "bin")
+	}
+	return filepath.Join(home, ".local", "bin")
+}
+
+// checkCommand reports whether the named command is available on PATH.
+func checkCommand(name string, required bool, fix string) doctorCheck {
+	c := doctorCheck{Name: name, Fix: fix}
+	path, err := exec.LookPath(name)
+	switch {
+	case err == nil:
+		c.Status = doctorOK
+		c.Detail = "found at " + path
+	case required:
+		c.Status = doctorFail
+		c.Detail = "not found"
+	default:
+		c.Status = doctorWarn
+		c.Detail = "not found (optional)"
+	}
+	return c
+}
+
+// checkDownloaders reports whether curl or wget is available.
+func checkDownloaders() doctorCheck {
+	c := doctorCheck{Name: "curl/wget"}
+	for _, name := range []string{"curl", "wget"} {
+		if path, err := exec.LookPath(name); err == nil {
+			c.Status = doctorOK
+			c.Detail = "found " + name + " at " + path
+			return c
+		}
+	}
+	c.Status = doctorFail
+	c.Detail = "neither curl nor wget found"
+	c.Fix = "Install curl or wget with your system package manager"
+	return c
+}
+
+// checkGitHubToken validates GITHUB_TOKEN against the GitHub API and reports
+// the remaining core rate limit.
+func checkGitHubToken() doctorCheck {
+	c := doctorCheck{Name: "GITHUB_TOKEN"}
+	token := os.Getenv("GITHUB_TOKEN")
+
+	apiURL := spec.DefaultGitHubAPIURL
+	if githubAPIURL != "" {
+		apiURL = strings.TrimSuffix(githubAPIURL, "/")
+	}
+	req, err := http.NewRequest("GET", apiURL+"/rate_limit", nil)
+	if err != nil {
+		c.Status = doctorWarn
+		c.Detail = fmt.Sprintf("failed to create request: %v", err)
+		return c
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpclient.HTTPClient().Do(req)
+	if err != nil {
+		c.Status = doctorWarn
+		c.Detail = fmt.Sprintf("could not reach GitHub API: %v", err)
+		c.Fix = "Check your network connection or proxy settings"
+		return c
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		c.Status = doctorFail
+		c.Detail = "token was rejected by GitHub (401 Unauthorized)"
+		c.Fix = "Regenerate the token or unset GITHUB_TOKEN"
+		return c
+	}
+	if resp.StatusCode != http.StatusOK {
+		c.Status = doctorWarn
+		c.Detail = fmt.Sprintf("unexpected status from GitHub API: %s", resp.Status)
+		return c
+	}
+
+	var rateLimit struct {
+		Resources struct {
+			Core struct {
+				Limit     int   `json:"limit"`
+				Remaining int   `json:"remaining"`
+				Reset     int64 `json:"reset"`
+			} `json:"core"`
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rateLimit); err != nil {
+		c.Status = doctorWarn
+		c.Detail = fmt.Sprintf("failed to parse rate limit response: %v", err)
+		return c
+	}
+	core := rateLimit.Resources.Core
+	c.Detail = fmt.Sprintf("rate limit %d/%d remaining", core.Remaining, core.Limit)
+
+	switch {
+	case core.Remaining == 0:
+		c.Status = doctorFail
+		c.Detail += fmt.Sprintf(", resets at %s", time.Unix(core.Reset, 0).Format(time.RFC3339))
+		c.Fix = "Wait for the rate limit to reset or set GITHUB_TOKEN"
+	case token == "":
+		c.Status = doctorWarn
+		c.Detail = "not set, " + c.Detail
+		c.Fix = "Set GITHUB_TOKEN to raise the GitHub API rate limit (e.g. export GITHUB_TOKEN=$(gh auth token))"
+	default:
+		c.Status = doctorOK
+		c.Detail = "valid, " + c.Detail
+	}
+	return c
+}
+
+// checkBinDirWritable reports whether files can be created in binDir, or in
+// its nearest existing parent when binDir does not exist yet.
+func checkBinDirWritable(binDir string) doctorCheck {
+	c := doctorCheck{Name: "bin dir"}
+	dir := binDir
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".binst-doctor-")
+	if err != nil {
+		c.Status = doctorFail
+		c.Detail = fmt.Sprintf("%s is not writable: %v", binDir, err)
+		c.Fix = "Choose a writable directory with BINSTALLER_BIN or the installer's -b flag"
+		return c
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	c.Status = doctorOK
+	if dir == binDir {
+		c.Detail = binDir + " is writable"
+	} else {
+		c.Detail = fmt.Sprintf("%s does not exist yet but can be created", binDir)
+	}
+	return c
+}
+
+// checkBinDirInPath reports whether binDir is listed in PATH.
+func checkBinDirInPath(binDir string) doctorCheck {
+	c := doctorCheck{Name: "PATH"}
+	clean := filepath.Clean(binDir)
+	paths := filepath.SplitList(os.Getenv("PATH"))
+	if slices.ContainsFunc(paths, func(p string) bool { return filepath.Clean(p) == clean }) {
+		c.Status = doctorOK
+		c.Detail = binDir + " is on PATH"
+		return c
+	}
+	c.Status = doctorWarn
+	c.Detail = binDir + " is not on PATH"
+	c.Fix = fmt.Sprintf("Add it to your shell profile: export PATH=\"%s:$PATH\"", binDir)
+	return c
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	// Flags specific to doctor command
+	doctorCmd.Flags().StringVarP(&doctorBinDir, "bin-dir", "b", "", "Bin directory to check (default: ${BINSTALLER_BIN} or ${HOME}/.local/bin)")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakePath sets PATH to a directory holding empty executables of the given
+// names.
+func fakePath(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	return dir
+}
+
+func TestCheckCommand(t *testing.T) {
+	dir := fakePath(t, "tar")
+	tests := []struct {
+		name     string
+		required bool
+		want     doctorStatus
+		detail   string
+	}{
+		{"tar", true, doctorOK, "found at " + filepath.Join(dir, "tar")},
+		{"unzip", true, doctorFail, "not found"},
+		{"cosign", false, doctorWarn, "not found (optional)"},
+	}
+	for _, tt := range tests {
+		c := checkCommand(tt.name, tt.required, "fix")
+		if c.Status != tt.want || c.Detail != tt.detail {
+			t.Errorf("checkCommand(%q, %v) = %s %q, want %s %q", tt.name, tt.required, c.Status, c.Detail, tt.want, tt.detail)
+		}
+	}
+}
+
+func TestCheckDownloaders(t *testing.T) {
+	fakePath(t, "wget")
+	if c := checkDownloaders(); c.Status != doctorOK || !strings.HasPrefix(c.Detail, "found wget") {
+		t.Errorf("checkDownloaders() = %s %q, want wget found", c.Status, c.Detail)
+	}
+	fakePath(t)
+	if c := checkDownloaders(); c.Status != doctorFail || c.Fix == "" {
+		t.Errorf("checkDownloaders() = %s %q, want fail with a fix", c.Status, c.Detail)
+	}
+}
+
+func TestCheckGitHubToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		status    int
+		remaining int
+		want      doctorStatus
+		detail    string
+	}{
+		{"valid", "good", http.StatusOK, 4999, doctorOK, "valid, rate limit 4999/5000 remaining"},
+		{"unset", "", http.StatusOK, 59, doctorWarn, "not set, rate limit 59/5000 remaining"},
+		{"rate limited", "good", http.StatusOK, 0, doctorFail, "rate limit 0/5000 remaining, resets at "},
+		{"rejected", "bad", http.StatusUnauthorized, 0, doctorFail, "token was rejected by GitHub (401 Unauthorized)"},
+		{"server error", "good", http.StatusInternalServerError, 0, doctorWarn, "unexpected status from GitHub API: 500 Internal Server Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/rate_limit" {
+					t.Errorf("unexpected request: %s", r.URL)
+				}
+				wantAuth := ""
+				if tt.token != "" {
+					wantAuth = "Bearer " + tt.token
+				}
+				if got := r.Header.Get("Authorization"); got != wantAuth {
+					t.Errorf("Authorization header = %q, want %q", got, wantAuth)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprintf(w, `{"resources":{"core":{"limit":5000,"remaining":%d,"reset":0}}}`, tt.remaining)
+			}))
+			defer srv.Close()
+			t.Setenv("GITHUB_TOKEN", tt.token)
+			old := githubAPIURL
+			githubAPIURL = srv.URL + "/"
+			defer func() { githubAPIURL = old }()
+
+			c := checkGitHubToken()
+			if c.Status != tt.want || !strings.HasPrefix(c.Detail, tt.detail) {
+				t.Errorf("checkGitHubToken() = %s %q, want %s %q", c.Status, c.Detail, tt.want, tt.detail)
+			}
+		})
+	}
+}
+
+func TestCheckBinDirWritable(t *testing.T) {
+	dir := t.TempDir()
+	if c := checkBinDirWritable(dir); c.Status != doctorOK || c.Detail != dir+" is writable" {
+		t.Errorf("checkBinDirWritable(existing) = %s %q", c.Status, c.Detail)
+	}
+	missing := filepath.Join(dir, "a", "bin")
+	if c := checkBinDirWritable(missing); c.Status != doctorOK || !strings.Contains(c.Detail, "can be created") {
+		t.Errorf("checkBinDirWritable(missing) = %s %q", c.Status, c.Detail)
+	}
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c := checkBinDirWritable(filepath.Join(file, "bin")); c.Status != doctorFail || c.Fix == "" {
+		t.Errorf("checkBinDirWritable(under a file) = %s %q, want fail with a fix", c.Status, c.Detail)
+	}
+}
+
+func TestCheckBinDirInPath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", "/usr/bin"+string(os.PathListSeparator)+dir+"/")
+	if c := checkBinDirInPath(dir); c.Status != doctorOK {
+		t.Errorf("checkBinDirInPath(%q) = %s %q, want ok", dir, c.Status, c.Detail)
+	}
+	other := filepath.Join(dir, "bin")
+	if c := checkBinDirInPath(other); c.Status != doctorWarn || !strings.Contains(c.Fix, other) {
+		t.Errorf("checkBinDirInPath(%q) = %s %q, want warn with a fix", other, c.Status, c.Fix)
+	}
+}
+
+func TestDefaultBinDir(t *testing.T) {
+	t.Setenv("BINSTALLER_BIN", "/opt/bin")
+	if got := defaultBinDir(); got != "/opt/bin" {
+		t.Errorf("defaultBinDir() = %q, want /opt/bin", got)
+	}
+	t.Setenv("BINSTALLER_BIN", "")
+	t.Setenv("HOME", "/home/user")
+	if got, want := defaultBinDir(), filepath.Join("/home/user", ".local", "bin"); got != want {
+		t.Errorf("defaultBinDir() = %q, want %q", got, want)
+	}
+}
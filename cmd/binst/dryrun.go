@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/apex/log"
+)
+
+// dryRunSkip reports whether an action writing files or to the network is
+// skipped with --dry-run, in which case the action is logged instead. format
+// and args describe the action, e.g. "write installer script to %s".
+func dryRunSkip(format string, args ...any) bool {
+	if !dryRun {
+		return false
+	}
+	log.Infof("[dry-run] Would %s", fmt.Sprintf(format, args...))
+	return true
+}
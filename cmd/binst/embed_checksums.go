@@ -2,12 +2,14 @@ package main
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/apex/log"
-	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/parser"
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
 	"github.com/haya14busa/goinstaller/pkg/checksums"
 	"github.com/haya14busa/goinstaller/pkg/spec"
 	"github.com/spf13/cobra"
@@ -15,11 +17,17 @@ import (
 
 var (
 	// Flags for embed-checksums command
-	embedVersion      string
-	embedOutput       string
-	embedMode         string
-	embedFile         string
-	embedAllPlatforms bool
+	embedVersions           []string
+	embedVersionsFromGitHub int
+	embedOutput             string
+	embedMode               string
+	embedFile               string
+	embedAllPlatforms       bool
+	embedUpdate             bool
+	embedVerify             bool
+	embedCacheDir           string
+	embedNoCache            bool
+	embedAll                bool
 )
 
 // embedChecksumsCmd represents the embed-checksums command
@@ -27,60 +35,37 @@ var embedChecksumsCmd = &cobra.Command{
 	Use:   "embed-checksums",
 	Short: "Embed checksums for release assets into a binstaller configuration",
 	Long: `Reads an InstallSpec configuration file and embeds checksums for the assets.
-This command supports three modes of operation:
+This command supports four modes of operation:
 - download: Fetches the checksum file from GitHub releases
 - checksum-file: Uses a local checksum file
-- calculate: Downloads the assets and calculates checksums directly`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		log.Info("Running embed-checksums command...")
+- calculate: Downloads the assets and calculates checksums directly. For
+  specs installing from a container image, the binary is extracted from the
+  image of every linux platform instead.
+- provenance: Reads the subject digests of the SLSA provenance or attestation
+  bundles of the release (or of --file), without downloading the assets.
+  The provenance signatures are not verified by this command.
 
-		// Determine config file path
-		cfgFile := configFile // Use the global flag value
-		if cfgFile == "" {
-			// Default detection logic if global flag is not set
-			defaultPath := ".binstaller.yml"
-			if _, err := os.Stat(defaultPath); err == nil {
-				cfgFile = defaultPath
-				log.Infof("Using default config file: %s", cfgFile)
-			} else {
-				// Try .binstaller.yaml as fallback
-				defaultPathYaml := ".binstaller.yaml"
-				if _, errYaml := os.Stat(defaultPathYaml); errYaml == nil {
-					cfgFile = defaultPathYaml
-					log.Infof("Using default config file: %s", cfgFile)
-				} else {
-					err := fmt.Errorf("config file not specified via --config and default (.binstaller.yml or .binstaller.yaml) not found")
-					log.WithError(err).Error("Config file detection failed")
-					return err
-				}
-			}
-		}
-		log.Debugf("Using config file: %s", cfgFile)
+Repeat --version, or use --versions-from-github N, to embed checksums for
+several releases in one run so that scripts pinned to older versions can still
+verify their downloads offline.
 
-		// Read the InstallSpec YAML file
-		log.Debugf("Reading InstallSpec from: %s", cfgFile)
+With --update, checksums already embedded for a version are kept and only
+missing assets are added, e.g. when a release gains new platform assets later.
+Hashes that differ from the embedded ones are reported as warnings.
 
-		ast, err := parser.ParseFile(cfgFile, parser.ParseComments)
-		if err != nil {
-			return err
-		}
+With --verify, the checksums of already embedded versions (all of them unless
+--version is given) are acquired again and compared with the embedded ones.
+Drift is reported without modifying the spec, and the command fails if an
+embedded checksum does not match, e.g. in scheduled CI audits.
 
-		yamlData, err := os.ReadFile(cfgFile)
-		if err != nil {
-			log.WithError(err).Errorf("Failed to read install spec file: %s", cfgFile)
-			return fmt.Errorf("failed to read install spec file %s: %w", cfgFile, err)
-		}
+With --all, checksums are embedded into every spec in the .binstaller/
+directory of a multi-tool repository, each being updated in place.
 
-		// Unmarshal YAML into InstallSpec struct
-		log.Debug("Unmarshalling InstallSpec YAML")
-		var installSpec spec.InstallSpec
-		err = yaml.UnmarshalWithOptions(yamlData, &installSpec, yaml.UseOrderedMap())
-		if err != nil {
-			log.WithError(err).Errorf("Failed to unmarshal install spec YAML from: %s", cfgFile)
-			return fmt.Errorf("failed to unmarshal install spec YAML from %s: %w", cfgFile, err)
-		}
+In download mode, if the spec enables signature verification of the checksum
+file, the signature is verified before the checksums are trusted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("Running embed-checksums command...")
 
-		// Create the embedder
 		var mode checksums.EmbedMode
 		switch embedMode {
 		case "download":
@@ -89,8 +74,10 @@ This command supports three modes of operation:
 			mode = checksums.EmbedModeChecksumFile
 		case "calculate":
 			mode = checksums.EmbedModeCalculate
+		case "provenance":
+			mode = checksums.EmbedModeProvenance
 		default:
-			return fmt.Errorf("invalid mode: %s. Must be one of: download, checksum-file, calculate", embedMode)
+			return fmt.Errorf("invalid mode: %s. Must be one of: download, checksum-file, calculate, provenance", embedMode)
 		}
 
 		// Validate checksum-file mode has a file
@@ -99,60 +86,225 @@ This command supports three modes of operation:
 			return fmt.Errorf("--file flag is required for checksum-file mode")
 		}
 
+		if !embedAll {
+			cfgFile, err := resolveConfigFile(configFile)
+			if err != nil {
+				return err
+			}
+			return embedChecksums(cfgFile, mode)
+		}
+
+		if embedOutput != "" || embedFile != "" {
+			return fmt.Errorf("--output and --file cannot be used with --all")
+		}
+		cfgFiles, err := configDirSpecFiles()
+		if err != nil {
+			return err
+		}
+		failed := 0
+		for _, cfgFile := range cfgFiles {
+			log.Infof("Embedding checksums into %s", cfgFile)
+			if err := embedChecksums(cfgFile, mode); err != nil {
+				log.WithError(err).Errorf("Failed to embed checksums into %s", cfgFile)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to embed checksums into %d of %d spec(s)", failed, len(cfgFiles))
+		}
+		return nil
+	},
+}
+
+// embedChecksums embeds the checksums acquired with mode into the spec
+// cfgFile, or verifies them with --verify.
+func embedChecksums(cfgFile string, mode checksums.EmbedMode) error {
+	log.Debugf("Using config file: %s", cfgFile)
+
+	// Read the InstallSpec YAML file
+	log.Debugf("Reading InstallSpec from: %s", cfgFile)
+
+	ast, err := parser.ParseFile(cfgFile, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	// Checksums are written to cfgFile itself, but the asset
+	// settings may come from the base specs it extends.
+	yamlData, err := readSpecFile(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	// Unmarshal YAML into InstallSpec struct
+	log.Debug("Unmarshalling InstallSpec YAML")
+	installSpec, err := spec.Parse(yamlData)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to unmarshal install spec YAML from: %s", cfgFile)
+		return fmt.Errorf("failed to unmarshal install spec YAML from %s: %w", cfgFile, err)
+	}
+	logSpec(installSpec)
+
+	if githubAPIURL != "" {
+		installSpec.GitHubAPIURL = githubAPIURL
+	}
+
+	versions := embedVersions
+	if embedVersionsFromGitHub > 0 {
+		if len(versions) > 0 {
+			return fmt.Errorf("--version and --versions-from-github cannot be used together")
+		}
+		lister := &checksums.Embedder{Spec: installSpec}
+		versions, err = lister.LatestReleaseTags(embedVersionsFromGitHub)
+		if err != nil {
+			log.WithError(err).Error("Failed to list releases")
+			return fmt.Errorf("failed to list releases: %w", err)
+		}
+		if len(versions) == 0 {
+			return binsterr.Wrap(binsterr.ErrNotFound, fmt.Errorf("no releases found for %s", installSpec.Repo))
+		}
+	}
+	if len(versions) == 0 && embedVerify && installSpec.Checksums != nil {
+		// Verify every embedded version by default
+		versions = slices.Sorted(maps.Keys(installSpec.Checksums.EmbeddedChecksums))
+	}
+	if len(versions) == 0 {
+		versions = []string{""} // latest
+	}
+	if embedFile != "" && len(versions) > 1 {
+		return fmt.Errorf("--file supports only a single version")
+	}
+
+	cacheDir := ""
+	if !embedNoCache {
+		cacheDir = resolveDownloadCacheDir(embedCacheDir)
+	}
+
+	if embedVerify {
+		return verifyEmbeddedChecksums(installSpec, mode, versions, cacheDir)
+	}
+
+	// Embed the checksums of each version into the same spec
+	for _, version := range versions {
 		embedder := &checksums.Embedder{
 			Mode:         mode,
-			Version:      embedVersion,
-			Spec:         &installSpec,
+			Version:      version,
+			Spec:         installSpec,
 			SpecAST:      ast,
 			ChecksumFile: embedFile,
 			AllPlatforms: embedAllPlatforms,
+			Update:       embedUpdate,
+			CacheDir:     cacheDir,
 		}
 
-		// Embed the checksums
-		log.Infof("Embedding checksums using %s mode for version: %s", mode, embedVersion)
+		log.Infof("Embedding checksums using %s mode for version: %s", mode, version)
 		if err := embedder.Embed(); err != nil {
 			log.WithError(err).Error("Failed to embed checksums")
 			return fmt.Errorf("failed to embed checksums: %w", err)
 		}
+	}
 
-		// Determine output file
-		outputFile := embedOutput
-		if outputFile == "" {
-			outputFile = cfgFile
-			log.Infof("No output specified, overwriting input file: %s", outputFile)
-		}
+	// Determine output file
+	outputFile := embedOutput
+	if outputFile == "" {
+		outputFile = cfgFile
+		log.Infof("No output specified, overwriting input file: %s", outputFile)
+	}
 
-		// Write the updated InstallSpec back to the output file
-		log.Infof("Writing updated InstallSpec to file: %s", outputFile)
+	if dryRunSkip("write InstallSpec with embedded checksums to %s", outputFile) {
+		return nil
+	}
+	// Write the updated InstallSpec back to the output file
+	log.Infof("Writing updated InstallSpec to file: %s", outputFile)
 
-		// Ensure the output directory exists
-		outputDir := filepath.Dir(outputFile)
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			log.WithError(err).Errorf("Failed to create output directory: %s", outputDir)
-			return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
-		}
+	// Ensure the output directory exists
+	outputDir := filepath.Dir(outputFile)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		log.WithError(err).Errorf("Failed to create output directory: %s", outputDir)
+		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
 
-		// Write the YAML to the output file
-		if err := os.WriteFile(outputFile, []byte(ast.String()), 0644); err != nil {
-			log.WithError(err).Errorf("Failed to write InstallSpec to file: %s", outputFile)
-			return fmt.Errorf("failed to write InstallSpec to file %s: %w", outputFile, err)
-		}
-		log.Infof("InstallSpec successfully updated with embedded checksums")
+	// Write the YAML to the output file
+	if err := os.WriteFile(outputFile, []byte(ast.String()), 0644); err != nil {
+		log.WithError(err).Errorf("Failed to write InstallSpec to file: %s", outputFile)
+		return fmt.Errorf("failed to write InstallSpec to file %s: %w", outputFile, err)
+	}
+	log.Infof("InstallSpec successfully updated with embedded checksums")
 
-		return nil
-	},
+	return nil
+}
+
+// resolveDownloadCacheDir returns dir, defaulting to binstaller/downloads in
+// the user cache directory. Caching is disabled with --dry-run or if the
+// user cache directory is unknown.
+func resolveDownloadCacheDir(dir string) string {
+	if dryRun {
+		log.Debug("Download cache disabled with --dry-run")
+		return ""
+	}
+	if dir != "" {
+		return dir
+	}
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		log.Debugf("Download cache disabled: %v", err)
+		return ""
+	}
+	return filepath.Join(userCacheDir, "binstaller", "downloads")
+}
+
+// verifyEmbeddedChecksums compares the embedded checksums of versions with
+// the releases and fails if any of them drifted.
+func verifyEmbeddedChecksums(installSpec *spec.InstallSpec, mode checksums.EmbedMode, versions []string, cacheDir string) error {
+	mismatches := 0
+	for _, version := range versions {
+		embedder := &checksums.Embedder{
+			Mode:         mode,
+			Version:      version,
+			Spec:         installSpec,
+			ChecksumFile: embedFile,
+			CacheDir:     cacheDir,
+		}
+		drifts, err := embedder.Verify()
+		if err != nil {
+			log.WithError(err).Error("Failed to verify checksums")
+			return fmt.Errorf("failed to verify checksums: %w", err)
+		}
+		for _, d := range drifts {
+			if d.Mismatch() {
+				mismatches++
+				log.Error(d.String())
+			} else {
+				log.Warn(d.String())
+			}
+		}
+	}
+	if mismatches > 0 {
+		return binsterr.Wrap(binsterr.ErrVerificationFailed, fmt.Errorf("%d embedded checksum(s) do not match the releases", mismatches))
+	}
+	log.Infof("Embedded checksums of %d version(s) match the releases", len(versions))
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(embedChecksumsCmd)
 
 	// Flags specific to embed-checksums command
-	embedChecksumsCmd.Flags().StringVarP(&embedVersion, "version", "v", "", "Version to embed checksums for (default: latest)")
+	embedChecksumsCmd.Flags().StringArrayVarP(&embedVersions, "version", "v", nil, "Version to embed checksums for; can be repeated (default: latest)")
+	embedChecksumsCmd.Flags().IntVar(&embedVersionsFromGitHub, "versions-from-github", 0, "Embed checksums for the N most recent GitHub releases")
 	embedChecksumsCmd.Flags().StringVarP(&embedOutput, "output", "o", "", "Output path for the updated InstallSpec (default: overwrite input file)")
-	embedChecksumsCmd.Flags().StringVarP(&embedMode, "mode", "m", "download", "Checksums acquisition mode (download, checksum-file, calculate)")
-	embedChecksumsCmd.Flags().StringVarP(&embedFile, "file", "f", "", "Path to checksum file (required for checksum-file mode)")
+	embedChecksumsCmd.Flags().StringVarP(&embedMode, "mode", "m", "download", "Checksums acquisition mode (download, checksum-file, calculate, provenance)")
+	embedChecksumsCmd.Flags().StringVarP(&embedFile, "file", "f", "", "Path to checksum file (required for checksum-file mode) or provenance file (provenance mode)")
 	embedChecksumsCmd.Flags().BoolVar(&embedAllPlatforms, "all-platforms", false, "Generate checksums for all supported platforms (for calculate mode)")
 
+	embedChecksumsCmd.Flags().BoolVar(&embedUpdate, "update", false, "Only add checksums for assets missing from the embedded ones instead of replacing them")
+
+	embedChecksumsCmd.Flags().BoolVar(&embedVerify, "verify", false, "Verify embedded checksums against the releases without modifying the spec")
+
+	embedChecksumsCmd.Flags().StringVar(&embedCacheDir, "cache-dir", "", "Directory to cache downloaded files across runs (default: binstaller/downloads in the user cache directory)")
+	embedChecksumsCmd.Flags().BoolVar(&embedNoCache, "no-cache", false, "Do not cache downloaded files")
+	embedChecksumsCmd.Flags().BoolVar(&embedAll, "all", false, "Embed checksums into every spec in the "+configDirName+"/ directory")
+
 	// Mark required flags
 	embedChecksumsCmd.MarkFlagRequired("mode")
 }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// envFlags are the environment variables of flags, so that CI can configure
+// binst without long flag lists. Flags given on the command line take
+// precedence. Other flags have no variable on purpose: installer scripts
+// already read BINSTALLER_* variables such as BINSTALLER_VERSION.
+var envFlags = map[string]string{
+	"config":         "BINSTALLER_CONFIG",
+	"dry-run":        "BINSTALLER_DRY_RUN",
+	"verbose":        "BINSTALLER_VERBOSE",
+	"quiet":          "BINSTALLER_QUIET",
+	"yes":            "BINSTALLER_YES",
+	"timeout":        "BINSTALLER_TIMEOUT",
+	"log-format":     "BINSTALLER_LOG_FORMAT",
+	"github-api-url": "BINSTALLER_GITHUB_API_URL",
+	"bin-dir":        "BINSTALLER_BIN",
+}
+
+// applyEnvFlags sets the flags of cmd that are not given on the command line
+// from their non-empty environment variables.
+func applyEnvFlags(cmd *cobra.Command) error {
+	for _, name := range slices.Sorted(maps.Keys(envFlags)) {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || f.Changed {
+			continue
+		}
+		env := envFlags[name]
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("invalid %s=%q: %w", env, value, err)
+		}
+	}
+	return nil
+}
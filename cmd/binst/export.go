@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/internal/shell"
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/ci"
+	"github.com/haya14busa/goinstaller/pkg/datasource"
+	"github.com/haya14busa/goinstaller/pkg/dockerfile"
+	"github.com/haya14busa/goinstaller/pkg/homebrew"
+	"github.com/haya14busa/goinstaller/pkg/nix"
+	"github.com/haya14busa/goinstaller/pkg/npm"
+	"github.com/haya14busa/goinstaller/pkg/readme"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"github.com/haya14busa/goinstaller/pkg/winget"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+// exportFormats are the formats supported by the export command.
+var exportFormats = []string{"homebrew", "homebrew-cask", "winget", "nix", "npm", "aqua", "markdown", "dockerfile", "ci", "gitlab-ci"}
+
+var (
+	// Flags for export command
+	exportFormat    string
+	exportVersion   string
+	exportOutput    string
+	exportDesc      string
+	exportLicense   string
+	exportHomepage  string
+	exportPackageID string
+	exportPublisher string
+	exportScriptURL string
+	exportTap       string
+	exportBucket    string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a binstaller configuration to another package manager",
+	Long: `Renders a package definition for another package manager from an InstallSpec
+configuration file, with per-platform URLs and checksums taken from the
+embedded checksums. Nothing is downloaded.
+
+Formats:
+  homebrew       Homebrew formula for macOS and Linux
+  homebrew-cask  Homebrew cask for macOS
+  winget         winget version, installer and locale manifests for Windows.
+                 --output names a directory, and --license and --desc are
+                 required.
+  nix            Nix derivation for Linux and macOS, to be used with
+                 callPackage
+  npm            npm package running the installer script in its postinstall
+                 script and exposing the binaries with Node.js shims, so that
+                 they can be installed with "npm install -g". --output names
+                 a directory, and --package-identifier sets the package name
+                 (default: name).
+  dockerfile     Dockerfile stage downloading the Linux asset of the buildx
+                 target platform (TARGETOS/TARGETARCH) to /out, to be copied
+                 into an image
+  ci             POSIX shell snippet for any CI system, installing the Linux
+                 or macOS asset of the runner platform to $BINDIR (default:
+                 ~/.local/bin) and adding it to PATH
+  gitlab-ci      GitLab CI configuration to include, with an .install-<name>
+                 job running the ci snippet in its before_script
+  aqua           aqua-registry registry.yaml package entry. It does not use
+                 embedded checksums, and features aqua cannot express, such
+                 as libc rules, are reported as errors.
+  markdown       Installation section for a README with the install command,
+                 supported platforms and verification. With --script-url,
+                 the command is the checksum-pinned one-liner of
+                 "binst gen --one-liner". --homebrew-tap and --scoop-bucket
+                 add those package managers.
+
+For the formats using embedded checksums, the version defaults to
+default_version if its checksums are embedded, or else to the newest version
+with embedded checksums.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgFile, err := resolveConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		installSpec, err := loadInstallSpec(cfgFile)
+		if err != nil {
+			return err
+		}
+		logSpec(installSpec)
+		if exportFormat == "aqua" {
+			registry, err := datasource.ExportAquaRegistry(installSpec, exportDesc)
+			if err != nil {
+				return err
+			}
+			return writeExport(registry, installSpec.Name)
+		}
+		if exportFormat == "markdown" {
+			return exportMarkdown(installSpec)
+		}
+		version, err := exportedVersion(installSpec, exportVersion)
+		if err != nil {
+			return err
+		}
+		setLogField("version", version)
+		embedder := &checksums.Embedder{Spec: installSpec, Version: version}
+		assets, err := embedder.EmbeddedAssets()
+		if err != nil {
+			return err
+		}
+
+		opts := homebrew.Options{Desc: exportDesc, License: exportLicense, Homepage: exportHomepage}
+		var buf bytes.Buffer
+		switch exportFormat {
+		case "homebrew":
+			err = homebrew.Formula(&buf, installSpec, version, assets, opts)
+		case "homebrew-cask":
+			err = homebrew.Cask(&buf, installSpec, version, assets, opts)
+		case "winget":
+			return exportWinget(installSpec, version, assets)
+		case "npm":
+			return exportNPM(installSpec, version, assets)
+		case "nix":
+			err = nix.Derivation(&buf, installSpec, version, assets, nix.Options(opts))
+		case "dockerfile":
+			err = dockerfile.Stage(&buf, installSpec, version, assets)
+		case "ci":
+			err = ci.Snippet(&buf, installSpec, version, assets)
+		case "gitlab-ci":
+			err = ci.GitLab(&buf, installSpec, version, assets)
+		default:
+			err = fmt.Errorf("unsupported export format %q (supported: %s)", exportFormat, strings.Join(exportFormats, ", "))
+		}
+		if err != nil {
+			return err
+		}
+
+		return writeExport(buf.Bytes(), installSpec.Name+"@"+version)
+	},
+}
+
+// writeExport writes data exported for pkg to --output, or to stdout.
+func writeExport(data []byte, pkg string) error {
+	if exportOutput == "" || exportOutput == "-" {
+		fmt.Print(string(data))
+		return nil
+	}
+	if dryRunSkip("write %s of %s to %s", exportFormat, pkg, exportOutput) {
+		return nil
+	}
+	if err := os.WriteFile(exportOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s to %s: %w", exportFormat, exportOutput, err)
+	}
+	log.Infof("%s of %s written to %s", exportFormat, pkg, exportOutput)
+	return nil
+}
+
+// exportMarkdown writes the README installation section of installSpec.
+func exportMarkdown(installSpec *spec.InstallSpec) error {
+	opts := readme.Options{ScriptURL: exportScriptURL, HomebrewTap: exportTap, ScoopBucket: exportBucket}
+	if exportScriptURL != "" {
+		scriptBytes, err := shell.Generate(installSpec)
+		if err != nil {
+			return fmt.Errorf("failed to generate installer script: %w", err)
+		}
+		if opts.OneLiner, err = shell.OneLiner(exportScriptURL, scriptBytes); err != nil {
+			return fmt.Errorf("failed to generate one-liner: %w", err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := readme.Section(&buf, installSpec, opts); err != nil {
+		return err
+	}
+	return writeExport(buf.Bytes(), installSpec.Name)
+}
+
+// exportWinget writes the winget manifests of version to the --output
+// directory, or to stdout as YAML documents.
+func exportWinget(installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset) error {
+	manifests, err := winget.Manifests(installSpec, version, assets, winget.Options{
+		PackageIdentifier: exportPackageID,
+		Publisher:         exportPublisher,
+		License:           exportLicense,
+		ShortDescription:  exportDesc,
+		Homepage:          exportHomepage,
+	})
+	if err != nil {
+		return err
+	}
+	if exportOutput == "" || exportOutput == "-" {
+		for i, m := range manifests {
+			if i > 0 {
+				fmt.Println("---")
+			}
+			fmt.Print(string(m.Content))
+		}
+		return nil
+	}
+	if dryRunSkip("write %d winget manifests to %s", len(manifests), exportOutput) {
+		return nil
+	}
+	if err := os.MkdirAll(exportOutput, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", exportOutput, err)
+	}
+	for _, m := range manifests {
+		path := filepath.Join(exportOutput, m.Filename)
+		if err := os.WriteFile(path, m.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write winget manifest to %s: %w", path, err)
+		}
+		log.Infof("winget manifest written to %s", path)
+	}
+	return nil
+}
+
+// exportNPM writes the npm package of version to the --output directory. The
+// packaged installer script installs version by default.
+func exportNPM(installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset) error {
+	if exportOutput == "" || exportOutput == "-" {
+		return fmt.Errorf("--output directory is required for the npm format")
+	}
+	pinned := *installSpec
+	pinned.DefaultVersion = version
+	script, err := shell.Generate(&pinned)
+	if err != nil {
+		return fmt.Errorf("failed to generate installer script: %w", err)
+	}
+	files, err := npm.Package(installSpec, version, assets, npm.Options{
+		PackageName: exportPackageID,
+		Desc:        exportDesc,
+		License:     exportLicense,
+		Homepage:    exportHomepage,
+		Script:      script,
+	})
+	if err != nil {
+		return err
+	}
+	if dryRunSkip("write npm package of %s@%s to %s", installSpec.Name, version, exportOutput) {
+		return nil
+	}
+	for _, f := range files {
+		path := filepath.Join(exportOutput, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, f.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	log.Infof("npm package of %s@%s written to %s", installSpec.Name, version, exportOutput)
+	return nil
+}
+
+// exportedVersion returns the embedded version to export: version if set,
+// default_version if it is embedded, or else the newest embedded version.
+func exportedVersion(installSpec *spec.InstallSpec, version string) (string, error) {
+	if installSpec.Checksums == nil || len(installSpec.Checksums.EmbeddedChecksums) == 0 {
+		return "", fmt.Errorf("spec has no embedded checksums; run 'binst embed-checksums' first")
+	}
+	embedded := installSpec.Checksums.EmbeddedChecksums
+	if version != "" {
+		return version, nil
+	}
+	if _, ok := embedded[installSpec.DefaultVersion]; ok {
+		return installSpec.DefaultVersion, nil
+	}
+	newest := ""
+	for v := range embedded {
+		if newest == "" || semver.Compare("v"+installSpec.VersionFromTag(v), "v"+installSpec.VersionFromTag(newest)) > 0 {
+			newest = v
+		}
+	}
+	return newest, nil
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	// Flags specific to export command
+	exportCmd.Flags().StringVar(&exportFormat, "format", "homebrew", "Output format ("+strings.Join(exportFormats, ", ")+")")
+	exportCmd.Flags().StringVar(&exportVersion, "version", "", "Version to export (default: default_version or the newest embedded version)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "-", "Output path (use '-' for stdout)")
+	exportCmd.Flags().StringVar(&exportDesc, "desc", "", "One-line description of the package")
+	exportCmd.Flags().StringVar(&exportLicense, "license", "", "SPDX license identifier of the package")
+	exportCmd.Flags().StringVar(&exportHomepage, "homepage", "", "Homepage of the package (default: the GitHub repository URL)")
+	exportCmd.Flags().StringVar(&exportPackageID, "package-identifier", "", "winget package identifier (default: <Owner>.<Repo>), or npm package name (default: name)")
+	exportCmd.Flags().StringVar(&exportPublisher, "publisher", "", "winget publisher (default: the repository owner)")
+	exportCmd.Flags().StringVar(&exportScriptURL, "script-url", "", "markdown: URL the installer generated by 'binst gen' is published at, for a checksum-pinned one-liner")
+	exportCmd.Flags().StringVar(&exportTap, "homebrew-tap", "", "markdown: GitHub repository of the Homebrew tap, e.g. owner/homebrew-tap")
+	exportCmd.Flags().StringVar(&exportBucket, "scoop-bucket", "", "markdown: GitHub repository of the Scoop bucket, e.g. owner/scoop-bucket")
+}
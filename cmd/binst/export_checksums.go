@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for export-checksums command
+	exportChecksumsFormat string
+	exportChecksumsOutput string
+)
+
+// exportChecksumsCmd represents the export-checksums command
+var exportChecksumsCmd = &cobra.Command{
+	Use:   "export-checksums",
+	Short: "Export the embedded checksums of a binstaller configuration",
+	Long: `Writes the checksums embedded in an InstallSpec configuration file as JSON or
+CSV, e.g. for external auditing, SBOM attachment, or publishing them alongside
+releases.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgFile, err := resolveConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		installSpec, err := loadInstallSpec(cfgFile)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		if err := checksums.Export(&buf, installSpec.Checksums, exportChecksumsFormat); err != nil {
+			return err
+		}
+
+		if exportChecksumsOutput == "" || exportChecksumsOutput == "-" {
+			fmt.Print(buf.String())
+			return nil
+		}
+		if dryRunSkip("write checksums to %s", exportChecksumsOutput) {
+			return nil
+		}
+		if err := os.WriteFile(exportChecksumsOutput, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write checksums to %s: %w", exportChecksumsOutput, err)
+		}
+		log.Infof("Checksums written to %s", exportChecksumsOutput)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportChecksumsCmd)
+
+	// Flags specific to export-checksums command
+	exportChecksumsCmd.Flags().StringVar(&exportChecksumsFormat, "format", "json", "Output format ("+strings.Join(checksums.ExportFormats, ", ")+")")
+	exportChecksumsCmd.Flags().StringVarP(&exportChecksumsOutput, "output", "o", "-", "Output path (use '-' for stdout)")
+}
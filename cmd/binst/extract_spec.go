@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for extract-spec command
+	extractSpecOutput string
+)
+
+// extractSpecCmd represents the extract-spec command
+var extractSpecCmd = &cobra.Command{
+	Use:   "extract-spec <install.sh>",
+	Short: "Recover the InstallSpec from a generated installer script",
+	Long: `Extracts the InstallSpec embedded in an installer script generated with
+"binst gen --embed-spec". Use "-" to read the script from stdin.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var script []byte
+		var err error
+		if args[0] == "-" {
+			script, err = io.ReadAll(os.Stdin)
+		} else {
+			script, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read installer script %s: %w", args[0], err)
+		}
+
+		specYAML, err := shell.ExtractSpec(script)
+		if err != nil {
+			return err
+		}
+
+		if extractSpecOutput == "" || extractSpecOutput == "-" {
+			fmt.Print(string(specYAML))
+			return nil
+		}
+		if dryRunSkip("write spec to %s", extractSpecOutput) {
+			return nil
+		}
+		if err := os.WriteFile(extractSpecOutput, specYAML, 0644); err != nil {
+			return fmt.Errorf("failed to write spec file %s: %w", extractSpecOutput, err)
+		}
+		log.Infof("Spec written to %s", extractSpecOutput)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(extractSpecCmd)
+
+	// Flags specific to extract-spec command
+	extractSpecCmd.Flags().StringVarP(&extractSpecOutput, "output", "o", "-", "Output path for the extracted spec (use '-' for stdout)")
+}
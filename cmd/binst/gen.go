@@ -1,21 +1,38 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/apex/log"
-	"github.com/haya14busa/goinstaller/internal/shell" // Placeholder for script generator
+	"github.com/haya14busa/goinstaller/internal/shell" // Registers the "sh" generator
+	"github.com/haya14busa/goinstaller/pkg/generator"
 	"github.com/haya14busa/goinstaller/pkg/spec"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var (
 	// Flags for gen command
 	genOutputFile string
+	genConfigDir  string
+	genOutputDir  string
+	genParallel   int
+	genEmbedSpec  bool
+	genSplit      bool
+	genOneLiner   bool
+	genScriptURL  string
+	genTemplates  []string
+	genFormat     string
+	genTarget     string
+	genCheck      bool
+	genAll        bool
 	// Input config file is handled by the global --config flag
 )
 
@@ -24,96 +41,397 @@ var genCmd = &cobra.Command{
 	Use:   "gen",
 	Short: "Generate an installer script from an InstallSpec config file",
 	Long: `Reads an InstallSpec configuration file (e.g., .binstaller.yml) and
-generates a POSIX-compatible shell installer script.`,
+generates a POSIX-compatible shell installer script.
+
+With --config-dir, every spec file (*.yml, *.yaml) in the directory is
+processed in parallel and one installer per spec is written to --output-dir
+as <name>.install.sh, followed by a summary report. --all does the same for
+the .binstaller/ directory, where a multi-tool repository keeps one spec per
+tool; --config NAME selects a single one of them, e.g. .binstaller/NAME.yml.
+
+With --embed-spec, the spec file is embedded into the script as a comment
+block so that it can be recovered later with "binst extract-spec".
+
+A multi-tool spec (one with a "tools" list) generates one combined script
+installing every tool at its default version. With --split, one installer
+per tool is written to --output-dir as <name>.install.sh instead.
+
+With --one-liner, a "curl ... | sh" command for READMEs is printed instead
+of the script. It downloads the script from --script-url, which should be
+pinned to a commit or release, and runs it only if its sha256 matches the
+generated script. The script itself is still written to --output if it is a
+file, and must be published unchanged.
+
+With --template, Go template files are applied on top of the built-in
+installer template in order. A file with a body replaces the whole script
+(see internal/shell/template.tmpl.sh), while a file with only
+{{ define "header" }} or {{ define "footer" }} blocks injects shell code run
+right after "set -e" or after the installation, e.g. for notices or policy
+checks.
+
+--format selects the script format among the registered generators. The
+default "sh" is the POSIX shell installer; --embed-spec and --one-liner are
+only supported for it.
+
+With --target os/arch, e.g. linux/amd64, the installer is restricted to that
+platform: platform detection is pre-resolved and only the embedded checksums
+of its assets are kept, which makes a much smaller script for Dockerfiles
+and CI images. -o and -a have no effect on such installers.
+
+With --check, nothing is written: the generated installers are compared
+with the existing output files, e.g. in pre-commit hooks and CI. The exit
+status is 0 if they are up to date, 1 if any is out of date or missing, and
+2 on other errors.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		log.Info("Running gen command...")
-
-		// Determine config file path
-		cfgFile := configFile // Use the global flag value
-		if cfgFile == "" {
-			// Default detection logic if global flag is not set
-			defaultPath := ".binstaller.yml"
-			if _, err := os.Stat(defaultPath); err == nil {
-				cfgFile = defaultPath
-				log.Infof("Using default config file: %s", cfgFile)
-			} else {
-				// Try .binstaller.yaml as fallback
-				defaultPathYaml := ".binstaller.yaml"
-				if _, errYaml := os.Stat(defaultPathYaml); errYaml == nil {
-					cfgFile = defaultPathYaml
-					log.Infof("Using default config file: %s", cfgFile)
-				} else {
-					err := fmt.Errorf("config file not specified via --config and default (.binstaller.yml or .binstaller.yaml) not found")
-					log.WithError(err).Error("Config file detection failed")
-					return err
-				}
-			}
+		err := runGen()
+		if genCheck {
+			// An out of date installer is a result rather than a usage error.
+			cmd.SilenceUsage = true
+			return checkExitError(err)
 		}
-		log.Debugf("Using config file: %s", cfgFile)
+		return err
+	},
+}
 
-		// Read the InstallSpec YAML file
-		log.Debugf("Reading InstallSpec from: %s", cfgFile)
-		var yamlData []byte
-		var err error
-		if cfgFile == "-" {
-			log.Debug("Reading install spec from stdin")
-			yamlData, err = io.ReadAll(os.Stdin)
-			if err != nil {
-				log.WithError(err).Error("Failed to read install spec from stdin")
-				return fmt.Errorf("failed to read install spec from stdin: %w", err)
-			}
-		} else {
-			yamlData, err = os.ReadFile(cfgFile)
-			if err != nil {
-				log.WithError(err).Errorf("Failed to read install spec file: %s", cfgFile)
-				return fmt.Errorf("failed to read install spec file %s: %w", cfgFile, err)
-			}
+// runGen generates the installers selected by the gen flags.
+func runGen() error {
+	log.Info("Running gen command...")
+	g, err := generator.Lookup(genFormat)
+	if err != nil {
+		return err
+	}
+	if genFormat != "sh" && (genEmbedSpec || genOneLiner) {
+		return fmt.Errorf("--embed-spec and --one-liner are only supported for --format=sh")
+	}
+	templates, err := readTemplates(genTemplates)
+	if err != nil {
+		return err
+	}
+	gen := installerGenerator{ScriptGenerator: g, opts: generator.Options{Templates: templates}}
+	if genTarget != "" {
+		target, err := spec.ParsePlatform(genTarget)
+		if err != nil {
+			return err
 		}
+		gen.opts.Target = &target
+	}
 
-		// Unmarshal YAML into InstallSpec struct
-		log.Debug("Unmarshalling InstallSpec YAML")
-		var installSpec spec.InstallSpec
-		err = yaml.Unmarshal(yamlData, &installSpec)
+	if genAll {
+		if genConfigDir != "" {
+			return fmt.Errorf("--all and --config-dir cannot be used together")
+		}
+		genConfigDir = configDirName
+	}
+	if genConfigDir != "" {
+		if genOneLiner {
+			return fmt.Errorf("--one-liner cannot be used with --config-dir")
+		}
+		return runBatchGen(genConfigDir, genOutputDir, genParallel, gen)
+	}
+
+	// Determine config file path
+	cfgFile, err := resolveConfigFile(configFile)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Using config file: %s", cfgFile)
+	if genOneLiner && genScriptURL == "" {
+		return fmt.Errorf("--script-url is required with --one-liner")
+	}
+
+	return generateInstaller(cfgFile, genOutputFile, gen)
+}
+
+// errOutOfDate is returned with --check for an output file that differs from
+// the generated installer.
+var errOutOfDate = errors.New("out of date")
+
+// checkExitError maps the result of gen --check to its exit status.
+func checkExitError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errOutOfDate):
+		return &exitError{code: 1, err: err}
+	default:
+		return &exitError{code: 2, err: err}
+	}
+}
+
+// installerGenerator is the generator of the --format and the options to
+// generate with.
+type installerGenerator struct {
+	generator.ScriptGenerator
+	opts generator.Options
+}
+
+func (g installerGenerator) generate(installSpec *spec.InstallSpec) ([]byte, error) {
+	return g.Generate(installSpec, g.opts)
+}
+
+func (g installerGenerator) generateMulti(tools []spec.InstallSpec) ([]byte, error) {
+	multi, ok := g.ScriptGenerator.(generator.MultiGenerator)
+	if !ok {
+		return nil, fmt.Errorf("format %s does not support multi-tool specs, use --split", genFormat)
+	}
+	return multi.GenerateMulti(tools, g.opts)
+}
+
+// readTemplates reads the user-supplied installer templates.
+func readTemplates(paths []string) ([]generator.Template, error) {
+	templates := make([]generator.Template, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
 		if err != nil {
-			log.WithError(err).Errorf("Failed to unmarshal install spec YAML from: %s", cfgFile)
-			return fmt.Errorf("failed to unmarshal install spec YAML from %s: %w", cfgFile, err)
+			return nil, fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+		templates = append(templates, generator.Template{Name: path, Text: string(b)})
+	}
+	return templates, nil
+}
+
+// generateInstaller generates an installer script from cfgFile and writes it
+// to outputFile ("" or "-" for stdout).
+func generateInstaller(cfgFile, outputFile string, gen installerGenerator) error {
+	yamlData, err := readSpecFile(cfgFile)
+	if err != nil {
+		return err
+	}
+	installSpec, err := parseInstallSpec(yamlData, cfgFile)
+	if err != nil {
+		return err
+	}
+	if len(installSpec.Tools) > 0 && genSplit {
+		return generateSplitInstallers(installSpec.Tools, genOutputDir, gen)
+	}
+
+	// Generate the script using the generator of the format
+	log.Info("Generating installer script...")
+	var scriptBytes []byte
+	if len(installSpec.Tools) > 0 {
+		log.Infof("Combining installers of %d tools", len(installSpec.Tools))
+		scriptBytes, err = gen.generateMulti(installSpec.Tools)
+	} else {
+		scriptBytes, err = gen.generate(installSpec) // Pass the loaded spec
+	}
+	if err != nil {
+		log.WithError(err).Error("Failed to generate installer script")
+		return fmt.Errorf("failed to generate installer script: %w", err)
+	}
+	log.Debug("Installer script generated successfully")
+	if genEmbedSpec {
+		scriptBytes = shell.EmbedSpec(scriptBytes, yamlData)
+	}
+	if genOneLiner {
+		return writeOneLiner(scriptBytes, outputFile)
+	}
+	return writeInstaller(scriptBytes, outputFile)
+}
+
+// writeOneLiner prints the checksum-pinned one-liner of scriptBytes and
+// writes the script to outputFile unless it is stdout.
+func writeOneLiner(scriptBytes []byte, outputFile string) error {
+	oneLiner, err := shell.OneLiner(genScriptURL, scriptBytes)
+	if err != nil {
+		return fmt.Errorf("failed to generate one-liner: %w", err)
+	}
+	if outputFile != "" && outputFile != "-" {
+		if err := writeInstaller(scriptBytes, outputFile); err != nil {
+			return err
 		}
+	}
+	fmt.Println(oneLiner)
+	return nil
+}
 
-		// Generate the script using the internal shell generator
-		log.Info("Generating installer script...")
-		scriptBytes, err := shell.Generate(&installSpec) // Pass the loaded spec
+// generateSplitInstallers writes one installer per tool of a multi-tool spec
+// to outputDir as <name>.install<ext>, e.g. <name>.install.sh.
+func generateSplitInstallers(tools []spec.InstallSpec, outputDir string, gen installerGenerator) error {
+	if outputDir == "" {
+		return fmt.Errorf("--output-dir is required with --split")
+	}
+	for i := range tools {
+		tool := &tools[i]
+		scriptBytes, err := gen.generate(tool)
 		if err != nil {
-			log.WithError(err).Error("Failed to generate installer script")
-			return fmt.Errorf("failed to generate installer script: %w", err)
-		}
-		log.Debug("Installer script generated successfully")
-
-		// Write the output script
-		if genOutputFile == "" || genOutputFile == "-" {
-			// Write to stdout
-			log.Debug("Writing installer script to stdout")
-			fmt.Print(string(scriptBytes))
-			log.Info("Installer script written to stdout")
-		} else {
-			// Write to file
-			log.Infof("Writing installer script to file: %s", genOutputFile)
-			// Ensure the output directory exists
-			outputDir := filepath.Dir(genOutputFile)
-			if err := os.MkdirAll(outputDir, 0755); err != nil {
-				log.WithError(err).Errorf("Failed to create output directory: %s", outputDir)
-				return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+			return fmt.Errorf("failed to generate installer for tool %s: %w", tool.Name, err)
+		}
+		if genEmbedSpec {
+			toolYAML, err := spec.Marshal(tool)
+			if err != nil {
+				return fmt.Errorf("failed to marshal spec of tool %s: %w", tool.Name, err)
 			}
+			scriptBytes = shell.EmbedSpec(scriptBytes, toolYAML)
+		}
+		if err := writeInstaller(scriptBytes, filepath.Join(outputDir, tool.Name+".install"+gen.Extension())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			err = os.WriteFile(genOutputFile, scriptBytes, 0755) // Make script executable
-			if err != nil {
-				log.WithError(err).Errorf("Failed to write installer script to file: %s", genOutputFile)
-				return fmt.Errorf("failed to write installer script to file %s: %w", genOutputFile, err)
+// writeInstaller writes scriptBytes to outputFile ("" or "-" for stdout).
+// With --check, outputFile is compared with scriptBytes instead.
+func writeInstaller(scriptBytes []byte, outputFile string) error {
+	if genCheck {
+		return checkInstaller(scriptBytes, outputFile)
+	}
+	// Write the output script
+	if outputFile == "" || outputFile == "-" {
+		// Write to stdout
+		log.Debug("Writing installer script to stdout")
+		fmt.Print(string(scriptBytes))
+		log.Info("Installer script written to stdout")
+	} else {
+		// Write to file
+		if dryRunSkip("write installer script to %s", outputFile) {
+			return nil
+		}
+		log.Infof("Writing installer script to file: %s", outputFile)
+		// Ensure the output directory exists
+		outputDir := filepath.Dir(outputFile)
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			log.WithError(err).Errorf("Failed to create output directory: %s", outputDir)
+			return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+		}
+
+		err := os.WriteFile(outputFile, scriptBytes, 0755) // Make script executable
+		if err != nil {
+			log.WithError(err).Errorf("Failed to write installer script to file: %s", outputFile)
+			return fmt.Errorf("failed to write installer script to file %s: %w", outputFile, err)
+		}
+		log.Infof("Installer script successfully written to %s", outputFile)
+	}
+
+	return nil
+}
+
+// checkInstaller returns errOutOfDate unless outputFile holds scriptBytes.
+func checkInstaller(scriptBytes []byte, outputFile string) error {
+	if outputFile == "" || outputFile == "-" {
+		return fmt.Errorf("--check requires an output file")
+	}
+	current, err := os.ReadFile(outputFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s does not exist: %w", outputFile, errOutOfDate)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read installer script %s: %w", outputFile, err)
+	}
+	if !bytes.Equal(current, scriptBytes) {
+		return fmt.Errorf("%s is %w; run binst gen to regenerate it", outputFile, errOutOfDate)
+	}
+	log.Infof("%s is up to date", outputFile)
+	return nil
+}
+
+// batchGenResult is the outcome of generating one installer in batch mode.
+type batchGenResult struct {
+	Config string
+	Output string
+	Err    error
+}
+
+// runBatchGen generates one installer per spec file in configDir.
+func runBatchGen(configDir, outputDir string, parallel int, gen installerGenerator) error {
+	if outputDir == "" {
+		return fmt.Errorf("--output-dir is required with --config-dir")
+	}
+	configs, err := listSpecFiles(configDir)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return fmt.Errorf("no spec files (*.yml, *.yaml) found in %s", configDir)
+	}
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	// Resolve every output path up front so that specs which map to the same
+	// installer (e.g. foo.yml and foo.yaml) fail before anything is written.
+	results := make([]batchGenResult, len(configs))
+	outputs := make(map[string]string, len(configs))
+	for i, cfg := range configs {
+		output := filepath.Join(outputDir, specBaseName(cfg)+".install"+gen.Extension())
+		if prev, ok := outputs[output]; ok {
+			return fmt.Errorf("%s and %s would both be generated to %s", prev, cfg, output)
+		}
+		outputs[output] = cfg
+		results[i] = batchGenResult{Config: cfg, Output: output}
+	}
+	log.Infof("Generating %d installer(s) from %s with parallelism %d", len(configs), configDir, parallel)
+
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(r *batchGenResult) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			r.Err = generateInstaller(r.Config, r.Output, gen)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	failed, outOfDate := 0, 0
+	fmt.Println("Summary:")
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			if errors.Is(r.Err, errOutOfDate) {
+				outOfDate++
 			}
-			log.Infof("Installer script successfully written to %s", genOutputFile)
+			fmt.Printf("  FAIL %s: %v\n", r.Config, r.Err)
+			continue
 		}
+		fmt.Printf("  OK   %s -> %s\n", r.Config, r.Output)
+	}
+	verb := "generated"
+	if genCheck {
+		verb = "up to date"
+	}
+	fmt.Printf("%d %s, %d failed\n", len(results)-failed, verb, failed)
 
-		return nil
-	},
+	if failed > 0 && failed == outOfDate {
+		return fmt.Errorf("%d of %d installer(s) are %w", failed, len(results), errOutOfDate)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to generate %d of %d installer(s)", failed, len(results))
+	}
+	return nil
+}
+
+// listSpecFiles returns the sorted spec file paths (*.yml, *.yaml) in dir.
+func listSpecFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory %s: %w", dir, err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if ext := filepath.Ext(e.Name()); ext == ".yml" || ext == ".yaml" {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// specBaseName returns the tool name part of a spec file name, e.g.
+// "reviewdog" for "reviewdog.binstaller.yml".
+func specBaseName(path string) string {
+	name := filepath.Base(path)
+	for _, suffix := range []string{".binstaller.yml", ".binstaller.yaml", ".yml", ".yaml"} {
+		if strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix)
+		}
+	}
+	return name
 }
 
 func init() {
@@ -122,4 +440,16 @@ func init() {
 	// Flags specific to gen command
 	// Input config file is handled by the global --config flag
 	genCmd.Flags().StringVarP(&genOutputFile, "output", "o", "-", "Output path for the generated script (use '-' for stdout)")
+	genCmd.Flags().StringVar(&genConfigDir, "config-dir", "", "Directory of spec files to generate installers for (batch mode)")
+	genCmd.Flags().StringVar(&genOutputDir, "output-dir", "", "Directory to write generated installers to in batch mode")
+	genCmd.Flags().BoolVar(&genAll, "all", false, "Generate installers for every spec in the "+configDirName+"/ directory (batch mode)")
+	genCmd.Flags().BoolVar(&genEmbedSpec, "embed-spec", false, "Embed the spec file into the generated script as a comment block")
+	genCmd.Flags().BoolVar(&genSplit, "split", false, "Write one installer per tool of a multi-tool spec to --output-dir")
+	genCmd.Flags().BoolVar(&genOneLiner, "one-liner", false, "Print a checksum-pinned curl | sh command for the script instead of the script")
+	genCmd.Flags().StringVar(&genScriptURL, "script-url", "", "URL the script is published at, pinned to a commit or release (used with --one-liner)")
+	genCmd.Flags().StringVar(&genFormat, "format", "sh", "Script format ("+strings.Join(generator.Formats(), ", ")+")")
+	genCmd.Flags().StringArrayVar(&genTemplates, "template", nil, "Installer template file applied on top of the built-in template (repeatable)")
+	genCmd.Flags().StringVar(&genTarget, "target", "", "Generate an installer for one platform only, e.g. linux/amd64")
+	genCmd.Flags().BoolVar(&genCheck, "check", false, "Exit with status 1 if the output files are not up to date instead of writing them")
+	genCmd.Flags().IntVarP(&genParallel, "parallel", "j", runtime.NumCPU(), "Number of installers to generate in parallel in batch mode")
 }
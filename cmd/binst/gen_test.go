@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/generator"
+)
+
+const testSpec = `schema: v1
+repo: owner/tool
+asset:
+  template: ${NAME}_${OS}_${ARCH}.tar.gz
+`
+
+func testInstallerGenerator(t *testing.T) installerGenerator {
+	t.Helper()
+	g, err := generator.Lookup("sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return installerGenerator{ScriptGenerator: g}
+}
+
+func writeSpecs(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(testSpec), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunBatchGen(t *testing.T) {
+	configDir, outputDir := t.TempDir(), t.TempDir()
+	writeSpecs(t, configDir, "foo.binstaller.yml", "bar.yaml")
+
+	if err := runBatchGen(configDir, outputDir, 2, testInstallerGenerator(t)); err != nil {
+		t.Fatalf("runBatchGen() error = %v", err)
+	}
+	for _, name := range []string{"foo.install.sh", "bar.install.sh"} {
+		b, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(b), "#!/bin/sh\n") {
+			t.Errorf("%s is not an installer script", name)
+		}
+	}
+}
+
+func TestRunBatchGenDuplicateOutput(t *testing.T) {
+	configDir, outputDir := t.TempDir(), t.TempDir()
+	writeSpecs(t, configDir, "a.yml", "foo.yml", "foo.yaml")
+
+	err := runBatchGen(configDir, outputDir, 1, testInstallerGenerator(t))
+	if err == nil || !strings.Contains(err.Error(), "foo.install.sh") {
+		t.Fatalf("runBatchGen() error = %v, want duplicate output error", err)
+	}
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("runBatchGen() wrote %d file(s), want none", len(entries))
+	}
+}
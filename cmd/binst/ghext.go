@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+// ghExtensionName is the executable name of binst installed as a GitHub CLI
+// extension, which gh runs for "gh binst ...".
+const ghExtensionName = "gh-binst"
+
+// ghExtension reports whether binst runs as a GitHub CLI extension.
+var ghExtension = isGHExtension(os.Args[0])
+
+func isGHExtension(arg0 string) bool {
+	return strings.TrimSuffix(filepath.Base(arg0), ".exe") == ghExtensionName
+}
+
+func init() {
+	if ghExtension {
+		rootCmd.Annotations = map[string]string{cobra.CommandDisplayNameAnnotation: "gh binst"}
+	}
+}
+
+// setupGHExtension reuses the gh login for GitHub API requests when
+// GITHUB_TOKEN is not set.
+func setupGHExtension() {
+	if os.Getenv("GITHUB_TOKEN") != "" {
+		return
+	}
+	token := os.Getenv("GH_TOKEN")
+	if token == "" {
+		token = ghAuthToken(ghHost(githubAPIURL))
+	}
+	if token == "" {
+		log.Debug("No gh login found; GitHub API requests are unauthenticated")
+		return
+	}
+	os.Setenv("GITHUB_TOKEN", token)
+}
+
+// ghAuthToken returns the token of the gh login for host, or the default host
+// if host is empty.
+func ghAuthToken(host string) string {
+	args := []string{"auth", "token"}
+	if host != "" {
+		args = append(args, "--hostname", host)
+	}
+	out, err := exec.CommandContext(context.Background(), "gh", args...).Output()
+	if err != nil {
+		log.Debugf("gh auth token failed: %v", err)
+		return ""
+	}
+	return string(bytes.TrimSpace(out))
+}
+
+// ghHost returns the host gh knows a GitHub API URL by, e.g. ghe.example.com
+// for https://ghe.example.com/api/v3.
+func ghHost(apiURL string) string {
+	if apiURL == "" {
+		return os.Getenv("GH_HOST")
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return strings.TrimPrefix(u.Host, "api.")
+}
+
+// defaultRepo returns repo, or when running as a GitHub CLI extension, the
+// repository of the origin remote of the current directory.
+func defaultRepo(repo string) string {
+	if repo != "" || !ghExtension {
+		return repo
+	}
+	out, err := exec.CommandContext(context.Background(), "git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		log.Debugf("Failed to get the origin remote: %v", err)
+		return ""
+	}
+	repo = parseRemoteRepo(string(bytes.TrimSpace(out)))
+	if repo != "" {
+		log.Infof("Using repository %s from the origin remote", repo)
+	}
+	return repo
+}
+
+// parseRemoteRepo returns owner/repo of a git remote URL such as
+// https://github.com/owner/repo.git or git@github.com:owner/repo.git.
+func parseRemoteRepo(remote string) string {
+	var path string
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	} else if _, p, ok := strings.Cut(remote, ":"); ok && !strings.Contains(remote, "://") {
+		path = p // scp-like syntax: [user@]host:owner/repo.git
+	} else {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(path, ".git"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
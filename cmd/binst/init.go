@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/apex/log"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
 	"github.com/haya14busa/goinstaller/pkg/datasource"
+	"github.com/haya14busa/goinstaller/pkg/spec"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -21,6 +24,19 @@ var (
 	initCommitSHA    string
 	initAssetPattern string
 	initOutputFile   string
+	initIgnoreAssets []string
+	initPreferAssets []string
+	initForce        bool
+	initMerge        bool
+)
+
+// initWriteMode is how init writes the spec to an existing output file.
+type initWriteMode int
+
+const (
+	initAbort initWriteMode = iota
+	initOverwrite
+	initMergeInto
 )
 
 // initCmd represents the init command
@@ -28,11 +44,36 @@ var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Generate an InstallSpec config file from various sources",
 	Long: `Initializes a binstaller configuration file (.binstaller.yml) by detecting
-settings from a source like a GoReleaser config file or a GitHub repository.`,
+settings from a source like a GoReleaser config file or a GitHub repository.
+
+An existing output file is not overwritten unless confirmed at the prompt or
+with --force (or --yes). With --merge, the detected settings are merged into
+the existing file instead: fields only in the file, such as embedded
+checksums, and the comments of unchanged fields are preserved.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		log.Infof("Running init command...")
+		if initForce && initMerge {
+			return fmt.Errorf("--force and --merge cannot be used together")
+		}
+
+		// Decide what to do with an existing output file before detecting
+		// the spec, so that init fails fast without network calls
+		writeMode := initOverwrite
+		if initOutputFile != "" && initOutputFile != "-" && !dryRun {
+			if _, err := os.Stat(initOutputFile); err == nil {
+				if writeMode, err = initExistingFileMode(initOutputFile); err != nil {
+					return err
+				}
+				if writeMode == initAbort {
+					return fmt.Errorf("aborted: %s was not modified", initOutputFile)
+				}
+			}
+		}
 
 		var adapter datasource.SourceAdapter
+		if initSourceFile == "" {
+			initRepo = defaultRepo(initRepo)
+		}
 
 		switch initSource {
 		case "goreleaser":
@@ -41,9 +82,10 @@ settings from a source like a GoReleaser config file or a GitHub repository.`,
 				initSourceFile, // filePath
 				initCommitSHA,  // commit
 				initName,       // nameOverride
+				githubAPIURL,   // githubAPIURL
 			)
 		case "github":
-			adapter = datasource.NewGitHubAdapter(initRepo)
+			adapter = datasource.NewGitHubAdapter(initRepo, githubAPIURL, initIgnoreAssets, initPreferAssets)
 		case "aqua":
 			// Use --file for registry YAML, or stdin if not specified
 			switch initSourceFile {
@@ -80,14 +122,24 @@ settings from a source like a GoReleaser config file or a GitHub repository.`,
 			log.WithError(err).Error("Failed to detect install spec")
 			return fmt.Errorf("failed to detect install spec: %w", err)
 		}
+		logSpec(installSpec)
 		if installSpec.Schema == "" {
 			installSpec.Schema = "v1"
 		}
+		if githubAPIURL != "" && installSpec.GitHubAPIURL == "" && installSpec.GitHubBaseURL == "" {
+			installSpec.GitHubAPIURL = strings.TrimSuffix(githubAPIURL, "/")
+		}
+		if len(initIgnoreAssets) > 0 {
+			installSpec.Asset.IgnoreAssets = initIgnoreAssets
+		}
+		if len(initPreferAssets) > 0 {
+			installSpec.Asset.PreferAssets = initPreferAssets
+		}
 		log.Info("Successfully detected InstallSpec")
 
 		// Marshal the spec to YAML
 		log.Debug("Marshalling InstallSpec to YAML")
-		yamlData, err := yaml.Marshal(installSpec)
+		yamlData, err := spec.Marshal(installSpec)
 		if err != nil {
 			log.WithError(err).Error("Failed to marshal InstallSpec to YAML")
 			return fmt.Errorf("failed to marshal install spec to YAML: %w", err)
@@ -101,6 +153,15 @@ settings from a source like a GoReleaser config file or a GitHub repository.`,
 			log.Info("InstallSpec YAML written to stdout")
 		} else {
 			// Write to file
+			if dryRunSkip("write InstallSpec YAML to %s", initOutputFile) {
+				return nil
+			}
+			if writeMode == initMergeInto {
+				log.Infof("Merging InstallSpec into existing file: %s", initOutputFile)
+				if yamlData, err = mergeInitSpec(initOutputFile, installSpec); err != nil {
+					return fmt.Errorf("failed to merge install spec into %s: %w", initOutputFile, err)
+				}
+			}
 			log.Infof("Writing InstallSpec YAML to file: %s", initOutputFile)
 			err = os.WriteFile(initOutputFile, yamlData, 0644) // Use standard file permissions
 			if err != nil {
@@ -123,12 +184,83 @@ func init() {
 
 	// Optional flags (depending on source)
 	initCmd.Flags().StringVar(&initSourceFile, "file", "", "Path to source file (e.g., .goreleaser.yml)")
-	initCmd.Flags().StringVar(&initRepo, "repo", "", "GitHub repository (owner/repo) for source 'goreleaser'/'github', or explicit override")
+	initCmd.Flags().StringVar(&initRepo, "repo", "", "GitHub repository (owner/repo) for source 'goreleaser'/'github', or explicit override (default for gh binst: origin remote)")
 	initCmd.Flags().StringVar(&initName, "name", "", "Explicit binary name override")
 	initCmd.Flags().StringVar(&initTag, "tag", "", "Release tag/ref to inspect (for source 'github')")
 	initCmd.Flags().StringVar(&initCommitSHA, "sha", "", "Commit SHA for source 'goreleaser'")
 	initCmd.Flags().StringVar(&initAssetPattern, "asset-pattern", "", "Template for asset file names (for source 'cli')") // TODO: Implement usage
+	initCmd.Flags().StringSliceVar(&initIgnoreAssets, "ignore-assets", nil, "Glob patterns of asset filenames to ignore (e.g. '*.sbom,*-debug*')")
+	initCmd.Flags().StringSliceVar(&initPreferAssets, "prefer-assets", nil, "Glob patterns of asset filenames to keep even if they match --ignore-assets")
 	initCmd.Flags().StringVarP(&initOutputFile, "output", "o", ".binstaller.yml", "Write spec to file instead of stdout (use '-' for stdout)")
 
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite the output file if it exists")
+	initCmd.Flags().BoolVar(&initMerge, "merge", false, "Merge the detected spec into the existing output file, preserving its other fields and comments")
+
 	// TODO: Add dependencies between flags (e.g., --file required if --source goreleaser and no --repo)
 }
+
+// initExistingFileMode returns how to write the spec to the existing
+// outputFile: as told by --merge, --force or --yes, or else as answered at the
+// prompt.
+func initExistingFileMode(outputFile string) (initWriteMode, error) {
+	switch {
+	case initMerge:
+		return initMergeInto, nil
+	case initForce || yes:
+		return initOverwrite, nil
+	case !isInteractive():
+		return initAbort, fmt.Errorf("%s already exists: use --force to overwrite it or --merge to update it", outputFile)
+	}
+	answer, err := prompt(fmt.Sprintf("%s already exists. Overwrite it (y), merge the detected spec into it (m) or abort (N)? ", outputFile))
+	if err != nil {
+		return initAbort, err
+	}
+	switch answer {
+	case "y", "yes":
+		return initOverwrite, nil
+	case "m", "merge":
+		return initMergeInto, nil
+	}
+	return initAbort, nil
+}
+
+// mergeInitSpec returns the spec file outputFile with the detected
+// installSpec merged into it. Fields only in the file and the comments of
+// unchanged fields are preserved.
+func mergeInitSpec(outputFile string, installSpec *spec.InstallSpec) ([]byte, error) {
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := spec.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Schema != "" {
+		// Keep the schema the file is written in
+		installSpec.Schema = existing.Schema
+	}
+	file, err := parser.ParseBytes(data, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	detected, err := spec.Marshal(installSpec)
+	if err != nil {
+		return nil, err
+	}
+	var values yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(detected, &values, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	if err := spec.MergeValue(file, "", values); err != nil {
+		return nil, err
+	}
+	out := file.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	if _, err := spec.Parse([]byte(out)); err != nil {
+		return nil, fmt.Errorf("merged spec is invalid: %w", err)
+	}
+	return []byte(out), nil
+}
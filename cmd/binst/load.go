@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+// configDirName is the directory holding one spec file per tool of a
+// multi-tool repository, e.g. .binstaller/reviewdog.yml.
+const configDirName = ".binstaller"
+
+// resolveConfigFile returns the config file path to use. A cfgFile that is
+// not a file may name a spec in the .binstaller directory, e.g. "reviewdog"
+// for .binstaller/reviewdog.yml. If cfgFile is empty, it falls back to
+// .binstaller.yml or .binstaller.yaml in the current directory, or to the
+// only spec in the .binstaller directory.
+func resolveConfigFile(cfgFile string) (string, error) {
+	if cfgFile != "" {
+		return resolveNamedConfigFile(cfgFile), nil
+	}
+	for _, defaultPath := range []string{".binstaller.yml", ".binstaller.yaml"} {
+		if _, err := os.Stat(defaultPath); err == nil {
+			log.Infof("Using default config file: %s", defaultPath)
+			return defaultPath, nil
+		}
+	}
+	if files, err := listSpecFiles(configDirName); err == nil {
+		switch len(files) {
+		case 0:
+		case 1:
+			log.Infof("Using default config file: %s", files[0])
+			return files[0], nil
+		default:
+			names := make([]string, len(files))
+			for i, f := range files {
+				names[i] = specBaseName(f)
+			}
+			err := fmt.Errorf("%s/ has %d specs (%s): select one with --config NAME", configDirName, len(files), strings.Join(names, ", "))
+			log.WithError(err).Error("Config file detection failed")
+			return "", err
+		}
+	}
+	err := binsterr.Wrap(binsterr.ErrNotFound, fmt.Errorf("config file not specified via --config and default (.binstaller.yml, .binstaller.yaml or %s/) not found", configDirName))
+	log.WithError(err).Error("Config file detection failed")
+	return "", err
+}
+
+// resolveNamedConfigFile returns the spec in the .binstaller directory named
+// name if name is not a file itself, or else name.
+func resolveNamedConfigFile(name string) string {
+	if name == "-" || strings.ContainsAny(name, `/\`) {
+		return name
+	}
+	if _, err := os.Stat(name); err == nil {
+		return name
+	}
+	files, err := listSpecFiles(configDirName)
+	if err != nil {
+		return name
+	}
+	for _, f := range files {
+		if specBaseName(f) == name || filepath.Base(f) == name {
+			log.Infof("Using config file: %s", f)
+			return f
+		}
+	}
+	return name
+}
+
+// configDirSpecFiles returns the spec files in the .binstaller directory.
+func configDirSpecFiles() ([]string, error) {
+	files, err := listSpecFiles(configDirName)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no spec files (*.yml, *.yaml) found in %s", configDirName)
+	}
+	return files, nil
+}
+
+// loadInstallSpec reads and unmarshals the InstallSpec from cfgFile.
+// A cfgFile of "-" reads the spec from stdin.
+func loadInstallSpec(cfgFile string) (*spec.InstallSpec, error) {
+	yamlData, err := readSpecFile(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	return parseInstallSpec(yamlData, cfgFile)
+}
+
+// readSpecFile reads the spec YAML from cfgFile ("-" for stdin) and merges
+// it onto the base specs named by "extends".
+func readSpecFile(cfgFile string) ([]byte, error) {
+	yamlData, err := readRawSpecFile(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := spec.ResolveExtends(yamlData, cfgFile)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to resolve extends of: %s", cfgFile)
+		return nil, fmt.Errorf("failed to resolve extends of %s: %w", cfgFile, err)
+	}
+	return resolved, nil
+}
+
+// readRawSpecFile reads the raw spec YAML from cfgFile ("-" for stdin).
+func readRawSpecFile(cfgFile string) ([]byte, error) {
+	log.Debugf("Reading InstallSpec from: %s", cfgFile)
+	if cfgFile == "-" {
+		log.Debug("Reading install spec from stdin")
+		yamlData, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.WithError(err).Error("Failed to read install spec from stdin")
+			return nil, fmt.Errorf("failed to read install spec from stdin: %w", err)
+		}
+		return yamlData, nil
+	}
+	yamlData, err := os.ReadFile(cfgFile)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to read install spec file: %s", cfgFile)
+		err = fmt.Errorf("failed to read install spec file %s: %w", cfgFile, err)
+		if errors.Is(err, fs.ErrNotExist) {
+			err = binsterr.Wrap(binsterr.ErrNotFound, err)
+		}
+		return nil, err
+	}
+	return yamlData, nil
+}
+
+// parseInstallSpec unmarshals spec YAML read from cfgFile.
+func parseInstallSpec(yamlData []byte, cfgFile string) (*spec.InstallSpec, error) {
+	log.Debug("Unmarshalling InstallSpec YAML")
+	installSpec, err := spec.Parse(yamlData)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to unmarshal install spec YAML from: %s", cfgFile)
+		return nil, fmt.Errorf("failed to unmarshal install spec YAML from %s: %w", cfgFile, err)
+	}
+	return installSpec, nil
+}
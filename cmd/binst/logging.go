@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/cli"
+	"github.com/apex/log/handlers/json"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+// setupLogging configures the log handler from --log-format and the level
+// from --verbose and --quiet. JSON logs have the stable fields command, repo,
+// version and asset, so that CI systems and log aggregators can parse them.
+func setupLogging(cmd *cobra.Command) error {
+	switch logFormat {
+	case "text":
+		log.SetHandler(cli.Default)
+	case "json":
+		log.SetHandler(json.New(os.Stderr))
+	default:
+		return fmt.Errorf("invalid --log-format %q: must be text or json", logFormat)
+	}
+	if verbose {
+		log.SetLevel(log.DebugLevel)
+		log.Debugf("Verbose logging enabled")
+	} else if quiet {
+		log.SetLevel(log.ErrorLevel) // Or FatalLevel? ErrorLevel allows warnings.
+	} else {
+		log.SetLevel(log.InfoLevel)
+	}
+	// Fields must be added last: the handler and level can only be set on
+	// the root logger
+	setLogField("command", cmd.Name())
+	return nil
+}
+
+// setLogField adds the field key to every following JSON log entry, unless
+// value is empty. Text logs leave them out as they are the same for the
+// whole run.
+func setLogField(key, value string) {
+	if logFormat == "json" && value != "" {
+		log.Log = log.Log.WithField(key, value)
+	}
+}
+
+// logSpec adds the repo of installSpec to the following log entries of a
+// command working on a single spec.
+func logSpec(installSpec *spec.InstallSpec) {
+	setLogField("repo", installSpec.Repo)
+}
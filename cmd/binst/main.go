@@ -1,10 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/apex/log"
-	"github.com/apex/log/handlers/cli"
+	"github.com/haya14busa/goinstaller/internal/httpclient"
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +24,10 @@ var (
 	verbose    bool
 	quiet      bool
 	yes        bool
-	timeout    string // TODO: Parse duration
+	timeout    string
+	logFormat  string
+
+	githubAPIURL string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -29,41 +37,82 @@ var rootCmd = &cobra.Command{
 	Long: `binstaller (binst) is a tool to generate installer scripts or directly
 install binaries based on an InstallSpec configuration file.
 
-It supports generating the spec from sources like GoReleaser config or GitHub releases.`,
+It supports generating the spec from sources like GoReleaser config or GitHub releases.
+
+Flags can also be set with environment variables, which the command line
+overrides: BINSTALLER_CONFIG, BINSTALLER_GITHUB_API_URL, BINSTALLER_TIMEOUT,
+BINSTALLER_LOG_FORMAT, BINSTALLER_DRY_RUN, BINSTALLER_VERBOSE,
+BINSTALLER_QUIET, BINSTALLER_YES and, for doctor, BINSTALLER_BIN (--bin-dir).`,
 	Version: fmt.Sprintf("%s (commit: %s)", version, commit),
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		log.SetHandler(cli.Default)
-		if verbose {
-			log.SetLevel(log.DebugLevel)
-			log.Debugf("Verbose logging enabled")
-		} else if quiet {
-			log.SetLevel(log.ErrorLevel) // Or FatalLevel? ErrorLevel allows warnings.
-		} else {
-			log.SetLevel(log.InfoLevel)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyEnvFlags(cmd); err != nil {
+			return err
+		}
+		if err := setupLogging(cmd); err != nil {
+			return err
+		}
+		if ghExtension {
+			setupGHExtension()
 		}
 		log.Debugf("Config file: %s", configFile)
-		// TODO: Parse timeout duration
+		d, err := parseTimeout(timeout)
+		if err != nil {
+			return err
+		}
+		httpclient.Timeout = d
+		return nil
 	},
 }
 
+// parseTimeout parses the --timeout flag: a duration like 30s or 2m, or a
+// number of seconds as BINSTALLER_TIMEOUT of installer scripts. 0 disables
+// the timeout.
+func parseTimeout(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if secs, aerr := strconv.Atoi(s); aerr == nil {
+		d, err = time.Duration(secs)*time.Second, nil
+	}
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid --timeout %q: use a duration like 30s or 2m, or 0 to disable", s)
+	}
+	return d, nil
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		log.WithError(exitErr.err).Error("command execution failed")
+		os.Exit(exitErr.code)
+	}
 	if err != nil {
-		log.WithError(err).Fatal("command execution failed")
-		// os.Exit(1) // log.Fatal exits automatically
+		log.WithError(err).Error("command execution failed")
+		os.Exit(binsterr.ExitCode(err))
 	}
 }
 
+// exitError makes the command exit with code instead of 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
 func init() {
 	// Add global flags
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to InstallSpec config file (default: .binstaller.yml)")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print actions without performing network or FS writes")
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Increase log verbosity")
 	rootCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "Suppress progress output")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format: text or json")
 	rootCmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "Assume \"yes\" on interactive prompts")
-	rootCmd.PersistentFlags().StringVar(&timeout, "timeout", "5m", "HTTP / process timeout (e.g. 30s, 2m)")
+	rootCmd.PersistentFlags().StringVar(&timeout, "timeout", "5m", "HTTP request timeout (e.g. 30s, 2m, or seconds; 0 to disable)")
+	rootCmd.PersistentFlags().StringVar(&githubAPIURL, "github-api-url", "", "GitHub API URL for GitHub Enterprise Server (e.g. https://ghe.example.com/api/v3)")
 
 	// Mark 'config' flag for auto-detection? Cobra doesn't directly support this.
 	// We'll handle default detection logic within commands if the flag is empty.
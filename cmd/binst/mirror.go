@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/publish"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for mirror command
+	mirrorVersion string
+	mirrorBucket  string
+	mirrorURL     string
+	mirrorOutput  string
+	mirrorMode    string
+	mirrorFile    string
+)
+
+// mirrorCmd represents the mirror command
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Mirror the release assets of a version to object storage",
+	Long: `Downloads the assets of a release, verifies them, and uploads them together
+with a manifest (` + checksums.MirrorManifestName + `) to object storage, for
+air-gapped or egress-restricted environments.
+
+The assets are verified against the checksums embedded in the spec for the
+version, or against those acquired with --mode otherwise. The checksum files of
+the release and their signatures are mirrored too.
+
+--bucket is an s3:// or gs:// URL, uploaded to with the aws or gcloud CLI, or
+a local directory. Files are stored under <REPO>/releases/download/<TAG>/, so
+the mirror also works with the --base-url option of installer scripts.
+
+A variant of the spec is written to --output (stdout by default). It downloads
+from the mirror, installs the mirrored version by default, and embeds the
+verified checksums. --url sets the URL the mirror is served at; it defaults to
+the public URL of S3 and GCS buckets.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("Running mirror command...")
+
+		if mirrorBucket == "" {
+			return fmt.Errorf("--bucket is required")
+		}
+		bucket := &publish.Bucket{URL: mirrorBucket}
+		baseURL := strings.TrimSuffix(mirrorURL, "/")
+		if baseURL == "" {
+			u, err := bucket.PublicURL()
+			if err != nil {
+				return err
+			}
+			baseURL = u
+		}
+
+		var mode checksums.EmbedMode
+		switch mirrorMode {
+		case "download":
+			mode = checksums.EmbedModeDownload
+		case "checksum-file":
+			mode = checksums.EmbedModeChecksumFile
+		case "provenance":
+			mode = checksums.EmbedModeProvenance
+		default:
+			return fmt.Errorf("invalid mode: %s. Must be one of: download, checksum-file, provenance", mirrorMode)
+		}
+		if mode == checksums.EmbedModeChecksumFile && mirrorFile == "" {
+			return fmt.Errorf("--file flag is required for checksum-file mode")
+		}
+
+		cfgFile, err := resolveConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		yamlData, err := readSpecFile(cfgFile)
+		if err != nil {
+			return err
+		}
+		installSpec, err := parseInstallSpec(yamlData, cfgFile)
+		if err != nil {
+			return err
+		}
+		logSpec(installSpec)
+		if githubAPIURL != "" {
+			installSpec.GitHubAPIURL = githubAPIURL
+		}
+		if installSpec.Repo == "" {
+			return fmt.Errorf("repo is required to mirror releases")
+		}
+
+		stage, err := os.MkdirTemp("", "binstaller-mirror")
+		if err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer os.RemoveAll(stage)
+
+		embedder := &checksums.Embedder{
+			Mode:         mode,
+			Version:      mirrorVersion,
+			Spec:         installSpec,
+			ChecksumFile: mirrorFile,
+			CacheDir:     resolveDownloadCacheDir(""),
+		}
+		manifest, err := embedder.Mirror(stage)
+		if err != nil {
+			log.WithError(err).Error("Failed to mirror release")
+			return err
+		}
+		setLogField("version", manifest.Tag)
+
+		if !dryRunSkip("upload %d files to %s", len(manifest.Files)+1, mirrorBucket) {
+			log.Infof("Uploading %d files to %s", len(manifest.Files)+1, mirrorBucket)
+			if err := bucket.Upload(context.Background(), stage); err != nil {
+				log.WithError(err).Error("Failed to upload mirror")
+				return err
+			}
+			log.Infof("Mirrored %s@%s to %s/%s", installSpec.Repo, manifest.Tag, baseURL, checksums.MirrorPath(installSpec.Repo, manifest.Tag))
+		}
+
+		variant, err := mirrorSpec(yamlData, installSpec, manifest, baseURL)
+		if err != nil {
+			return fmt.Errorf("failed to create mirror spec: %w", err)
+		}
+		if mirrorOutput == "" || mirrorOutput == "-" {
+			fmt.Print(variant)
+			return nil
+		}
+		if dryRunSkip("write mirror spec to %s", mirrorOutput) {
+			return nil
+		}
+		if err := os.WriteFile(mirrorOutput, []byte(variant), 0644); err != nil {
+			return fmt.Errorf("failed to write mirror spec to %s: %w", mirrorOutput, err)
+		}
+		log.Infof("Mirror spec written to %s", mirrorOutput)
+		return nil
+	},
+}
+
+// mirrorSpec returns the spec YAML edited to download the mirrored release
+// from baseURL by default, with the verified checksums embedded.
+func mirrorSpec(yamlData []byte, installSpec *spec.InstallSpec, manifest *checksums.MirrorManifest, baseURL string) (string, error) {
+	file, err := parser.ParseBytes(yamlData, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	urlTemplate := baseURL + "/${REPO}/releases/download/${TAG}/${ASSET_FILENAME}"
+	if err := spec.SetValue(file, "asset.download_url_template", urlTemplate); err != nil {
+		return "", err
+	}
+	if err := spec.SetValue(file, "default_version", manifest.Tag); err != nil {
+		return "", err
+	}
+	installSpec.Checksums.EmbeddedChecksums = mergeEmbedded(installSpec.Checksums, manifest)
+	p, err := yaml.PathString("$.checksums")
+	if err != nil {
+		return "", err
+	}
+	node, err := yaml.ValueToNode(spec.ChecksumConfig{EmbeddedChecksums: installSpec.Checksums.EmbeddedChecksums})
+	if err != nil {
+		return "", err
+	}
+	if err := p.MergeFromNode(file, node); err != nil {
+		return "", err
+	}
+	out := file.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return out, nil
+}
+
+// mergeEmbedded replaces the embedded checksums of the mirrored version with
+// the verified ones of the manifest.
+func mergeEmbedded(c *spec.ChecksumConfig, manifest *checksums.MirrorManifest) map[string][]spec.EmbeddedChecksum {
+	embedded := c.EmbeddedChecksums
+	if embedded == nil {
+		embedded = make(map[string][]spec.EmbeddedChecksum)
+	}
+	verified := manifest.Checksums()
+	for i := range verified {
+		if verified[i].Algorithm == c.EntryAlgorithm(spec.EmbeddedChecksum{}) {
+			verified[i].Algorithm = ""
+		}
+	}
+	embedded[manifest.Tag] = verified
+	return embedded
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorCmd)
+
+	// Flags specific to mirror command
+	mirrorCmd.Flags().StringVar(&mirrorVersion, "version", "latest", "Version to mirror")
+	mirrorCmd.Flags().StringVar(&mirrorBucket, "bucket", "", "Destination: s3://bucket/prefix, gs://bucket/prefix or a local directory")
+	mirrorCmd.Flags().StringVar(&mirrorURL, "url", "", "URL the mirror is served at (default: public URL of the bucket)")
+	mirrorCmd.Flags().StringVarP(&mirrorOutput, "output", "o", "-", "Output path of the mirror spec (use '-' for stdout)")
+	mirrorCmd.Flags().StringVar(&mirrorMode, "mode", "download", "Checksums acquisition mode when none are embedded (download, checksum-file, provenance)")
+	mirrorCmd.Flags().StringVar(&mirrorFile, "file", "", "Checksum file for checksum-file mode")
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/internal/shell"
+	"github.com/haya14busa/goinstaller/pkg/publish"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for publish command
+	publishScript  string
+	publishName    string
+	publishRepo    string
+	publishRelease string
+	publishPin     bool
+	publishGHPages bool
+	publishBranch  string
+	publishRemote  string
+	publishSign    bool
+	publishKey     string
+)
+
+// publishCmd represents the publish command
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Publish the generated installer script to a GitHub release or gh-pages",
+	Long: `Publishes an installer script so users can download the canonical install.sh.
+
+The script is generated from the InstallSpec config file unless --script is
+given. It can be uploaded as an asset of an existing GitHub release
+(--release, requires GITHUB_TOKEN) and/or committed to a gh-pages branch of
+the git repository in the current directory (--gh-pages).
+
+The script uploaded to a release installs that release by default, so every
+release ships a matching installer; --pin=false uploads the script as is.
+Scripts given with --script are never changed. A sha256sum checksum file
+(<name>.sha256) is published next to the script.
+
+With --sign, the script is signed with cosign (keyless unless --key is set)
+and the .sig (and .pem) files are published next to it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("Running publish command...")
+
+		if publishRelease == "" && !publishGHPages {
+			return fmt.Errorf("at least one of --release or --gh-pages must be specified")
+		}
+		setLogField("version", publishRelease)
+
+		var script, releaseScript []byte
+		repo := publishRepo
+		apiURL := githubAPIURL
+		if publishScript != "" {
+			data, err := os.ReadFile(publishScript)
+			if err != nil {
+				return fmt.Errorf("failed to read installer script %s: %w", publishScript, err)
+			}
+			script = data
+		} else {
+			cfgFile, err := resolveConfigFile(configFile)
+			if err != nil {
+				return err
+			}
+			installSpec, err := loadInstallSpec(cfgFile)
+			if err != nil {
+				return err
+			}
+			logSpec(installSpec)
+			script, err = shell.Generate(installSpec)
+			if err != nil {
+				return fmt.Errorf("failed to generate installer script: %w", err)
+			}
+			if publishRelease != "" && publishPin {
+				// The release asset installs the release it is attached to
+				pinned := *installSpec
+				pinned.DefaultVersion = publishRelease
+				releaseScript, err = shell.Generate(&pinned)
+				if err != nil {
+					return fmt.Errorf("failed to generate installer script for %s: %w", publishRelease, err)
+				}
+			}
+			if repo == "" {
+				repo = installSpec.Repo
+			}
+			if apiURL == "" && (installSpec.GitHubAPIURL != "" || installSpec.GitHubBaseURL != "") {
+				apiURL = installSpec.GitHubAPI()
+			}
+		}
+		if releaseScript == nil {
+			releaseScript = script
+		}
+
+		ctx := context.Background()
+		var releaseFiles map[string][]byte
+		if publishRelease != "" {
+			repo = defaultRepo(repo)
+			if repo == "" {
+				return fmt.Errorf("--repo is required when publishing a release asset without a config file")
+			}
+			files, err := publishFiles(ctx, releaseScript)
+			if err != nil {
+				return err
+			}
+			releaseFiles = files
+			uploader := &publish.ReleaseUploader{
+				Repo:   repo,
+				Tag:    publishRelease,
+				Token:  os.Getenv("GITHUB_TOKEN"),
+				APIURL: apiURL,
+			}
+			names := slices.Sorted(maps.Keys(files))
+			if !dryRunSkip("upload %s to release %s of %s", strings.Join(names, ", "), publishRelease, repo) {
+				for _, name := range names {
+					if err := uploader.Upload(ctx, name, files[name]); err != nil {
+						log.WithError(err).Error("Failed to upload release asset")
+						return err
+					}
+				}
+				log.Infof("Published %s to release %s of %s", publishName, publishRelease, repo)
+			}
+		}
+
+		if publishGHPages {
+			files := releaseFiles
+			if files == nil || !bytes.Equal(script, releaseScript) {
+				var err error
+				files, err = publishFiles(ctx, script)
+				if err != nil {
+					return err
+				}
+			}
+			pages := &publish.GHPages{
+				Remote:  publishRemote,
+				Branch:  publishBranch,
+				Message: fmt.Sprintf("Update %s", publishName),
+			}
+			if !dryRunSkip("push %s to branch %s of %s", strings.Join(slices.Sorted(maps.Keys(files)), ", "), publishBranch, publishRemote) {
+				if err := pages.Publish(ctx, files); err != nil {
+					log.WithError(err).Error("Failed to publish to gh-pages")
+					return err
+				}
+				log.Infof("Published %s to branch %s", publishName, publishBranch)
+			}
+		}
+
+		return nil
+	},
+}
+
+// publishFiles returns the files published for script: the script itself, its
+// sha256 checksum file and, with --sign, its cosign signature.
+func publishFiles(ctx context.Context, script []byte) (map[string][]byte, error) {
+	files := map[string][]byte{
+		publishName:             script,
+		publishName + ".sha256": publish.SHA256Sum(publishName, script),
+	}
+	if publishSign && !dryRunSkip("sign %s with cosign", publishName) {
+		log.Info("Signing installer script with cosign")
+		sig, cert, err := publish.CosignSignBlob(ctx, script, publishKey)
+		if err != nil {
+			return nil, err
+		}
+		files[publishName+".sig"] = sig
+		if cert != nil {
+			files[publishName+".pem"] = cert
+		}
+	}
+	return files, nil
+}
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+
+	// Flags specific to publish command
+	publishCmd.Flags().StringVar(&publishScript, "script", "", "Path to an already generated installer script (default: generate from config)")
+	publishCmd.Flags().StringVar(&publishName, "name", "install.sh", "File name of the published script")
+	publishCmd.Flags().StringVar(&publishRepo, "repo", "", "GitHub repository (owner/repo) to publish to (default: repo in config)")
+	publishCmd.Flags().StringVar(&publishRelease, "release", "", "Upload the script as an asset of the release with this tag")
+	publishCmd.Flags().BoolVar(&publishPin, "pin", true, "Make the script uploaded with --release install that release by default")
+	publishCmd.Flags().BoolVar(&publishGHPages, "gh-pages", false, "Commit the script to the gh-pages branch and push it")
+	publishCmd.Flags().StringVar(&publishBranch, "branch", "gh-pages", "Branch to commit to with --gh-pages")
+	publishCmd.Flags().StringVar(&publishRemote, "remote", "origin", "Git remote to push to with --gh-pages")
+	publishCmd.Flags().BoolVar(&publishSign, "sign", false, "Sign the script with cosign and publish the signature")
+	publishCmd.Flags().StringVar(&publishKey, "key", "", "Cosign private key for --sign (default: keyless)")
+}
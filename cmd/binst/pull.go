@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/pkg/publish"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for pull command
+	pullOutputDir string
+	pullInsecure  bool
+)
+
+// pullCmd represents the pull command
+var pullCmd = &cobra.Command{
+	Use:   "pull <registry/repository:tag|registry/repository@digest>",
+	Short: "Pull an InstallSpec pushed with binst push from an OCI registry",
+	Long: `Downloads the files of an artifact pushed with "binst push" (the spec
+and, if included, the installer script) into --output-dir. Every file is
+verified against the artifact's manifest, and the digest of the artifact is
+printed to stdout. Pull by digest to get exactly the artifact you reviewed
+or verified the signature of.
+
+Credentials are read from the Docker config, as with "docker login".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("Running pull command...")
+		registry := &publish.OCIRegistry{Insecure: pullInsecure}
+		artifact, err := registry.Pull(context.Background(), args[0])
+		if err != nil {
+			log.WithError(err).Error("Failed to pull from the registry")
+			return err
+		}
+
+		if dryRunSkip("write %d files to %s", len(artifact.Files), pullOutputDir) {
+			fmt.Println(artifact.Digest)
+			return nil
+		}
+		if err := os.MkdirAll(pullOutputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory %s: %w", pullOutputDir, err)
+		}
+		for _, f := range artifact.Files {
+			mode := os.FileMode(0644)
+			if f.MediaType == publish.OCIScriptMediaType {
+				mode = 0755
+			}
+			path := filepath.Join(pullOutputDir, f.Name)
+			if err := os.WriteFile(path, f.Data, mode); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			log.Infof("Wrote %s", path)
+		}
+		fmt.Println(artifact.Digest)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+
+	// Flags specific to pull command
+	pullCmd.Flags().StringVarP(&pullOutputDir, "output-dir", "o", ".", "Directory to write the pulled files to")
+	pullCmd.Flags().BoolVar(&pullInsecure, "insecure", false, "Allow pulling from a registry over plain HTTP")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/internal/shell"
+	"github.com/haya14busa/goinstaller/pkg/publish"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for push command
+	pushIncludeScript bool
+	pushScriptName    string
+	pushInsecure      bool
+)
+
+// pushCmd represents the push command
+var pushCmd = &cobra.Command{
+	Use:   "push <registry/repository:tag>",
+	Short: "Push the InstallSpec to an OCI registry",
+	Long: `Stores the InstallSpec config file as an OCI artifact in a container
+registry, e.g. ghcr.io/owner/binstaller-specs/tool:v1.0.0, so that specs can
+be versioned and shared inside an organization. Base specs named by
+"extends" are merged into the pushed spec, so it is self-contained.
+
+With --include-script, the installer script generated from the spec is
+stored in the same artifact.
+
+The digest of the artifact is printed to stdout. Pin it with
+"binst pull <repository>@<digest>" and sign it, e.g. with
+"cosign sign <repository>@<digest>". Pushing the same files again yields the
+same digest.
+
+Credentials are read from the Docker config, as with "docker login".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Info("Running push command...")
+		cfgFile, err := resolveConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+		specYAML, err := readSpecFile(cfgFile)
+		if err != nil {
+			return err
+		}
+		installSpec, err := parseInstallSpec(specYAML, cfgFile)
+		if err != nil {
+			return err
+		}
+		logSpec(installSpec)
+
+		specName := filepath.Base(cfgFile)
+		if cfgFile == "-" {
+			specName = ".binstaller.yml"
+		}
+		files := []publish.OCIFile{{Name: specName, MediaType: publish.OCISpecMediaType, Data: specYAML}}
+		if pushIncludeScript {
+			script, err := shell.Generate(installSpec)
+			if err != nil {
+				return fmt.Errorf("failed to generate installer script: %w", err)
+			}
+			files = append(files, publish.OCIFile{Name: pushScriptName, MediaType: publish.OCIScriptMediaType, Data: script})
+		}
+		var annotations map[string]string
+		if installSpec.Repo != "" {
+			annotations = map[string]string{"org.opencontainers.image.source": installSpec.GitHubBase() + "/" + installSpec.Repo}
+		}
+
+		if dryRunSkip("push %d files to %s", len(files), args[0]) {
+			return nil
+		}
+		registry := &publish.OCIRegistry{Insecure: pushInsecure}
+		digest, err := registry.Push(context.Background(), args[0], files, annotations)
+		if err != nil {
+			log.WithError(err).Error("Failed to push to the registry")
+			return err
+		}
+		log.Infof("Pushed %s to %s", specName, args[0])
+		fmt.Println(digest)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pushCmd)
+
+	// Flags specific to push command
+	pushCmd.Flags().BoolVar(&pushIncludeScript, "include-script", false, "Also push the installer script generated from the spec")
+	pushCmd.Flags().StringVar(&pushScriptName, "script-name", "install.sh", "File name of the installer script with --include-script")
+	pushCmd.Flags().BoolVar(&pushInsecure, "insecure", false, "Allow pushing to a registry over plain HTTP")
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/internal/shell"
+	"github.com/haya14busa/goinstaller/pkg/publish"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags for sign command
+	signMethod          string
+	signKey             string
+	signPublicKey       string
+	signScriptURL       string
+	signSignatureURL    string
+	signOutputSignature string
+)
+
+// signCmd represents the sign command
+var signCmd = &cobra.Command{
+	Use:   "sign <install.sh>",
+	Short: "Sign a generated installer script with cosign or minisign",
+	Long: `Adds a self-verification header to an installer script generated by
+"binst gen" and writes a detached signature of the result with cosign or
+minisign. Publish the signature at --signature-url.
+
+Users running the script with BINSTALLER_VERIFY_SCRIPT=1 get its signature
+verified before anything else runs. A script piped to sh downloads a fresh
+copy from --url, verifies it, and runs that copy instead.`,
+	Example: `  binst sign install.sh --method minisign --key minisign.key --public-key minisign.pub \
+    --url https://example.com/install.sh`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scriptPath := args[0]
+		suffix := ".sig"
+		if signMethod == "minisign" {
+			suffix = ".minisig"
+		}
+		signatureURL := signSignatureURL
+		if signatureURL == "" {
+			if signScriptURL == "" {
+				return fmt.Errorf("--signature-url or --url is required")
+			}
+			signatureURL = signScriptURL + suffix
+		}
+		output := signOutputSignature
+		if output == "" {
+			output = scriptPath + suffix
+		}
+
+		publicKey, err := readSignPublicKey(signPublicKey, signMethod)
+		if err != nil {
+			return err
+		}
+		script, err := os.ReadFile(scriptPath)
+		if err != nil {
+			return fmt.Errorf("failed to read installer script %s: %w", scriptPath, err)
+		}
+		signed, err := shell.AddScriptSignature(script, shell.ScriptSignature{
+			Method:       signMethod,
+			PublicKey:    publicKey,
+			ScriptURL:    signScriptURL,
+			SignatureURL: signatureURL,
+		})
+		if err != nil {
+			return err
+		}
+		if dryRunSkip("write signed script to %s and its %s signature to %s", scriptPath, signMethod, output) {
+			return nil
+		}
+		if err := os.WriteFile(scriptPath, signed, 0755); err != nil {
+			return fmt.Errorf("failed to write installer script %s: %w", scriptPath, err)
+		}
+
+		// Let cosign and minisign prompt for the login or key password
+		publish.Interactive = true
+		var sig []byte
+		ctx := context.Background()
+		switch signMethod {
+		case "minisign":
+			sig, err = publish.MinisignSign(ctx, signed, signKey)
+		default:
+			sig, _, err = publish.CosignSignBlob(ctx, signed, signKey)
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(output, sig, 0644); err != nil {
+			return fmt.Errorf("failed to write signature %s: %w", output, err)
+		}
+		log.Infof("Signature written to %s; publish it at %s", output, signatureURL)
+		return nil
+	},
+}
+
+// readSignPublicKey reads the public key embedded in the signed script. For
+// minisign, the untrusted comment line of the key file is dropped.
+func readSignPublicKey(path, method string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read public key %s: %w", path, err)
+	}
+	key := strings.TrimSpace(string(data))
+	if method == "minisign" {
+		lines := strings.Split(key, "\n")
+		key = strings.TrimSpace(lines[len(lines)-1])
+	}
+	return key, nil
+}
+
+func init() {
+	rootCmd.AddCommand(signCmd)
+
+	// Flags specific to sign command
+	signCmd.Flags().StringVar(&signMethod, "method", "cosign", "Signing tool (cosign, minisign)")
+	signCmd.Flags().StringVar(&signKey, "key", "", "Private key used to sign the script")
+	signCmd.Flags().StringVar(&signPublicKey, "public-key", "", "Public key file embedded in the script to verify the signature")
+	signCmd.Flags().StringVar(&signScriptURL, "url", "", "URL the script is published at, needed to verify scripts piped to sh")
+	signCmd.Flags().StringVar(&signSignatureURL, "signature-url", "", "URL the signature is published at (default: --url with a .sig or .minisig suffix)")
+	signCmd.Flags().StringVar(&signOutputSignature, "output-signature", "", "Output path of the signature (default: the script path with a .sig or .minisig suffix)")
+	_ = signCmd.MarkFlagRequired("key")
+	_ = signCmd.MarkFlagRequired("public-key")
+}
@@ -0,0 +1,41 @@
+package devserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffLines(t *testing.T) {
+	got := DiffLines("a\nb\nc", "a\nc\nd")
+	want := []DiffLine{
+		{Op: DiffEqual, Text: "a"},
+		{Op: DiffDelete, Text: "b"},
+		{Op: DiffEqual, Text: "c"},
+		{Op: DiffInsert, Text: "d"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DiffLines() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHighlightLine(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   `if [ -n "$X" ]; then # check`,
+			want: `<span class="k">if</span> [ -n <span class="s">&#34;$X&#34;</span> ]; <span class="k">then</span> <span class="c"># check</span>`,
+		},
+		{
+			in:   `echo ${NAME}<x`,
+			want: `echo <span class="v">${NAME}</span>&lt;x`,
+		},
+	}
+	for _, tt := range tests {
+		if got := highlightLine(tt.in); got != tt.want {
+			t.Errorf("highlightLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package devserver
+
+import (
+	"html"
+	"html/template"
+	"strings"
+)
+
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"case": true, "esac": true, "for": true, "while": true, "until": true,
+	"do": true, "done": true, "in": true, "return": true, "exit": true,
+	"local": true, "shift": true, "set": true, "trap": true,
+}
+
+// Highlight returns the script as HTML lines with comments, strings,
+// variables and keywords wrapped in spans (classes c, s, v and k). It is a
+// lightweight tokenizer meant for previews, not a full shell parser.
+func Highlight(script string) []template.HTML {
+	lines := strings.Split(strings.TrimSuffix(script, "\n"), "\n")
+	out := make([]template.HTML, len(lines))
+	for i, line := range lines {
+		out[i] = template.HTML(highlightLine(line))
+	}
+	return out
+}
+
+func highlightLine(line string) string {
+	var b strings.Builder
+	span := func(class, text string) {
+		b.WriteString(`<span class="` + class + `">` + html.EscapeString(text) + `</span>`)
+	}
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			span("c", line[i:])
+			return b.String()
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(line[i+1:], c)
+			if end < 0 {
+				span("s", line[i:])
+				return b.String()
+			}
+			span("s", line[i:i+end+2])
+			i += end + 2
+		case c == '$':
+			j := i + 1
+			if j < len(line) && line[j] == '{' {
+				if end := strings.IndexByte(line[j:], '}'); end >= 0 {
+					j += end + 1
+				} else {
+					j = len(line)
+				}
+			} else {
+				for j < len(line) && isWordChar(line[j]) {
+					j++
+				}
+			}
+			span("v", line[i:j])
+			i = j
+		case isWordChar(c):
+			j := i
+			for j < len(line) && isWordChar(line[j]) {
+				j++
+			}
+			word := line[i:j]
+			if shellKeywords[word] && (i == 0 || !isWordChar(line[i-1])) {
+				span("k", word)
+			} else {
+				b.WriteString(html.EscapeString(word))
+			}
+			i = j
+		default:
+			b.WriteString(html.EscapeString(string(c)))
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,286 @@
+// Package devserver implements a local preview server for installer template
+// development. It re-renders the installer whenever a watched file changes and
+// serves the result with syntax highlighting and a diff to the previous render.
+package devserver
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// Server renders an installer script and serves it to the browser, reloading
+// the page whenever the rendered output changes.
+type Server struct {
+	// Render produces the installer script.
+	Render func() ([]byte, error)
+	// WatchPaths are files or directories whose changes trigger a re-render.
+	// Directories are watched non-recursively.
+	WatchPaths []string
+	// Interval is how often watched paths are polled. Defaults to 500ms.
+	Interval time.Duration
+
+	mu          sync.Mutex
+	current     *render
+	previous    *render
+	subscribers map[chan struct{}]struct{}
+}
+
+type render struct {
+	Script []byte
+	Err    error
+	At     time.Time
+}
+
+// Run renders once, then serves on addr and watches for changes until ctx is
+// canceled.
+func (s *Server) Run(ctx context.Context, addr string) error {
+	s.rerender()
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	srv := &http.Server{Handler: s.Handler()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	go s.watch(ctx)
+
+	log.Infof("Serving installer preview at http://%s", ln.Addr())
+	if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler serving the preview page ("/"), the raw
+// script ("/install.sh") and the change notification stream ("/events").
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/install.sh", s.handleScript)
+	mux.HandleFunc("/events", s.handleEvents)
+	return mux
+}
+
+func (s *Server) snapshot() (cur, prev *render) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current, s.previous
+}
+
+// rerender renders the script and notifies subscribers if the output changed.
+func (s *Server) rerender() {
+	script, err := s.Render()
+	r := &render{Script: script, Err: err, At: time.Now()}
+	if err != nil {
+		log.WithError(err).Error("Failed to render installer")
+	} else {
+		log.Info("Rendered installer")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil && s.current.Err == nil && err == nil && string(s.current.Script) == string(script) {
+		return
+	}
+	if s.current != nil && s.current.Err == nil {
+		s.previous = s.current
+	}
+	s.current = r
+	for ch := range s.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// watch polls WatchPaths and re-renders when any of them change.
+func (s *Server) watch(ctx context.Context) {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 500 * time.Millisecond
+	}
+	last := s.fingerprint()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if fp := s.fingerprint(); fp != last {
+				last = fp
+				log.Debug("Change detected, re-rendering")
+				s.rerender()
+			}
+		}
+	}
+}
+
+// fingerprint summarizes the modification time and size of all watched files.
+func (s *Server) fingerprint() string {
+	var files []string
+	for _, p := range s.WatchPaths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			files = append(files, p)
+			continue
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				files = append(files, filepath.Join(p, e.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+
+	var b strings.Builder
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			fmt.Fprintf(&b, "%s:missing\n", f)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d\n", f, fi.ModTime().UnixNano(), fi.Size())
+	}
+	return b.String()
+}
+
+func (s *Server) handleScript(w http.ResponseWriter, r *http.Request) {
+	cur, _ := s.snapshot()
+	if cur == nil || cur.Err != nil {
+		http.Error(w, "installer failed to render", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/x-shellscript; charset=utf-8")
+	_, _ = w.Write(cur.Script)
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[chan struct{}]struct{})
+	}
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ch:
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	cur, prev := s.snapshot()
+	data := indexData{}
+	if cur != nil {
+		data.RenderedAt = cur.At.Format(time.TimeOnly)
+		if cur.Err != nil {
+			data.Error = cur.Err.Error()
+		} else {
+			data.Script = Highlight(string(cur.Script))
+			if prev != nil {
+				data.Diff = DiffLines(string(prev.Script), string(cur.Script))
+				data.HasDiff = hasChanges(data.Diff)
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := indexTemplate.Execute(w, data); err != nil {
+		log.WithError(err).Error("Failed to render preview page")
+	}
+}
+
+type indexData struct {
+	RenderedAt string
+	Error      string
+	Script     []template.HTML
+	Diff       []DiffLine
+	HasDiff    bool
+}
+
+func hasChanges(diff []DiffLine) bool {
+	for _, l := range diff {
+		if l.Op != DiffEqual {
+			return true
+		}
+	}
+	return false
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>binst dev</title>
+<style>
+body { font-family: sans-serif; margin: 1em; background: #fafafa; }
+pre { background: #fff; border: 1px solid #ddd; padding: .5em; overflow-x: auto; counter-reset: line; }
+pre span.l { display: block; }
+pre span.l::before { counter-increment: line; content: counter(line); display: inline-block; width: 4em; color: #aaa; }
+.error { color: #b00; white-space: pre-wrap; }
+.c { color: #6a737d; } .s { color: #032f62; } .v { color: #e36209; } .k { color: #d73a49; font-weight: bold; }
+.add { background: #e6ffed; } .del { background: #ffeef0; }
+</style>
+</head>
+<body>
+<h1>binst dev</h1>
+<p>Rendered at {{ .RenderedAt }} &middot; <a href="/install.sh">install.sh</a></p>
+{{ if .Error }}<h2>Render error</h2><pre class="error">{{ .Error }}</pre>{{ end }}
+{{ if .HasDiff }}<h2>Diff to last render</h2>
+<pre>{{ range .Diff }}{{ if eq .Op "+" }}<div class="add">+{{ .Text }}</div>{{ else if eq .Op "-" }}<div class="del">-{{ .Text }}</div>{{ end }}{{ end }}</pre>{{ end }}
+{{ if .Script }}<h2>install.sh</h2>
+<pre>{{ range .Script }}<span class="l">{{ . }}</span>{{ end }}</pre>{{ end }}
+<script>
+new EventSource("/events").onmessage = function() { location.reload(); };
+</script>
+</body>
+</html>
+`))
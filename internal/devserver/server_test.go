@@ -0,0 +1,118 @@
+package devserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, h http.Handler, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestServerHandler(t *testing.T) {
+	s := &Server{Render: func() ([]byte, error) { return []byte("#!/bin/sh\necho hello\n"), nil }}
+	s.rerender()
+	h := s.Handler()
+
+	code, body := get(t, h, "/install.sh")
+	if code != http.StatusOK || body != "#!/bin/sh\necho hello\n" {
+		t.Errorf("GET /install.sh = %d %q", code, body)
+	}
+	code, body = get(t, h, "/")
+	if code != http.StatusOK || !strings.Contains(body, `<span class="l">echo hello</span>`) {
+		t.Errorf("GET / = %d, body does not contain the highlighted script:\n%s", code, body)
+	}
+	if strings.Contains(body, "Render error") || strings.Contains(body, "Diff to last render") {
+		t.Errorf("GET / shows an error or diff on the first render:\n%s", body)
+	}
+	if code, _ := get(t, h, "/missing"); code != http.StatusNotFound {
+		t.Errorf("GET /missing = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestServerHandlerRenderError(t *testing.T) {
+	s := &Server{Render: func() ([]byte, error) { return nil, errors.New("template: bad <action>") }}
+	s.rerender()
+	h := s.Handler()
+
+	if code, _ := get(t, h, "/install.sh"); code != http.StatusInternalServerError {
+		t.Errorf("GET /install.sh = %d, want %d", code, http.StatusInternalServerError)
+	}
+	code, body := get(t, h, "/")
+	if code != http.StatusOK || !strings.Contains(body, "template: bad &lt;action&gt;") {
+		t.Errorf("GET / = %d, body does not contain the escaped render error:\n%s", code, body)
+	}
+}
+
+func TestServerRerendersOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yml")
+	if err := os.WriteFile(path, []byte("echo v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		Render:     func() ([]byte, error) { return os.ReadFile(path) },
+		WatchPaths: []string{filepath.Dir(path)},
+		Interval:   10 * time.Millisecond,
+	}
+	s.rerender()
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("GET /events Content-Type = %q", ct)
+	}
+
+	go s.watch(ctx)
+	// Give the watcher its initial fingerprint before changing the file.
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("echo v2 changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading /events: %v", err)
+	}
+	if line != "data: reload\n" {
+		t.Errorf("event = %q, want %q", line, "data: reload\n")
+	}
+
+	scriptResp, err := http.Get(srv.URL + "/install.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer scriptResp.Body.Close()
+	b, _ := io.ReadAll(scriptResp.Body)
+	if string(b) != "echo v2 changed\n" {
+		t.Errorf("GET /install.sh = %q after change", b)
+	}
+	_, body := get(t, s.Handler(), "/")
+	for _, want := range []string{`<div class="del">-echo v1</div>`, `<div class="add">+echo v2 changed</div>`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("GET / does not contain %q:\n%s", want, body)
+		}
+	}
+}
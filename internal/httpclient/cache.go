@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// Download downloads rawURL to the file dest. With a cache directory, the
+// file is served from the cache if its ETag is unchanged.
+func (c *Client) Download(rawURL, accept, dest string) error {
+	req, err := c.NewRequest(rawURL, accept)
+	if err != nil {
+		return err
+	}
+	cachePath := c.cachePath(rawURL)
+	if cachePath != "" {
+		if etag, ok := cachedETag(cachePath); ok {
+			req.Header.Set("If-None-Match", etag)
+		}
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cachePath != "" {
+		log.Debugf("Using cached %s", rawURL)
+		return copyFile(cachePath, dest)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return StatusError(resp, fmt.Errorf("bad status: %s", resp.Status))
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+
+	if etag := resp.Header.Get("ETag"); cachePath != "" && etag != "" {
+		if err := storeCache(cachePath, etag, dest); err != nil {
+			log.Debugf("Failed to cache %s: %v", rawURL, err)
+		}
+	}
+	return nil
+}
+
+// cachePath returns the cache file of url, or "" if caching is disabled.
+func (c *Client) cachePath(url string) string {
+	if c.CacheDir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.CacheDir, hex.EncodeToString(sum[:]))
+}
+
+// cachedETag returns the ETag of the cached file at cachePath.
+func cachedETag(cachePath string) (string, bool) {
+	if _, err := os.Stat(cachePath); err != nil {
+		return "", false
+	}
+	etag, err := os.ReadFile(cachePath + ".etag")
+	if err != nil || len(etag) == 0 {
+		return "", false
+	}
+	return strings.TrimSpace(string(etag)), true
+}
+
+// storeCache stores the downloaded file src with its etag at cachePath.
+// Files are renamed into place so that concurrent runs never read a partial
+// file.
+func storeCache(cachePath, etag, src string) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", cachePath, os.Getpid())
+	if err := copyFile(src, tmp); err != nil {
+		return err
+	}
+	// Drop the old ETag first so a stale one never pairs with new content
+	os.Remove(cachePath + ".etag")
+	if err := os.Rename(tmp, cachePath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.WriteFile(tmp, []byte(etag), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cachePath+".etag")
+}
+
+// copyFile copies the file src to dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
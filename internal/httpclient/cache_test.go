@@ -0,0 +1,42 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadCache(t *testing.T) {
+	full := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		fmt.Fprint(w, "content")
+	}))
+	defer srv.Close()
+
+	c := &Client{CacheDir: t.TempDir()}
+	for i := 0; i < 3; i++ {
+		dest := filepath.Join(t.TempDir(), "tool.tar.gz")
+		if err := c.Download(srv.URL+"/tool.tar.gz", "", dest); err != nil {
+			t.Fatalf("Download() error = %v", err)
+		}
+		got, err := os.ReadFile(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "content" {
+			t.Errorf("run %d: content = %q, want %q", i, got, "content")
+		}
+	}
+	if full != 1 {
+		t.Errorf("full downloads = %d, want 1", full)
+	}
+}
@@ -0,0 +1,188 @@
+// Package httpclient sends the HTTP requests of binst. GitHub requests are
+// authenticated with GITHUB_TOKEN (or GH_TOKEN), transient failures and rate
+// limits are retried with exponential backoff, and downloads can be cached
+// across runs by ETag.
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
+)
+
+const (
+	// maxRetries is the number of times a request is retried after a
+	// transient failure or rate limiting.
+	maxRetries = 3
+	// maxRetryWait caps how long to wait for a rate limit to reset.
+	maxRetryWait = time.Minute
+)
+
+var (
+	// Timeout bounds every request, including reading the response body.
+	// It is set from the global --timeout flag; zero means no timeout.
+	Timeout time.Duration
+
+	// retryBaseDelay is the first exponential backoff delay.
+	retryBaseDelay = time.Second
+	// sleep is replaced in tests to avoid waiting.
+	sleep = time.Sleep
+)
+
+// DefaultGitHubHosts are the hosts of github.com the GitHub token is sent to.
+var DefaultGitHubHosts = []string{"github.com", "api.github.com", "raw.githubusercontent.com"}
+
+// Token returns the GitHub token from GITHUB_TOKEN, or from GH_TOKEN as used
+// by the GitHub CLI.
+func Token() string {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	return os.Getenv("GH_TOKEN")
+}
+
+// HTTPClient returns an http.Client with the global Timeout, for libraries
+// that send requests themselves.
+func HTTPClient() *http.Client {
+	return &http.Client{Timeout: Timeout}
+}
+
+// Client sends GET requests with GitHub authentication, retries and caching.
+// The zero value is ready to use.
+type Client struct {
+	// GitHubHosts are the hosts (with port, if any) the GitHub token is sent
+	// to, so that it does not leak to custom download servers. Default:
+	// DefaultGitHubHosts.
+	GitHubHosts []string
+	// CacheDir caches downloaded files across runs, keyed by URL and
+	// revalidated with their ETag. Caching is disabled if empty.
+	CacheDir string
+}
+
+// Get sends a GET request to rawURL with the given Accept header.
+func (c *Client) Get(rawURL, accept string) (*http.Response, error) {
+	req, err := c.NewRequest(rawURL, accept)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// NewRequest creates a GET request to rawURL with the given Accept header,
+// authenticated with the GitHub token if rawURL is on a GitHub host.
+func (c *Client) NewRequest(rawURL, accept string) (*http.Request, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if token := Token(); token != "" && c.isGitHubURL(req.URL) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// isGitHubURL reports whether u points to one of the GitHubHosts.
+func (c *Client) isGitHubURL(u *url.URL) bool {
+	hosts := c.GitHubHosts
+	if hosts == nil {
+		hosts = DefaultGitHubHosts
+	}
+	return slices.ContainsFunc(hosts, func(h string) bool { return strings.EqualFold(h, u.Host) })
+}
+
+// Do sends req, retrying network errors, server errors and rate limited
+// responses with exponential backoff. Retry-After and GitHub's
+// X-RateLimit-Reset headers take precedence over the backoff delay. req must
+// not have a body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	client := HTTPClient()
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt == maxRetries {
+			if err == nil && isRateLimited(resp) {
+				log.Warnf("GitHub API rate limit exceeded for %s; set GITHUB_TOKEN to raise the limit", req.URL)
+			}
+			return resp, binsterr.Wrap(binsterr.ErrNetwork, err)
+		}
+
+		wait := delay
+		switch {
+		case err != nil:
+			log.Debugf("Request to %s failed: %v", req.URL, err)
+		case isRateLimited(resp) || resp.StatusCode >= http.StatusInternalServerError:
+			if w, ok := retryAfter(resp); ok {
+				wait = w
+			}
+			if wait > maxRetryWait {
+				log.Warnf("Rate limited by %s until %s; not waiting", req.URL.Host, time.Now().Add(wait).Format(time.RFC3339))
+				return resp, nil
+			}
+			log.Debugf("Request to %s failed with status %d", req.URL, resp.StatusCode)
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		log.Infof("Retrying %s in %s (attempt %d/%d)", req.URL, wait, attempt+1, maxRetries)
+		sleep(wait)
+		delay *= 2
+	}
+}
+
+// StatusError categorizes err, the error of the unexpected response resp:
+// binsterr.ErrNotFound for 404, binsterr.ErrRateLimited for rate limits and
+// binsterr.ErrNetwork for server errors.
+func StatusError(resp *http.Response, err error) error {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return binsterr.Wrap(binsterr.ErrNotFound, err)
+	case isRateLimited(resp):
+		return binsterr.Wrap(binsterr.ErrRateLimited, err)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return binsterr.Wrap(binsterr.ErrNetwork, err)
+	}
+	return err
+}
+
+// isRateLimited reports whether resp was rejected because of rate limiting.
+// GitHub answers 403 with X-RateLimit-Remaining: 0 for the primary rate limit
+// and 403 or 429 with Retry-After for secondary rate limits.
+func isRateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// retryAfter returns how long the server asks to wait before retrying.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(s); err == nil {
+			return time.Until(t), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Until(time.Unix(reset, 0)), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,137 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
+)
+
+func TestGetRetries(t *testing.T) {
+	var waits []time.Duration
+	origSleep := sleep
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = origSleep }()
+	t.Setenv("GITHUB_TOKEN", "test-token")
+
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch attempts {
+		case 1:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, "secondary rate limit", http.StatusForbidden)
+		default:
+			fmt.Fprint(w, "ok")
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{GitHubHosts: []string{strings.TrimPrefix(srv.URL, "http://")}}
+	resp, err := c.Get(srv.URL+"/repos/o/r/releases/latest", "")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("Get() = %d %q, want 200 \"ok\"", resp.StatusCode, body)
+	}
+	if want := []time.Duration{retryBaseDelay, 5 * time.Second}; fmt.Sprint(waits) != fmt.Sprint(want) {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+}
+
+func TestGetDoesNotSendTokenToOtherHosts(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			http.Error(w, "token leaked", http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{}
+	resp, err := c.Get(srv.URL+"/tool.tar.gz", "")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Get() status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestTokenFallsBackToGHToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "gh-token")
+	if got := Token(); got != "gh-token" {
+		t.Errorf("Token() = %q, want %q", got, "gh-token")
+	}
+	t.Setenv("GITHUB_TOKEN", "github-token")
+	if got := Token(); got != "github-token" {
+		t.Errorf("Token() = %q, want %q", got, "github-token")
+	}
+}
+
+func TestGetTimeout(t *testing.T) {
+	origSleep, origTimeout := sleep, Timeout
+	sleep = func(time.Duration) {}
+	Timeout = 50 * time.Millisecond
+	defer func() { sleep, Timeout = origSleep, origTimeout }()
+
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	c := &Client{}
+	if resp, err := c.Get(srv.URL+"/slow", ""); err == nil {
+		resp.Body.Close()
+		t.Fatal("Get() error = nil, want timeout")
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	base := errors.New("bad status")
+	tests := []struct {
+		status int
+		header http.Header
+		want   error
+	}{
+		{http.StatusNotFound, nil, binsterr.ErrNotFound},
+		{http.StatusForbidden, http.Header{"X-Ratelimit-Remaining": {"0"}}, binsterr.ErrRateLimited},
+		{http.StatusTooManyRequests, nil, binsterr.ErrRateLimited},
+		{http.StatusBadGateway, nil, binsterr.ErrNetwork},
+		{http.StatusForbidden, nil, nil},
+	}
+	for _, tt := range tests {
+		err := StatusError(&http.Response{StatusCode: tt.status, Header: tt.header}, base)
+		if !errors.Is(err, base) {
+			t.Errorf("StatusError(%d) = %v, want to wrap %v", tt.status, err, base)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("StatusError(%d) = %v, want %v", tt.status, err, tt.want)
+		}
+		if tt.want == nil && binsterr.ExitCode(err) != binsterr.ExitFailure {
+			t.Errorf("StatusError(%d) is categorized, want uncategorized", tt.status)
+		}
+	}
+}
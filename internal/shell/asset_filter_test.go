@@ -0,0 +1,100 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestGenerateIgnoreAssets(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	installSpec := &spec.InstallSpec{
+		Repo: "owner/tool",
+		Asset: spec.AssetConfig{
+			Template: "${NAME}_${OS}_${ARCH}.tar.gz",
+			Rules: []spec.AssetRule{
+				{When: spec.PlatformCondition{OS: "linux"}, FallbackTemplates: []string{"${NAME}-debug_${OS}.tar.gz", "${NAME}_${OS}.tar.gz"}},
+			},
+			IgnoreAssets: []string{"*-debug*", "*.sbom", "tool_[^a-z]*", "tool (copy)*", "[bad"},
+			PreferAssets: []string{"tool-debug-keep*"},
+		},
+	}
+	script, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	// Run the asset selection with the network stubbed out: only
+	// tool_linux.tar.gz exists in the release.
+	probed := filepath.Join(t.TempDir(), "probed")
+	prelude := `log_crit() { echo "$@" >&2; }
+log_debug() { :; }
+log_warn() { :; }
+find_embedded_checksum() { :; }
+release_url() { echo "$1"; }
+http_exists() { echo "$1" >> "$PROBED"; test "$1" = tool_linux.tar.gz; }
+` + shellFunction(t, string(script), "asset_allowed") +
+		shellFunction(t, string(script), "select_asset_candidate") +
+		shellFunction(t, string(script), "resolve_asset_filename")
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command("sh", append([]string{"-c", prelude + args[0], "sh"}, args[1:]...)...)
+		cmd.Env = append(os.Environ(), "PROBED="+probed)
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	out, err := run(`NAME=tool UNAME_OS=darwin OS=darwin ARCH=amd64 resolve_asset_filename && echo "$ASSET_FILENAME"`)
+	if err != nil {
+		t.Fatalf("resolve_asset_filename failed: %v\n%s", err, out)
+	}
+	if got, want := strings.TrimSpace(out), "tool_darwin_amd64.tar.gz"; got != want {
+		t.Errorf("resolved asset = %q, want %q", got, want)
+	}
+	if out, err := run(`NAME=tool-debug UNAME_OS=darwin OS=darwin ARCH=amd64 resolve_asset_filename`); err == nil {
+		t.Errorf("resolve_asset_filename selected an ignored asset:\n%s", out)
+	}
+
+	// Ignored fallbacks are neither probed nor selected
+	out, err = run(`NAME=tool UNAME_OS=linux OS=linux ARCH=amd64 resolve_asset_filename && echo "$ASSET_FILENAME"`)
+	if err != nil {
+		t.Fatalf("resolve_asset_filename failed: %v\n%s", err, out)
+	}
+	if got, want := strings.TrimSpace(out), "tool_linux.tar.gz"; got != want {
+		t.Errorf("selected asset = %q, want %q", got, want)
+	}
+	data, err := os.ReadFile(probed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Fields(string(data)), []string{"tool_linux_amd64.tar.gz", "tool_linux.tar.gz"}; !slices.Equal(got, want) {
+		t.Errorf("probed assets = %q, want %q", got, want)
+	}
+
+	// asset_allowed agrees with AssetConfig.AllowAsset
+	for _, filename := range []string{
+		"tool_linux.tar.gz", "tool-debug_linux.tar.gz", "tool-debug-keep.tar.gz", "tool.sbom",
+		"tool_1.tar.gz", "tool (copy).tar.gz", "tool (copy.tar.gz", "[bad", "tool_$(id).tar.gz",
+	} {
+		_, err := run(`asset_allowed "$1"`, filename)
+		if got, want := err == nil, installSpec.Asset.AllowAsset(filename); got != want {
+			t.Errorf("asset_allowed %q = %v, want %v", filename, got, want)
+		}
+	}
+}
+
+// shellFunction returns the definition of the shell function name in script.
+func shellFunction(t *testing.T, script, name string) string {
+	t.Helper()
+	start := strings.Index(script, "\n"+name+"() {\n")
+	if start < 0 {
+		t.Fatalf("script does not define %s", name)
+	}
+	end := strings.Index(script[start:], "\n}\n")
+	return script[start+1 : start+end+3]
+}
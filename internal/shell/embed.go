@@ -45,5 +45,20 @@ var hashSHA1 string
 //go:embed hash_md5.sh
 var hashMD5 string
 
+//go:embed hash_sha3-256.sh
+var hashSHA3256 string
+
+//go:embed hash_blake2b.sh
+var hashBLAKE2b string
+
+//go:embed hash_blake3.sh
+var hashBLAKE3 string
+
 //go:embed shell_functions.sh
 var shellFunctions string
+
+// scriptSignatureTemplate is the self-verification header added to a
+// script by AddScriptSignature.
+//
+//go:embed script_signature.tmpl.sh
+var scriptSignatureTemplate string
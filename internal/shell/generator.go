@@ -0,0 +1,29 @@
+package shell
+
+import (
+	"github.com/haya14busa/goinstaller/pkg/generator"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func init() {
+	generator.Register("sh", Generator{})
+}
+
+// Generator is the generator.ScriptGenerator of POSIX shell installers,
+// registered as format "sh".
+type Generator struct{}
+
+// Generate implements generator.ScriptGenerator.
+func (Generator) Generate(installSpec *spec.InstallSpec, opts generator.Options) ([]byte, error) {
+	return generate(installSpec, opts)
+}
+
+// GenerateMulti implements generator.MultiGenerator.
+func (Generator) GenerateMulti(tools []spec.InstallSpec, opts generator.Options) ([]byte, error) {
+	return generateMulti(tools, opts)
+}
+
+// Extension implements generator.ScriptGenerator.
+func (Generator) Extension() string {
+	return ".sh"
+}
@@ -0,0 +1,17 @@
+hash_blake2b() {
+  TARGET=${1:-/dev/stdin}
+  if is_command b2sum; then
+    hash=$(b2sum "$TARGET") || return 1
+    echo "$hash" | cut -d ' ' -f 1
+  elif is_command openssl; then
+    hash=$(openssl dgst -blake2b512 "$TARGET") || return 1
+    echo "$hash" | cut -d ' ' -f 2
+  else
+    log_crit "hash_blake2b unable to find command to compute blake2b hash"
+    return 1
+  fi
+}
+
+hash_compute() {
+  hash_blake2b "$1"
+}
@@ -0,0 +1,14 @@
+hash_blake3() {
+  TARGET=${1:-/dev/stdin}
+  if is_command b3sum; then
+    hash=$(b3sum --no-names "$TARGET") || return 1
+    echo "$hash" | cut -d ' ' -f 1
+  else
+    log_crit "hash_blake3 unable to find b3sum to compute blake3 hash"
+    return 1
+  fi
+}
+
+hash_compute() {
+  hash_blake3 "$1"
+}
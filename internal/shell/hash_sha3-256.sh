@@ -0,0 +1,20 @@
+hash_sha3_256() {
+  TARGET=${1:-/dev/stdin}
+  if is_command sha3sum; then
+    hash=$(sha3sum -a 256 "$TARGET") || return 1
+    echo "$hash" | cut -d ' ' -f 1
+  elif is_command rhash; then
+    hash=$(rhash --sha3-256 "$TARGET") || return 1
+    echo "$hash" | cut -d ' ' -f 1
+  elif is_command openssl; then
+    hash=$(openssl dgst -sha3-256 "$TARGET") || return 1
+    echo "$hash" | cut -d ' ' -f 2
+  else
+    log_crit "hash_sha3_256 unable to find command to compute sha3-256 hash"
+    return 1
+  fi
+}
+
+hash_compute() {
+  hash_sha3_256 "$1"
+}
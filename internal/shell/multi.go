@@ -0,0 +1,66 @@
+package shell
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/haya14busa/goinstaller/pkg/generator"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"github.com/pkg/errors"
+)
+
+// multiHeredocEnd terminates the heredoc holding each tool's installer.
+const multiHeredocEnd = "BINSTALLER_TOOL_EOF"
+
+var nonIdentRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// GenerateMulti creates one installer script that installs every tool of a
+// multi-tool spec. Each tool's installer is generated with Generate and run
+// in turn with the arguments given to the combined script, so options such
+// as -b apply to every tool and each tool installs its default version.
+// templates are applied to every tool as with GenerateWithTemplates.
+func GenerateMulti(tools []spec.InstallSpec, templates ...Template) ([]byte, error) {
+	return generateMulti(tools, generator.Options{Templates: templates})
+}
+
+func generateMulti(tools []spec.InstallSpec, opts generator.Options) ([]byte, error) {
+	if len(tools) == 0 {
+		return nil, errors.New("multi-tool spec has no tools")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("#!/bin/sh\n# Code generated by binstaller. DO NOT EDIT.\n#\n")
+	buf.WriteString("# Installs: ")
+	names := make([]string, 0, len(tools))
+	for i := range tools {
+		tools[i].SetDefaults()
+		names = append(names, tools[i].Name)
+	}
+	buf.WriteString(strings.Join(names, ", ") + "\n")
+	buf.WriteString("set -e\n")
+
+	for i := range tools {
+		script, err := generate(&tools[i], opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to generate installer for tool %s", tools[i].Name)
+		}
+		if bytes.Contains(script, []byte("\n"+multiHeredocEnd+"\n")) {
+			return nil, errors.Errorf("installer for tool %s contains the heredoc terminator %s", tools[i].Name, multiHeredocEnd)
+		}
+		fmt.Fprintf(&buf, "\ninstall_%s() {\n", nonIdentRe.ReplaceAllString(tools[i].Name, "_"))
+		fmt.Fprintf(&buf, "  sh -s -- \"$@\" <<'%s'\n", multiHeredocEnd)
+		buf.Write(script)
+		if !bytes.HasSuffix(script, []byte("\n")) {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(multiHeredocEnd + "\n}\n")
+	}
+
+	buf.WriteString("\n")
+	for i := range tools {
+		fmt.Fprintf(&buf, "install_%s \"$@\"\n", nonIdentRe.ReplaceAllString(tools[i].Name, "_"))
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,38 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestGenerateMulti(t *testing.T) {
+	tools := []spec.InstallSpec{
+		{Repo: "owner/tool-a", Asset: spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"}},
+		{Name: "b", Repo: "owner/b", Asset: spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"}},
+	}
+	script, err := GenerateMulti(tools)
+	if err != nil {
+		t.Fatalf("GenerateMulti() error = %v", err)
+	}
+	got := string(script)
+	for _, want := range []string{
+		"# Installs: tool-a, b\n",
+		"install_tool_a() {\n  sh -s -- \"$@\" <<'BINSTALLER_TOOL_EOF'\n#!/bin/sh\n",
+		"\ninstall_tool_a \"$@\"\ninstall_b \"$@\"\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GenerateMulti() output does not contain %q", want)
+		}
+	}
+	if n := strings.Count(got, "\nBINSTALLER_TOOL_EOF\n}\n"); n != 2 {
+		t.Errorf("got %d heredoc terminators, want 2", n)
+	}
+}
+
+func TestGenerateMultiNoTools(t *testing.T) {
+	if _, err := GenerateMulti(nil); err == nil {
+		t.Error("GenerateMulti() expected error for empty tools")
+	}
+}
@@ -0,0 +1,32 @@
+package shell
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// oneLinerVerifier is run by sh with the script piped to it. It saves the
+// script to a temporary file and only runs it if its sha256 matches.
+const oneLinerVerifier = `f=$(mktemp) || exit 1; trap "rm -f \"$f\"" EXIT; cat >"$f"; ` +
+	`h=$({ sha256sum "$f" 2>/dev/null || shasum -a 256 "$f"; } | cut -d" " -f1); ` +
+	`[ "$h" = %[1]s ] || { echo "installer script checksum mismatch: got $h, want %[1]s" >&2; exit 1; }; ` +
+	`sh "$f" "$@"`
+
+// OneLiner returns a copy-pasteable "curl ... | sh" command that downloads
+// the installer script published at scriptURL and runs it only if it is
+// identical to script. Installer arguments can be appended to the command.
+func OneLiner(scriptURL string, script []byte) (string, error) {
+	if scriptURL == "" {
+		return "", errors.New("script URL is required")
+	}
+	if strings.ContainsAny(scriptURL, "' \t\n") {
+		return "", errors.Errorf("script URL must not contain single quotes or whitespace: %s", scriptURL)
+	}
+	sum := sha256.Sum256(script)
+	verifier := fmt.Sprintf(oneLinerVerifier, hex.EncodeToString(sum[:]))
+	return fmt.Sprintf("curl -sSfL '%s' | sh -c '%s' sh", scriptURL, verifier), nil
+}
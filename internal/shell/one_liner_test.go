@@ -0,0 +1,54 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOneLiner(t *testing.T) {
+	for _, name := range []string{"sh", "curl"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s not available", name)
+		}
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "install.sh")
+	script := []byte("#!/bin/sh\necho \"installed $*\"\n")
+	oneLiner, err := OneLiner("file://"+path, script)
+	if err != nil {
+		t.Fatalf("OneLiner() error = %v", err)
+	}
+
+	if err := os.WriteFile(path, script, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("sh", "-c", oneLiner+" -b ./bin v1.0.0").CombinedOutput()
+	if err != nil {
+		t.Fatalf("one-liner failed: %v\n%s", err, out)
+	}
+	if got, want := string(out), "installed -b ./bin v1.0.0\n"; got != want {
+		t.Errorf("one-liner output = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho pwned\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err = exec.Command("sh", "-c", oneLiner).CombinedOutput()
+	if err == nil {
+		t.Fatalf("one-liner ran a tampered script:\n%s", out)
+	}
+	if strings.Contains(string(out), "pwned") || !strings.Contains(string(out), "checksum mismatch") {
+		t.Errorf("unexpected output for a tampered script:\n%s", out)
+	}
+}
+
+func TestOneLinerInvalidURL(t *testing.T) {
+	for _, url := range []string{"", "https://example.com/it's.sh", "https://example.com/a b.sh"} {
+		if _, err := OneLiner(url, nil); err == nil {
+			t.Errorf("OneLiner(%q) error = nil, want error", url)
+		}
+	}
+}
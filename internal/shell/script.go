@@ -3,10 +3,16 @@ package shell
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/generator"
 	"github.com/haya14busa/goinstaller/pkg/spec"
+	"github.com/haya14busa/goinstaller/pkg/verify"
 	"github.com/pkg/errors"
 )
 
@@ -17,34 +23,168 @@ type templateData struct {
 	Shlib             string // The content of the shell function library
 	HashFunctions     string
 	ShellFunctions    string
+	// Target is the platform the installer is restricted to, if any.
+	Target *spec.Platform
 }
 
+// Template is a user-supplied installer template.
+type Template = generator.Template
+
 // Generate creates the installer shell script content based on the InstallSpec.
 // The generated script will dynamically determine OS, Arch, and Version at runtime.
 func Generate(installSpec *spec.InstallSpec) ([]byte, error) {
+	return GenerateWithTemplates(installSpec, nil)
+}
+
+// GenerateWithTemplates is like Generate but parses templates on top of the
+// built-in template, in order. A template with a body replaces the whole
+// script, while one with only {{ define }} blocks overrides those sections of
+// it: "header" runs right after "set -e" and "footer" after the
+// installation.
+func GenerateWithTemplates(installSpec *spec.InstallSpec, templates []Template) ([]byte, error) {
+	return generate(installSpec, generator.Options{Templates: templates})
+}
+
+func generate(installSpec *spec.InstallSpec, opts generator.Options) ([]byte, error) {
 	if installSpec == nil {
 		return nil, errors.New("install spec cannot be nil")
 	}
 	// Apply spec defaults first
 	installSpec.SetDefaults()
+	if opts.Target != nil {
+		var err error
+		if installSpec, err = forTarget(installSpec, *opts.Target); err != nil {
+			return nil, err
+		}
+	}
+	if err := validate(installSpec); err != nil {
+		return nil, err
+	}
 
 	// --- Prepare Template Data ---
 	// Only pass static data known at generation time, plus the shell functions
+	hashFunctions, err := hashFuncs(installSpec, func(algo string) (string, error) {
+		return hashFunc(algo), nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	data := templateData{
 		InstallSpec:    installSpec,
 		Shlib:          shlib,
-		HashFunctions:  hashFunc(installSpec),
+		HashFunctions:  hashFunctions,
 		ShellFunctions: shellFunctions,
+		Target:         opts.Target,
+	}
+	if opts.Target != nil {
+		// Platform detection is pre-resolved.
+		data.Shlib = withoutFunctions(shlib, "uname_os", "uname_arch", "uname_os_check", "uname_arch_check")
+	}
+	return execute(mainScriptTemplate, data, opts.Templates...)
+}
+
+// forTarget returns the spec narrowed down to target, keeping only the
+// embedded checksums of the assets the installer may download on it.
+func forTarget(installSpec *spec.InstallSpec, target spec.Platform) (*spec.InstallSpec, error) {
+	if ps := installSpec.SupportedPlatforms; len(ps) > 0 && !slices.Contains(ps, target) {
+		return nil, errors.Errorf("target %s is not a supported platform", target)
+	}
+	narrowed := installSpec.ForPlatform(target)
+	if c := narrowed.Checksums; c != nil && len(c.EmbeddedChecksums) > 0 {
+		embedded := make(map[string][]spec.EmbeddedChecksum, len(c.EmbeddedChecksums))
+		for version, entries := range c.EmbeddedChecksums {
+			filenames, err := (&checksums.Embedder{Spec: installSpec, Version: version}).AssetFilenames(target)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to resolve assets of %s for %s", version, target)
+			}
+			for _, ec := range entries {
+				if slices.Contains(filenames, ec.Filename) {
+					embedded[version] = append(embedded[version], ec)
+				}
+			}
+		}
+		c.EmbeddedChecksums = embedded
+	}
+	return narrowed, nil
+}
+
+// withoutFunctions removes the definitions of the named shell functions
+// from lib. A definition spans from its "name() {" line to the next line
+// consisting of "}".
+func withoutFunctions(lib string, names ...string) string {
+	var b strings.Builder
+	skipping := false
+	for _, line := range strings.SplitAfter(lib, "\n") {
+		switch {
+		case skipping:
+			skipping = strings.TrimRight(line, "\n") != "}"
+		case slices.ContainsFunc(names, func(name string) bool { return strings.HasPrefix(line, name+"() {") }):
+			skipping = true
+		default:
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// GenerateFromDir is like Generate but reads the template and shell function
+// files from dir (e.g. internal/shell in a working tree) instead of the
+// embedded copies, so template changes can be previewed without rebuilding.
+// Files missing from dir fall back to the embedded versions.
+func GenerateFromDir(installSpec *spec.InstallSpec, dir string) ([]byte, error) {
+	if installSpec == nil {
+		return nil, errors.New("install spec cannot be nil")
+	}
+	installSpec.SetDefaults()
+	if err := validate(installSpec); err != nil {
+		return nil, err
+	}
+
+	read := func(name, embedded string) (string, error) {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			return embedded, nil
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read %s", name)
+		}
+		return string(b), nil
+	}
+
+	mainTemplate, err := read("template.tmpl.sh", mainScriptTemplate)
+	if err != nil {
+		return nil, err
+	}
+	data := templateData{InstallSpec: installSpec}
+	if data.Shlib, err = read("shlib.sh", shlib); err != nil {
+		return nil, err
+	}
+	data.HashFunctions, err = hashFuncs(installSpec, func(algo string) (string, error) {
+		return read("hash_"+algo+".sh", hashFunc(algo))
+	})
+	if err != nil {
+		return nil, err
 	}
+	if data.ShellFunctions, err = read("shell_functions.sh", shellFunctions); err != nil {
+		return nil, err
+	}
+	return execute(mainTemplate, data)
+}
 
+func execute(mainTemplate string, data templateData, overrides ...Template) ([]byte, error) {
 	// --- Prepare Template ---
 	// The template now needs to contain the logic for runtime detection and asset resolution
 	funcMap := createFuncMap() // Keep helper funcs like default, tolower etc.
 
-	tmpl, err := template.New("installer").Funcs(funcMap).Parse(mainScriptTemplate) // Parse only the main template
+	tmpl, err := template.New("installer").Funcs(funcMap).Parse(mainTemplate) // Parse only the main template
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse installer template")
 	}
+	for _, t := range overrides {
+		if _, err := tmpl.Parse(t.Text); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse template %s", t.Name)
+		}
+	}
 
 	// --- Execute Template ---
 	var buf bytes.Buffer
@@ -57,20 +197,176 @@ func Generate(installSpec *spec.InstallSpec) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func hashFunc(installSpec *spec.InstallSpec) string {
-	algo := ""
+// validate checks the spec for settings the generated script cannot work
+// without.
+func validate(installSpec *spec.InstallSpec) error {
+	if v := installSpec.Version; v != nil {
+		switch v.Source {
+		case "github":
+		case "static":
+			if len(v.Static) == 0 {
+				return errors.New("version.static must list at least one tag for the static version source")
+			}
+		case "url":
+			if v.LatestURL == "" {
+				return errors.New("version.latest_url is required for the url version source")
+			}
+		default:
+			return errors.Errorf("unsupported version source: %s", v.Source)
+		}
+	}
+	if err := installSpec.DefaultBinDir.Validate(); err != nil {
+		return err
+	}
+	if err := installSpec.ValidateVariables(); err != nil {
+		return err
+	}
+	for _, rule := range installSpec.Asset.Rules {
+		switch rule.When.Libc {
+		case "", spec.LibcGNU, spec.LibcMusl:
+		default:
+			return errors.Errorf("unsupported libc in asset rule: %s", rule.When.Libc)
+		}
+		if d := rule.When.Distro; d != "" && !spec.ValidDistro(d) {
+			return errors.Errorf("invalid distro in asset rule: %q", d)
+		}
+	}
+	if c := installSpec.Checksums; c != nil {
+		for _, rule := range c.Rules {
+			if d := rule.When.Distro; d != "" && !spec.ValidDistro(d) {
+				return errors.Errorf("invalid distro in checksums rule: %q", d)
+			}
+		}
+		for version, checksums := range c.EmbeddedChecksums {
+			for _, ec := range checksums {
+				if !c.EntryAlgorithm(ec).Valid() {
+					return errors.Errorf("unsupported algorithm %s of embedded checksum %s@%s", ec.Algorithm, ec.Filename, version)
+				}
+			}
+		}
+	}
+	if i := installSpec.Install; i != nil && strings.ContainsAny(i.GoModule, " \t\n'\"`$\\") {
+		return errors.Errorf("invalid install.go_module: %q", i.GoModule)
+	}
+	if img := installSpec.Image; img != nil {
+		if img.Ref == "" || img.Path == "" {
+			return errors.New("image.ref and image.path are required to install from a container image")
+		}
+		if !strings.HasPrefix(img.Path, "/") || strings.ContainsAny(img.Path, "'\"`$\\\n") || strings.ContainsAny(img.Ref, "'\"`\\\n") {
+			return errors.Errorf("invalid image: ref %q, path %q", img.Ref, img.Path)
+		}
+	}
+	for _, shell := range installSpec.Install.Completions() {
+		if !slices.Contains(spec.CompletionShells, shell) {
+			return errors.Errorf("unsupported shell in install.completion_shells: %s", shell)
+		}
+	}
+	if d := installSpec.Download; d != nil && (d.RetryCount() < 0 || d.Timeout < 0) {
+		return errors.New("download.retries and download.timeout must not be negative")
+	}
+	return validateSignature(installSpec.Signature)
+}
+
+// validateSignature checks the signature config for settings the generated
+// script cannot work without.
+func validateSignature(sig *spec.SignatureConfig) error {
+	if sig == nil || sig.Enabled == nil || !*sig.Enabled {
+		return nil
+	}
+	switch sig.Type {
+	case "cosign":
+		if sig.Key == "" && (sig.CertificateIdentity == "" && sig.CertificateIdentityRegexp == "" || sig.CertificateOIDCIssuer == "") {
+			return errors.New("signature.certificate_identity (or certificate_identity_regexp) and signature.certificate_oidc_issuer are required for keyless cosign verification")
+		}
+	case "minisign", "signify":
+		if sig.Key == "" {
+			return errors.Errorf("signature.key is required for %s verification", sig.Type)
+		}
+	default:
+		return errors.Errorf("unsupported signature type: %s", sig.Type)
+	}
+	if sig.Target != "asset" && sig.Target != "checksum" {
+		return errors.Errorf("unsupported signature target: %s", sig.Target)
+	}
+	return nil
+}
+
+// hashAlgorithm returns the checksum algorithm used by the installer.
+func hashAlgorithm(installSpec *spec.InstallSpec) string {
 	if installSpec.Checksums != nil {
-		algo = installSpec.Checksums.Algorithm
+		if algo := installSpec.Checksums.Algorithm; algo.Valid() {
+			return string(algo)
+		}
+	}
+	return "sha256"
+}
+
+// usesAttestation reports whether the installer verifies GitHub artifact
+// attestations.
+func usesAttestation(installSpec *spec.InstallSpec) bool {
+	a := installSpec.Attestation
+	return a != nil && a.Enabled != nil && *a.Enabled
+}
+
+// extraHashAlgorithms returns the sorted algorithms of embedded checksums
+// other than the installer's checksum algorithm.
+func extraHashAlgorithms(installSpec *spec.InstallSpec) []string {
+	c := installSpec.Checksums
+	if c == nil {
+		return nil
+	}
+	primary := hashAlgorithm(installSpec)
+	var algos []string
+	for _, checksums := range c.EmbeddedChecksums {
+		for _, ec := range checksums {
+			if algo := string(c.EntryAlgorithm(ec)); algo != primary && !slices.Contains(algos, algo) {
+				algos = append(algos, algo)
+			}
+		}
+	}
+	slices.Sort(algos)
+	return algos
+}
+
+// hashFuncs returns the hash functions of the installer: the script of the
+// checksum algorithm, which defines hash_compute, followed by the hash
+// functions of extraHashAlgorithms and of sha256 for attestation lookups.
+// read returns the script of an algorithm.
+func hashFuncs(installSpec *spec.InstallSpec, read func(algo string) (string, error)) (string, error) {
+	funcs, err := read(hashAlgorithm(installSpec))
+	if err != nil {
+		return "", err
 	}
+	algos := extraHashAlgorithms(installSpec)
+	if usesAttestation(installSpec) && hashAlgorithm(installSpec) != "sha256" && !slices.Contains(algos, "sha256") {
+		algos = append(algos, "sha256")
+	}
+	for _, algo := range algos {
+		script, err := read(algo)
+		if err != nil {
+			return "", err
+		}
+		// Only the checksum algorithm may define hash_compute
+		script, _, _ = strings.Cut(script, "\nhash_compute() {")
+		funcs = strings.TrimRight(funcs, "\n") + "\n\n" + strings.TrimRight(script, "\n") + "\n"
+	}
+	return funcs, nil
+}
+
+func hashFunc(algo string) string {
 	switch algo {
 	case "sha1":
 		return hashSHA1
 	case "md5":
 		return hashMD5
-	case "sha256":
-		return hashSHA256
 	case "sha512":
 		return hashSHA512
+	case "sha3-256":
+		return hashSHA3256
+	case "blake2b":
+		return hashBLAKE2b
+	case "blake3":
+		return hashBLAKE3
 	}
 	return hashSHA256
 }
@@ -85,6 +381,19 @@ func createFuncMap() template.FuncMap {
 			}
 			return val
 		},
+		"hashAlgorithm":       hashAlgorithm,
+		"extraHashAlgorithms": extraHashAlgorithms,
+		// embeddedAlgorithm returns the algorithm of ec if it differs from
+		// the installer's checksum algorithm.
+		"embeddedAlgorithm": func(installSpec *spec.InstallSpec, ec spec.EmbeddedChecksum) string {
+			if algo := string(installSpec.Checksums.EntryAlgorithm(ec)); algo != hashAlgorithm(installSpec) {
+				return algo
+			}
+			return ""
+		},
+		"hashFuncName": func(algo string) string {
+			return "hash_" + strings.ReplaceAll(algo, "-", "_")
+		},
 		"hasBinaryOverride": func(asset spec.AssetConfig) bool {
 			for _, rule := range asset.Rules {
 				if len(rule.Binaries) > 0 {
@@ -93,8 +402,88 @@ func createFuncMap() template.FuncMap {
 			}
 			return false
 		},
+		"hasFallbackTemplates": func(asset spec.AssetConfig) bool {
+			for _, rule := range asset.Rules {
+				if len(rule.FallbackTemplates) > 0 {
+					return true
+				}
+			}
+			return false
+		},
+		"hasAliases": func(asset spec.AssetConfig) bool {
+			for _, binary := range asset.Binaries {
+				if len(binary.Aliases) > 0 {
+					return true
+				}
+			}
+			return false
+		},
+		"windowsArm64X64": func(s *spec.InstallSpec) bool {
+			if s.Asset.ArchEmulation == nil || !s.Asset.ArchEmulation.WindowsArm64X64 {
+				return false
+			}
+			// A native windows/arm64 asset always wins over emulation.
+			for _, p := range s.SupportedPlatforms {
+				if p.OS == "windows" && p.Arch == "arm64" {
+					return false
+				}
+			}
+			return true
+		},
+		"usesArchCondition": func(s *spec.InstallSpec) bool {
+			for _, rule := range s.Asset.Rules {
+				if rule.When.Arch != "" {
+					return true
+				}
+			}
+			if s.Checksums != nil {
+				for _, rule := range s.Checksums.Rules {
+					if rule.When.Arch != "" {
+						return true
+					}
+				}
+			}
+			return false
+		},
+		"usesLibc": func(asset spec.AssetConfig) bool {
+			return asset.UsesLibc()
+		},
+		"usesDistro": func(asset spec.AssetConfig) bool {
+			return asset.UsesDistro()
+		},
+		"shellQuote": func(s string) string {
+			return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+		},
 		"trimPrefix": func(s, prefix string) string {
 			return strings.TrimPrefix(s, prefix)
 		},
+		// casePatterns joins the globs into the pattern of a case statement
+		// branch, dropping malformed globs that never match.
+		"casePatterns": func(globs []string) string {
+			var patterns []string
+			for _, glob := range globs {
+				if p, ok := spec.GlobToCasePattern(glob); ok {
+					patterns = append(patterns, p)
+				}
+			}
+			return strings.Join(patterns, "|")
+		},
+		"hasEmbeddedURLs": func(checksums *spec.ChecksumConfig) bool {
+			if checksums == nil {
+				return false
+			}
+			for _, entries := range checksums.EmbeddedChecksums {
+				for _, ec := range entries {
+					if len(ec.URLs) > 0 {
+						return true
+					}
+				}
+			}
+			return false
+		},
+		"deref": func(b *bool) bool {
+			return b != nil && *b
+		},
+		"commandName": verify.CommandName,
 	}
 }
@@ -0,0 +1,84 @@
+package shell
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	scriptSignatureBegin = "# --- BEGIN BINSTALLER SCRIPT SIGNATURE ---"
+	scriptSignatureEnd   = "# --- END BINSTALLER SCRIPT SIGNATURE ---"
+)
+
+// ScriptSignature describes the detached signature of a published installer
+// script.
+type ScriptSignature struct {
+	// Method is the signing tool: "cosign" or "minisign".
+	Method string
+	// PublicKey is the PEM encoded cosign public key or the base64 minisign
+	// public key.
+	PublicKey string
+	// ScriptURL is where the script is published. Without it, scripts piped
+	// to sh cannot verify themselves.
+	ScriptURL string
+	// SignatureURL is where the detached signature is published.
+	SignatureURL string
+}
+
+// AddScriptSignature inserts a header into script that, when the script is
+// run with BINSTALLER_VERIFY_SCRIPT=1, verifies the signature described by sig
+// before anything else runs. A header added before is replaced, so the script
+// must be signed after calling AddScriptSignature.
+func AddScriptSignature(script []byte, sig ScriptSignature) ([]byte, error) {
+	if sig.Method != "cosign" && sig.Method != "minisign" {
+		return nil, errors.Errorf("unsupported script signature method: %s (must be cosign or minisign)", sig.Method)
+	}
+	if sig.SignatureURL == "" {
+		return nil, errors.New("script signature URL is required")
+	}
+	sig.PublicKey = strings.TrimSpace(sig.PublicKey)
+	for name, v := range map[string]string{"public key": sig.PublicKey, "script URL": sig.ScriptURL, "signature URL": sig.SignatureURL} {
+		if strings.Contains(v, "'") {
+			return nil, errors.Errorf("script signature %s must not contain single quotes", name)
+		}
+	}
+	if sig.PublicKey == "" {
+		return nil, errors.New("script signature public key is required")
+	}
+
+	tmpl, err := template.New("script_signature").Parse(scriptSignatureTemplate)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse script signature template")
+	}
+	var block bytes.Buffer
+	if err := tmpl.Execute(&block, sig); err != nil {
+		return nil, errors.Wrap(err, "failed to execute script signature template")
+	}
+
+	script = removeScriptSignature(script)
+	pos := headerEnd(script)
+	out := make([]byte, 0, len(script)+block.Len())
+	out = append(out, script[:pos]...)
+	out = append(out, block.Bytes()...)
+	out = append(out, script[pos:]...)
+	return out, nil
+}
+
+// removeScriptSignature removes the header added by AddScriptSignature.
+func removeScriptSignature(script []byte) []byte {
+	begin := bytes.Index(script, []byte(scriptSignatureBegin+"\n"))
+	if begin < 0 {
+		return script
+	}
+	end := bytes.Index(script[begin:], []byte(scriptSignatureEnd+"\n"))
+	if end < 0 {
+		return script
+	}
+	end += begin + len(scriptSignatureEnd) + 1
+	out := make([]byte, 0, len(script)-(end-begin))
+	out = append(out, script[:begin]...)
+	return append(out, script[end:]...)
+}
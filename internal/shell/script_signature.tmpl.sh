@@ -0,0 +1,55 @@
+# --- BEGIN BINSTALLER SCRIPT SIGNATURE ---
+# Added by "binst sign". Run with BINSTALLER_VERIFY_SCRIPT=1 to verify the
+# {{ .Method }} signature of this script before it does anything else.
+BINSTALLER_SCRIPT_URL='{{ .ScriptURL }}'
+BINSTALLER_SCRIPT_SIGNATURE_URL='{{ .SignatureURL }}'
+binstaller_fetch() {
+  if command -v curl >/dev/null 2>&1; then
+    curl -fsSL -o "$1" "$2"
+  elif command -v wget >/dev/null 2>&1; then
+    wget -q -O "$1" "$2"
+  else
+    echo "binstaller: curl or wget is required to verify this script" 1>&2
+    return 1
+  fi
+}
+binstaller_verify_script() {
+  {{- if eq .Method "minisign" }}
+  minisign -V -q -P '{{ .PublicKey }}' -x "$2" -m "$1" 1>&2
+  {{- else }}
+  printf '%s\n' '{{ .PublicKey }}' >"$2.pub"
+  cosign verify-blob --key "$2.pub" --signature "$2" "$1" 1>&2
+  {{- end }}
+}
+if [ "${BINSTALLER_VERIFY_SCRIPT:-0}" = "1" ] && [ -z "${BINSTALLER_SCRIPT_VERIFIED:-}" ]; then
+  if ! command -v {{ .Method }} >/dev/null 2>&1; then
+    echo "binstaller: {{ .Method }} is required to verify this script" 1>&2
+    exit 1
+  fi
+  binstaller_tmp=$(mktemp -d)
+  if [ -f "$0" ] && grep -q '^BINSTALLER_SCRIPT_SIGNATURE_URL=' "$0"; then
+    binstaller_script="$0"
+  elif [ -n "$BINSTALLER_SCRIPT_URL" ]; then
+    # The script is piped to sh and cannot read itself: verify a fresh copy
+    # and run that instead.
+    binstaller_script="${binstaller_tmp}/install.sh"
+    binstaller_fetch "$binstaller_script" "$BINSTALLER_SCRIPT_URL" || exit 1
+  else
+    echo "binstaller: cannot verify a script read from stdin without a script URL" 1>&2
+    exit 1
+  fi
+  binstaller_fetch "${binstaller_tmp}/install.sh.sig" "$BINSTALLER_SCRIPT_SIGNATURE_URL" || exit 1
+  if ! binstaller_verify_script "$binstaller_script" "${binstaller_tmp}/install.sh.sig"; then
+    echo "binstaller: signature verification of this script failed" 1>&2
+    rm -rf "$binstaller_tmp"
+    exit 1
+  fi
+  echo "binstaller: script signature verified" 1>&2
+  if [ "$binstaller_script" != "$0" ]; then
+    BINSTALLER_SCRIPT_VERIFIED=1 sh "$binstaller_script" "$@" && binstaller_rc=0 || binstaller_rc=$?
+    rm -rf "$binstaller_tmp"
+    exit "$binstaller_rc"
+  fi
+  rm -rf "$binstaller_tmp"
+fi
+# --- END BINSTALLER SCRIPT SIGNATURE ---
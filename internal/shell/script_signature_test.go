@@ -0,0 +1,130 @@
+package shell
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddScriptSignatureReplacesHeader(t *testing.T) {
+	script := []byte("#!/bin/sh\n# Code generated by binstaller. DO NOT EDIT.\n#\nset -e\necho installed\n")
+	sig := ScriptSignature{Method: "minisign", PublicKey: "RWQkey1\n", SignatureURL: "https://example.com/install.sh.minisig"}
+
+	signed, err := AddScriptSignature(script, sig)
+	if err != nil {
+		t.Fatalf("AddScriptSignature() error = %v", err)
+	}
+	if !strings.HasPrefix(string(signed), "#!/bin/sh\n# Code generated by binstaller. DO NOT EDIT.\n"+scriptSignatureBegin+"\n") {
+		t.Errorf("signature header not inserted after header:\n%s", signed)
+	}
+	if !strings.HasSuffix(string(signed), scriptSignatureEnd+"\n#\nset -e\necho installed\n") {
+		t.Errorf("script body not preserved:\n%s", signed)
+	}
+
+	sig.PublicKey = "RWQkey2"
+	resigned, err := AddScriptSignature(signed, sig)
+	if err != nil {
+		t.Fatalf("AddScriptSignature() error = %v", err)
+	}
+	if strings.Count(string(resigned), scriptSignatureBegin) != 1 || strings.Contains(string(resigned), "RWQkey1") {
+		t.Errorf("signature header not replaced:\n%s", resigned)
+	}
+	if string(removeScriptSignature(resigned)) != string(script) {
+		t.Errorf("removeScriptSignature() = %q, want %q", removeScriptSignature(resigned), script)
+	}
+}
+
+func TestAddScriptSignatureInvalid(t *testing.T) {
+	script := []byte("#!/bin/sh\n")
+	for _, sig := range []ScriptSignature{
+		{Method: "gpg", PublicKey: "k", SignatureURL: "https://example.com/sig"},
+		{Method: "cosign", PublicKey: "k"},
+		{Method: "cosign", SignatureURL: "https://example.com/sig"},
+		{Method: "cosign", PublicKey: "k", SignatureURL: "https://example.com/'sig"},
+	} {
+		if _, err := AddScriptSignature(script, sig); err == nil {
+			t.Errorf("AddScriptSignature(%+v) expected error", sig)
+		}
+	}
+}
+
+func TestScriptSignatureVerification(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not available")
+	}
+	var signed []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/install.sh":
+			w.Write(signed)
+		case "/install.sh.minisig":
+			w.Write([]byte("signature\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var err error
+	signed, err = AddScriptSignature([]byte("#!/bin/sh\n# Code generated by binstaller. DO NOT EDIT.\necho installed \"$@\"\n"), ScriptSignature{
+		Method:       "minisign",
+		PublicKey:    "RWQkey",
+		ScriptURL:    srv.URL + "/install.sh",
+		SignatureURL: srv.URL + "/install.sh.minisig",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "install.sh")
+	if err := os.WriteFile(script, signed, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// The fake minisign accepts the signature if MINISIGN_OK is set.
+	bin := filepath.Join(dir, "bin")
+	if err := os.Mkdir(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fake := "#!/bin/sh\n[ \"$4\" = RWQkey ] && [ -n \"$MINISIGN_OK\" ]\n"
+	if err := os.WriteFile(filepath.Join(bin, "minisign"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		piped   bool
+		env     []string
+		want    string
+		wantErr bool
+	}{
+		{name: "verification disabled", want: "installed v1"},
+		{name: "valid signature", env: []string{"BINSTALLER_VERIFY_SCRIPT=1", "MINISIGN_OK=1"}, want: "installed v1"},
+		{name: "invalid signature", env: []string{"BINSTALLER_VERIFY_SCRIPT=1"}, wantErr: true},
+		{name: "piped valid signature", piped: true, env: []string{"BINSTALLER_VERIFY_SCRIPT=1", "MINISIGN_OK=1"}, want: "installed v1"},
+		{name: "piped invalid signature", piped: true, env: []string{"BINSTALLER_VERIFY_SCRIPT=1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", script, "v1")
+			if tt.piped {
+				cmd = exec.Command("sh", "-s", "--", "v1")
+				cmd.Stdin = strings.NewReader(string(signed))
+			}
+			cmd.Env = append(os.Environ(), append(tt.env, "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))...)
+			out, err := cmd.Output()
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("script error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.TrimSpace(string(out)); !tt.wantErr && got != tt.want {
+				t.Errorf("script output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,426 @@
+package shell
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/generator"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestGenerateMixedHashAlgorithms(t *testing.T) {
+	installSpec := &spec.InstallSpec{
+		Repo:  "owner/tool",
+		Asset: spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"},
+		Checksums: &spec.ChecksumConfig{
+			Algorithm: spec.SHA256,
+			EmbeddedChecksums: map[string][]spec.EmbeddedChecksum{
+				"v1.0.0": {
+					{Filename: "tool_linux_amd64.tar.gz", Hash: "aaa"},
+					{Filename: "tool_darwin_arm64.tar.gz", Hash: "bbb", Algorithm: spec.SHA512},
+					{Filename: "tool_windows_amd64.tar.gz", Hash: "ccc", Algorithm: spec.SHA3_256},
+				},
+			},
+		},
+	}
+	script, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	got := string(script)
+	for _, want := range []string{
+		"\n1.0.0:tool_linux_amd64.tar.gz:aaa\n",
+		"\n1.0.0:tool_darwin_arm64.tar.gz:bbb:sha512\n",
+		"\n1.0.0:tool_windows_amd64.tar.gz:ccc:sha3-256\"",
+		"\nhash_sha512() {\n",
+		"\nhash_sha3_256() {\n",
+		"  sha512) hash_sha512 \"$1\" ;;\n",
+		"  sha3-256) hash_sha3_256 \"$1\" ;;\n",
+		"got=$(hash_embedded \"${TMPDIR}/${ASSET_FILENAME}\")",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Generate() output does not contain %q", want)
+		}
+	}
+	if n := strings.Count(got, "\nhash_compute() {\n"); n != 1 {
+		t.Errorf("got %d hash_compute definitions, want 1", n)
+	}
+}
+
+func TestGenerateInvalidEmbeddedAlgorithm(t *testing.T) {
+	installSpec := &spec.InstallSpec{
+		Repo:  "owner/tool",
+		Asset: spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"},
+		Checksums: &spec.ChecksumConfig{
+			EmbeddedChecksums: map[string][]spec.EmbeddedChecksum{
+				"v1.0.0": {{Filename: "tool_linux_amd64.tar.gz", Hash: "aaa", Algorithm: "crc32"}},
+			},
+		},
+	}
+	if _, err := Generate(installSpec); err == nil {
+		t.Error("Generate() error = nil, want error for unsupported algorithm")
+	}
+}
+
+func TestGenerateGoModule(t *testing.T) {
+	installSpec := &spec.InstallSpec{
+		Repo:    "owner/tool",
+		Asset:   spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"},
+		Install: &spec.InstallConfig{GoModule: "github.com/owner/tool/cmd/tool"},
+	}
+	script, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(script), `go install "github.com/owner/tool/cmd/tool@v${VERSION}"`) {
+		t.Error("generated script does not build the Go module")
+	}
+
+	installSpec.Install.GoModule = "github.com/owner/tool; rm -rf /"
+	if _, err := Generate(installSpec); err == nil {
+		t.Error("Generate() error = nil, want error for invalid go_module")
+	}
+}
+
+func TestGenerateCompletions(t *testing.T) {
+	installSpec := &spec.InstallSpec{
+		Repo:  "owner/tool",
+		Asset: spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"},
+	}
+	script, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Contains(string(script), "completions") {
+		t.Error("generated script installs completions without install.completion_command")
+	}
+
+	installSpec.Install = &spec.InstallConfig{CompletionCommand: "${BINARY} completion ${COMPLETION_SHELL}"}
+	script, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	got := string(script)
+	for _, want := range []string{
+		"      completions) COMPLETIONS=1 ;;\n",
+		"  for COMPLETION_SHELL in bash zsh fish; do\n",
+		`    if ! (${BINARY} completion ${COMPLETION_SHELL}) >"${TMPDIR}/completion"`,
+		"  if [ -n \"${COMPLETIONS}\" ]; then\n    install_completions\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Generate() output does not contain %q", want)
+		}
+	}
+
+	installSpec.Install.CompletionShells = []string{"fish"}
+	script, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(string(script), "  for COMPLETION_SHELL in fish; do\n") {
+		t.Error("generated script does not install only fish completions")
+	}
+
+	installSpec.Install.CompletionShells = []string{"powershell"}
+	if _, err := Generate(installSpec); err == nil {
+		t.Error("Generate() error = nil, want error for unsupported shell")
+	}
+}
+
+func TestGenerateImage(t *testing.T) {
+	installSpec := &spec.InstallSpec{
+		Repo:  "owner/tool",
+		Asset: spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}"},
+		Image: &spec.ImageConfig{Ref: "ghcr.io/owner/tool:${TAG}", Path: "/usr/local/bin/tool"},
+	}
+	script, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	got := string(script)
+	for _, want := range []string{
+		`  IMAGE_REF="ghcr.io/owner/tool:${TAG}"`,
+		`    image_extract "${TMPDIR}/${ASSET_FILENAME}"`,
+		"    crane export --platform",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Generate() output does not contain %q", want)
+		}
+	}
+
+	for _, image := range []*spec.ImageConfig{
+		{Path: "/usr/local/bin/tool"},
+		{Ref: "ghcr.io/owner/tool:${TAG}"},
+		{Ref: "ghcr.io/owner/tool:${TAG}", Path: "usr/local/bin/tool"},
+		{Ref: "ghcr.io/owner/tool:${TAG}", Path: "/usr/local/bin/$(id)"},
+	} {
+		installSpec.Image = image
+		if _, err := Generate(installSpec); err == nil {
+			t.Errorf("Generate() error = nil, want error for image %+v", image)
+		}
+	}
+}
+
+func TestGenerateDistro(t *testing.T) {
+	installSpec := &spec.InstallSpec{
+		Repo: "owner/tool",
+		Asset: spec.AssetConfig{
+			Template: "${NAME}_${OS}_${ARCH}.tar.gz",
+			Rules: []spec.AssetRule{
+				{When: spec.PlatformCondition{Distro: "ubuntu20.04"}, Template: "${NAME}_${DISTRO}_${ARCH}.tar.gz"},
+			},
+		},
+	}
+	script, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	got := string(script)
+	for _, want := range []string{
+		"\ndetect_distro() {\n",
+		`DISTRO="${BINSTALLER_DISTRO:-$(detect_distro)}"`,
+		`[ "${DISTRO}" = 'ubuntu20.04' ]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Generate() output does not contain %q", want)
+		}
+	}
+
+	installSpec.Asset.Rules[0].When.Distro = "ubuntu'; rm -rf /"
+	if _, err := Generate(installSpec); err == nil {
+		t.Error("Generate() error = nil, want error for invalid distro")
+	}
+}
+
+func TestGenerateWithTemplates(t *testing.T) {
+	newSpec := func() *spec.InstallSpec {
+		return &spec.InstallSpec{
+			Repo:  "owner/tool",
+			Asset: spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"},
+		}
+	}
+	sections := Template{Name: "sections.tmpl", Text: `
+{{ define "header" }}
+echo "Installing {{ .Name }}"{{ end }}
+{{ define "footer" }}
+echo "Done"{{ end }}
+`}
+	script, err := GenerateWithTemplates(newSpec(), []Template{sections})
+	if err != nil {
+		t.Fatalf("GenerateWithTemplates() error = %v", err)
+	}
+	got := string(script)
+	for _, want := range []string{
+		"\nset -e\necho \"Installing tool\"\n",
+		"\nexecute\necho \"Done\"\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GenerateWithTemplates() output does not contain %q", want)
+		}
+	}
+
+	script, err = GenerateWithTemplates(newSpec(), []Template{sections, {Name: "full.tmpl", Text: "#!/bin/sh\necho {{ .Repo }}\n"}})
+	if err != nil {
+		t.Fatalf("GenerateWithTemplates() error = %v", err)
+	}
+	if got, want := string(script), "#!/bin/sh\necho owner/tool\n"; got != want {
+		t.Errorf("GenerateWithTemplates() = %q, want %q", got, want)
+	}
+
+	if _, err := GenerateWithTemplates(newSpec(), []Template{{Name: "broken.tmpl", Text: "{{ .Name"}}); err == nil || !strings.Contains(err.Error(), "broken.tmpl") {
+		t.Errorf("GenerateWithTemplates() error = %v, want parse error of broken.tmpl", err)
+	}
+}
+
+func TestGenerateTarget(t *testing.T) {
+	installSpec := &spec.InstallSpec{
+		Repo:               "owner/tool",
+		SupportedPlatforms: []spec.Platform{{OS: "linux", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}},
+		Asset: spec.AssetConfig{
+			Template:      "${NAME}_${OS}_${ARCH}.tar.gz",
+			ArchEmulation: &spec.ArchEmulation{Rosetta2: true},
+		},
+		Checksums: &spec.ChecksumConfig{
+			EmbeddedChecksums: map[string][]spec.EmbeddedChecksum{
+				"v1.0.0": {
+					{Filename: "tool_linux_amd64.tar.gz", Hash: "aaa"},
+					{Filename: "tool_darwin_arm64.tar.gz", Hash: "bbb"},
+				},
+			},
+		},
+	}
+	script, err := Generator{}.Generate(installSpec, generator.Options{Target: &spec.Platform{OS: "linux", Arch: "amd64"}})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	got := string(script)
+	for _, want := range []string{
+		"\nOS='linux'\n",
+		"\nARCH='amd64'\n",
+		"\n1.0.0:tool_linux_amd64.tar.gz:aaa\"",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Generate() output does not contain %q", want)
+		}
+	}
+	for _, notWant := range []string{"uname_os", "uname_arch", "is_rosetta2_available", "tool_darwin_arm64.tar.gz"} {
+		if strings.Contains(got, notWant) {
+			t.Errorf("Generate() output contains %q", notWant)
+		}
+	}
+	if len(installSpec.Checksums.EmbeddedChecksums["v1.0.0"]) != 2 {
+		t.Error("Generate() modified the embedded checksums of the spec")
+	}
+
+	_, err = Generator{}.Generate(installSpec, generator.Options{Target: &spec.Platform{OS: "windows", Arch: "amd64"}})
+	if err == nil {
+		t.Error("Generate() error = nil, want error for an unsupported target")
+	}
+}
+
+func TestGenerateVerifyPlugins(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	pwned := filepath.Join(t.TempDir(), "pwned")
+	required := false
+	installSpec := &spec.InstallSpec{
+		Repo:  "owner/tool",
+		Asset: spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"},
+		Verify: &spec.VerifyConfig{Plugins: []spec.VerifyPlugin{
+			{Name: `it's "$(touch ` + pwned + `)"`, Command: `SCAN_MODE=full sh -c 'cat > "$OUT"; echo "$SCAN_MODE $1" >> "$OUT"' scan`},
+			{Name: "optional", Command: "binstaller-no-such-command", Required: &required},
+		}},
+	}
+	script, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	prelude := `log_info() { :; }
+log_warn() { :; }
+log_crit() { echo "$@" >&2; }
+` + shellFunction(t, string(script), "is_command") +
+		shellFunction(t, string(script), "json_string") +
+		shellFunction(t, string(script), "verify_plugins")
+	run := func(plugins string) (string, error) {
+		cmd := exec.Command("sh", "-c", prelude+plugins+`
+NAME=tool REPO='o/"tool"' VERSION=1.0.0 TAG=v1.0.0 UNAME_OS=linux ARCH=amd64 ASSET_FILENAME='tool\x.tar.gz'
+verify_plugins /tmp/tool.tar.gz`)
+		cmd.Env = append(os.Environ(), "OUT="+out)
+		got, err := cmd.CombinedOutput()
+		return string(got), err
+	}
+
+	if got, err := run(""); err != nil {
+		t.Fatalf("verify_plugins failed: %v\n%s", err, got)
+	}
+	if _, err := os.Stat(pwned); err == nil {
+		t.Error("the plugin name was evaluated by the shell")
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("plugin did not run: %v", err)
+	}
+	payload, rest, _ := strings.Cut(string(data), "\n")
+	var info map[string]string
+	if err := json.Unmarshal([]byte(payload), &info); err != nil {
+		t.Fatalf("plugin stdin is not valid JSON: %v\n%s", err, payload)
+	}
+	if info["repo"] != `o/"tool"` || info["asset_filename"] != `tool\x.tar.gz` || info["asset_path"] != "/tmp/tool.tar.gz" {
+		t.Errorf("plugin stdin = %v", info)
+	}
+	if got, want := strings.TrimSpace(rest), "full /tmp/tool.tar.gz"; got != want {
+		t.Errorf("plugin got %q, want %q", got, want)
+	}
+
+	// A required plugin that is not installed fails the verification
+	installSpec.Verify.Plugins[1].Required = nil
+	script, err = Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	prelude = prelude[:strings.Index(prelude, "verify_plugins() {")] + shellFunction(t, string(script), "verify_plugins")
+	if got, err := run(""); err == nil || !strings.Contains(got, "binstaller-no-such-command") {
+		t.Errorf("verify_plugins with a missing required plugin = %v\n%s", err, got)
+	}
+}
+
+func TestGenerateInvalidSignature(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name    string
+		sig     spec.SignatureConfig
+		wantErr bool
+	}{
+		{name: "cosign key", sig: spec.SignatureConfig{Key: "https://example.com/cosign.pub"}},
+		{name: "cosign keyless identity", sig: spec.SignatureConfig{
+			CertificateIdentity:   "https://github.com/owner/tool/.github/workflows/release.yml@refs/tags/v1.0.0",
+			CertificateOIDCIssuer: "https://token.actions.githubusercontent.com",
+		}},
+		{name: "cosign keyless identity regexp", sig: spec.SignatureConfig{
+			CertificateIdentityRegexp: "^https://github.com/owner/tool/",
+			CertificateOIDCIssuer:     "https://token.actions.githubusercontent.com",
+		}},
+		{name: "cosign keyless without identity", sig: spec.SignatureConfig{
+			CertificateOIDCIssuer: "https://token.actions.githubusercontent.com",
+		}, wantErr: true},
+		{name: "cosign keyless without issuer", sig: spec.SignatureConfig{
+			CertificateIdentityRegexp: "^https://github.com/owner/tool/",
+		}, wantErr: true},
+		{name: "cosign keyless empty", sig: spec.SignatureConfig{Type: "cosign"}, wantErr: true},
+		{name: "minisign key", sig: spec.SignatureConfig{Type: "minisign", Key: "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"}},
+		{name: "minisign without key", sig: spec.SignatureConfig{Type: "minisign"}, wantErr: true},
+		{name: "signify without key", sig: spec.SignatureConfig{Type: "signify"}, wantErr: true},
+		{name: "unsupported type", sig: spec.SignatureConfig{Type: "gpg", Key: "key"}, wantErr: true},
+		{name: "unsupported target", sig: spec.SignatureConfig{Key: "key", Target: "archive"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := tt.sig
+			sig.Enabled = &enabled
+			installSpec := &spec.InstallSpec{
+				Repo:      "owner/tool",
+				Asset:     spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"},
+				Signature: &sig,
+			}
+			_, err := Generate(installSpec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateSignify(t *testing.T) {
+	enabled := true
+	installSpec := &spec.InstallSpec{
+		Repo:  "owner/tool",
+		Asset: spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"},
+		Signature: &spec.SignatureConfig{
+			Enabled: &enabled,
+			Type:    "signify",
+			Key:     "RWRCSwAAAAB12q4Jj3Ad3iA8DvwfDmhBJ3g4qW7wDJQYkfyyjA2Y5mXW6DjSDwp4",
+		},
+	}
+	script, err := Generate(installSpec)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	got := string(script)
+	for _, want := range []string{
+		"signature_filename() (\n  TARGET_FILENAME=\"$1\"\n  echo \"${TARGET_FILENAME}.sig\"\n)",
+		"SIGNIFY=signify-openbsd",
+		"RWRCSwAAAAB12q4Jj3Ad3iA8DvwfDmhBJ3g4qW7wDJQYkfyyjA2Y5mXW6DjSDwp4\" >\"${TMPDIR}/signify.pub\"",
+		"\"$SIGNIFY\" -V -q -p \"${TMPDIR}/signify.pub\"",
+		"verify_signature \"${TMPDIR}/${ASSET_FILENAME}\"",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Generate() output does not contain %q", want)
+		}
+	}
+}
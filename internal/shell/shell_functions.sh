@@ -1,21 +1,289 @@
+log_warn() {
+  log_priority 4 || return 0
+  echoerr "$(log_prefix)" "$(log_tag 4)" "$@"
+}
+
+# Download the release asset named $3 of tag $2 of repository $1 to $4
+# through the GitHub API using GITHUB_TOKEN, which also works for private
+# repositories.
+github_api_download() {
+  owner_repo=$1
+  tag=$2
+  asset_name=$3
+  local_file=$4
+  release_json=$(http_copy "${GITHUB_API_URL}/repos/${owner_repo}/releases/tags/${tag}" "Authorization: Bearer ${GITHUB_TOKEN}") || return 1
+  asset_url=$(echo "$release_json" | awk -F'"' -v name="$asset_name" '
+    $2 == "url" && $4 ~ /\/releases\/assets\/[0-9]+$/ { url = $4 }
+    $2 == "name" && $4 == name && url != "" { print url; exit }')
+  test -z "$asset_url" && return 1
+  log_debug "github_api_download ${asset_url}"
+  if is_command curl; then
+    curl -fsSL --retry "${DOWNLOAD_RETRIES:-0}" -H "Authorization: Bearer ${GITHUB_TOKEN}" -H "Accept: application/octet-stream" -o "$local_file" "$asset_url"
+    return
+  elif is_command wget; then
+    if ! wget --help 2>&1 | grep -q -- --max-redirect; then
+      log_debug "github_api_download requires curl or a wget supporting --max-redirect, e.g. not BusyBox wget"
+      return 1
+    fi
+    # wget resends custom headers on redirect, which the storage backend
+    # rejects, so resolve the redirect first and download it without them.
+    location=$(wget -q -S --max-redirect=0 --header "Authorization: Bearer ${GITHUB_TOKEN}" --header "Accept: application/octet-stream" -O /dev/null "$asset_url" 2>&1 | sed -n 's/^ *Location: *//p' | tr -d '\r' | tail -n 1)
+    test -z "$location" && return 1
+    wget -q -O "$local_file" "$location"
+    return
+  fi
+  log_crit "github_api_download unable to find wget or curl"
+  return 1
+}
+
+# Report whether the URL $1 exists without downloading it.
+http_exists() {
+  if is_command curl; then
+    curl -fsIL -o /dev/null "$1" 2>/dev/null
+  elif is_command wget; then
+    wget -q --spider "$1" 2>/dev/null
+  else
+    return 1
+  fi
+}
+
+# Print the latest release tag of repository $1. With GITHUB_TOKEN, the
+# GitHub API is queried with the token so that the request counts against the
+# token's rate limit instead of the anonymous one.
+github_latest_release() {
+  if [ -z "${GITHUB_TOKEN:-}" ]; then
+    github_release "$1" latest
+    return
+  fi
+  json=$(http_copy "${GITHUB_API_URL}/repos/$1/releases/latest" "Authorization: Bearer ${GITHUB_TOKEN}")
+  test -z "$json" && return 1
+  tag=$(echo "$json" | tr -s '\n' ' ' | sed 's/.*"tag_name": *"//' | sed 's/".*//')
+  test -z "$tag" && return 1
+  echo "$tag"
+}
+
+# Print the published release tags of repository $1, newest first.
+# Prereleases are skipped unless $2 is non-empty. Only the first page of 100
+# releases is considered.
+github_releases() {
+  releases_url="${GITHUB_API_URL}/repos/$1/releases?per_page=100"
+  if [ -n "${GITHUB_TOKEN:-}" ]; then
+    json=$(http_copy "$releases_url" "Authorization: Bearer ${GITHUB_TOKEN}")
+  else
+    json=$(http_copy "$releases_url")
+  fi
+  test -z "$json" && return 1
+  echo "$json" | tr ',{' '\n\n' | awk -F'"' -v pre="${2:-}" '
+    $2 == "tag_name" { tag = $4; draft = 0 }
+    $2 == "draft" && $3 ~ /true/ { draft = 1 }
+    $2 == "prerelease" && tag != "" {
+      if ((pre != "" || $3 !~ /true/) && !draft) print tag
+      tag = ""
+    }'
+}
+
+# Print the newest published release tag of repository $1 starting with $2,
+# for monorepos that release several components from one repository.
+# Prereleases are considered if $3 is non-empty.
+github_release_with_prefix() {
+  tag=$(github_releases "$1" "${3:-}" | awk -v prefix="$2" 'prefix == "" || index($0, prefix) == 1 { print; exit }')
+  test -z "$tag" && return 1
+  echo "$tag"
+}
+
+# Report whether $1 is a version range rather than a tag: ^1.2, ~1.2.3,
+# 1.x or 1.2.*
+is_version_range() {
+  echo "$1" | grep -Eq '^[~^]|(^|[.])[xX*]([.]|$)'
+}
+
+# Print the highest release tag of repository $1 whose version matches the
+# range $2. Tags are stripped of the prefix $3 and a leading v before they
+# are compared; tags that are not plain MAJOR[.MINOR[.PATCH]] versions are
+# ignored. As with npm, ^ allows changes that do not modify the left-most
+# non-zero part and ~ allows patch-level changes.
+github_release_in_range() {
+  tag=$(github_releases "$1" | awk -v range="$2" -v prefix="$3" '
+    function cmp(a, b, i) {
+      for (i = 1; i <= 3; i++) if (a[i] != b[i]) return a[i] < b[i] ? -1 : 1
+      return 0
+    }
+    BEGIN {
+      op = substr(range, 1, 1)
+      if (op == "^" || op == "~") range = substr(range, 2)
+      else op = ""
+      sub(/^v/, "", range)
+      n = split(range, p, ".")
+      fixed = 0
+      for (i = 1; i <= 3; i++) {
+        if (i <= n && p[i] ~ /^[0-9]+$/) {
+          if (fixed == i - 1) fixed = i
+          lo[i] = p[i] + 0
+        } else {
+          lo[i] = 0
+        }
+      }
+      # Upper bound (exclusive): bump the last part that must stay fixed
+      keep = fixed
+      if (op == "~") keep = fixed >= 2 ? 2 : 1
+      if (op == "^") {
+        keep = 1
+        if (lo[1] == 0 && fixed >= 2) keep = 2
+        if (lo[1] == 0 && lo[2] == 0 && fixed >= 3) keep = 3
+      }
+      if (keep > fixed) keep = fixed
+      for (i = 1; i <= 3; i++) hi[i] = i <= keep ? lo[i] : 0
+      unbounded = keep == 0
+      if (!unbounded) hi[keep]++
+      if (op == "") for (i = 1; i <= 3; i++) if (i > fixed) lo[i] = 0
+    }
+    {
+      v = $0
+      if (prefix != "") {
+        if (index(v, prefix) != 1) next
+        v = substr(v, length(prefix) + 1)
+      }
+      sub(/^v/, "", v)
+      if (v !~ /^[0-9]+([.][0-9]+)?([.][0-9]+)?$/) next
+      m = split(v, q, ".")
+      for (i = 1; i <= 3; i++) x[i] = i <= m ? q[i] + 0 : 0
+      if (cmp(x, lo) < 0 || (!unbounded && cmp(x, hi) >= 0)) next
+      print x[1], x[2], x[3], $0
+    }' | sort -k1,1n -k2,2n -k3,3n | tail -n 1 | cut -d' ' -f4)
+  test -z "$tag" && return 1
+  echo "$tag"
+}
+
+# Print $1 with the .exe suffix executables need on Windows
+exe_name() {
+  case "$1" in
+  *.exe) echo "$1" ;;
+  *) echo "$1.exe" ;;
+  esac
+}
+
+# Print the bsdtar command, which is tar itself on macOS and the BSDs
+bsdtar_command() {
+  if is_command bsdtar; then
+    echo bsdtar
+  elif tar --version 2>/dev/null | grep -q bsdtar; then
+    echo tar
+  else
+    return 1
+  fi
+}
+
+# Extract the tarball $2 by piping it through the decompressor $1 (or cat)
+# into plain "tar -x -o -f -", which minimal tar implementations such as
+# BusyBox tar support, stripping $3 leading path components.
+untar_portable() {
+  decompress=$1
+  tarball=$2
+  strip_components=${3:-0}
+  set -- -x -o -f -
+  if [ "${strip_components}" -gt 0 ]; then
+    set -- "$@" --strip-components "${strip_components}"
+  fi
+  if [ "$decompress" = cat ]; then
+    tar "$@" <"${tarball}"
+  else
+    "$decompress" -dc "${tarball}" | tar "$@"
+  fi
+}
+
 untar() {
   tarball=$1
   strip_components=${2:-0} # default 0
+  if [ -n "${PORTABLE:-}" ]; then
+    case "${tarball}" in
+    *.tar.gz | *.tgz) untar_portable gzip "${tarball}" "${strip_components}"; return ;;
+    *.tar.xz) untar_portable xz "${tarball}" "${strip_components}"; return ;;
+    *.tar.bz2) untar_portable bzip2 "${tarball}" "${strip_components}"; return ;;
+    *.tar) untar_portable cat "${tarball}" "${strip_components}"; return ;;
+    esac
+  fi
   case "${tarball}" in
   *.tar.gz | *.tgz) tar --no-same-owner -xzf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar.xz) tar --no-same-owner -xJf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar.bz2) tar --no-same-owner -xjf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar) tar --no-same-owner -xf "${tarball}" --strip-components "${strip_components}" ;;
   *.gz) gunzip "${tarball}" ;;
+  *.7z)
+    if is_command 7z; then
+      7z x -y "${tarball}" >/dev/null
+    elif is_command 7zz; then
+      7zz x -y "${tarball}" >/dev/null
+    elif is_command 7za; then
+      7za x -y "${tarball}" >/dev/null
+    else
+      log_err "7z, 7zz or 7za is required to extract ${tarball}"
+      return 1
+    fi
+    ;;
+  *.deb)
+    if is_command dpkg-deb; then
+      dpkg-deb -x "${tarball}" .
+    elif is_command ar; then
+      ar x "${tarball}"
+      for data in data.tar*; do
+        tar --no-same-owner -xf "${data}"
+      done
+    else
+      log_err "dpkg-deb or ar is required to extract ${tarball}"
+      return 1
+    fi
+    ;;
+  *.rpm)
+    if is_command rpm2cpio && is_command cpio; then
+      rpm2cpio "${tarball}" | cpio -idm --quiet
+    elif is_command bsdtar; then
+      bsdtar -xf "${tarball}"
+    else
+      log_err "rpm2cpio and cpio (or bsdtar) are required to extract ${tarball}"
+      return 1
+    fi
+    ;;
+  *.dmg)
+    if ! is_command hdiutil; then
+      log_err "hdiutil is required to extract ${tarball} (macOS only)"
+      return 1
+    fi
+    mount_point=$(mktemp -d)
+    hdiutil attach -nobrowse -readonly -noautoopen -mountpoint "${mount_point}" "${tarball}" >/dev/null
+    copy_status=0
+    cp -R "${mount_point}"/. . || copy_status=$?
+    hdiutil detach "${mount_point}" -quiet
+    rmdir "${mount_point}" 2>/dev/null || true
+    return "${copy_status}"
+    ;;
+  *.pkg)
+    if ! is_command pkgutil; then
+      log_err "pkgutil is required to extract ${tarball} (macOS only)"
+      return 1
+    fi
+    expand_dir=$(basename "${tarball%.pkg}")_expanded
+    pkgutil --expand "${tarball}" "${expand_dir}"
+    # Payloads are gzipped cpio archives, either at the top level (component
+    # package) or inside each component of a product archive.
+    for payload in "${expand_dir}"/Payload "${expand_dir}"/*.pkg/Payload; do
+      test -f "${payload}" || continue
+      gunzip -dc "${payload}" | cpio -idm --quiet
+    done
+    ;;
+  *.apk) tar --no-same-owner -xzf "${tarball}" 2>/dev/null ;;
   *.zip)
-    # unzip doesn't have a standard --strip-components
-    # Workaround: extract to a subdir and move contents up if stripping
-    if [ "$strip_components" -gt 0 ]; then
+    bsdtar=$(bsdtar_command) || bsdtar=""
+    if [ -n "$bsdtar" ] && { [ "$strip_components" -gt 0 ] || ! is_command unzip; }; then
+      # bsdtar reads zip files and, unlike unzip, strips components
+      "$bsdtar" --no-same-owner -xf "${tarball}" --strip-components "${strip_components}"
+    elif [ "$strip_components" -gt 0 ]; then
+      # unzip doesn't have a standard --strip-components
+      # Workaround: extract to a subdir and move contents up
       extract_dir=$(basename "${tarball%.zip}")_extracted
       unzip -q "${tarball}" -d "${extract_dir}"
       # Move contents of the *first* directory found inside extract_dir up
       # This assumes wrap_in_directory=true convention
-      first_subdir=$(find "${extract_dir}" -mindepth 1 -maxdepth 1 -type d -print -quit)
+      first_subdir=$(find "${extract_dir}" -mindepth 1 -maxdepth 1 -type d | head -n 1)
       if [ -n "$first_subdir" ]; then
         # Move all contents (* includes hidden files)
         mv "${first_subdir}"/* .
@@ -37,6 +305,170 @@ untar() {
   esac
 }
 
+# Print the object value of the first member named $1 of the JSON document
+# read from stdin, e.g. the first attestation bundle of an API response.
+json_object() {
+  tr -d '\n' | awk -v key="\"$1\"" '{
+    start = index($0, key)
+    if (start == 0) exit 1
+    s = substr($0, start + length(key))
+    sub(/^[ \t]*:[ \t]*/, "", s)
+    if (substr(s, 1, 1) != "{") exit 1
+    depth = 0
+    instr = 0
+    esc = 0
+    n = length(s)
+    for (i = 1; i <= n; i++) {
+      c = substr(s, i, 1)
+      if (instr) {
+        if (esc) esc = 0
+        else if (c == "\\") esc = 1
+        else if (c == "\"") instr = 0
+      } else if (c == "\"") {
+        instr = 1
+      } else if (c == "{") {
+        depth++
+      } else if (c == "}") {
+        depth--
+        if (depth == 0) {
+          print substr(s, 1, i)
+          exit 0
+        }
+      }
+    }
+    exit 1
+  }'
+}
+
+# Print $1 as a JSON string
+json_string() {
+  printf '"%s"' "$(printf '%s' "$1" | sed 's/\\/\\\\/g; s/"/\\"/g')"
+}
+
+# Check whether directory $1, or its nearest existing parent, is writable
+is_writable() {
+  dir="$1"
+  while [ ! -d "$dir" ]; do
+    dir=$(dirname "$dir")
+  done
+  test -w "$dir"
+}
+
+# Set SUDO so that as_installer can write to directory $1, using sudo only
+# if it is not writable and $2 is non-empty.
+prepare_install_dir() {
+  SUDO=""
+  is_writable "$1" && return 0
+  if [ -z "$2" ]; then
+    log_crit "$1 is not writable. Re-run with --sudo to install with sudo, or choose another directory with -b"
+    return 1
+  fi
+  if ! is_command sudo; then
+    log_crit "$1 is not writable and sudo is not installed"
+    return 1
+  fi
+  log_info "$1 is not writable, installing with sudo"
+  SUDO=sudo
+}
+
+# Run the install command $@, with sudo if prepare_install_dir enabled it
+as_installer() {
+  if [ -n "${SUDO:-}" ]; then
+    sudo "$@"
+  else
+    "$@"
+  fi
+}
+
+# Report whether process $1 is running. kill -0 fails for processes of
+# other users, e.g. an installer running with sudo, so /proc and ps are
+# checked too.
+is_running() {
+  kill -0 "$1" 2>/dev/null || [ -d "/proc/$1" ] || ps -p "$1" >/dev/null 2>&1
+}
+
+# Take the lock directory $1 so that concurrent installers on the same host
+# do not overwrite each other's files, waiting up to $2 seconds for the
+# current holder. mkdir is atomic, so only one installer can create it. A
+# lock whose process is gone, or which never recorded a process, is stale and
+# removed. Call release_lock when done.
+acquire_lock() {
+  lock=$1
+  waited=0
+  while ! as_installer mkdir "$lock" 2>/dev/null; do
+    owner=$(cat "$lock/pid" 2>/dev/null || true)
+    if [ -n "$owner" ] && ! is_running "$owner"; then
+      log_warn "Removing stale lock ${lock} of process ${owner}"
+      as_installer rm -rf "$lock"
+      continue
+    fi
+    if [ "$waited" -ge "$2" ]; then
+      if [ -z "$owner" ]; then
+        log_warn "Removing stale lock ${lock}"
+        as_installer rm -rf "$lock"
+        waited=0
+        continue
+      fi
+      log_crit "Timed out waiting for process ${owner} holding ${lock}"
+      return 1
+    fi
+    test "$waited" -eq 0 && log_info "Waiting for another installation holding ${lock}"
+    sleep 1
+    waited=$((waited + 1))
+  done
+  echo "$$" | as_installer tee "$lock/pid" >/dev/null
+  INSTALL_LOCK="$lock"
+}
+
+# Release the lock taken by acquire_lock, if any
+release_lock() {
+  if [ -n "${INSTALL_LOCK:-}" ]; then
+    as_installer rm -rf "$INSTALL_LOCK"
+    INSTALL_LOCK=""
+  fi
+}
+
+# Print the startup file of the user's shell
+shell_profile() {
+  case "${SHELL##*/}" in
+  zsh) echo "${ZDOTDIR:-$HOME}/.zshrc" ;;
+  bash) echo "${HOME}/.bashrc" ;;
+  fish) echo "${XDG_CONFIG_HOME:-$HOME/.config}/fish/config.fish" ;;
+  *) echo "${HOME}/.profile" ;;
+  esac
+}
+
+# Print the line of the shell profile adding directory $1 to PATH
+path_profile_line() {
+  case "${SHELL##*/}" in
+  fish) echo "fish_add_path \"$1\"" ;;
+  *) echo "export PATH=\"$1:\$PATH\"" ;;
+  esac
+}
+
+# Check whether directory $1 is in PATH. If it is not, append it to the
+# shell profile when $2 is non-empty, or print how to do so.
+check_path() {
+  dir=$(cd "$1" && pwd) || return 0
+  case ":${PATH}:" in
+  *":${dir}:"* | *":${dir}/:"*) return 0 ;;
+  esac
+  profile=$(shell_profile)
+  line=$(path_profile_line "$dir")
+  if [ -z "$2" ]; then
+    log_warn "${dir} is not in your PATH. To add it, run:"
+    log_priority 4 && echoerr "  echo '${line}' >>\"${profile}\""
+    return 0
+  fi
+  if [ -f "$profile" ] && grep -Fqx -- "$line" "$profile"; then
+    log_info "${dir} is already added to PATH in ${profile}; restart your shell to use it"
+    return 0
+  fi
+  mkdir -p "${profile%/*}"
+  printf '\n# Added by the %s installer\n%s\n' "$(log_prefix)" "$line" >>"$profile"
+  log_info "Added ${dir} to PATH in ${profile}; restart your shell to use it"
+}
+
 extract_hash() {
   TARGET=$1
   checksums=$2
@@ -45,7 +477,13 @@ extract_hash() {
     return 1
   fi
   BASENAME=${TARGET##*/}
-  grep -E "([[:space:]]|/|\*)${BASENAME}$" "${checksums}" 2>/dev/null | tr '\t' ' ' | cut -d ' ' -f 1
+  # Strip CRs so that checksum files with Windows line endings match
+  hash=$(tr -d '\r' 2>/dev/null <"${checksums}" | grep -E "([[:space:]]|/|\*)${BASENAME}$" | tr '\t' ' ' | cut -d ' ' -f 1)
+  if [ -z "$hash" ]; then
+    # BSD style: "SHA256 (file) = hash"
+    hash=$(sed -n -E "s/^[A-Za-z0-9-]+ \((.*\/)?${BASENAME}\) = ([0-9A-Fa-f]+)\r?$/\2/p" "${checksums}" 2>/dev/null)
+  fi
+  echo "$hash"
 }
 
 
@@ -61,12 +499,12 @@ hash_verify() {
     log_err "failed to calculate hash: ${TARGET_PATH}"
     return 1
   fi
-  # 1) “hash-only” line?
+  # 1) “hash-only” line, as in per-asset sidecar files (e.g. ${ASSET_FILENAME}.sha256)?
   if grep -i -E "^${got}[[:space:]]*$" "$SUMFILE" >/dev/null 2>&1; then
     return 0
   fi
   # 2) Check hash & file name match
-  want=$(extract_hash "${TARGET_PATH}" "${SUMFILE}")
+  want=$(extract_hash "${TARGET_PATH}" "${SUMFILE}" | tr 'A-F' 'a-f')
   if [ "$want" != "$got" ]; then
     log_err "hash_verify checksum for '$TARGET_PATH' did not verify ${want} vs ${got}"
     return 1
@@ -0,0 +1,403 @@
+package shell
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sha256 of "hello\n"
+const helloSHA256 = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+
+func TestHashVerifyChecksumFiles(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	asset := filepath.Join(dir, "tool_linux_amd64.tar.gz")
+	if err := os.WriteFile(asset, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lib := filepath.Join(dir, "lib.sh")
+	if err := os.WriteFile(lib, []byte(shlib+"\n"+shellFunctions+"\n"+hashSHA256), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		sumfile string
+		wantOK  bool
+	}{
+		{name: "sidecar hash only", sumfile: helloSHA256 + "\n", wantOK: true},
+		{name: "sidecar hash only CRLF", sumfile: helloSHA256 + "\r\n", wantOK: true},
+		{name: "sidecar with filename", sumfile: helloSHA256 + "  tool_linux_amd64.tar.gz\n", wantOK: true},
+		{name: "sidecar with path and CRLF", sumfile: helloSHA256 + " *dist/tool_linux_amd64.tar.gz\r\n", wantOK: true},
+		{name: "uppercase hash", sumfile: "5891B5B522D5DF086D0FF0B110FBD9D21BB4FC7163AF34D08286A2E846F6BE03  tool_linux_amd64.tar.gz\n", wantOK: true},
+		{name: "checksums file", sumfile: "0000  tool_darwin_arm64.tar.gz\n" + helloSHA256 + "  tool_linux_amd64.tar.gz\n", wantOK: true},
+		{name: "bsd style", sumfile: "SHA256 (tool_linux_amd64.tar.gz) = " + helloSHA256 + "\n", wantOK: true},
+		{name: "mismatch", sumfile: "0000  tool_linux_amd64.tar.gz\n", wantOK: false},
+		{name: "sidecar mismatch", sumfile: "0000\n", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sumfile := filepath.Join(dir, "tool_linux_amd64.tar.gz.sha256")
+			if err := os.WriteFile(sumfile, []byte(tt.sumfile), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cmd := exec.Command("sh", "-c", `. "$1" && hash_verify "$2" "$3"`, "sh", lib, asset, sumfile)
+			out, err := cmd.CombinedOutput()
+			if gotOK := err == nil; gotOK != tt.wantOK {
+				t.Errorf("hash_verify ok = %v, want %v\n%s", gotOK, tt.wantOK, out)
+			}
+		})
+	}
+}
+
+func TestGitHubLatestReleaseUsesToken(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not available")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/tool/releases/latest" || r.Header.Get("Authorization") != "Bearer test-token" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"url":"https://api.github.com/repos/o/tool/releases/1","tag_name":"v1.2.3","name":"v1.2.3"}`)
+	}))
+	defer srv.Close()
+
+	lib := filepath.Join(t.TempDir(), "lib.sh")
+	if err := os.WriteFile(lib, []byte(shlib+"\n"+shellFunctions), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("sh", "-c", `. "$1" && github_latest_release o/tool`, "sh", lib)
+	cmd.Env = append(os.Environ(), "GITHUB_TOKEN=test-token", "GITHUB_API_URL="+srv.URL)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("github_latest_release error = %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "v1.2.3" {
+		t.Errorf("github_latest_release = %q, want %q", got, "v1.2.3")
+	}
+}
+
+func TestUntarZipStripComponents(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "tool.zip")
+	writeZip(t, archive, map[string]string{
+		"tool-1.0.0/tool":          "binary\n",
+		"tool-1.0.0/doc/README.md": "readme\n",
+	})
+	lib := filepath.Join(dir, "lib.sh")
+	if err := os.WriteFile(lib, []byte(shlib+"\n"+shellFunctions), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// setup runs after sourcing lib, e.g. to hide bsdtar
+		setup string
+		need  string
+	}{
+		{name: "bsdtar", setup: "bsdtar_command >/dev/null || exit 77", need: ""},
+		{name: "unzip fallback", setup: "bsdtar_command() { return 1; }", need: "unzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.need != "" {
+				if _, err := exec.LookPath(tt.need); err != nil {
+					t.Skipf("%s not available", tt.need)
+				}
+			}
+			dest := t.TempDir()
+			cmd := exec.Command("sh", "-c", `. "$1" && `+tt.setup+` && untar "$2" 1`, "sh", lib, archive)
+			cmd.Dir = dest
+			out, err := cmd.CombinedOutput()
+			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 77 {
+				t.Skip("bsdtar not available")
+			}
+			if err != nil {
+				t.Fatalf("untar error = %v\n%s", err, out)
+			}
+			for name, want := range map[string]string{"tool": "binary\n", "doc/README.md": "readme\n"} {
+				got, err := os.ReadFile(filepath.Join(dest, name))
+				if err != nil {
+					t.Errorf("%s not extracted: %v", name, err)
+					continue
+				}
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUntarPortable(t *testing.T) {
+	for _, name := range []string{"sh", "gzip", "tar"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s not available", name)
+		}
+	}
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "tool.tar.gz")
+	writeTarGz(t, archive, map[string]string{
+		"tool-1.0.0/tool":          "binary\n",
+		"tool-1.0.0/doc/README.md": "readme\n",
+	})
+	lib := filepath.Join(dir, "lib.sh")
+	if err := os.WriteFile(lib, []byte(shlib+"\n"+shellFunctions), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := t.TempDir()
+	cmd := exec.Command("sh", "-c", `. "$1" && PORTABLE=1 && untar "$2" 1`, "sh", lib, archive)
+	cmd.Dir = dest
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("untar error = %v\n%s", err, out)
+	}
+	for name, want := range map[string]string{"tool": "binary\n", "doc/README.md": "readme\n"} {
+		got, err := os.ReadFile(filepath.Join(dest, name))
+		if err != nil {
+			t.Errorf("%s not extracted: %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSONObject(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	lib := filepath.Join(t.TempDir(), "lib.sh")
+	if err := os.WriteFile(lib, []byte(shlib+"\n"+shellFunctions), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "attestations response",
+			input:  "{\"attestations\": [\n  {\"bundle\": {\"a\": {\"b\": 1}}, \"repository_id\": 1},\n  {\"bundle\": {\"c\": 2}}\n]}\n",
+			want:   `{"a": {"b": 1}}`,
+			wantOK: true,
+		},
+		{
+			name:   "braces and escaped quotes in strings",
+			input:  `{"bundle":{"s":"}\"{","t":{}}}`,
+			want:   `{"s":"}\"{","t":{}}`,
+			wantOK: true,
+		},
+		{name: "missing key", input: `{"attestations":[]}`, wantOK: false},
+		{name: "not an object", input: `{"bundle":"x"}`, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", `. "$1" && json_object bundle`, "sh", lib)
+			cmd.Stdin = strings.NewReader(tt.input)
+			out, err := cmd.Output()
+			if gotOK := err == nil; gotOK != tt.wantOK {
+				t.Fatalf("json_object ok = %v, want %v\n%s", gotOK, tt.wantOK, out)
+			}
+			if got := strings.TrimSpace(string(out)); tt.wantOK && got != tt.want {
+				t.Errorf("json_object = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubReleaseInRange(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not available")
+	}
+	tags := []struct {
+		tag        string
+		prerelease bool
+	}{
+		{tag: "v2.0.0-rc.1", prerelease: true},
+		{tag: "v1.10.0"},
+		{tag: "v2.1.0"},
+		{tag: "v1.9.3"},
+		{tag: "v1.2.4"},
+		{tag: "v1.2.10"},
+		{tag: "v0.3.1"},
+		{tag: "v0.2.5"},
+		{tag: "v0.0.3"},
+		{tag: "nightly"},
+		{tag: "cli/v1.3.0"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/tool/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		var releases []string
+		for _, tt := range tags {
+			releases = append(releases, fmt.Sprintf(`{"tag_name": "%s", "draft": false, "prerelease": %t}`, tt.tag, tt.prerelease))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(releases, ",\n"))
+	}))
+	defer srv.Close()
+
+	lib := filepath.Join(t.TempDir(), "lib.sh")
+	if err := os.WriteFile(lib, []byte(shlib+"\n"+shellFunctions), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		version string
+		prefix  string
+		want    string
+	}{
+		{version: "^1.2", want: "v1.10.0"},
+		{version: "~1.2", want: "v1.2.10"},
+		{version: "~1.2.5", want: "v1.2.10"},
+		{version: "1.x", want: "v1.10.0"},
+		{version: "1.2.*", want: "v1.2.10"},
+		{version: "*", want: "v2.1.0"},
+		{version: "^0.2.1", want: "v0.2.5"},
+		{version: "^0.0.3", want: "v0.0.3"},
+		{version: "^v2", want: "v2.1.0"},
+		{version: "^1", prefix: "cli/", want: "cli/v1.3.0"},
+		{version: "^3", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", `. "$1" && is_version_range "$2" && github_release_in_range o/tool "$2" "$3"`, "sh", lib, tt.version, tt.prefix)
+			cmd.Env = append(os.Environ(), "GITHUB_API_URL="+srv.URL)
+			out, err := cmd.Output()
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("github_release_in_range(%q) = %q (err: %v), want %q", tt.version, got, err, tt.want)
+			}
+		})
+	}
+	for _, tt := range []struct {
+		prefix, pre, want string
+	}{
+		{want: "v1.10.0"},
+		{pre: "1", want: "v2.0.0-rc.1"},
+		{prefix: "cli/", pre: "1", want: "cli/v1.3.0"},
+	} {
+		cmd := exec.Command("sh", "-c", `. "$1" && github_release_with_prefix o/tool "$2" "$3"`, "sh", lib, tt.prefix, tt.pre)
+		cmd.Env = append(os.Environ(), "GITHUB_API_URL="+srv.URL)
+		out, _ := cmd.Output()
+		if got := strings.TrimSpace(string(out)); got != tt.want {
+			t.Errorf("github_release_with_prefix(%q, %q) = %q, want %q", tt.prefix, tt.pre, got, tt.want)
+		}
+	}
+	for _, tag := range []string{"v1.2.3", "latest", "nightly-x86", "v1.0.0-linux"} {
+		if err := exec.Command("sh", "-c", `. "$1" && is_version_range "$2"`, "sh", lib, tag).Run(); err == nil {
+			t.Errorf("is_version_range(%q) = true, want false", tag)
+		}
+	}
+}
+
+func TestAcquireLock(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	lib := filepath.Join(t.TempDir(), "lib.sh")
+	if err := os.WriteFile(lib, []byte(shlib+"\n"+shellFunctions), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		owner  string // pid file content of an existing lock, "-" for no lock
+		wantOK bool
+	}{
+		{name: "free", owner: "-", wantOK: true},
+		{name: "held by running process", owner: fmt.Sprint(os.Getpid()), wantOK: false},
+		{name: "stale", owner: "999999999", wantOK: true},
+		{name: "stale without pid", owner: "", wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock := filepath.Join(t.TempDir(), ".tool.lock")
+			if tt.owner != "-" {
+				if err := os.Mkdir(lock, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if tt.owner != "" {
+					if err := os.WriteFile(filepath.Join(lock, "pid"), []byte(tt.owner+"\n"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			cmd := exec.Command("sh", "-c", `. "$1" && acquire_lock "$2" 0 && test "$(cat "$2/pid")" = "$$" && release_lock && test ! -e "$2"`, "sh", lib, lock)
+			out, err := cmd.CombinedOutput()
+			if gotOK := err == nil; gotOK != tt.wantOK {
+				t.Errorf("acquire_lock ok = %v, want %v\n%s", gotOK, tt.wantOK, out)
+			}
+		})
+	}
+}
@@ -128,21 +128,55 @@ http_download_curl() {
   local_file=$1
   source_url=$2
   header=$3
-  if [ -z "$header" ]; then
-    curl -fsSL -o "$local_file" "$source_url"
+  set -- -fSL --retry "${DOWNLOAD_RETRIES:-0}"
+  if [ -n "${DOWNLOAD_PROGRESS:-}" ]; then
+    set -- "$@" -#
   else
-    curl -fsSL -H "$header" -o "$local_file" "$source_url"
+    set -- "$@" -s
   fi
+  if [ -n "${DOWNLOAD_RESUME:-}" ]; then
+    set -- "$@" -C -
+  fi
+  if [ "${DOWNLOAD_TIMEOUT:-0}" != 0 ]; then
+    set -- "$@" --max-time "$DOWNLOAD_TIMEOUT"
+  fi
+  if [ -n "$header" ]; then
+    set -- "$@" -H "$header"
+  fi
+  curl "$@" -o "$local_file" "$source_url"
 }
 http_download_wget() {
   local_file=$1
   source_url=$2
   header=$3
-  if [ -z "$header" ]; then
-    wget -q -O "$local_file" "$source_url"
-  else
-    wget -q --header "$header" -O "$local_file" "$source_url"
+  set -- -q
+  if [ -n "${DOWNLOAD_PROGRESS:-}" ]; then
+    if wget --help 2>&1 | grep -q -- --show-progress; then
+      set -- "$@" --show-progress
+    else
+      set --
+    fi
+  fi
+  if [ -n "${DOWNLOAD_RESUME:-}" ]; then
+    set -- "$@" -c
+  fi
+  if [ "${DOWNLOAD_TIMEOUT:-0}" != 0 ]; then
+    set -- "$@" -T "$DOWNLOAD_TIMEOUT"
   fi
+  if [ -n "$header" ]; then
+    set -- "$@" --header "$header"
+  fi
+  attempt=0
+  delay=1
+  until wget "$@" -O "$local_file" "$source_url"; do
+    attempt=$((attempt + 1))
+    if [ "$attempt" -gt "${DOWNLOAD_RETRIES:-0}" ]; then
+      return 1
+    fi
+    log_debug "http_download_wget retrying $source_url in ${delay}s"
+    sleep "$delay"
+    delay=$((delay * 2))
+  done
 }
 http_download() {
   log_debug "http_download $2"
@@ -158,7 +192,13 @@ http_download() {
 }
 http_copy() {
   tmp=$(mktemp)
-  http_download "${tmp}" "$1" "$2" || return 1
+  progress=${DOWNLOAD_PROGRESS:-}
+  DOWNLOAD_PROGRESS=""
+  http_download "${tmp}" "$1" "$2" || {
+    DOWNLOAD_PROGRESS=$progress
+    return 1
+  }
+  DOWNLOAD_PROGRESS=$progress
   body=$(cat "$tmp")
   rm -f "${tmp}"
   echo "$body"
@@ -167,7 +207,7 @@ github_release() {
   owner_repo=$1
   version=$2
   test -z "$version" && version="latest"
-  giturl="https://github.com/${owner_repo}/releases/${version}"
+  giturl="${GITHUB_BASE_URL:-https://github.com}/${owner_repo}/releases/${version}"
   json=$(http_copy "$giturl" "Accept:application/json")
   test -z "$json" && return 1
   version=$(echo "$json" | tr -s '\n' ' ' | sed 's/.*"tag_name":"//' | sed 's/".*//')
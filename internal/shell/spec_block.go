@@ -0,0 +1,77 @@
+package shell
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	specBlockBegin = "# --- BEGIN BINSTALLER SPEC ---"
+	specBlockEnd   = "# --- END BINSTALLER SPEC ---"
+)
+
+// EmbedSpec inserts specYAML into script as a comment block right after the
+// "Code generated" header line, making the script self-describing. The spec
+// can be recovered with ExtractSpec.
+func EmbedSpec(script, specYAML []byte) []byte {
+	var block bytes.Buffer
+	block.WriteString(specBlockBegin + "\n")
+	for _, line := range strings.Split(strings.TrimSuffix(string(specYAML), "\n"), "\n") {
+		if line == "" {
+			block.WriteString("#\n")
+			continue
+		}
+		block.WriteString("# " + line + "\n")
+	}
+	block.WriteString(specBlockEnd + "\n")
+
+	pos := headerEnd(script)
+	out := make([]byte, 0, len(script)+block.Len())
+	out = append(out, script[:pos]...)
+	out = append(out, block.Bytes()...)
+	out = append(out, script[pos:]...)
+	return out
+}
+
+// headerEnd returns the offset right after the shebang and the generated
+// header comment of script.
+func headerEnd(script []byte) int {
+	pos := 0
+	for i := 0; i < 2; i++ {
+		nl := bytes.IndexByte(script[pos:], '\n')
+		if nl < 0 {
+			break
+		}
+		pos += nl + 1
+	}
+	return pos
+}
+
+// ExtractSpec returns the spec YAML embedded in script by EmbedSpec.
+func ExtractSpec(script []byte) ([]byte, error) {
+	var out bytes.Buffer
+	inBlock, found := false, false
+	for _, line := range strings.Split(string(script), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case line == specBlockBegin:
+			inBlock = true
+		case line == specBlockEnd && inBlock:
+			inBlock, found = false, true
+		case inBlock:
+			if line != "#" && !strings.HasPrefix(line, "# ") {
+				return nil, errors.Errorf("malformed embedded spec line: %q", line)
+			}
+			out.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "#"), " ") + "\n")
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		return nil, errors.New("no embedded spec found in script (generate it with --embed-spec)")
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,33 @@
+package shell
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbedSpecRoundTrip(t *testing.T) {
+	script := []byte("#!/bin/sh\n# Code generated by binstaller. DO NOT EDIT.\n#\nset -e\n")
+	specYAML := []byte("schema: v1\n# comment\nname: foo\n\nasset:\n  template: ${NAME}\n")
+
+	embedded := EmbedSpec(script, specYAML)
+	if !strings.HasPrefix(string(embedded), "#!/bin/sh\n# Code generated by binstaller. DO NOT EDIT.\n"+specBlockBegin+"\n") {
+		t.Errorf("spec block not inserted after header:\n%s", embedded)
+	}
+	if !strings.HasSuffix(string(embedded), specBlockEnd+"\n#\nset -e\n") {
+		t.Errorf("script body not preserved:\n%s", embedded)
+	}
+
+	got, err := ExtractSpec(embedded)
+	if err != nil {
+		t.Fatalf("ExtractSpec() error = %v", err)
+	}
+	if string(got) != string(specYAML) {
+		t.Errorf("ExtractSpec() = %q, want %q", got, specYAML)
+	}
+}
+
+func TestExtractSpecNotFound(t *testing.T) {
+	if _, err := ExtractSpec([]byte("#!/bin/sh\nset -e\n")); err == nil {
+		t.Error("ExtractSpec() expected error for script without spec")
+	}
+}
@@ -1,18 +1,70 @@
 #!/bin/sh
 # Code generated by binstaller. DO NOT EDIT.
 #
-set -e
+set -e{{ block "header" . }}{{ end }}
 usage() {
   this=$1
   cat <<EOF
 $this: download ${NAME} from ${REPO}
 
-Usage: $this [-b bindir] [-d] [tag]
+Usage: $this [-b bindir] [-d] [-n] [-o os] [-a arch] [-v version]
+          [--retries=N] [--timeout=SECONDS] [--progress] [--resume] [--print-url]
+          [--from-file=PATH] [--base-url=URL] [--require-checksum]
+          [--pre] [--force] [--add-to-path] [--sudo] [--json]
+          [--keep=DIR] [--extract-only=DIR]{{ with .Install }}{{ if .GoModule }} [--go-install]{{ end }}{{ if .CompletionCommand }} [--completions]{{ end }}{{ end }} [tag]
   -b sets bindir or installation directory, Defaults to ./bin
   -d turns on debug logging
+  -n, --dry-run resolves the release and logs every action without
+     downloading or installing anything
+  -o sets the target OS instead of detecting it, e.g. linux (or \$OVERRIDE_OS)
+  -a sets the target architecture instead of detecting it, e.g. arm64
+     (or \$OVERRIDE_ARCH)
+  -v sets the tag or version range to install, like [tag]
+  --retries=N retries failed downloads N times with exponential backoff
+     (default: {{ .Download.RetryCount }}, or \$BINSTALLER_RETRIES)
+  --timeout=SECONDS aborts downloads taking longer than SECONDS
+     (default: {{ .Download.TimeoutSeconds }}, 0 for no limit, or \$BINSTALLER_TIMEOUT)
+  --progress shows download progress when run in a terminal
+     (or \$BINSTALLER_PROGRESS=1)
+  --resume resumes interrupted downloads on retry (or \$BINSTALLER_RESUME=1)
+  --print-url prints the resolved platform, version, asset URL, checksum URL
+     and expected hash as KEY=VALUE lines without downloading anything
+  --from-file=PATH installs the pre-downloaded release asset at PATH instead
+     of downloading it. It is verified like a downloaded asset, against the
+     embedded checksum if any, so pass the tag of the release it belongs to.
+  --base-url=URL downloads release files from a mirror of {{ .GitHubBase }}
+     at URL, e.g. an Artifactory or Nexus proxy (or \$DOWNLOAD_BASE_URL).
+     Checksums are still verified. Pass a tag to avoid querying GitHub for
+     the latest release.
+  --require-checksum fails instead of falling back to the checksum file when
+     no checksum is embedded for the asset (or \$BINSTALLER_REQUIRE_CHECKSUM=1)
+  --pre considers prereleases when resolving the latest release
+     (or \$BINSTALLER_PRERELEASE=1{{ if .Version.IncludesPrereleases }}, default for this script{{ end }})
+{{- with .Install }}{{ if .VersionCommand }}
+  --force reinstalls even if the requested version is already installed
+     (or \$BINSTALLER_FORCE=1)
+{{- end }}{{ end }}
+  --add-to-path appends bindir to the PATH of your shell's startup file if
+     it is missing (or \$BINSTALLER_ADD_TO_PATH=1)
+  --sudo installs with sudo if bindir is not writable, e.g. /usr/local/bin.
+     Only the final install step runs under sudo.
+  --json prints a JSON object describing the installation to stdout
+  --keep=DIR copies the verified release asset to DIR
+  --extract-only=DIR extracts the verified release asset to DIR instead of
+     installing it
+{{- with .Install }}{{ if .GoModule }}
+  --go-install builds {{ .GoModule }} with go install if the release has
+     no asset for the platform (or \$BINSTALLER_GO_INSTALL=1)
+{{- end }}{{ end }}
+{{- with .Install.Completions }}
+  --completions installs {{ range $i, $shell := . }}{{ if $i }}, {{ end }}{{ $shell }}{{ end }} completions generated by the installed
+     binary under the parent directory of bindir (or \$BINSTALLER_COMPLETIONS=1)
+{{- end }}
    [tag] is a tag from
-   https://github.com/{{ .Repo }}/releases
+   {{ .GitHubBase }}/{{ .Repo }}/releases
    If tag is missing, then the latest will be used.
+   A version range like ^1.2, ~1.2.3 or 1.x installs the highest release
+   matching it.
 
  Generated by binstaller
   https://github.com/haya14busa/binstaller
@@ -26,12 +78,16 @@ EOF
 
 {{ .ShellFunctions }}
 
-# --- Embedded Checksums (Format: VERSION:FILENAME:HASH) ---
+{{- $hashEmbedded := "hash_compute" }}
+{{- $extraAlgorithms := extraHashAlgorithms .InstallSpec }}
+{{- if $extraAlgorithms }}{{ $hashEmbedded = "hash_embedded" }}{{ end }}
+
+# --- Embedded Checksums (Format: VERSION:FILENAME:HASH{{ if $extraAlgorithms }}[:ALGORITHM]{{ end }}) ---
 EMBEDDED_CHECKSUMS="
 {{- if .Checksums -}}
 {{- range $version, $checksums := .Checksums.EmbeddedChecksums }}
 {{- range $checksum := $checksums }}
-{{ trimPrefix $version "v" }}:{{ $checksum.Filename }}:{{ $checksum.Hash }}
+{{ $.VersionFromTag $version }}:{{ $checksum.Filename }}:{{ $checksum.Hash }}{{ with embeddedAlgorithm $.InstallSpec $checksum }}:{{ . }}{{ end }}
 {{- end }}
 {{- end }}
 {{- end }}"
@@ -42,42 +98,404 @@ find_embedded_checksum() {
   filename="$2"
   echo "$EMBEDDED_CHECKSUMS" | grep -E "^${version}:${filename}:" | cut -d':' -f3
 }
+{{- if $extraAlgorithms }}
+
+# Find the algorithm of the embedded checksum for a given version and
+# filename, empty for {{ hashAlgorithm .InstallSpec }}
+find_embedded_algorithm() {
+  version="$1"
+  filename="$2"
+  echo "$EMBEDDED_CHECKSUMS" | grep -E "^${version}:${filename}:" | cut -d':' -f4
+}
+
+# Compute the hash of $1 with the algorithm of the embedded checksum
+hash_embedded() {
+  case "${EMBEDDED_ALGORITHM}" in
+  {{- range $extraAlgorithms }}
+  {{ . }}) {{ hashFuncName . }} "$1" ;;
+  {{- end }}
+  *) hash_compute "$1" ;;
+  esac
+}
+{{- end }}
+{{- if hasEmbeddedURLs .Checksums }}
+
+# --- Embedded alternate download URLs (Format: VERSION:FILENAME:URL) ---
+EMBEDDED_URLS="
+{{- range $version, $checksums := .Checksums.EmbeddedChecksums }}
+{{- range $checksum := $checksums }}
+{{- range $url := $checksum.URLs }}
+{{ $.VersionFromTag $version }}:{{ $checksum.Filename }}:{{ $url }}
+{{- end }}
+{{- end }}
+{{- end }}"
+
+# Find alternate download URLs for a given version and filename
+find_embedded_urls() {
+  version="$1"
+  filename="$2"
+  echo "$EMBEDDED_URLS" | grep -E "^${version}:${filename}:" | cut -d':' -f3-
+}
+
+# Download the release file to $1, falling back to each of the given mirror
+# URLs in turn, until the file matches the expected hash $2. Mirrors are
+# never trusted without a matching hash.
+download_verified() {
+  target="$1"
+  want="$2"
+  shift 2
+  log_info "Downloading ${ASSET_URL}"
+  if download_release_file "${target}" "${target##*/}"; then
+    got=$({{ $hashEmbedded }} "${target}")
+    if [ "$got" = "$want" ]; then
+      return 0
+    fi
+    log_warn "Checksum mismatch for ${ASSET_URL}: expected ${want}, got ${got}"
+  else
+    log_warn "Download failed: ${ASSET_URL}"
+  fi
+  for url in "$@"; do
+    log_info "Downloading ${url}"
+    if ! http_download "${target}" "${url}"; then
+      log_warn "Download failed: ${url}"
+      continue
+    fi
+    got=$({{ $hashEmbedded }} "${target}")
+    if [ "$got" = "$want" ]; then
+      return 0
+    fi
+    log_warn "Checksum mismatch for ${url}: expected ${want}, got ${got}"
+  done
+  log_crit "Unable to download ${target##*/} with a matching checksum"
+  return 1
+}
+{{- end }}
 
 parse_args() {
-  BINDIR="{{ .DefaultBinDir }}"
-  while getopts "b:dqh?x" arg; do
+  BINDIR="{{ if not .DefaultBinDir.PerOS }}{{ .DefaultBinDir.Default }}{{ end }}"
+  DOWNLOAD_RETRIES="${BINSTALLER_RETRIES:-{{ .Download.RetryCount }}}"
+  DOWNLOAD_TIMEOUT="${BINSTALLER_TIMEOUT:-{{ .Download.TimeoutSeconds }}}"
+  DOWNLOAD_PROGRESS="${BINSTALLER_PROGRESS:-}"
+  DOWNLOAD_RESUME="${BINSTALLER_RESUME:-}"
+  LOCK_TIMEOUT="${BINSTALLER_LOCK_TIMEOUT:-300}"
+  PRINT_URL=""
+  DRY_RUN=""
+  FROM_FILE=""
+  DOWNLOAD_BASE_URL="${DOWNLOAD_BASE_URL:-}"
+  FORCE="${BINSTALLER_FORCE:-}"
+  ADD_TO_PATH="${BINSTALLER_ADD_TO_PATH:-}"
+  USE_SUDO=""
+  JSON_OUTPUT=""
+  PORTABLE="${BINSTALLER_PORTABLE:-{{ if and .Unpack .Unpack.Portable }}1{{ end }}}"
+  KEEP_DIR=""
+  EXTRACT_DIR=""
+  GO_INSTALL="${BINSTALLER_GO_INSTALL:-}"
+{{- if .Install.Completions }}
+  COMPLETIONS="${BINSTALLER_COMPLETIONS:-}"
+{{- end }}
+  PRERELEASE="${BINSTALLER_PRERELEASE:-{{ if .Version.IncludesPrereleases }}1{{ end }}}"
+  REQUIRE_CHECKSUM="${BINSTALLER_REQUIRE_CHECKSUM:-{{ with .Checksums }}{{ if deref .RequireEmbedded }}1{{ end }}{{ end }}}"
+  OVERRIDE_OS="${OVERRIDE_OS:-${BINSTALLER_OS:-}}"
+  OVERRIDE_ARCH="${OVERRIDE_ARCH:-${BINSTALLER_ARCH:-}}"
+  VERSION_ARG=""
+  while getopts "b:dnqo:a:v:h?x-:" arg; do
     case "$arg" in
     b) BINDIR="$OPTARG" ;;
     d) log_set_priority 10 ;;
+    n) DRY_RUN=1 ;;
     q) log_set_priority 3 ;;
+    o) OVERRIDE_OS="$OPTARG" ;;
+    a) OVERRIDE_ARCH="$OPTARG" ;;
+    v) VERSION_ARG="$OPTARG" ;;
     h | \?) usage "$0" ;;
     x) set -x ;;
+    -)
+      # Long options: --name or --name=value
+      case "$OPTARG" in
+      retries=*) DOWNLOAD_RETRIES="${OPTARG#*=}" ;;
+      timeout=*) DOWNLOAD_TIMEOUT="${OPTARG#*=}" ;;
+      progress) DOWNLOAD_PROGRESS=1 ;;
+      resume) DOWNLOAD_RESUME=1 ;;
+      print-url) PRINT_URL=1 ;;
+      dry-run) DRY_RUN=1 ;;
+      from-file=*) FROM_FILE="${OPTARG#*=}" ;;
+      base-url=*) DOWNLOAD_BASE_URL="${OPTARG#*=}" ;;
+      require-checksum) REQUIRE_CHECKSUM=1 ;;
+      pre) PRERELEASE=1 ;;
+      force) FORCE=1 ;;
+      add-to-path) ADD_TO_PATH=1 ;;
+      sudo) USE_SUDO=1 ;;
+      json) JSON_OUTPUT=1 ;;
+      keep=*) KEEP_DIR="${OPTARG#*=}" ;;
+      extract-only=*) EXTRACT_DIR="${OPTARG#*=}" ;;
+      go-install) GO_INSTALL=1 ;;
+{{- if .Install.Completions }}
+      completions) COMPLETIONS=1 ;;
+{{- end }}
+      from-file)
+        eval "FROM_FILE=\"\${$OPTIND:-}\""
+        OPTIND=$((OPTIND + 1))
+        ;;
+      *) usage "$0" ;;
+      esac
+      ;;
     esac
   done
   shift $((OPTIND - 1))
-  TAG="${1:-{{- .DefaultVersion | default "latest" -}}}"
+  if [ ! -t 2 ] || [ -n "${CI:-}" ]; then
+    # No progress bars in non-interactive environments
+    DOWNLOAD_PROGRESS=""
+  fi
+  if [ -n "$FROM_FILE" ] && [ ! -f "$FROM_FILE" ]; then
+    log_crit "--from-file: no such file '${FROM_FILE}'"
+    exit 1
+  fi
+  for n in "$DOWNLOAD_RETRIES" "$DOWNLOAD_TIMEOUT" "$LOCK_TIMEOUT"; do
+    case "$n" in
+    '' | *[!0-9]*)
+      log_crit "--retries, --timeout and \$BINSTALLER_LOCK_TIMEOUT must be non-negative integers, got '${n}'"
+      exit 1
+      ;;
+    esac
+  done
+{{- with .RenovateComment }}
+  # {{ . }}
+{{- end }}
+  TAG="${1:-${VERSION_ARG:-{{- .DefaultVersion | default "latest" -}}}}"
 }
 
 tag_to_version() {
   if [ "$TAG" = "latest" ]; then
-    log_info "checking GitHub for latest tag"
-    REALTAG=$(github_release "${REPO}" "${TAG}") && true
+{{- if and .Version (eq .Version.Source "static") }}
+    REALTAG="{{ index .Version.Static 0 }}"
+{{- else if and .Version (eq .Version.Source "url") }}
+    log_info "checking {{ .Version.LatestURL }} for latest tag"
+    REALTAG=$(http_copy "{{ .Version.LatestURL }}" | head -n 1 | tr -d '[:space:]') && true
+{{- else }}
+    if [ -n "$PRERELEASE" ]; then
+      log_info "checking GitHub for latest tag{{ with .Version.Prefix }} with prefix {{ . }}{{ end }} including prereleases"
+      REALTAG=$(github_release_with_prefix "${REPO}" {{ shellQuote .Version.Prefix }} 1) && true
+{{- if .Version.Prefix }}
+    else
+      log_info "checking GitHub for latest tag with prefix {{ .Version.Prefix }}"
+      REALTAG=$(github_release_with_prefix "${REPO}" {{ shellQuote .Version.Prefix }}) && true
+{{- else }}
+    else
+      log_info "checking GitHub for latest tag"
+      REALTAG=$(github_latest_release "${REPO}") && true
+{{- end }}
+    fi
+{{- end }}
     test -n "$REALTAG" || {
       log_crit "Could not determine latest tag for ${REPO}"
       exit 1
     }
+  elif is_version_range "$TAG"; then
+    log_info "checking GitHub for the highest tag matching ${TAG}"
+    REALTAG=$(github_release_in_range "${REPO}" "$TAG" {{ shellQuote .Version.Prefix }}) && true
+    test -n "$REALTAG" || {
+      log_crit "Could not find a release of ${REPO} matching ${TAG}"
+      exit 1
+    }
   else
     # Assume TAG is a valid tag/version string
     REALTAG="$TAG"
+{{- with .Version.Prefix }}
+    case "$REALTAG" in
+      {{ shellQuote . }}*) ;;
+      {{- if $.Version.TagTemplate }}
+      *) VERSION="${REALTAG#v}" && REALTAG="{{ $.Version.TagTemplate }}" ;;
+      {{- else }}
+      *) REALTAG={{ shellQuote . }}"${REALTAG}" ;;
+      {{- end }}
+    esac
+{{- end }}
   fi
   if test -z "$REALTAG"; then
-    log_crit "unable to find '${TAG}' - use 'latest' or see https://github.com/${REPO}/releases for details"
+    log_crit "unable to find '${TAG}' - use 'latest' or see ${GITHUB_BASE_URL}/${REPO}/releases for details"
     exit 1
   fi
+{{- with .Version.Prefix }}
+  VERSION=${REALTAG#{{ shellQuote . }}}
+  VERSION=${VERSION#v} # Strip leading 'v'
+{{- else }}
   VERSION=${REALTAG#v} # Strip leading 'v'
+{{- end }}
   TAG="$REALTAG"       # Use the resolved tag
   log_info "Resolved version: ${VERSION} (tag: ${TAG})"
 }
+{{- with .Verify }}{{ with .Plugins }}
+
+# --- Verifier plugins (from spec verify.plugins) ---
+verify_plugins() {
+  asset_path="$1"
+  plugin_json="{\"name\":$(json_string "$NAME"),\"repo\":$(json_string "$REPO"),\"version\":$(json_string "$VERSION"),\"tag\":$(json_string "$TAG")"
+  plugin_json="${plugin_json},\"os\":$(json_string "$UNAME_OS"),\"arch\":$(json_string "$ARCH"),\"asset_filename\":$(json_string "$ASSET_FILENAME"),\"asset_path\":$(json_string "$asset_path")}"
+  {{- range . }}
+  if is_command {{ shellQuote (commandName .Command) }}; then
+    log_info "Running verifier plugin: "{{ shellQuote .Name }}
+    if ! (
+      export BINSTALLER_NAME="$NAME" BINSTALLER_REPO="$REPO" BINSTALLER_VERSION="$VERSION" BINSTALLER_TAG="$TAG"
+      export BINSTALLER_OS="$UNAME_OS" BINSTALLER_ARCH="$ARCH" BINSTALLER_ASSET_FILENAME="$ASSET_FILENAME" BINSTALLER_ASSET_PATH="$asset_path"
+      printf '%s\n' "$plugin_json" | {{ .Command }} "$asset_path" 1>&2
+    ); then
+      log_crit "Verifier plugin failed: "{{ shellQuote .Name }}
+      return 1
+    fi
+  else
+    {{- if and .Required (not (deref .Required)) }}
+    log_warn "Skipping verifier plugin "{{ shellQuote .Name }}": "{{ shellQuote (commandName .Command) }}" not found"
+    {{- else }}
+    log_crit "Verifier plugin "{{ shellQuote .Name }}" requires "{{ shellQuote (commandName .Command) }}" which is not installed"
+    return 1
+    {{- end }}
+  fi
+  {{- end }}
+}
+{{- end }}{{ end }}
+{{- with .Signature }}{{ if deref .Enabled }}
+
+# --- Signature verification (from spec signature) ---
+
+# Print the signature filename of the file $1
+signature_filename() (
+  TARGET_FILENAME="$1"
+  echo "{{ .SignatureTemplate }}"
+)
+
+verify_signature() {
+  target="$1"
+  TARGET_FILENAME="${target##*/}"
+  {{- if eq .Type "signify" }}
+  SIGNIFY=signify
+  if ! is_command signify && is_command signify-openbsd; then
+    SIGNIFY=signify-openbsd
+  fi
+  if ! is_command "$SIGNIFY"; then
+  {{- else }}
+  if ! is_command {{ .Type }}; then
+  {{- end }}
+    {{- if deref .Require }}
+    log_crit "{{ .Type }} is required to verify the signature of ${TARGET_FILENAME} but is not installed"
+    return 1
+    {{- else }}
+    log_warn "{{ .Type }} not found, skipping signature verification of ${TARGET_FILENAME}"
+    return 0
+    {{- end }}
+  fi
+  SIGNATURE_FILENAME=$(signature_filename "${TARGET_FILENAME}")
+  if [ ! -f "${TMPDIR}/${SIGNATURE_FILENAME}" ]; then
+    log_info "Downloading signature ${SIGNATURE_FILENAME}"
+    download_release_file "${TMPDIR}/${SIGNATURE_FILENAME}" "${SIGNATURE_FILENAME}"
+  fi
+  log_info "Verifying signature of ${TARGET_FILENAME} with {{ .Type }}"
+  {{- if eq .Type "minisign" }}
+  if ! minisign -V -q -P "{{ .Key }}" -x "${TMPDIR}/${SIGNATURE_FILENAME}" -m "$target" 1>&2; then
+  {{- else if eq .Type "signify" }}
+  printf 'untrusted comment: %s public key\n%s\n' "${NAME}" "{{ .Key }}" >"${TMPDIR}/signify.pub"
+  if ! "$SIGNIFY" -V -q -p "${TMPDIR}/signify.pub" -x "${TMPDIR}/${SIGNATURE_FILENAME}" -m "$target" 1>&2; then
+  {{- else }}
+  {{- if .Key }}
+  set -- --key "{{ .Key }}"
+  {{- else }}
+  CERTIFICATE_FILENAME="{{ .CertificateTemplate | default "${TARGET_FILENAME}.pem" }}"
+  log_info "Downloading certificate ${CERTIFICATE_FILENAME}"
+  download_release_file "${TMPDIR}/${CERTIFICATE_FILENAME}" "${CERTIFICATE_FILENAME}"
+  set -- --certificate "${TMPDIR}/${CERTIFICATE_FILENAME}"
+  {{- if .CertificateIdentityRegexp }}
+  set -- "$@" --certificate-identity-regexp "{{ .CertificateIdentityRegexp }}"
+  {{- else }}
+  set -- "$@" --certificate-identity "{{ .CertificateIdentity }}"
+  {{- end }}
+  set -- "$@" --certificate-oidc-issuer "{{ .CertificateOIDCIssuer }}"
+  {{- end }}
+  if ! cosign verify-blob "$@" --signature "${TMPDIR}/${SIGNATURE_FILENAME}" "$target" 1>&2; then
+  {{- end }}
+    log_crit "Signature verification failed for ${TARGET_FILENAME}"
+    return 1
+  fi
+  log_info "Signature verification successful"
+}
+{{- end }}{{ end }}
+{{- with .Provenance }}{{ if deref .Enabled }}
+
+# --- SLSA provenance verification (from spec provenance) ---
+verify_provenance() {
+  target="$1"
+  if ! is_command slsa-verifier; then
+    {{- if deref .Require }}
+    log_crit "slsa-verifier is required to verify the provenance of ${ASSET_FILENAME} but is not installed"
+    return 1
+    {{- else }}
+    log_warn "slsa-verifier not found, skipping provenance verification of ${ASSET_FILENAME}"
+    return 0
+    {{- end }}
+  fi
+  PROVENANCE_FILENAME="{{ .Template }}"
+  log_info "Downloading provenance ${PROVENANCE_FILENAME}"
+  download_release_file "${TMPDIR}/${PROVENANCE_FILENAME}" "${PROVENANCE_FILENAME}"
+  log_info "Verifying provenance of ${ASSET_FILENAME} with slsa-verifier"
+  if ! slsa-verifier verify-artifact "$target" \
+    --provenance-path "${TMPDIR}/${PROVENANCE_FILENAME}" \
+    --source-uri "{{ .SourceURI }}" \
+    {{- if .BuilderID }}
+    --builder-id "{{ .BuilderID }}" \
+    {{- end }}
+    --source-tag "${TAG}" 1>&2; then
+    log_crit "Provenance verification failed for ${ASSET_FILENAME}"
+    return 1
+  fi
+  log_info "Provenance verification successful"
+}
+{{- end }}{{ end }}
+{{- with .Attestation }}{{ if deref .Enabled }}
+
+# --- GitHub artifact attestation verification (from spec attestation) ---
+verify_attestation() {
+  target="$1"
+  if is_command gh; then
+    log_info "Verifying attestation of ${ASSET_FILENAME} with gh"
+    if ! GH_TOKEN="${GH_TOKEN:-${GITHUB_TOKEN:-}}" gh attestation verify "$target" --repo "${REPO}"{{ with .VerifyFlags }} {{ . }}{{ end }} 1>&2; then
+      log_crit "Attestation verification failed for ${ASSET_FILENAME}"
+      return 1
+    fi
+  elif is_command cosign; then
+    # cosign can verify attestations signed with the public-good Sigstore
+    # instance, which GitHub uses for public repositories.
+    log_info "gh not found, verifying attestation of ${ASSET_FILENAME} with cosign"
+    digest=$(hash_sha256 "$target")
+    attestations_url="${GITHUB_API_URL}/repos/${REPO}/attestations/sha256:${digest}"
+    if [ -n "${GITHUB_TOKEN:-}" ]; then
+      json=$(http_copy "$attestations_url" "Authorization: Bearer ${GITHUB_TOKEN}") || json=""
+    else
+      json=$(http_copy "$attestations_url") || json=""
+    fi
+    echo "$json" | json_object bundle >"${TMPDIR}/attestation.json" || true
+    if [ ! -s "${TMPDIR}/attestation.json" ]; then
+      log_crit "No attestation found for ${ASSET_FILENAME} at ${attestations_url}"
+      return 1
+    fi
+    if ! cosign verify-blob-attestation "$target" \
+      --bundle "${TMPDIR}/attestation.json" \
+      --new-bundle-format \
+      --type https://slsa.dev/provenance/v1 \
+      --certificate-oidc-issuer https://token.actions.githubusercontent.com \
+      --certificate-identity-regexp "^https://github.com/${REPO}/" 1>&2; then
+      log_crit "Attestation verification failed for ${ASSET_FILENAME}"
+      return 1
+    fi
+  else
+    {{- if deref .Require }}
+    log_crit "gh or cosign is required to verify the attestation of ${ASSET_FILENAME} but neither is installed"
+    return 1
+    {{- else }}
+    log_warn "gh and cosign not found, skipping attestation verification of ${ASSET_FILENAME}"
+    return 0
+    {{- end }}
+  fi
+  log_info "Attestation verification successful"
+}
+{{- end }}{{ end }}
 {{ if eq .Asset.NamingConvention.OS "titlecase" }}
 capitalize() {
   input="$1"
@@ -93,18 +511,69 @@ is_rosetta2_available() {
   arch -arch x86_64 true 2>/dev/null
 }
 {{- end }}
+{{ if windowsArm64X64 .InstallSpec }}
+is_windows_arm64_x64_available() {
+  [ "${UNAME_OS}" = windows ] || return 1
+  [ "$(uname_arch)" = arm64 ] || [ "${PROCESSOR_ARCHITECTURE:-}" = ARM64 ] || [ "${PROCESSOR_ARCHITEW6432:-}" = ARM64 ]
+}
+{{- end }}
+{{ if usesLibc .Asset }}
+detect_libc() {
+  [ "${UNAME_OS}" = linux ] || return 0
+  if ldd --version 2>&1 | grep -qi musl; then
+    echo musl
+  elif ls /lib/ld-musl-* >/dev/null 2>&1; then
+    echo musl
+  else
+    echo gnu
+  fi
+}
+{{- end }}
+{{- if usesDistro .Asset }}
+
+# detect_distro prints ID and VERSION_ID of os-release(5), e.g. ubuntu20.04.
+detect_distro() {
+  [ "${UNAME_OS}" = linux ] || return 0
+  for os_release in /etc/os-release /usr/lib/os-release; do
+    if [ -r "${os_release}" ]; then
+      (. "${os_release}" && echo "${ID:-}${VERSION_ID:-}") | tr '[:upper:]' '[:lower:]'
+      return 0
+    fi
+  done
+}
+{{- end }}
 
 resolve_asset_filename() {
   {{ if eq .Asset.NamingConvention.OS "titlecase" -}}
   OS="$(capitalize "${OS}")"
   {{- end }}
+  {{- with .Variables }}
+  # --- Custom variables ---
+  {{- range $name := $.VariableNames }}{{ with index $.Variables $name }}
+  {{- if .Input }}
+  case "{{ .Input }}" in
+  {{- range $key, $value := .Cases }}
+    {{ shellQuote $key }}) {{ $name }}={{ shellQuote $value }} ;;
+  {{- end }}
+    *) {{ $name }}={{ shellQuote .Default }} ;;
+  esac
+  {{- else }}
+  {{ $name }}={{ shellQuote .Value }}
+  {{- end }}
+  {{- end }}{{ end }}
+  {{- end }}
   # --- Apply Rules ---
   ASSET_FILENAME=""
+  {{- if hasFallbackTemplates .Asset }}
+  ASSET_FALLBACKS=""
+  {{- end }}
   {{- with .Asset.Rules }}
   {{- range . }}
   if
     {{- if .When.OS }} [ "${UNAME_OS}" = '{{.When.OS}}' ] && {{- end }}
     {{- if .When.Arch }} [ "${UNAME_ARCH}" = '{{.When.Arch}}' ] && {{- end }}
+    {{- if .When.Libc }} [ "${LIBC}" = '{{.When.Libc}}' ] && {{- end }}
+    {{- if .When.Distro }} [ "${DISTRO}" = '{{.When.Distro}}' ] && {{- end }}
     {{- " true" }}
   then
     {{- "\n   " -}}
@@ -112,6 +581,9 @@ resolve_asset_filename() {
     {{- if .Arch }} ARCH='{{ .Arch }}' {{- end }}
     {{- if .Ext }} EXT='{{ .Ext }}' {{- end }}
     {{- if .Template }} ASSET_FILENAME="{{ .Template }}" {{- end }}
+    {{- with .FallbackTemplates }}
+    ASSET_FALLBACKS="{{ range $i, $t := . }}{{ if $i }} {{ end }}{{ $t }}{{ end }}"
+    {{- end }}
     {{- range $i, $binary := .Binaries }}
     BINARY_NAME_{{ $i }}={{ $binary.Name }}
     BINARY_PATH_{{ $i }}={{ $binary.Path }}
@@ -122,35 +594,369 @@ resolve_asset_filename() {
   if [ -z "${ASSET_FILENAME}" ]; then
     ASSET_FILENAME="{{ .Asset.Template }}"
   fi
+  if [ "${UNAME_OS}" = "windows" ] && [ -z "${EXT}" ]; then
+    # Raw binaries are published as executables on Windows
+    ASSET_FILENAME=$(exe_name "${ASSET_FILENAME}")
+    {{- if hasFallbackTemplates .Asset }}
+    ASSET_FALLBACKS=$(for candidate in ${ASSET_FALLBACKS}; do exe_name "$candidate"; done | tr '\n' ' ')
+    {{- end }}
+    EXT=".exe"
+  fi
+  {{- if hasFallbackTemplates .Asset }}
+  select_asset_candidate
+  {{- end }}
+  {{- if casePatterns .Asset.IgnoreAssets }}
+  if ! asset_allowed "${ASSET_FILENAME}"; then
+    log_crit "${ASSET_FILENAME} is excluded by asset.ignore_assets"
+    exit 1
+  fi
+  {{- end }}
+}
+{{- with casePatterns .Asset.IgnoreAssets }}
+
+# Report whether the asset $1 is not excluded by asset.ignore_assets, unless
+# asset.prefer_assets keeps it.
+asset_allowed() {
+  {{- with casePatterns $.Asset.PreferAssets }}
+  case "$1" in
+    {{ . }}) return 0 ;;
+  esac
+  {{- end }}
+  case "$1" in
+    {{ . }}) return 1 ;;
+  esac
+  return 0
+}
+{{- end }}
+{{- if hasFallbackTemplates .Asset }}
+
+# Pick the first of ASSET_FILENAME and ASSET_FALLBACKS that has an embedded
+# checksum or exists in the release.
+select_asset_candidate() {
+  test -z "${ASSET_FALLBACKS}" && return 0
+  for candidate in "${ASSET_FILENAME}" ${ASSET_FALLBACKS}; do
+    {{- if casePatterns .Asset.IgnoreAssets }}
+    asset_allowed "$candidate" || continue
+    {{- end }}
+    if [ -n "$(find_embedded_checksum "$VERSION" "$candidate")" ]; then
+      ASSET_FILENAME="$candidate"
+      return 0
+    fi
+  done
+  for candidate in "${ASSET_FILENAME}" ${ASSET_FALLBACKS}; do
+    {{- if casePatterns .Asset.IgnoreAssets }}
+    asset_allowed "$candidate" || continue
+    {{- end }}
+    log_debug "checking whether ${candidate} exists"
+    if http_exists "$(release_url "$candidate")"; then
+      ASSET_FILENAME="$candidate"
+      return 0
+    fi
+  done
+  log_warn "none of the candidate assets were found, trying ${ASSET_FILENAME}"
+}
+{{- end }}
+
+{{- with .Install }}{{ if .VersionCommand }}
+
+# Check whether the binary in BINDIR already reports VERSION
+is_installed() {
+  {{- with index $.Asset.Binaries 0 }}
+  BINARY="${BINDIR}/{{ .Name }}"
+  {{- end }}
+  {{- if (hasBinaryOverride $.Asset) }}
+  if [ -n "$BINARY_NAME_0" ]; then
+    BINARY="${BINDIR}/$BINARY_NAME_0"
+  fi
+  {{- end }}
+  if [ "${UNAME_OS}" = "windows" ]; then
+    case "${BINARY}" in *.exe) ;; *) BINARY="${BINARY}.exe" ;; esac
+  fi
+  test -x "${BINARY}" || return 1
+  output=$({{ .VersionCommand }} 2>&1 </dev/null) || return 1
+  echo "$output" | grep -Eo {{ shellQuote (.VersionRegex | default "[0-9]+(\\.[0-9A-Za-z]+)+(-[0-9A-Za-z.]+)?") }} | sed 's/^v//' | grep -Fqx -- "${VERSION}"
+}
+{{- end }}{{ end }}
+
+# Print the download URL of the release file $1. URLs under GITHUB_BASE_URL
+# are rewritten to DOWNLOAD_BASE_URL if set.
+release_url() (
+  ASSET_FILENAME="$1"
+  url="{{ .Asset.DownloadURLTemplate | default "${GITHUB_BASE_URL}/${REPO}/releases/download/${TAG}/${ASSET_FILENAME}" }}"
+  if [ -n "${DOWNLOAD_BASE_URL}" ]; then
+    case "$url" in
+    "${GITHUB_BASE_URL}"/*) url="${DOWNLOAD_BASE_URL%/}${url#"${GITHUB_BASE_URL}"}" ;;
+    esac
+  fi
+  echo "$url"
+)
+
+# Download the release file $2 to $1
+download_release_file() {
+{{- if not .Asset.DownloadURLTemplate }}
+  if [ -n "${GITHUB_TOKEN:-}" ] && [ -z "${DOWNLOAD_BASE_URL}" ]; then
+    # Use the API so that assets of private repositories can be downloaded
+    if github_api_download "${REPO}" "${TAG}" "$2" "$1"; then
+      return 0
+    fi
+    log_debug "GitHub API download of $2 failed, falling back to $(release_url "$2")"
+  fi
+{{- end }}
+  http_download "$1" "$(release_url "$2")"
+}
+
+# Download the checksum file to $1
+download_checksum_file() {
+{{- if .Checksums.HasURLTemplate }}
+  if [ -n "$CHECKSUM_EXTERNAL" ]; then
+    http_download "$1" "${CHECKSUM_URL}"
+    return
+  fi
+{{- end }}
+  download_release_file "$1" "${CHECKSUM_FILENAME}"
+}
+
+# Download TMPDIR/$1 in the background with the download function $2, called
+# with the destination and the remaining arguments, so that it overlaps with
+# the asset download. Failures are ignored: files missing after
+# wait_prefetch are downloaded again when needed.
+prefetch() {
+  name="$1"
+  fetch="$2"
+  shift 2
+  (
+    "$fetch" "${TMPDIR}/.prefetch.${name}" "$@" >/dev/null 2>&1 &&
+      mv "${TMPDIR}/.prefetch.${name}" "${TMPDIR}/${name}"
+  ) &
+  PREFETCH_PIDS="${PREFETCH_PIDS} $!"
+}
+
+# Wait for the downloads started by prefetch
+wait_prefetch() {
+  for pid in ${PREFETCH_PIDS}; do
+    wait "$pid" || true
+  done
+  PREFETCH_PIDS=""
+}
+
+# Print what execute would download as KEY=VALUE lines for --print-url.
+# EXPECTED_HASH is empty unless the checksum is embedded, since checksum
+# files are not downloaded.
+print_resolved() {
+  echo "PLATFORM=${PLATFORM}"
+  echo "VERSION=${VERSION}"
+  echo "TAG=${TAG}"
+  echo "ASSET_FILENAME=${ASSET_FILENAME}"
+  echo "ASSET_URL=${ASSET_URL}"
+  echo "CHECKSUM_URL=${CHECKSUM_URL}"
+  echo "EXPECTED_HASH=${EMBEDDED_HASH}"
+}
+
+# Log the actions execute would take for --dry-run. Nothing is downloaded
+# and no files are written.
+dry_run() {
+  if [ -n "$FROM_FILE" ]; then
+    log_info "[dry-run] Would use local file ${FROM_FILE} as ${ASSET_FILENAME}"
+  else
+    log_info "[dry-run] Would {{ if .Image }}extract {{ .Image.Path }} from{{ else }}download{{ end }} ${ASSET_URL}"
+  fi
+  if [ -n "$EMBEDDED_HASH" ]; then
+    log_info "[dry-run] Would verify ${ASSET_FILENAME} against embedded checksum ${EMBEDDED_HASH}"
+  elif [ -n "$CHECKSUM_URL" ]; then
+    log_info "[dry-run] Would download checksums from ${CHECKSUM_URL} and verify ${ASSET_FILENAME}"
+{{- with .Signature }}{{ if and (deref .Enabled) (eq .Target "checksum") }}
+    log_info "[dry-run] Would verify the signature of ${CHECKSUM_FILENAME} with {{ .Type }}"
+{{- end }}{{ end }}
+  else
+    log_info "[dry-run] Would skip checksum verification: no checksum found"
+  fi
+{{- with .Signature }}{{ if and (deref .Enabled) (eq .Target "asset") }}
+  log_info "[dry-run] Would verify the signature of ${ASSET_FILENAME} with {{ .Type }}"
+{{- end }}{{ end }}
+{{- with .Provenance }}{{ if deref .Enabled }}
+  log_info "[dry-run] Would verify the provenance of ${ASSET_FILENAME} with slsa-verifier"
+{{- end }}{{ end }}
+{{- with .Attestation }}{{ if deref .Enabled }}
+  log_info "[dry-run] Would verify the attestation of ${ASSET_FILENAME} with gh or cosign"
+{{- end }}{{ end }}
+{{- with .Verify }}{{ range .Plugins }}
+  log_info "[dry-run] Would run verifier plugin: {{ .Name }}"
+{{- end }}{{ end }}
+  if [ -n "${KEEP_DIR}" ]; then
+    log_info "[dry-run] Would copy ${ASSET_FILENAME} to ${KEEP_DIR}"
+  fi
+  if [ -n "${EXTRACT_DIR}" ]; then
+    log_info "[dry-run] Would extract ${ASSET_FILENAME} to ${EXTRACT_DIR} without installing"
+    return 0
+  fi
+  if [ -n "${EXT}" ] && [ "${EXT}" != ".exe" ]; then
+    log_info "[dry-run] Would extract ${ASSET_FILENAME}"
+  fi
+  if ! is_writable "${BINDIR}"; then
+    if [ -n "${USE_SUDO}" ]; then
+      log_info "[dry-run] Would install with sudo: ${BINDIR} is not writable"
+    else
+      log_warn "[dry-run] ${BINDIR} is not writable, installation would fail without --sudo"
+    fi
+  fi
+  {{- range $i, $binary := .Asset.Binaries }}
+  BINARY_NAME='{{ $binary.Name }}'
+  {{- if (hasBinaryOverride $.Asset) }}
+  if [ -n "$BINARY_NAME_{{ $i }}" ]; then
+    BINARY_NAME="$BINARY_NAME_{{ $i }}"
+  fi
+  {{- end }}
+  if [ "${UNAME_OS}" = "windows" ]; then
+    case "${BINARY_NAME}" in *.exe) ;; *) BINARY_NAME="${BINARY_NAME}.exe" ;; esac
+  fi
+  {{- if and $.Install $.Install.Versioned }}
+  log_info "[dry-run] Would install ${BINARY_NAME} to ${BINDIR} as a versioned binary linked from ${BINDIR}/${BINARY_NAME}"
+  {{- else }}
+  log_info "[dry-run] Would install ${BINARY_NAME} to ${BINDIR}/${BINARY_NAME}"
+  {{- end }}
+  {{- range $binary.Aliases }}
+  log_info "[dry-run] Would link ${BINDIR}/{{ . }} -> ${BINARY_NAME}"
+  {{- end }}
+  {{- end }}
+  {{- with .Asset.ExtraFiles }}
+  log_info "[dry-run] Would install extra files relative to ${BINSTALLER_PREFIX:-$(dirname "${BINDIR}")}"
+  {{- range . }}
+  log_info "[dry-run] Would install {{ .Src }} to {{ .Dest }}"
+  {{- end }}
+  {{- end }}
+  {{- if .Install.Completions }}
+  if [ -n "${COMPLETIONS}" ]; then
+    log_info "[dry-run] Would install shell completions relative to ${BINSTALLER_PREFIX:-$(dirname "${BINDIR}")}"
+  fi
+  {{- end }}
+  {{- with .PostInstall }}{{ if deref .Enabled }}
+  {{- range $i, $hook := .Hooks }}{{ with $hook.Run }}
+  test -n "${BINSTALLER_SKIP_POST_INSTALL:-}" || log_info {{ shellQuote (printf "[dry-run] Would run post-install hook: %s" ($hook.Name | default .)) }}
+  {{- end }}{{ end }}
+  {{- end }}{{ end }}
+  if [ -n "${ADD_TO_PATH}" ]; then
+    log_info "[dry-run] Would add ${BINDIR} to PATH in $(shell_profile) if missing"
+  fi
 }
 
 execute() {
   STRIP_COMPONENTS={{ if .Unpack }}{{ .Unpack.StripComponents | default 0 }}{{ else }}0{{ end }}
   CHECKSUM_FILENAME="{{ if .Checksums }}{{ .Checksums.Template }}{{ end }}"
+  {{- with .Checksums }}{{ range .Rules }}
+  if
+    {{- if .When.OS }} [ "${UNAME_OS}" = '{{.When.OS}}' ] && {{- end }}
+    {{- if .When.Arch }} [ "${UNAME_ARCH}" = '{{.When.Arch}}' ] && {{- end }}
+    {{- if .When.Libc }} [ "${LIBC}" = '{{.When.Libc}}' ] && {{- end }}
+    {{- if .When.Distro }} [ "${DISTRO}" = '{{.When.Distro}}' ] && {{- end }}
+    {{- " true" }}
+  then
+    CHECKSUM_FILENAME="{{ .Template }}"
+  fi
+  {{- end }}{{ end }}
 
   # --- Construct URLs ---
-  GITHUB_DOWNLOAD="https://github.com/${REPO}/releases/download"
-  ASSET_URL="${GITHUB_DOWNLOAD}/${TAG}/${ASSET_FILENAME}"
+{{- with .Image }}
+  # The binary extracted from the image is installed as a raw binary
+  IMAGE_REF="{{ .Ref }}"
+  ASSET_URL="${IMAGE_REF}"
+  EXT=""
+{{- else }}
+  ASSET_URL=$(release_url "${ASSET_FILENAME}")
+{{- end }}
   CHECKSUM_URL=""
+{{- if .Checksums.HasURLTemplate }}
+  CHECKSUM_EXTERNAL=""
+  case "$CHECKSUM_FILENAME" in
+    http://* | https://*)
+      # Checksums hosted outside the release
+      CHECKSUM_URL="$CHECKSUM_FILENAME"
+      CHECKSUM_EXTERNAL=1
+      CHECKSUM_FILENAME="${CHECKSUM_URL%%\?*}"
+      CHECKSUM_FILENAME="${CHECKSUM_FILENAME##*/}"
+      ;;
+    ?*)
+      CHECKSUM_URL=$(release_url "${CHECKSUM_FILENAME}")
+      ;;
+  esac
+{{- else }}
   if [ -n "$CHECKSUM_FILENAME" ]; then
-    CHECKSUM_URL="${GITHUB_DOWNLOAD}/${TAG}/${CHECKSUM_FILENAME}"
+    CHECKSUM_URL=$(release_url "${CHECKSUM_FILENAME}")
   fi
+{{- end }}
+
+  # Try to find embedded checksum first
+  EMBEDDED_HASH=$(find_embedded_checksum "$VERSION" "$ASSET_FILENAME")
+{{- if $extraAlgorithms }}
+  EMBEDDED_ALGORITHM=$(find_embedded_algorithm "$VERSION" "$ASSET_FILENAME")
+{{- end }}
+
+  if [ -n "$PRINT_URL" ]; then
+    print_resolved
+    return 0
+  fi
+  if [ -z "$EMBEDDED_HASH" ] && [ -n "$REQUIRE_CHECKSUM" ]; then
+    log_crit "No embedded checksum for ${ASSET_FILENAME} (version ${VERSION}) and a checksum is required"
+    return 1
+  fi
+  if [ -n "$DRY_RUN" ]; then
+    dry_run
+    return 0
+  fi
+{{- with .Install }}{{ if and .GoModule (not $.Image) }}
+  if [ -z "${FROM_FILE}${EMBEDDED_HASH}" ] && ! http_exists "$ASSET_URL"; then
+    if [ -n "$GO_INSTALL" ]; then
+      go_install
+      return
+    fi
+    log_warn "${ASSET_FILENAME} not found in release ${TAG}; re-run with --go-install to build {{ .GoModule }} with go install"
+  fi
+{{- end }}{{ end }}
 
   # --- Download and Verify ---
   TMPDIR=$(mktemp -d)
-  trap 'rm -rf -- "$TMPDIR"' EXIT HUP INT TERM
+  INSTALL_LOCK=""
+  trap 'release_lock; rm -rf -- "$TMPDIR"' EXIT HUP INT TERM
   log_debug "Downloading files into ${TMPDIR}"
-  log_info "Downloading ${ASSET_URL}"
-  http_download "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_URL}"
 
-  # Try to find embedded checksum first
-  EMBEDDED_HASH=$(find_embedded_checksum "$VERSION" "$ASSET_FILENAME")
+  # Fetch the small verification files while the asset downloads
+  PREFETCH_PIDS=""
+  if [ -z "$EMBEDDED_HASH" ] && [ -n "$CHECKSUM_URL" ]; then
+    prefetch "${CHECKSUM_FILENAME}" download_checksum_file
+{{- with .Signature }}{{ if and (deref .Enabled) (eq .Target "checksum") }}
+    prefetch "$(signature_filename "${CHECKSUM_FILENAME}")" download_release_file "$(signature_filename "${CHECKSUM_FILENAME}")"
+{{- end }}{{ end }}
+  fi
+{{- with .Signature }}{{ if and (deref .Enabled) (eq .Target "asset") }}
+  prefetch "$(signature_filename "${ASSET_FILENAME}")" download_release_file "$(signature_filename "${ASSET_FILENAME}")"
+{{- end }}{{ end }}
+
+  if [ -n "$FROM_FILE" ]; then
+    log_info "Using local file ${FROM_FILE} as ${ASSET_FILENAME}"
+    cp "${FROM_FILE}" "${TMPDIR}/${ASSET_FILENAME}"
+{{- if hasEmbeddedURLs .Checksums }}
+  elif [ -n "$EMBEDDED_HASH" ]; then
+    # Try the release URL first, then the embedded alternate URLs
+    # shellcheck disable=SC2046
+    download_verified "${TMPDIR}/${ASSET_FILENAME}" "$EMBEDDED_HASH" $(find_embedded_urls "$VERSION" "$ASSET_FILENAME")
+{{- end }}
+  else
+{{- if .Image }}
+    log_info "Extracting {{ .Image.Path }} from ${IMAGE_REF}"
+    image_extract "${TMPDIR}/${ASSET_FILENAME}"
+{{- else }}
+    log_info "Downloading ${ASSET_URL}"
+    download_release_file "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_FILENAME}"
+{{- end }}
+  fi
+  wait_prefetch
 
+  CHECKSUM_VERIFICATION=none
   if [ -n "$EMBEDDED_HASH" ]; then
     log_info "Using embedded checksum for verification"
-    
+    CHECKSUM_VERIFICATION=embedded
+
     # Verify using embedded hash
-    got=$(hash_compute "${TMPDIR}/${ASSET_FILENAME}")
+    got=$({{ $hashEmbedded }} "${TMPDIR}/${ASSET_FILENAME}")
     if [ "$got" != "$EMBEDDED_HASH" ]; then
       log_crit "Checksum verification failed for ${ASSET_FILENAME}"
       log_crit "Expected: ${EMBEDDED_HASH}"
@@ -161,12 +967,45 @@ execute() {
   elif [ -n "$CHECKSUM_URL" ]; then
     # Fall back to downloading checksum file
     log_info "Downloading checksums from ${CHECKSUM_URL}"
-    http_download "${TMPDIR}/${CHECKSUM_FILENAME}" "${CHECKSUM_URL}"
+    if [ ! -f "${TMPDIR}/${CHECKSUM_FILENAME}" ]; then
+      download_checksum_file "${TMPDIR}/${CHECKSUM_FILENAME}"
+    fi
+{{- with .Signature }}{{ if and (deref .Enabled) (eq .Target "checksum") }}
+    verify_signature "${TMPDIR}/${CHECKSUM_FILENAME}"
+{{- end }}{{ end }}
     log_info "Verifying checksum ..."
     hash_verify "${TMPDIR}/${ASSET_FILENAME}" "${TMPDIR}/${CHECKSUM_FILENAME}"
+    CHECKSUM_VERIFICATION=checksum_file
   else
     log_info "No checksum found, skipping verification."
   fi
+{{- with .Signature }}{{ if and (deref .Enabled) (eq .Target "asset") }}
+
+  verify_signature "${TMPDIR}/${ASSET_FILENAME}"
+{{- end }}{{ end }}
+
+{{- with .Provenance }}{{ if deref .Enabled }}
+
+  verify_provenance "${TMPDIR}/${ASSET_FILENAME}"
+{{- end }}{{ end }}
+{{- with .Attestation }}{{ if deref .Enabled }}
+
+  verify_attestation "${TMPDIR}/${ASSET_FILENAME}"
+{{- end }}{{ end }}
+{{- with .Verify }}{{ with .Plugins }}
+
+  verify_plugins "${TMPDIR}/${ASSET_FILENAME}"
+{{- end }}{{ end }}
+
+  if [ -n "${KEEP_DIR}" ]; then
+    log_info "Copying ${ASSET_FILENAME} to ${KEEP_DIR}"
+    test ! -d "${KEEP_DIR}" && install -d "${KEEP_DIR}"
+    cp "${TMPDIR}/${ASSET_FILENAME}" "${KEEP_DIR}/"
+  fi
+  if [ -n "${EXTRACT_DIR}" ]; then
+    extract_only
+    return 0
+  fi
 
   if [ -z "${EXT}" ] || [ "${EXT}" = ".exe" ]; then
     log_debug "Target is raw binary"
@@ -175,6 +1014,12 @@ execute() {
     (cd "${TMPDIR}" && untar "${ASSET_FILENAME}" "${STRIP_COMPONENTS}")
   fi
 
+  prepare_install_dir "${BINDIR}" "${USE_SUDO}"
+  test ! -d "${BINDIR}" && as_installer install -d "${BINDIR}"
+  # Serialize installs into the same directory, e.g. by parallel CI jobs
+  acquire_lock "${BINDIR}/.${NAME}.lock" "${LOCK_TIMEOUT}"
+  INSTALLED_JSON=""
+
   {{- range $i, $binary := .Asset.Binaries }}
   BINARY_NAME='{{ $binary.Name }}'
   if [ -z "${EXT}" ] || [ "${EXT}" = ".exe" ]; then
@@ -192,8 +1037,10 @@ execute() {
   {{- end }}
 
   if [ "${UNAME_OS}" = "windows" ]; then
-    case "${BINARY_NAME}" in *.exe) ;; *) BINARY_NAME="${BINARY_NAME}.exe" ;; esac
-    case "${BINARY_PATH}" in *.exe) ;; *) BINARY_PATH="${BINARY_PATH}.exe" ;; esac
+    BINARY_NAME=$(exe_name "${BINARY_NAME}")
+    if [ -n "${EXT}" ] && [ "${EXT}" != ".exe" ]; then
+      BINARY_PATH=$(exe_name "${BINARY_PATH}")
+    fi
   fi
 
   if [ ! -f "${BINARY_PATH}" ]; then
@@ -209,16 +1056,279 @@ execute() {
 
   # Install the binary
   INSTALL_PATH="${BINDIR}/${BINARY_NAME}"
+  test ! -d "${BINDIR}" && as_installer install -d "${BINDIR}"
+  {{- if and $.Install $.Install.Versioned }}
+  # Install as NAME-VERSION and point NAME at it for easy rollback
+  case "${BINARY_NAME}" in
+  *.exe) VERSIONED_NAME="${BINARY_NAME%.exe}-${VERSION}.exe" ;;
+  *) VERSIONED_NAME="${BINARY_NAME}-${VERSION}" ;;
+  esac
+  log_info "Installing binary to ${BINDIR}/${VERSIONED_NAME}"
+  as_installer install "${BINARY_PATH}" "${BINDIR}/${VERSIONED_NAME}"
+  as_installer rm -f "${INSTALL_PATH}"
+  as_installer ln -s "${VERSIONED_NAME}" "${INSTALL_PATH}"
+  {{- else }}
   log_info "Installing binary to ${INSTALL_PATH}"
-  test ! -d "${BINDIR}" && install -d "${BINDIR}"
-  install "${BINARY_PATH}" "${INSTALL_PATH}"
+  as_installer install "${BINARY_PATH}" "${INSTALL_PATH}"
+  {{- end }}
+  {{- range $binary.Aliases }}
+  install_alias "${BINARY_NAME}" '{{ . }}'
+  {{- end }}
+  INSTALLED_JSON="${INSTALLED_JSON:+${INSTALLED_JSON},}$(json_string "${INSTALL_PATH}")"
   log_info "${BINARY_NAME} installation complete!"
   {{- end }}
+  {{- with .Asset.ExtraFiles }}
+
+  # Install extra files relative to the install prefix
+  PREFIX="${BINSTALLER_PREFIX:-$(dirname "${BINDIR}")}"
+  {{- range . }}
+  install_extra_files "{{ .Dest }}" "${TMPDIR}"/{{ .Src }}
+  {{- end }}
+  {{- end }}
+  {{- if .Install.Completions }}
+
+  if [ -n "${COMPLETIONS}" ]; then
+    install_completions
+  fi
+  {{- end }}
+  {{- with .PostInstall }}{{ if deref .Enabled }}
+
+  post_install
+  {{- end }}{{ end }}
+  release_lock
+
+  check_path "${BINDIR}" "${ADD_TO_PATH}"
+
+  if [ -n "$JSON_OUTPUT" ]; then
+    print_json_result
+  fi
+}
+
+{{- with .Install }}{{ if .GoModule }}
+# Build {{ .GoModule }} with go install for platforms without a release asset
+# for --go-install. go verifies the module against the Go checksum database.
+go_install() {
+  if ! is_command go; then
+    log_crit "go is required to build ${NAME} for ${PLATFORM} from source"
+    return 1
+  fi
+  if [ -n "${OVERRIDE_OS}${OVERRIDE_ARCH}" ]; then
+    log_crit "--go-install cannot build for a platform other than the host"
+    return 1
+  fi
+  GOBIN_DIR=$(mktemp -d)
+  INSTALL_LOCK=""
+  trap 'release_lock; rm -rf -- "$GOBIN_DIR"' EXIT HUP INT TERM
+  log_info "Building {{ .GoModule }}@v${VERSION} with go install"
+  GOBIN="${GOBIN_DIR}" go install "{{ .GoModule }}@v${VERSION}"
+
+  prepare_install_dir "${BINDIR}" "${USE_SUDO}"
+  test ! -d "${BINDIR}" && as_installer install -d "${BINDIR}"
+  acquire_lock "${BINDIR}/.${NAME}.lock" "${LOCK_TIMEOUT}"
+  INSTALLED_JSON=""
+  for binary in "${GOBIN_DIR}"/*; do
+    INSTALL_PATH="${BINDIR}/${binary##*/}"
+    log_info "Installing binary to ${INSTALL_PATH}"
+    as_installer install "$binary" "${INSTALL_PATH}"
+    INSTALLED_JSON="${INSTALLED_JSON:+${INSTALLED_JSON},}$(json_string "${INSTALL_PATH}")"
+  done
+  release_lock
+  log_info "${NAME} installation complete!"
+
+  check_path "${BINDIR}" "${ADD_TO_PATH}"
+  if [ -n "$JSON_OUTPUT" ]; then
+    printf '{"name":%s,"repo":%s,"version":%s,"tag":%s,"platform":%s,' \
+      "$(json_string "$NAME")" "$(json_string "$REPO")" "$(json_string "$VERSION")" "$(json_string "$TAG")" "$(json_string "$PLATFORM")"
+    printf '"go_module":%s,"verification":"go_install","installed":[%s]}\n' "$(json_string '{{ .GoModule }}')" "$INSTALLED_JSON"
+  fi
+}
+{{- end }}{{ end }}
+{{- with .Image }}
+
+# Extract {{ .Path }} from the linux image IMAGE_REF of ARCH to $1 with
+# crane, or with skopeo by searching the image layers from the top.
+image_extract() {
+  if [ "${OS}" != linux ]; then
+    log_crit "${NAME} is only available for linux from ${IMAGE_REF}"
+    return 1
+  fi
+  image_path='{{ trimPrefix .Path "/" }}'
+  image_dir="${TMPDIR}/image"
+  mkdir "${image_dir}"
+  case "${ARCH}" in
+  armv*) image_arch=arm image_variant="v${ARCH#armv}" ;;
+  *) image_arch="${ARCH}" image_variant="" ;;
+  esac
+  if is_command crane; then
+    crane export --platform "linux/${image_arch}${image_variant:+/${image_variant}}" "${IMAGE_REF}" - |
+      (cd "${image_dir}" && tar -xf - "${image_path}")
+  elif is_command skopeo; then
+    skopeo copy --override-os linux --override-arch "${image_arch}" ${image_variant:+--override-variant "${image_variant}"} \
+      "docker://${IMAGE_REF}" "dir:${image_dir}/layers" >/dev/null
+    # Layers are listed from the bottom up and the config blob is not a tarball
+    layer=""
+    for digest in $(grep -o 'sha256:[0-9a-f]*' "${image_dir}/layers/manifest.json"); do
+      if tar -tzf "${image_dir}/layers/${digest#sha256:}" 2>/dev/null | grep -Fqx -e "${image_path}" -e "./${image_path}"; then
+        layer="${image_dir}/layers/${digest#sha256:}"
+      fi
+    done
+    if [ -z "${layer}" ]; then
+      log_crit "{{ .Path }} not found in ${IMAGE_REF}"
+      return 1
+    fi
+    member=$(tar -tzf "${layer}" | grep -Fx -e "${image_path}" -e "./${image_path}" | tail -n 1)
+    (cd "${image_dir}" && tar -xzf "${layer}" "${member}")
+  else
+    log_crit "crane or skopeo is required to install ${NAME} from ${IMAGE_REF}"
+    return 1
+  fi
+  if [ ! -f "${image_dir}/${image_path}" ] || [ -h "${image_dir}/${image_path}" ]; then
+    log_crit "{{ .Path }} is not a regular file in ${IMAGE_REF}"
+    return 1
+  fi
+  mv "${image_dir}/${image_path}" "$1"
+}
+{{- end }}
+
+# Extract the verified release asset to EXTRACT_DIR for --extract-only
+extract_only() {
+  test ! -d "${EXTRACT_DIR}" && install -d "${EXTRACT_DIR}"
+  if [ -z "${EXT}" ] || [ "${EXT}" = ".exe" ]; then
+    log_info "Copying ${ASSET_FILENAME} to ${EXTRACT_DIR}"
+    cp "${TMPDIR}/${ASSET_FILENAME}" "${EXTRACT_DIR}/"
+  else
+    log_info "Extracting ${ASSET_FILENAME} to ${EXTRACT_DIR}"
+    (cd "${EXTRACT_DIR}" && untar "${TMPDIR}/${ASSET_FILENAME}" "${STRIP_COMPONENTS}")
+  fi
+}
+
+# Print the result of execute as a JSON object for --json
+print_json_result() {
+  printf '{"name":%s,"repo":%s,"version":%s,"tag":%s,"platform":%s,' \
+    "$(json_string "$NAME")" "$(json_string "$REPO")" "$(json_string "$VERSION")" "$(json_string "$TAG")" "$(json_string "$PLATFORM")"
+  printf '"asset":%s,"url":%s,"checksum":{"algorithm":"%s","hash":%s},' \
+    "$(json_string "$ASSET_FILENAME")" "$(json_string "$ASSET_URL")" \
+{{- if $extraAlgorithms }}
+    "${EMBEDDED_ALGORITHM:-{{ hashAlgorithm .InstallSpec }}}" "$(json_string "$(hash_embedded "${TMPDIR}/${ASSET_FILENAME}")")"
+{{- else }}
+    "{{ hashAlgorithm .InstallSpec }}" "$(json_string "$(hash_compute "${TMPDIR}/${ASSET_FILENAME}")")"
+{{- end }}
+  printf '"verification":"%s","installed":[%s]}\n' "$CHECKSUM_VERIFICATION" "$INSTALLED_JSON"
+}
+{{- with .PostInstall }}{{ if deref .Enabled }}
+
+# --- Post-install hooks (from spec post_install) ---
+# The commands below are copied verbatim from the spec's post_install.hooks.
+# Set BINSTALLER_SKIP_POST_INSTALL=1 to skip them.
+post_install() {
+  if [ -n "${BINSTALLER_SKIP_POST_INSTALL:-}" ]; then
+    log_info "Skipping post-install hooks"
+    return 0
+  fi
+  {{- range $i, $hook := .Hooks }}
+  # post_install.hooks[{{ $i }}]
+  {{- with $hook.Message }}
+  log_info {{ shellQuote . }}
+  {{- end }}
+  {{- with $hook.Run }}
+  log_info {{ shellQuote (printf "Running post-install hook: %s" ($hook.Name | default .)) }}
+  if ! (
+    {{ . }}
+  ); then
+    {{- if $hook.IgnoreError }}
+    log_warn {{ shellQuote (printf "Post-install hook failed: %s" ($hook.Name | default .)) }}
+    {{- else }}
+    log_crit {{ shellQuote (printf "Post-install hook failed: %s" ($hook.Name | default .)) }}
+    return 1
+    {{- end }}
+  fi
+  {{- end }}
+  {{- end }}
+}
+{{- end }}{{ end }}
+{{- if hasAliases .Asset }}
+
+# Symlink the alias $2 to the installed binary $1 in BINDIR
+install_alias() {
+  alias_name="$2"
+  if [ "${UNAME_OS}" = "windows" ]; then
+    case "${alias_name}" in *.exe) ;; *) alias_name="${alias_name}.exe" ;; esac
+  fi
+  log_info "Linking ${BINDIR}/${alias_name} -> $1"
+  as_installer rm -f "${BINDIR}/${alias_name}"
+  as_installer ln -s "$1" "${BINDIR}/${alias_name}"
+}
+{{- end }}
+{{- with .Asset.ExtraFiles }}
+
+# Install the files $2... into the directory $1 under PREFIX
+install_extra_files() {
+  case "$1" in
+  /*) dest="$1" ;;
+  *) dest="${PREFIX}/$1" ;;
+  esac
+  shift
+  prepare_install_dir "${dest}" "${USE_SUDO}" || return 1
+  for f in "$@"; do
+    if [ ! -e "$f" ]; then
+      log_warn "Extra file not found in archive: ${f#"${TMPDIR}"/}"
+      continue
+    fi
+    test ! -d "${dest}" && as_installer install -d "${dest}"
+    log_info "Installing ${f##*/} to ${dest}"
+    as_installer install -m 644 "$f" "${dest}/"
+  done
+}
+{{- end }}
+{{- with .Install.Completions }}
+
+# Install the completions of each shell printed by the completion command of
+# the installed binary for --completions. Failures only log a warning.
+install_completions() {
+  {{- with index $.Asset.Binaries 0 }}
+  BINARY="${BINDIR}/{{ .Name }}"
+  {{- end }}
+  {{- if (hasBinaryOverride $.Asset) }}
+  if [ -n "$BINARY_NAME_0" ]; then
+    BINARY="${BINDIR}/$BINARY_NAME_0"
+  fi
+  {{- end }}
+  if [ "${UNAME_OS}" = "windows" ]; then
+    case "${BINARY}" in *.exe) ;; *) BINARY="${BINARY}.exe" ;; esac
+  fi
+  if [ -n "${OVERRIDE_OS}${OVERRIDE_ARCH}" ]; then
+    log_warn "Skipping shell completions: the binary was installed for another platform"
+    return 0
+  fi
+  COMPLETION_NAME="${BINARY##*/}"
+  COMPLETION_NAME="${COMPLETION_NAME%.exe}"
+  PREFIX="${BINSTALLER_PREFIX:-$(dirname "${BINDIR}")}"
+  for COMPLETION_SHELL in{{ range . }} {{ . }}{{ end }}; do
+    case "${COMPLETION_SHELL}" in
+    bash) dest="${PREFIX}/share/bash-completion/completions" file="${COMPLETION_NAME}" ;;
+    zsh) dest="${PREFIX}/share/zsh/site-functions" file="_${COMPLETION_NAME}" ;;
+    fish) dest="${PREFIX}/share/fish/vendor_completions.d" file="${COMPLETION_NAME}.fish" ;;
+    esac
+    if ! ({{ $.Install.CompletionCommand }}) >"${TMPDIR}/completion" 2>/dev/null </dev/null || [ ! -s "${TMPDIR}/completion" ]; then
+      log_warn "Failed to generate ${COMPLETION_SHELL} completions"
+      continue
+    fi
+    if ! prepare_install_dir "${dest}" "${USE_SUDO}"; then
+      log_warn "Skipping ${COMPLETION_SHELL} completions"
+      continue
+    fi
+    test ! -d "${dest}" && as_installer install -d "${dest}"
+    log_info "Installing ${COMPLETION_SHELL} completions to ${dest}/${file}"
+    as_installer install -m 644 "${TMPDIR}/completion" "${dest}/${file}"
+  done
 }
+{{- end }}
 
 # --- Configuration  ---
 NAME='{{ .Name }}'
 REPO='{{ .Repo }}'
+GITHUB_BASE_URL='{{ .GitHubBase }}'
+GITHUB_API_URL='{{ .GitHubAPI }}'
 EXT='{{ .Asset.DefaultExtension }}'
 
 # use in logging routines
@@ -229,31 +1339,73 @@ log_prefix() {
 parse_args "$@"
 
 # --- Determine target platform ---
-OS="${BINSTALLER_OS:-$(uname_os)}"
+{{- if .Target }}
+OS='{{ .Target.OS }}'
+UNAME_OS="${OS}"
+ARCH='{{ .Target.Arch }}'
+PLATFORM="${OS}/${ARCH}"
+log_info "Platform: ${PLATFORM}"
+{{- else }}
+OS="${OVERRIDE_OS:-$(uname_os)}"
 UNAME_OS="${OS}"
 {{ if and .Asset.ArchEmulation .Asset.ArchEmulation.Rosetta2 }}
-if is_rosetta2_available; then
+if [ -z "${OVERRIDE_OS}${OVERRIDE_ARCH}" ] && is_rosetta2_available; then
   log_info 'Apple Silicon with Rosetta 2 found: using amd64 as ARCH'
-	ARCH="${BINSTALLER_ARCH:-amd64}"
+	ARCH=amd64
 else
-	ARCH="${BINSTALLER_ARCH:-$(uname_arch)}"
+	ARCH="${OVERRIDE_ARCH:-$(uname_arch)}"
 fi
 {{ else }}
-ARCH="${BINSTALLER_ARCH:-$(uname_arch)}"
+ARCH="${OVERRIDE_ARCH:-$(uname_arch)}"
+{{- end }}
+{{- if windowsArm64X64 .InstallSpec }}
+if [ -z "${OVERRIDE_OS}${OVERRIDE_ARCH}" ] && is_windows_arm64_x64_available; then
+  log_info 'Windows on ARM64 with x64 emulation found: using amd64 as ARCH'
+  ARCH=amd64
+fi
 {{- end }}
-{{ with .Asset.Rules }}
-{{- range . }}
-{{- if .When.Arch -}} UNAME_ARCH="${ARCH}" {{- break }}{{ end }}
+{{ if usesArchCondition .InstallSpec -}} UNAME_ARCH="${ARCH}" {{- end }}
+PLATFORM="${OS}/${ARCH}"
+log_info "Detected Platform: ${PLATFORM}"
 {{- end }}
+{{- with .DefaultBinDir.PerOS }}
+if [ -z "${BINDIR}" ]; then
+  case "$(uname_os)" in
+  {{- range . }}
+  {{ . }}) BINDIR="{{ index $.DefaultBinDir . }}" ;;
+  {{- end }}
+  *) BINDIR="{{ $.DefaultBinDir.Default }}" ;;
+  esac
+fi
+{{- end }}
+{{- if usesLibc .Asset }}
+LIBC="${BINSTALLER_LIBC:-$(detect_libc)}"
+if [ -n "${LIBC}" ]; then
+  log_info "Detected libc: ${LIBC}"
+fi
 {{- end }}
-log_info "Detected Platform: ${OS}/${ARCH}"
+{{- if usesDistro .Asset }}
+DISTRO="${BINSTALLER_DISTRO:-$(detect_distro)}"
+if [ -n "${DISTRO}" ]; then
+  log_info "Detected distro: ${DISTRO}"
+fi
+{{- end }}
+{{- if not .Target }}
 
 # --- Validate platform ---
 uname_os_check "$OS"
 uname_arch_check "$ARCH"
+{{- end }}
 
 tag_to_version
 
 resolve_asset_filename
+{{- with .Install }}{{ if .VersionCommand }}
+
+if [ -z "${FORCE}${PRINT_URL}${OVERRIDE_OS}${OVERRIDE_ARCH}${KEEP_DIR}${EXTRACT_DIR}" ] && is_installed; then
+  log_info "${NAME} ${VERSION} is already installed in ${BINDIR}, skipping (use --force to reinstall)"
+  exit 0
+fi
+{{- end }}{{ end }}
 
-execute
+execute{{ block "footer" . }}{{ end }}
@@ -0,0 +1,86 @@
+// Package binsterr defines the categories of binst errors and the process
+// exit code of each, so that scripts and CI can branch on the class of a
+// failure. Errors are categorized with Wrap and tested with errors.Is:
+//
+//	if errors.Is(err, binsterr.ErrRateLimited) {
+//		// retry later
+//	}
+package binsterr
+
+import "errors"
+
+// Sentinel errors of the failure categories.
+var (
+	// ErrNetwork is a failed request, or a server error response.
+	ErrNetwork = errors.New("network error")
+	// ErrRateLimited is a request rejected by a rate limit, e.g. of the
+	// GitHub API without GITHUB_TOKEN.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrSpecInvalid is an InstallSpec that cannot be parsed or is invalid.
+	ErrSpecInvalid = errors.New("invalid spec")
+	// ErrVerificationFailed is a checksum or signature that does not match.
+	ErrVerificationFailed = errors.New("verification failed")
+	// ErrNotFound is a missing config file, release or asset.
+	ErrNotFound = errors.New("not found")
+)
+
+// Exit codes of binst. 2 is used by "binst gen --check" for errors other
+// than out of date installers.
+const (
+	ExitOK                 = 0
+	ExitFailure            = 1
+	ExitNetwork            = 3
+	ExitRateLimited        = 4
+	ExitSpecInvalid        = 5
+	ExitVerificationFailed = 6
+	ExitNotFound           = 7
+)
+
+// exitCodes are the exit codes of the categories, in order of precedence.
+var exitCodes = []struct {
+	category error
+	code     int
+}{
+	{ErrVerificationFailed, ExitVerificationFailed},
+	{ErrSpecInvalid, ExitSpecInvalid},
+	{ErrRateLimited, ExitRateLimited},
+	{ErrNotFound, ExitNotFound},
+	{ErrNetwork, ExitNetwork},
+}
+
+// categoryError is an error in a category. Its message is the message of
+// err alone.
+type categoryError struct {
+	category error
+	err      error
+}
+
+func (e *categoryError) Error() string { return e.err.Error() }
+
+func (e *categoryError) Unwrap() []error { return []error{e.err, e.category} }
+
+// Wrap returns err in category, one of the sentinel errors, so that
+// errors.Is(err, category) reports true. The message of err is unchanged.
+// Wrap returns nil if err is nil.
+func Wrap(category, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &categoryError{category: category, err: err}
+}
+
+// ExitCode returns the exit code of err: ExitOK if err is nil, the code of
+// its category, or ExitFailure if it has none. If err is in several
+// categories, verification failures take precedence, followed by invalid
+// specs, rate limits, not found errors and network errors.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	for _, c := range exitCodes {
+		if errors.Is(err, c.category) {
+			return c.code
+		}
+	}
+	return ExitFailure
+}
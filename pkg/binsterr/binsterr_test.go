@@ -0,0 +1,52 @@
+package binsterr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	base := errors.New("failed to get release v1.0.0, status code: 404")
+	err := fmt.Errorf("failed to embed checksums: %w", Wrap(ErrNotFound, base))
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("errors.Is(err, ErrNotFound) = false, want true")
+	}
+	if !errors.Is(err, base) {
+		t.Error("errors.Is(err, base) = false, want true")
+	}
+	if errors.Is(err, ErrNetwork) {
+		t.Error("errors.Is(err, ErrNetwork) = true, want false")
+	}
+	if want := "failed to embed checksums: " + base.Error(); err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if Wrap(ErrNotFound, nil) != nil {
+		t.Error("Wrap(ErrNotFound, nil) != nil")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	base := errors.New("base")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"uncategorized", base, ExitFailure},
+		{"network", Wrap(ErrNetwork, base), ExitNetwork},
+		{"rate limited", Wrap(ErrRateLimited, base), ExitRateLimited},
+		{"spec invalid", fmt.Errorf("load: %w", Wrap(ErrSpecInvalid, base)), ExitSpecInvalid},
+		{"verification failed", Wrap(ErrVerificationFailed, base), ExitVerificationFailed},
+		{"not found", Wrap(ErrNotFound, base), ExitNotFound},
+		{"precedence", Wrap(ErrNetwork, Wrap(ErrVerificationFailed, base)), ExitVerificationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package checksums
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+// PlatformAsset is the release asset of one platform together with its
+// embedded checksum.
+type PlatformAsset struct {
+	OS        string
+	Arch      string
+	Filename  string
+	URL       string
+	Hash      string
+	Algorithm spec.HashAlgorithm
+	// Raw is set for assets that are the executable itself rather than an
+	// archive containing it.
+	Raw bool
+	// Binaries are the binaries installed from the asset.
+	Binaries []spec.Binary
+}
+
+// EmbeddedAssets returns the asset of every platform of e.Version that has an
+// embedded checksum, resolving filenames and URLs as the installer script
+// does. Linux platforms resolve to their glibc asset. Nothing is downloaded.
+func (e *Embedder) EmbeddedAssets() ([]PlatformAsset, error) {
+	if e.Spec == nil {
+		return nil, fmt.Errorf("InstallSpec cannot be nil")
+	}
+	if e.Spec.Checksums == nil {
+		return nil, fmt.Errorf("spec has no embedded checksums")
+	}
+	embedded, ok := e.Spec.Checksums.EmbeddedChecksums[e.Version]
+	if !ok {
+		return nil, fmt.Errorf("no checksums embedded for version %s", e.Version)
+	}
+	byFilename := make(map[string]spec.EmbeddedChecksum, len(embedded))
+	for _, ec := range embedded {
+		byFilename[ec.Filename] = ec
+	}
+
+	var assets []PlatformAsset
+	for _, p := range e.Platforms() {
+		target := assetTarget{OS: p.OS, Arch: p.Arch}
+		if strings.ToLower(target.OS) == "linux" {
+			target.Libc = spec.LibcGNU
+		}
+		a, err := e.resolveAsset(target)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range append([]string{a.template}, a.fallbacks...) {
+			filename := a.filename(t)
+			ec, ok := byFilename[filename]
+			if !ok {
+				continue
+			}
+			assets = append(assets, PlatformAsset{
+				OS:        p.OS,
+				Arch:      p.Arch,
+				Filename:  filename,
+				URL:       e.releaseURL(filename),
+				Hash:      ec.Hash,
+				Algorithm: e.Spec.Checksums.EntryAlgorithm(ec),
+				Raw:       a.ext == "" || a.ext == ".exe",
+				Binaries:  a.binaries,
+			})
+			break
+		}
+	}
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("no embedded checksum of version %s matches a platform asset", e.Version)
+	}
+	return assets, nil
+}
+
+// AssetFilenames returns the filenames of every asset the installer may
+// download for e.Version on platform p: the candidates of each C library and
+// Linux distribution variant, without duplicates.
+func (e *Embedder) AssetFilenames(p spec.Platform) ([]string, error) {
+	if e.Spec == nil {
+		return nil, fmt.Errorf("InstallSpec cannot be nil")
+	}
+	var filenames []string
+	for _, target := range e.assetTargets([]spec.Platform{p}) {
+		candidates, err := e.assetCandidates(target)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range candidates {
+			if !slices.Contains(filenames, c) {
+				filenames = append(filenames, c)
+			}
+		}
+	}
+	return filenames, nil
+}
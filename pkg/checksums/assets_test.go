@@ -0,0 +1,63 @@
+package checksums
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestEmbeddedAssets(t *testing.T) {
+	binaries := []spec.Binary{{Name: "tool", Path: "tool"}}
+	embedder := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name: "tool",
+			Repo: "owner/tool",
+			Asset: spec.AssetConfig{
+				Template:         "${NAME}_${VERSION}_${OS}_${ARCH}${EXT}",
+				DefaultExtension: ".tar.gz",
+				Binaries:         binaries,
+				Rules: []spec.AssetRule{
+					{When: spec.PlatformCondition{OS: "windows"}, Ext: ""},
+					{When: spec.PlatformCondition{OS: "darwin"}, FallbackTemplates: []string{"${NAME}_${VERSION}_macos_${ARCH}${EXT}"}},
+				},
+			},
+			Checksums: &spec.ChecksumConfig{
+				Algorithm: spec.SHA256,
+				EmbeddedChecksums: map[string][]spec.EmbeddedChecksum{
+					"v1.0.0": {
+						{Filename: "tool_1.0.0_linux_amd64.tar.gz", Hash: "aaa"},
+						{Filename: "tool_1.0.0_macos_arm64.tar.gz", Hash: "bbb", Algorithm: spec.SHA512},
+					},
+				},
+			},
+			SupportedPlatforms: []spec.Platform{{OS: "linux", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}, {OS: "windows", Arch: "amd64"}},
+		},
+		Version: "v1.0.0",
+	}
+
+	got, err := embedder.EmbeddedAssets()
+	if err != nil {
+		t.Fatalf("EmbeddedAssets() error = %v", err)
+	}
+	want := []PlatformAsset{
+		{
+			OS: "linux", Arch: "amd64", Filename: "tool_1.0.0_linux_amd64.tar.gz",
+			URL:  "https://github.com/owner/tool/releases/download/v1.0.0/tool_1.0.0_linux_amd64.tar.gz",
+			Hash: "aaa", Algorithm: spec.SHA256, Binaries: binaries,
+		},
+		{
+			OS: "darwin", Arch: "arm64", Filename: "tool_1.0.0_macos_arm64.tar.gz",
+			URL:  "https://github.com/owner/tool/releases/download/v1.0.0/tool_1.0.0_macos_arm64.tar.gz",
+			Hash: "bbb", Algorithm: spec.SHA512, Binaries: binaries,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EmbeddedAssets() = %+v, want %+v", got, want)
+	}
+
+	embedder.Version = "v2.0.0"
+	if _, err := embedder.EmbeddedAssets(); err == nil {
+		t.Error("EmbeddedAssets() error = nil, want error for a version without checksums")
+	}
+}
@@ -2,10 +2,9 @@ package checksums
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
@@ -15,16 +14,8 @@ import (
 
 // calculateChecksums downloads assets and calculates checksums
 func (e *Embedder) calculateChecksums() (map[string]string, error) {
-	checksums := make(map[string]string)
-	var platforms []spec.Platform
-
-	// Determine which platforms to calculate checksums for
-	if len(e.Spec.SupportedPlatforms) > 0 {
-		// Use the supported platforms from the spec
-		platforms = e.Spec.SupportedPlatforms
-	} else {
-		// If no platforms specified, use common ones
-		platforms = getCommonPlatforms()
+	if e.Spec.Image != nil {
+		return e.calculateImageChecksums()
 	}
 
 	// Create a temporary directory for downloads
@@ -34,43 +25,74 @@ func (e *Embedder) calculateChecksums() (map[string]string, error) {
 	}
 	defer os.RemoveAll(tempDir)
 
+	// Prefer the actual asset list of GitHub releases over filenames
+	// generated from the asset templates
+	if e.Spec.Asset.DownloadURLTemplate == "" {
+		release, err := e.releaseAssets()
+		if err == nil {
+			return e.calculateReleaseAssetChecksums(tempDir, release)
+		}
+		log.Warnf("Failed to list release assets, falling back to asset templates: %v", err)
+	}
+
+	checksums := make(map[string]string)
+	targets := e.assetTargets(e.Platforms())
+
 	// Use a wait group to process platforms concurrently
 	var wg sync.WaitGroup
-	resultCh := make(chan *checksumResult, len(platforms))
-	errorCh := make(chan error, len(platforms))
+	resultCh := make(chan *checksumResult, len(targets))
+	errorCh := make(chan error, len(targets))
+	verifyErrCh := make(chan error, len(targets))
 
 	// Process each platform
-	for _, platform := range platforms {
+	for _, target := range targets {
 		wg.Add(1)
-		go func(p spec.Platform) {
+		go func(p assetTarget) {
 			defer wg.Done()
 
-			filename, err := e.generateAssetFilename(p.OS, p.Arch)
+			candidates, err := e.assetCandidates(p)
 			if err != nil {
 				errorCh <- fmt.Errorf("failed to generate asset filename for %s/%s: %w", p.OS, p.Arch, err)
 				return
 			}
 
-			// Skip empty filenames
+			// Use the first candidate that can be downloaded
+			filename, assetPath := "", ""
+			for _, candidate := range candidates {
+				// Skip empty filenames
+				if candidate == "" {
+					log.Warnf("Skipping empty filename for %s/%s", p.OS, p.Arch)
+					continue
+				}
+
+				if !e.Spec.Asset.AllowAsset(candidate) {
+					e.assetLog(candidate).Infof("Skipping ignored asset %s for %s/%s", candidate, p.OS, p.Arch)
+					continue
+				}
+
+				// Download the asset
+				candidatePath := filepath.Join(tempDir, candidate)
+				assetURL := e.releaseURL(candidate)
+
+				e.assetLog(candidate).Infof("Downloading %s", assetURL)
+				if err := e.downloadReleaseFile(candidate, candidatePath); err != nil {
+					// Just log the error but don't fail the entire process
+					e.assetLog(candidate).Warnf("Failed to download asset %s: %v", assetURL, err)
+					continue
+				}
+				filename, assetPath = candidate, candidatePath
+				break
+			}
 			if filename == "" {
-				log.Warnf("Skipping empty filename for %s/%s", p.OS, p.Arch)
 				return
 			}
-
-			// Download the asset
-			assetPath := filepath.Join(tempDir, filename)
-			assetURL := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s",
-				e.Spec.Repo, e.Version, filename)
-
-			log.Infof("Downloading %s", assetURL)
-			if err := downloadFile(assetURL, assetPath); err != nil {
-				// Just log the error but don't fail the entire process
-				log.Warnf("Failed to download asset %s: %v", assetURL, err)
+			if err := e.runVerifyPlugins(filename, assetPath); err != nil {
+				verifyErrCh <- err
 				return
 			}
 
 			// Calculate the checksum
-			hash, err := ComputeHash(assetPath, e.Spec.Checksums.Algorithm)
+			hash, err := ComputeHash(assetPath, string(e.Spec.Checksums.Algorithm))
 			if err != nil {
 				errorCh <- fmt.Errorf("failed to compute hash for %s: %w", filename, err)
 				return
@@ -80,13 +102,19 @@ func (e *Embedder) calculateChecksums() (map[string]string, error) {
 				Filename: filename,
 				Hash:     hash,
 			}
-		}(platform)
+		}(target)
 	}
 
 	// Wait for all downloads and hash calculations to finish
 	wg.Wait()
 	close(resultCh)
 	close(errorCh)
+	close(verifyErrCh)
+
+	// Assets rejected by verify plugins must not be embedded
+	if err, ok := <-verifyErrCh; ok {
+		return nil, err
+	}
 
 	// Check for errors
 	for err := range errorCh {
@@ -106,21 +134,266 @@ func (e *Embedder) calculateChecksums() (map[string]string, error) {
 	return checksums, nil
 }
 
+// calculateReleaseAssetChecksums downloads the assets of release, except
+// checksum and signature files, and calculates their checksums. The assets
+// are then matched back to the platforms for reporting.
+func (e *Embedder) calculateReleaseAssetChecksums(tempDir string, release *githubReleaseAssets) (map[string]string, error) {
+	checksumFiles, err := e.checksumFilenames()
+	if err != nil {
+		return nil, err
+	}
+	var filenames []string
+	for _, a := range release.Assets {
+		switch {
+		case slices.Contains(checksumFiles, a.Name) || isReleaseMetadata(a.Name):
+			log.Debugf("Skipping release metadata file %s", a.Name)
+		case !e.Spec.Asset.AllowAsset(a.Name):
+			e.assetLog(a.Name).Infof("Skipping ignored asset %s", a.Name)
+		default:
+			filenames = append(filenames, a.Name)
+		}
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		checksums = make(map[string]string)
+		verifyErr error
+	)
+	for _, filename := range filenames {
+		wg.Add(1)
+		go func(filename string) {
+			defer wg.Done()
+			assetPath := filepath.Join(tempDir, filename)
+			e.assetLog(filename).Infof("Downloading %s", e.releaseURL(filename))
+			if err := e.downloadReleaseFile(filename, assetPath); err != nil {
+				e.assetLog(filename).Warnf("Failed to download asset %s: %v", filename, err)
+				return
+			}
+			if err := e.runVerifyPlugins(filename, assetPath); err != nil {
+				mu.Lock()
+				if verifyErr == nil {
+					verifyErr = err
+				}
+				mu.Unlock()
+				return
+			}
+			hash, err := ComputeHash(assetPath, string(e.Spec.Checksums.Algorithm))
+			if err != nil {
+				e.assetLog(filename).Warnf("Error calculating checksum: failed to compute hash for %s: %v", filename, err)
+				return
+			}
+			mu.Lock()
+			checksums[filename] = hash
+			mu.Unlock()
+		}(filename)
+	}
+	wg.Wait()
+
+	// Assets rejected by verify plugins must not be embedded
+	if verifyErr != nil {
+		return nil, verifyErr
+	}
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("failed to calculate any checksums")
+	}
+	e.reportPlatformAssets(checksums)
+	return checksums, nil
+}
+
+// reportPlatformAssets logs which asset each platform resolves to and warns
+// about platforms without a matching asset.
+func (e *Embedder) reportPlatformAssets(checksums map[string]string) {
+	for _, target := range e.assetTargets(e.Platforms()) {
+		platform := target.String()
+		candidates, err := e.assetCandidates(target)
+		if err != nil {
+			log.Warnf("Failed to generate asset filename for %s: %v", platform, err)
+			continue
+		}
+		found := false
+		for _, candidate := range candidates {
+			if _, ok := checksums[candidate]; ok {
+				log.Infof("%s: %s", platform, candidate)
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Warnf("No release asset found for %s (tried %s)", platform, strings.Join(candidates, ", "))
+		}
+	}
+}
+
+// releaseMetadataSuffixes are suffixes of release files that accompany the
+// assets, such as checksums, signatures and SBOMs.
+var releaseMetadataSuffixes = []string{
+	".sha256", ".sha256sum", ".sha512", ".sha512sum", ".sha1", ".md5",
+	".sig", ".asc", ".minisig", ".pem", ".crt", ".bundle",
+	".sbom", ".sbom.json", ".spdx", ".spdx.json", ".cdx.json", ".intoto.jsonl", ".sigstore", ".sigstore.json",
+}
+
+// isReleaseMetadata reports whether the release file filename is metadata
+// about other assets rather than an installable asset.
+func isReleaseMetadata(filename string) bool {
+	lower := strings.ToLower(filename)
+	if strings.Contains(lower, "checksums") {
+		return true
+	}
+	for _, suffix := range releaseMetadataSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
 // checksumResult represents a checksum calculation result
 type checksumResult struct {
 	Filename string
 	Hash     string
 }
 
+// Platforms returns the platforms to calculate checksums for: the supported
+// platforms of the spec or, if none are specified, common ones.
+func (e *Embedder) Platforms() []spec.Platform {
+	if len(e.Spec.SupportedPlatforms) > 0 {
+		return e.Spec.SupportedPlatforms
+	}
+	return getCommonPlatforms()
+}
+
+// assetTarget is a platform to calculate a checksum for, optionally narrowed
+// down to a C library flavor and a Linux distribution.
+type assetTarget struct {
+	OS   string
+	Arch string
+	Libc string
+	// Distro is empty for the generic asset used when no distro rule
+	// matches.
+	Distro string
+}
+
+func (t assetTarget) String() string {
+	s := t.OS + "/" + t.Arch
+	var details []string
+	for _, d := range []string{t.Libc, t.Distro} {
+		if d != "" {
+			details = append(details, d)
+		}
+	}
+	if len(details) > 0 {
+		s += " (" + strings.Join(details, ", ") + ")"
+	}
+	return s
+}
+
+// assetTargets expands platforms into asset targets. When the asset naming
+// depends on ${LIBC}, every linux platform yields one target per libc. When
+// it depends on ${DISTRO}, every linux platform additionally yields one
+// target per distro named by the asset rules.
+func (e *Embedder) assetTargets(platforms []spec.Platform) []assetTarget {
+	libcs := []string{""}
+	if e.Spec.Asset.UsesLibc() {
+		libcs = []string{spec.LibcGNU, spec.LibcMusl}
+	}
+	distros := []string{""}
+	if e.Spec.Asset.UsesDistro() {
+		distros = append(distros, e.Spec.Asset.Distros()...)
+	}
+	var targets []assetTarget
+	for _, p := range platforms {
+		if strings.ToLower(p.OS) != "linux" {
+			targets = append(targets, assetTarget{OS: p.OS, Arch: p.Arch})
+			continue
+		}
+		for _, distro := range distros {
+			for _, libc := range libcs {
+				targets = append(targets, assetTarget{OS: p.OS, Arch: p.Arch, Libc: libc, Distro: distro})
+			}
+		}
+	}
+	return targets
+}
+
 // generateAssetFilename creates an asset filename for a specific OS and Arch
 func (e *Embedder) generateAssetFilename(osInput, archInput string) (string, error) {
+	target := assetTarget{OS: osInput, Arch: archInput}
+	if strings.ToLower(osInput) == "linux" {
+		target.Libc = spec.LibcGNU
+	}
+	return e.generateTargetAssetFilename(target)
+}
+
+// generateTargetAssetFilename creates an asset filename for a specific
+// target. Libc and Distro are empty on non-linux platforms, matching the
+// installer script.
+func (e *Embedder) generateTargetAssetFilename(target assetTarget) (string, error) {
+	candidates, err := e.assetCandidates(target)
+	if err != nil {
+		return "", err
+	}
+	return candidates[0], nil
+}
+
+// assetCandidates returns the asset filename for a specific target followed
+// by the fallback filenames of the matching rule, in the order the installer
+// script probes them.
+func (e *Embedder) assetCandidates(target assetTarget) ([]string, error) {
+	a, err := e.resolveAsset(target)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]string, 0, 1+len(a.fallbacks))
+	for _, t := range append([]string{a.template}, a.fallbacks...) {
+		candidates = append(candidates, a.filename(t))
+	}
+	return candidates, nil
+}
+
+// resolvedAsset holds the placeholder values and templates of the asset for
+// one platform after applying naming conventions and asset rules.
+type resolvedAsset struct {
+	e         *Embedder
+	osValue   string
+	archValue string
+	ext       string
+	libc      string
+	distro    string
+	template  string
+	fallbacks []string
+	binaries  []spec.Binary
+	vars      map[string]string
+	// exe is set for raw binaries on Windows, which are published as
+	// executables.
+	exe bool
+}
+
+// expand performs variable substitution in template with the platform values.
+func (a *resolvedAsset) expand(template string) string {
+	return a.e.expandAssetTemplate(template, a.osValue, a.archValue, a.ext, a.libc, a.distro, a.vars)
+}
+
+// filename returns the asset filename of the asset template template.
+func (a *resolvedAsset) filename(template string) string {
+	name := a.expand(template)
+	if a.exe {
+		return spec.ExecutableName("windows", name)
+	}
+	return name
+}
+
+// resolveAsset applies naming conventions and the first matching asset rule
+// for a specific target.
+func (e *Embedder) resolveAsset(target assetTarget) (*resolvedAsset, error) {
 	if e.Spec == nil || e.Spec.Asset.Template == "" {
-		return "", fmt.Errorf("asset template not defined in spec")
+		return nil, fmt.Errorf("asset template not defined in spec")
 	}
 
 	// Keep original values for rule matching
-	osMatch := strings.ToLower(osInput)
-	archMatch := strings.ToLower(archInput)
+	osMatch := strings.ToLower(target.OS)
+	archMatch := strings.ToLower(target.Arch)
+	libc, distro := target.Libc, target.Distro
 
 	// Create formatted values for template substitution
 	osValue := osMatch
@@ -136,11 +409,18 @@ func (e *Embedder) generateAssetFilename(osInput, archInput string) (string, err
 	// Apply rules to get the right extension and override OS/Arch if needed
 	ext := e.Spec.Asset.DefaultExtension
 	template := e.Spec.Asset.Template
+	binaries := e.Spec.Asset.Binaries
+	var fallbacks []string
+
+	// Custom variables see the platform before rule overrides, as in the
+	// installer script
+	vars := e.Spec.ResolveVariables(func(s string) string {
+		return e.expandAssetTemplate(s, osValue, archValue, ext, libc, distro, nil)
+	})
 
 	// Check if any rule applies - use osMatch/archMatch for condition checking
 	for _, rule := range e.Spec.Asset.Rules {
-		if (rule.When.OS == "" || rule.When.OS == osMatch) &&
-			(rule.When.Arch == "" || rule.When.Arch == archMatch) {
+		if matchPlatform(rule.When, target) {
 			if rule.OS != "" {
 				osValue = rule.OS
 			}
@@ -153,17 +433,51 @@ func (e *Embedder) generateAssetFilename(osInput, archInput string) (string, err
 			if rule.Template != "" {
 				template = rule.Template
 			}
+			if len(rule.Binaries) > 0 {
+				binaries = rule.Binaries
+			}
+			fallbacks = rule.FallbackTemplates
 			break
 		}
 	}
 
-	// Perform variable substitution in the template
+	return &resolvedAsset{
+		e:         e,
+		osValue:   osValue,
+		archValue: archValue,
+		ext:       ext,
+		libc:      libc,
+		distro:    distro,
+		template:  template,
+		fallbacks: fallbacks,
+		binaries:  binaries,
+		vars:      vars,
+		exe:       osMatch == "windows" && ext == "",
+	}, nil
+}
+
+// matchPlatform reports whether a rule condition matches target.
+func matchPlatform(when spec.PlatformCondition, target assetTarget) bool {
+	return (when.OS == "" || when.OS == strings.ToLower(target.OS)) &&
+		(when.Arch == "" || when.Arch == strings.ToLower(target.Arch)) &&
+		(when.Libc == "" || when.Libc == target.Libc) &&
+		(when.Distro == "" || when.Distro == target.Distro)
+}
+
+// expandAssetTemplate performs variable substitution in an asset template.
+// vars holds resolved custom variables keyed by placeholder.
+func (e *Embedder) expandAssetTemplate(template, osValue, archValue, ext, libc, distro string, vars map[string]string) string {
 	filename := template
+	for placeholder, value := range vars {
+		filename = strings.ReplaceAll(filename, placeholder, value)
+	}
 	filename = strings.ReplaceAll(filename, "${NAME}", e.Spec.Name)
-	filename = strings.ReplaceAll(filename, "${VERSION}", e.Version)
+	filename = strings.ReplaceAll(filename, "${VERSION}", e.Spec.VersionFromTag(e.Version))
 	filename = strings.ReplaceAll(filename, "${OS}", osValue)
 	filename = strings.ReplaceAll(filename, "${ARCH}", archValue)
 	filename = strings.ReplaceAll(filename, "${EXT}", ext)
+	filename = strings.ReplaceAll(filename, "${LIBC}", libc)
+	filename = strings.ReplaceAll(filename, "${DISTRO}", distro)
 
 	// For consistency with the shell script, also handle repo owner/name expansion
 	if strings.Contains(filename, "${REPO_OWNER}") || strings.Contains(filename, "${REPO_NAME}") {
@@ -174,7 +488,7 @@ func (e *Embedder) generateAssetFilename(osInput, archInput string) (string, err
 		}
 	}
 
-	return filename, nil
+	return filename
 }
 
 // titleCase converts a string to title case (first letter uppercase, rest lowercase)
@@ -185,35 +499,6 @@ func titleCase(s string) string {
 	return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
 }
 
-// downloadFile downloads a file from a URL to a local path
-func downloadFile(url, filepath string) error {
-	// Create the file
-	out, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer out.Close()
-
-	// Get the data
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
-	}
-
-	// Write the body to file
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to save file: %w", err)
-	}
-
-	return nil
-}
-
 // getCommonPlatforms returns a list of common platforms
 func getCommonPlatforms() []spec.Platform {
 	return []spec.Platform{
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha3"
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
@@ -12,14 +13,19 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/apex/log"
 	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
+	"github.com/haya14busa/goinstaller/internal/httpclient"
 	"github.com/haya14busa/goinstaller/pkg/spec"
+	"golang.org/x/crypto/blake2b"
+	"lukechampine.com/blake3"
 )
 
 // EmbedMode represents the checksum acquisition mode
@@ -32,6 +38,9 @@ const (
 	EmbedModeChecksumFile EmbedMode = "checksum-file"
 	// EmbedModeCalculate downloads assets and calculates checksums
 	EmbedModeCalculate EmbedMode = "calculate"
+	// EmbedModeProvenance reads subject digests from SLSA provenance or
+	// attestation bundles
+	EmbedModeProvenance EmbedMode = "provenance"
 )
 
 // Embedder manages the process of embedding checksums
@@ -42,6 +51,12 @@ type Embedder struct {
 	SpecAST      *ast.File
 	ChecksumFile string
 	AllPlatforms bool
+	// CacheDir caches downloaded files across runs, keyed by URL and
+	// revalidated with their ETag. Caching is disabled if empty.
+	CacheDir string
+	// Update keeps the existing checksums of the version and only adds
+	// entries for assets that are missing, warning about hash mismatches.
+	Update bool
 }
 
 // Embed performs the checksum embedding process and returns the updated spec
@@ -69,34 +84,56 @@ func (e *Embedder) Embed() error {
 		e.Spec.Checksums.EmbeddedChecksums = make(map[string][]spec.EmbeddedChecksum)
 	}
 
+	// Keep alternate URLs of existing entries so they survive re-embedding
+	existing := e.Spec.Checksums.EmbeddedChecksums[e.Version]
+	existingURLs := make(map[string][]string)
+	for _, ec := range existing {
+		if len(ec.URLs) > 0 {
+			existingURLs[ec.Filename+":"+ec.Hash] = ec.URLs
+		}
+	}
+
 	// Clear any existing checksums for this version to avoid duplicates
 	e.Spec.Checksums.EmbeddedChecksums[e.Version] = nil
 
 	// Perform checksums embedding based on the selected mode
-	var checksums map[string]string
-	var embedErr error
-
-	switch e.Mode {
-	case EmbedModeDownload:
-		checksums, embedErr = e.downloadAndParseChecksumFile()
-	case EmbedModeChecksumFile:
-		checksums, embedErr = e.parseChecksumFile()
-	case EmbedModeCalculate:
-		checksums, embedErr = e.calculateChecksums()
-	default:
-		return fmt.Errorf("invalid mode: %s", e.Mode)
-	}
-
-	if embedErr != nil {
-		return fmt.Errorf("failed to embed checksums: %w", embedErr)
+	checksums, err := e.acquireChecksums()
+	if err != nil {
+		return err
 	}
 
 	// Convert the checksums to EmbeddedChecksum structs
 	embeddedChecksums := make([]spec.EmbeddedChecksum, 0, len(checksums))
+	existingHashes := make(map[string]string)
+	if e.Update {
+		algorithm := e.Spec.Checksums.EntryAlgorithm(spec.EmbeddedChecksum{})
+		for _, ec := range existing {
+			existingHashes[ec.Filename] = ec.Hash
+			if e.Spec.Checksums.EntryAlgorithm(ec) != algorithm {
+				// Hashes of other algorithms are kept but cannot be compared
+				existingHashes[ec.Filename] = ""
+			}
+			embeddedChecksums = append(embeddedChecksums, ec)
+		}
+	}
 	for filename, hash := range checksums {
+		if !e.Spec.Asset.AllowAsset(filename) {
+			e.assetLog(filename).Debugf("Skipping ignored asset: %s", filename)
+			continue
+		}
+		if existingHash, ok := existingHashes[filename]; ok {
+			if existingHash != "" && !strings.EqualFold(existingHash, hash) {
+				e.assetLog(filename).Warnf("Checksum mismatch for %s@%s: embedded %s, got %s (keeping embedded)", filename, e.Version, existingHash, hash)
+			}
+			continue
+		}
+		if e.Update {
+			e.assetLog(filename).Infof("Adding checksum for %s@%s", filename, e.Version)
+		}
 		ec := spec.EmbeddedChecksum{
 			Filename: filename,
 			Hash:     hash,
+			URLs:     existingURLs[filename+":"+hash],
 		}
 		embeddedChecksums = append(embeddedChecksums, ec)
 	}
@@ -126,34 +163,77 @@ func (e *Embedder) Embed() error {
 	return nil
 }
 
+// acquireChecksums returns the checksums of the release e.Version, keyed by
+// filename, using the selected mode.
+func (e *Embedder) acquireChecksums() (map[string]string, error) {
+	var checksums map[string]string
+	var err error
+
+	switch e.Mode {
+	case EmbedModeDownload:
+		checksums, err = e.downloadAndParseChecksumFile()
+	case EmbedModeChecksumFile:
+		checksums, err = e.parseChecksumFile()
+	case EmbedModeCalculate:
+		checksums, err = e.calculateChecksums()
+	case EmbedModeProvenance:
+		checksums, err = e.provenanceChecksums()
+	default:
+		return nil, fmt.Errorf("invalid mode: %s", e.Mode)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed checksums: %w", err)
+	}
+	return checksums, nil
+}
+
 // githubRelease represents the minimal structure needed from GitHub release API
 type githubRelease struct {
 	TagName string `json:"tag_name"`
 }
 
+// assetLog returns the log entry for messages about the release asset
+// filename of e.Version.
+func (e *Embedder) assetLog(filename string) *log.Entry {
+	return log.WithFields(log.Fields{"version": e.Version, "asset": filename})
+}
+
 // resolveVersion resolves "latest" or empty version to an actual version string
 func (e *Embedder) resolveVersion(version string) (string, error) {
 	if version != "latest" && version != "" {
+		if e.Spec != nil {
+			return e.Spec.TagFromVersion(version), nil
+		}
 		return version, nil
 	}
 
+	if e.Spec != nil && e.Spec.Version != nil {
+		switch v := e.Spec.Version; v.Source {
+		case "static":
+			if len(v.Static) == 0 {
+				return "", fmt.Errorf("version.static is empty")
+			}
+			log.Infof("Resolved latest version: %s", v.Static[0])
+			return v.Static[0], nil
+		case "url":
+			return e.resolveVersionFromURL(v.LatestURL)
+		}
+	}
+
 	if e.Spec == nil || e.Spec.Repo == "" {
 		return "", fmt.Errorf("repository not specified in spec")
 	}
 
-	// Use GitHub API to get the latest release
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", e.Spec.Repo)
-
-	// Set up the request with Accept header for JSON response
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	if prefix := e.Spec.Version.Prefix(); prefix != "" || e.Spec.Version.IncludesPrereleases() {
+		return e.resolveLatestTagWithPrefix(prefix)
 	}
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// Use GitHub API to get the latest release
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", e.Spec.GitHubAPI(), e.Spec.Repo)
+
+	// Send the request with Accept header for JSON response
+	resp, err := e.client().Get(url, "application/vnd.github.v3+json")
 	if err != nil {
 		return "", fmt.Errorf("failed to get latest release: %w", err)
 	}
@@ -161,7 +241,7 @@ func (e *Embedder) resolveVersion(version string) (string, error) {
 
 	// Check for successful response
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to get latest release, status code: %d", resp.StatusCode)
+		return "", httpclient.StatusError(resp, fmt.Errorf("failed to get latest release, status code: %d", resp.StatusCode))
 	}
 
 	// Parse the JSON response
@@ -179,53 +259,183 @@ func (e *Embedder) resolveVersion(version string) (string, error) {
 	return release.TagName, nil
 }
 
+// resolveLatestTagWithPrefix returns the newest published release tag that
+// starts with prefix. Monorepos release several components from one
+// repository, so the repository-wide latest release may belong to another one.
+// Prereleases are considered if the spec opts in to them, which
+// /releases/latest never returns.
+func (e *Embedder) resolveLatestTagWithPrefix(prefix string) (string, error) {
+	tags, err := e.LatestReleaseTags(1)
+	if err != nil {
+		return "", err
+	}
+	if len(tags) == 0 {
+		if prefix == "" {
+			return "", fmt.Errorf("no release found")
+		}
+		return "", fmt.Errorf("no release found with tag prefix %q", prefix)
+	}
+	log.Infof("Resolved latest version: %s", tags[0])
+	return tags[0], nil
+}
+
+// resolveVersionFromURL returns the first line of the body of url as the
+// latest version.
+func (e *Embedder) resolveVersionFromURL(url string) (string, error) {
+	resp, err := e.client().Get(url, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest version from %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", httpclient.StatusError(resp, fmt.Errorf("failed to get latest version from %s, status code: %d", url, resp.StatusCode))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read latest version from %s: %w", url, err)
+	}
+	version, _, _ := strings.Cut(string(body), "\n")
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return "", fmt.Errorf("empty version returned from %s", url)
+	}
+	log.Infof("Resolved latest version: %s", version)
+	return version, nil
+}
+
+// releaseURL returns the download URL of the release file filename, using
+// asset.download_url_template if set and GitHub releases otherwise.
+func (e *Embedder) releaseURL(filename string) string {
+	tmpl := e.Spec.Asset.DownloadURLTemplate
+	if tmpl == "" {
+		return fmt.Sprintf("%s/%s/releases/download/%s/%s", e.Spec.GitHubBase(), e.Spec.Repo, e.Version, filename)
+	}
+	return strings.NewReplacer(
+		"${NAME}", e.Spec.Name,
+		"${REPO}", e.Spec.Repo,
+		"${TAG}", e.Version,
+		"${VERSION}", e.Spec.VersionFromTag(e.Version),
+		"${ASSET_FILENAME}", filename,
+	).Replace(tmpl)
+}
+
 // downloadAndParseChecksumFile downloads a checksum file from GitHub releases and parses it
 func (e *Embedder) downloadAndParseChecksumFile() (map[string]string, error) {
-	// Create the expected checksum URL using the spec template
-	checksumFilename := e.createChecksumFilename()
-	if checksumFilename == "" {
+	// Create the expected checksum filenames using the spec templates
+	sources, err := e.checksumSources()
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
 		return nil, fmt.Errorf("unable to generate checksum filename")
 	}
 
-	checksumURL := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s",
-		e.Spec.Repo, e.Version, checksumFilename)
-
-	log.Infof("Downloading checksums from %s", checksumURL)
-
-	// Create a temporary file to store the checksum file
+	// Create a temporary directory to store the checksum files
 	tempDir, err := os.MkdirTemp("", "binstaller-checksums")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
 	defer os.RemoveAll(tempDir)
 
-	tempFilePath := filepath.Join(tempDir, "checksums.txt")
+	checksums := make(map[string]string)
+	for i, src := range sources {
+		checksumFilename := src.Filename
+		tempFilePath := filepath.Join(tempDir, fmt.Sprintf("checksums-%d.txt", i))
+
+		// Download the checksum file, which may be hosted outside the release
+		if spec.IsURLTemplate(checksumFilename) {
+			checksumURL := checksumFilename
+			checksumFilename = spec.URLFilename(checksumURL)
+			log.Infof("Downloading checksums from %s", checksumURL)
+			if err := e.client().Download(checksumURL, "", tempFilePath); err != nil {
+				return nil, fmt.Errorf("failed to download checksum file: %w", err)
+			}
+		} else {
+			log.Infof("Downloading checksums from %s", e.releaseURL(checksumFilename))
+			if err := e.downloadReleaseFile(checksumFilename, tempFilePath); err != nil {
+				return nil, fmt.Errorf("failed to download checksum file: %w", err)
+			}
+		}
+		if err := e.verifyChecksumSignature(checksumFilename, tempFilePath); err != nil {
+			return nil, err
+		}
 
-	// Download the checksum file
-	resp, err := http.Get(checksumURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download checksum file: %w", err)
+		// Parse the checksum file
+		parsed, err := parseChecksumFileForAsset(tempFilePath, src.Asset)
+		if err != nil {
+			return nil, err
+		}
+		for filename, hash := range parsed {
+			checksums[filename] = hash
+		}
 	}
-	defer resp.Body.Close()
+	return checksums, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download checksum file, status code: %d", resp.StatusCode)
-	}
+// checksumSource is a checksum file to download. Asset is set for per-asset
+// sidecar files such as "${ASSET_FILENAME}.sha256", whose hash-only contents
+// belong to that asset.
+type checksumSource struct {
+	Filename string
+	Asset    string
+}
 
-	// Save the checksum file to a temporary file
-	tempFile, err := os.Create(tempFilePath)
+// checksumFilenames returns the distinct checksum filenames to download.
+func (e *Embedder) checksumFilenames() ([]string, error) {
+	sources, err := e.checksumSources()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		return nil, err
 	}
-	defer tempFile.Close()
+	filenames := make([]string, 0, len(sources))
+	for _, src := range sources {
+		filenames = append(filenames, src.Filename)
+	}
+	return filenames, nil
+}
 
-	_, err = io.Copy(tempFile, resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to save checksum file: %w", err)
+// checksumSources returns the distinct checksum files to download. With
+// checksums.rules or a platform dependent template, the checksum file of
+// every platform is resolved like the installer script does.
+func (e *Embedder) checksumSources() ([]checksumSource, error) {
+	if e.Spec.Checksums == nil {
+		return nil, nil
+	}
+	if len(e.Spec.Checksums.Rules) == 0 && !strings.Contains(e.expandChecksumPlaceholders(e.Spec.Checksums.Template), "${") {
+		if filename := e.createChecksumFilename(); filename != "" {
+			return []checksumSource{{Filename: filename}}, nil
+		}
+		return nil, nil
 	}
 
-	// Parse the checksum file
-	return parseChecksumFileInternal(tempFilePath)
+	var sources []checksumSource
+	seen := make(map[string]bool)
+	for _, target := range e.assetTargets(e.Platforms()) {
+		a, err := e.resolveAsset(target)
+		if err != nil {
+			return nil, err
+		}
+		// Later matching rules win, as in the installer script
+		template := e.Spec.Checksums.Template
+		for _, rule := range e.Spec.Checksums.Rules {
+			if matchPlatform(rule.When, target) {
+				template = rule.Template
+			}
+		}
+		if template == "" {
+			continue
+		}
+		src := checksumSource{}
+		if strings.Contains(template, "${ASSET_FILENAME}") {
+			src.Asset = a.filename(a.template)
+			template = strings.ReplaceAll(template, "${ASSET_FILENAME}", src.Asset)
+		}
+		src.Filename = e.expandChecksumPlaceholders(a.expand(template))
+		if !seen[src.Filename] {
+			seen[src.Filename] = true
+			sources = append(sources, src)
+		}
+	}
+	return sources, nil
 }
 
 // parseChecksumFile parses a local checksum file
@@ -238,8 +448,19 @@ func (e *Embedder) parseChecksumFile() (map[string]string, error) {
 	return parseChecksumFileInternal(e.ChecksumFile)
 }
 
+// bsdChecksumLineRe matches BSD-style checksum lines as written by BSD
+// checksum tools and "shasum --tag", e.g. "SHA256 (file) = hash".
+var bsdChecksumLineRe = regexp.MustCompile(`^[A-Za-z0-9-]+ \((.+)\) = ([0-9A-Fa-f]+)$`)
+
 // parseChecksumFileInternal parses a checksum file and returns a map of filename to hash
 func parseChecksumFileInternal(checksumFile string) (map[string]string, error) {
+	return parseChecksumFileForAsset(checksumFile, "")
+}
+
+// parseChecksumFileForAsset parses a checksum file like
+// parseChecksumFileInternal. If asset is set, the file is a sidecar of that
+// asset and may contain just the hash.
+func parseChecksumFileForAsset(checksumFile, asset string) (map[string]string, error) {
 	checksums := make(map[string]string)
 
 	file, err := os.Open(checksumFile)
@@ -256,19 +477,33 @@ func parseChecksumFileInternal(checksumFile string) (map[string]string, error) {
 			continue
 		}
 
+		// BSD format: <ALGORITHM> (<filename>) = <hash>
+		if m := bsdChecksumLineRe.FindStringSubmatch(line); m != nil {
+			checksums[m[1]] = strings.ToLower(m[2])
+			continue
+		}
+
 		// Parse the line as a checksum entry
 		// Format: <hash> [*]<filename>
 		parts := strings.Fields(line)
+		if len(parts) == 1 && asset != "" {
+			checksums[asset] = strings.ToLower(parts[0])
+			continue
+		}
 		if len(parts) < 2 {
 			log.Warnf("Ignoring invalid checksum line: %s", line)
 			continue
 		}
 
-		hash := parts[0]
+		// Installer scripts compare against lowercase hashes
+		hash := strings.ToLower(parts[0])
 		filename := parts[1] // Take the second field as filename
 
 		// If the filename starts with *, remove it (common in standard checksums)
 		filename = strings.TrimPrefix(filename, "*")
+		if asset != "" && path.Base(filename) == asset {
+			filename = asset
+		}
 
 		checksums[filename] = hash
 	}
@@ -292,8 +527,17 @@ func (e *Embedder) createChecksumFilename() string {
 
 	// Perform variable substitution in the template
 	filename := e.Spec.Checksums.Template
+	for placeholder, value := range e.Spec.ResolveVariables(e.expandChecksumPlaceholders) {
+		filename = strings.ReplaceAll(filename, placeholder, value)
+	}
+	return e.expandChecksumPlaceholders(filename)
+}
+
+// expandChecksumPlaceholders substitutes the platform independent built-in
+// placeholders of a checksum template.
+func (e *Embedder) expandChecksumPlaceholders(filename string) string {
 	filename = strings.ReplaceAll(filename, "${NAME}", e.Spec.Name)
-	filename = strings.ReplaceAll(filename, "${VERSION}", e.Version)
+	filename = strings.ReplaceAll(filename, "${VERSION}", e.Spec.VersionFromTag(e.Version))
 	filename = strings.ReplaceAll(filename, "${REPO}", e.Spec.Repo)
 
 	// For consistency with the shell script, also handle repo owner/name expansion
@@ -324,6 +568,15 @@ func ComputeHash(filepath string, algorithm string) (string, error) {
 		h = sha1.New()
 	case "sha512":
 		h = sha512.New()
+	case "sha3-256":
+		h = sha3.New256()
+	case "blake2b":
+		h, err = blake2b.New512(nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to create blake2b hash: %w", err)
+		}
+	case "blake3":
+		h = blake3.New(32, nil)
 	default:
 		return "", fmt.Errorf("unsupported hash algorithm: %s", algorithm)
 	}
@@ -1,10 +1,15 @@
 package checksums
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/goccy/go-yaml/parser"
 	"github.com/haya14busa/goinstaller/pkg/spec"
 )
 
@@ -23,6 +28,7 @@ func TestParseChecksumFileInternal(t *testing.T) {
 abc123 test-1.0.0-linux-amd64.tar.gz
 def456  test-1.0.0-darwin-amd64.tar.gz
 ghi789 *test-1.0.0-windows-amd64.zip
+SHA256 (test-1.0.0-freebsd-amd64.tar.gz) = 0123abcd
 `
 	if err := os.WriteFile(checksumFile, []byte(content), 0644); err != nil {
 		t.Fatalf("Failed to write test checksum file: %v", err)
@@ -39,6 +45,7 @@ ghi789 *test-1.0.0-windows-amd64.zip
 		"test-1.0.0-linux-amd64.tar.gz":   "abc123",
 		"test-1.0.0-darwin-amd64.tar.gz":  "def456",
 		"test-1.0.0-windows-amd64.zip":    "ghi789",
+		"test-1.0.0-freebsd-amd64.tar.gz": "0123abcd",
 	}
 
 	if len(checksums) != len(expected) {
@@ -154,4 +161,493 @@ func TestComputeHash(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for unsupported algorithm, got nil")
 	}
-}
\ No newline at end of file
+}
+
+func TestReleaseURL(t *testing.T) {
+	embedder := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name: "test-tool",
+			Repo: "test-owner/test-repo",
+		},
+		Version: "v1.0.0",
+	}
+
+	got := embedder.releaseURL("test-tool_1.0.0_linux_amd64.tar.gz")
+	expected := "https://github.com/test-owner/test-repo/releases/download/v1.0.0/test-tool_1.0.0_linux_amd64.tar.gz"
+	if got != expected {
+		t.Errorf("Expected URL %s, got %s", expected, got)
+	}
+
+	embedder.Spec.Asset.DownloadURLTemplate = "https://dl.example.com/${NAME}/${VERSION}/${ASSET_FILENAME}"
+	got = embedder.releaseURL("checksums.txt")
+	expected = "https://dl.example.com/test-tool/1.0.0/checksums.txt"
+	if got != expected {
+		t.Errorf("Expected URL %s, got %s", expected, got)
+	}
+}
+
+func TestGenerateAssetFilenameLibc(t *testing.T) {
+	embedder := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name: "tool",
+			Asset: spec.AssetConfig{
+				Template: "${NAME}-${VERSION}-${ARCH}-unknown-${OS}-${LIBC}${EXT}",
+				Rules: []spec.AssetRule{
+					{When: spec.PlatformCondition{OS: "darwin"}, Template: "${NAME}-${VERSION}-${ARCH}-apple-darwin${EXT}"},
+					{When: spec.PlatformCondition{Libc: "musl"}, Ext: ".tgz"},
+				},
+				DefaultExtension: ".tar.gz",
+			},
+		},
+		Version: "1.0.0",
+	}
+
+	targets := embedder.assetTargets([]spec.Platform{{OS: "linux", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}})
+	want := []assetTarget{
+		{OS: "linux", Arch: "amd64", Libc: "gnu"},
+		{OS: "linux", Arch: "amd64", Libc: "musl"},
+		{OS: "darwin", Arch: "arm64"},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Fatalf("assetTargets() = %+v, want %+v", targets, want)
+	}
+
+	wantNames := []string{
+		"tool-1.0.0-amd64-unknown-linux-gnu.tar.gz",
+		"tool-1.0.0-amd64-unknown-linux-musl.tgz",
+		"tool-1.0.0-arm64-apple-darwin.tar.gz",
+	}
+	for i, target := range targets {
+		got, err := embedder.generateTargetAssetFilename(target)
+		if err != nil {
+			t.Fatalf("generateTargetAssetFilename failed: %v", err)
+		}
+		if got != wantNames[i] {
+			t.Errorf("generateTargetAssetFilename(%+v) = %q, want %q", target, got, wantNames[i])
+		}
+	}
+}
+
+func TestResolveVersionTagPrefix(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/mono/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"tag_name":"cli/v2.0.0-rc.1","prerelease":true},
+			{"tag_name":"server/v3.1.0"},
+			{"tag_name":"cli/v1.9.0","draft":true},
+			{"tag_name":"cli/v1.8.0"}
+		]`)
+	}))
+	defer srv.Close()
+
+	e := &Embedder{
+		Spec: &spec.InstallSpec{
+			Repo:         "o/mono",
+			GitHubAPIURL: srv.URL,
+			Version:      &spec.VersionConfig{TagTemplate: "cli/v${VERSION}"},
+		},
+	}
+	got, err := e.resolveVersion("latest")
+	if err != nil {
+		t.Fatalf("resolveVersion() error = %v", err)
+	}
+	if got != "cli/v1.8.0" {
+		t.Errorf("resolveVersion(latest) = %q, want %q", got, "cli/v1.8.0")
+	}
+
+	got, err = e.resolveVersion("1.2.3")
+	if err != nil {
+		t.Fatalf("resolveVersion() error = %v", err)
+	}
+	if got != "cli/v1.2.3" {
+		t.Errorf("resolveVersion(1.2.3) = %q, want %q", got, "cli/v1.2.3")
+	}
+}
+
+func TestResolveVersionPrerelease(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/tool/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"tag_name":"v2.0.0-rc.2","draft":true,"prerelease":true},
+			{"tag_name":"v2.0.0-rc.1","prerelease":true},
+			{"tag_name":"v1.8.0"}
+		]`)
+	}))
+	defer srv.Close()
+
+	e := &Embedder{
+		Spec: &spec.InstallSpec{
+			Repo:         "o/tool",
+			GitHubAPIURL: srv.URL,
+			Version:      &spec.VersionConfig{Prerelease: true},
+		},
+	}
+	got, err := e.resolveVersion("latest")
+	if err != nil {
+		t.Fatalf("resolveVersion() error = %v", err)
+	}
+	if got != "v2.0.0-rc.1" {
+		t.Errorf("resolveVersion(latest) = %q, want %q", got, "v2.0.0-rc.1")
+	}
+}
+
+func TestGenerateAssetFilenameWindowsExe(t *testing.T) {
+	embedder := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name: "tool",
+			Asset: spec.AssetConfig{
+				Template: "${NAME}-${OS}-${ARCH}${EXT}",
+				Rules: []spec.AssetRule{
+					{When: spec.PlatformCondition{OS: "darwin"}, Ext: ".zip"},
+				},
+			},
+		},
+		Version: "v1.0.0",
+	}
+	tests := []struct {
+		os, want string
+	}{
+		{os: "windows", want: "tool-windows-amd64.exe"},
+		{os: "linux", want: "tool-linux-amd64"},
+		{os: "darwin", want: "tool-darwin-amd64.zip"},
+	}
+	for _, tt := range tests {
+		got, err := embedder.generateAssetFilename(tt.os, "amd64")
+		if err != nil {
+			t.Fatalf("generateAssetFilename(%s) error = %v", tt.os, err)
+		}
+		if got != tt.want {
+			t.Errorf("generateAssetFilename(%s) = %q, want %q", tt.os, got, tt.want)
+		}
+	}
+
+	// Archives on Windows keep their name
+	embedder.Spec.Asset.DefaultExtension = ".zip"
+	got, err := embedder.generateAssetFilename("windows", "amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "tool-windows-amd64.zip" {
+		t.Errorf("generateAssetFilename(windows) = %q, want %q", got, "tool-windows-amd64.zip")
+	}
+}
+
+func TestGenerateAssetFilenameDistro(t *testing.T) {
+	embedder := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name: "tool",
+			Asset: spec.AssetConfig{
+				Template: "${NAME}-${VERSION}-${OS}-${ARCH}${EXT}",
+				Rules: []spec.AssetRule{
+					{When: spec.PlatformCondition{Distro: "ubuntu20.04"}, Template: "${NAME}-${VERSION}-${DISTRO}-${ARCH}${EXT}"},
+					{When: spec.PlatformCondition{Distro: "amzn2"}, Template: "${NAME}-${VERSION}-amazonlinux2-${ARCH}${EXT}"},
+				},
+				DefaultExtension: ".tar.gz",
+			},
+		},
+		Version: "1.0.0",
+	}
+
+	targets := embedder.assetTargets([]spec.Platform{{OS: "linux", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}})
+	want := []assetTarget{
+		{OS: "linux", Arch: "amd64"},
+		{OS: "linux", Arch: "amd64", Distro: "ubuntu20.04"},
+		{OS: "linux", Arch: "amd64", Distro: "amzn2"},
+		{OS: "darwin", Arch: "arm64"},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Fatalf("assetTargets() = %+v, want %+v", targets, want)
+	}
+
+	wantNames := []string{
+		"tool-1.0.0-linux-amd64.tar.gz",
+		"tool-1.0.0-ubuntu20.04-amd64.tar.gz",
+		"tool-1.0.0-amazonlinux2-amd64.tar.gz",
+		"tool-1.0.0-darwin-arm64.tar.gz",
+	}
+	for i, target := range targets {
+		got, err := embedder.generateTargetAssetFilename(target)
+		if err != nil {
+			t.Fatalf("generateTargetAssetFilename failed: %v", err)
+		}
+		if got != wantNames[i] {
+			t.Errorf("generateTargetAssetFilename(%+v) = %q, want %q", target, got, wantNames[i])
+		}
+	}
+}
+
+func TestAssetCandidates(t *testing.T) {
+	embedder := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name: "tool",
+			Asset: spec.AssetConfig{
+				Template: "${NAME}_${VERSION}_${OS}_${ARCH}${EXT}",
+				Rules: []spec.AssetRule{
+					{
+						When:              spec.PlatformCondition{OS: "darwin"},
+						FallbackTemplates: []string{"${NAME}_${VERSION}_macos_${ARCH}${EXT}", "${NAME}_${VERSION}_darwin_all${EXT}"},
+					},
+				},
+				DefaultExtension: ".tar.gz",
+			},
+		},
+		Version: "v1.0.0",
+	}
+
+	got, err := embedder.assetCandidates(assetTarget{OS: "darwin", Arch: "arm64"})
+	if err != nil {
+		t.Fatalf("assetCandidates failed: %v", err)
+	}
+	want := []string{
+		"tool_1.0.0_darwin_arm64.tar.gz",
+		"tool_1.0.0_macos_arm64.tar.gz",
+		"tool_1.0.0_darwin_all.tar.gz",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("assetCandidates() = %q, want %q", got, want)
+	}
+
+	got, err = embedder.assetCandidates(assetTarget{OS: "linux", Arch: "amd64", Libc: "gnu"})
+	if err != nil {
+		t.Fatalf("assetCandidates failed: %v", err)
+	}
+	if want := []string{"tool_1.0.0_linux_amd64.tar.gz"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("assetCandidates() = %q, want %q", got, want)
+	}
+}
+
+func TestChecksumFilenames(t *testing.T) {
+	embedder := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name: "tool",
+			Asset: spec.AssetConfig{
+				Template:         "${NAME}_${OS}_${ARCH}${EXT}",
+				DefaultExtension: ".tar.gz",
+				NamingConvention: &spec.NamingConvention{OS: "titlecase"},
+			},
+			Checksums: &spec.ChecksumConfig{
+				Template: "${NAME}_${VERSION}_checksums.txt",
+				Rules: []spec.ChecksumRule{
+					{When: spec.PlatformCondition{OS: "linux"}, Template: "checksums-${OS}.txt"},
+					{When: spec.PlatformCondition{OS: "darwin"}, Template: "checksums-macos.txt"},
+				},
+			},
+			SupportedPlatforms: []spec.Platform{
+				{OS: "linux", Arch: "amd64"},
+				{OS: "linux", Arch: "arm64"},
+				{OS: "darwin", Arch: "arm64"},
+				{OS: "windows", Arch: "amd64"},
+			},
+		},
+		Version: "v1.0.0",
+	}
+	got, err := embedder.checksumFilenames()
+	if err != nil {
+		t.Fatalf("checksumFilenames() error = %v", err)
+	}
+	want := []string{"checksums-Linux.txt", "checksums-macos.txt", "tool_1.0.0_checksums.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checksumFilenames() = %q, want %q", got, want)
+	}
+}
+
+func TestComputeHashAlgorithms(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Reference digests of "hello\n".
+	tests := map[string]string{
+		"sha256":   "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+		"sha3-256": "b314e28493eae9dab57ac4f0c6d887bddbbeb810e900d818395ace558e96516d",
+		"blake2b":  "f60ce482e5cc1229f39d71313171a8d9f4ca3a87d066bf4b205effb528192a75f14f3271e2c1a90e1de53f275b4d4793eef2f5e31ea90d2ce29d2e481c36435f",
+		"blake3":   "8e4c7c1b99dbfd50e7a95185fead5ee1448fa904a2fdd778eaf5f2dbfd629a99",
+	}
+	for algo, want := range tests {
+		got, err := ComputeHash(path, algo)
+		if err != nil {
+			t.Fatalf("ComputeHash(%s) error = %v", algo, err)
+		}
+		if got != want {
+			t.Errorf("ComputeHash(%s) = %s, want %s", algo, got, want)
+		}
+	}
+}
+
+func TestEmbedUpdate(t *testing.T) {
+	checksumFile := filepath.Join(t.TempDir(), "checksums.txt")
+	content := "aaaa  tool_linux_amd64.tar.gz\nffff  tool_darwin_arm64.tar.gz\ncccc  tool_windows_amd64.zip\n"
+	if err := os.WriteFile(checksumFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	specYAML := `name: tool
+repo: o/tool
+checksums:
+  embedded_checksums:
+    v1.0.0:
+      - filename: tool_darwin_arm64.tar.gz
+        hash: bbbb
+`
+	file, err := parser.ParseBytes([]byte(specYAML), parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	installSpec, err := spec.Parse([]byte(specYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &Embedder{
+		Mode:         EmbedModeChecksumFile,
+		Version:      "v1.0.0",
+		Spec:         installSpec,
+		SpecAST:      file,
+		ChecksumFile: checksumFile,
+		Update:       true,
+	}
+	if err := e.Embed(); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	got := installSpec.Checksums.EmbeddedChecksums["v1.0.0"]
+	want := []spec.EmbeddedChecksum{
+		{Filename: "tool_darwin_arm64.tar.gz", Hash: "bbbb"}, // mismatch keeps the embedded hash
+		{Filename: "tool_linux_amd64.tar.gz", Hash: "aaaa"},
+		{Filename: "tool_windows_amd64.zip", Hash: "cccc"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EmbeddedChecksums = %+v, want %+v", got, want)
+	}
+}
+
+func TestEmbedDeterministicOutput(t *testing.T) {
+	checksumFile := filepath.Join(t.TempDir(), "checksums.txt")
+	content := "cccc  tool_windows_amd64.zip\naaaa  tool_linux_amd64.tar.gz\nbbbb  tool_darwin_arm64.tar.gz\n"
+	if err := os.WriteFile(checksumFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	specYAML := `name: tool
+repo: o/tool
+checksums:
+  embedded_checksums:
+    v2.0.0:
+      - filename: tool_linux_amd64.tar.gz
+        hash: dddd
+`
+	embed := func() string {
+		file, err := parser.ParseBytes([]byte(specYAML), parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		installSpec, err := spec.Parse([]byte(specYAML))
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := &Embedder{
+			Mode:         EmbedModeChecksumFile,
+			Version:      "v1.0.0",
+			Spec:         installSpec,
+			SpecAST:      file,
+			ChecksumFile: checksumFile,
+		}
+		if err := e.Embed(); err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+		return file.String()
+	}
+
+	want := `name: tool
+repo: o/tool
+checksums:
+  embedded_checksums:
+    v1.0.0:
+    - filename: tool_darwin_arm64.tar.gz
+      hash: bbbb
+    - filename: tool_linux_amd64.tar.gz
+      hash: aaaa
+    - filename: tool_windows_amd64.zip
+      hash: cccc
+    v2.0.0:
+    - filename: tool_linux_amd64.tar.gz
+      hash: dddd
+`
+	for i := 0; i < 5; i++ {
+		if got := embed(); got != want {
+			t.Fatalf("run %d: embedded spec =\n%s\nwant\n%s", i, got, want)
+		}
+	}
+}
+
+func TestDownloadChecksumFileFromURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cdn/1.0.0/SHA256SUMS" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "aaaa  tool_linux_amd64.tar.gz\n")
+	}))
+	defer srv.Close()
+
+	e := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name:      "tool",
+			Repo:      "o/tool",
+			Checksums: &spec.ChecksumConfig{Template: srv.URL + "/cdn/${VERSION}/SHA256SUMS"},
+		},
+		Version: "v1.0.0",
+	}
+	got, err := e.downloadAndParseChecksumFile()
+	if err != nil {
+		t.Fatalf("downloadAndParseChecksumFile() error = %v", err)
+	}
+	if want := map[string]string{"tool_linux_amd64.tar.gz": "aaaa"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("downloadAndParseChecksumFile() = %v, want %v", got, want)
+	}
+}
+
+func TestDownloadSidecarChecksumFiles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/o/tool/releases/download/v1.0.0/tool_linux_amd64.tar.gz.sha256":
+			fmt.Fprint(w, "AAAA\r\n") // hash only
+		case "/o/tool/releases/download/v1.0.0/tool_darwin_arm64.tar.gz.sha256":
+			fmt.Fprint(w, "bbbb  ./dist/tool_darwin_arm64.tar.gz\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	e := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name:          "tool",
+			Repo:          "o/tool",
+			GitHubBaseURL: srv.URL,
+			Asset: spec.AssetConfig{
+				Template:         "${NAME}_${OS}_${ARCH}${EXT}",
+				DefaultExtension: ".tar.gz",
+			},
+			Checksums: &spec.ChecksumConfig{Template: "${ASSET_FILENAME}.sha256"},
+			SupportedPlatforms: []spec.Platform{
+				{OS: "linux", Arch: "amd64"},
+				{OS: "darwin", Arch: "arm64"},
+			},
+		},
+		Version: "v1.0.0",
+	}
+	got, err := e.downloadAndParseChecksumFile()
+	if err != nil {
+		t.Fatalf("downloadAndParseChecksumFile() error = %v", err)
+	}
+	want := map[string]string{
+		"tool_linux_amd64.tar.gz":  "aaaa",
+		"tool_darwin_arm64.tar.gz": "bbbb",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("downloadAndParseChecksumFile() = %v, want %v", got, want)
+	}
+}
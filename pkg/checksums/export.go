@@ -0,0 +1,79 @@
+package checksums
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+// ExportFormats are the formats supported by Export.
+var ExportFormats = []string{"json", "csv"}
+
+// ExportedChecksum is an embedded checksum as written by Export.
+type ExportedChecksum struct {
+	Version   string   `json:"version"`
+	Filename  string   `json:"filename"`
+	Algorithm string   `json:"algorithm"`
+	Hash      string   `json:"hash"`
+	URLs      []string `json:"urls,omitempty"`
+}
+
+// Export writes the embedded checksums of c to w in format ("json" or
+// "csv"), ordered by version and filename, for external auditing or
+// publishing alongside releases.
+func Export(w io.Writer, c *spec.ChecksumConfig, format string) error {
+	entries := exportedChecksums(c)
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"version", "filename", "algorithm", "hash", "urls"}); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := cw.Write([]string{e.Version, e.Filename, e.Algorithm, e.Hash, strings.Join(e.URLs, " ")}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unsupported export format %q (supported: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+func exportedChecksums(c *spec.ChecksumConfig) []ExportedChecksum {
+	entries := []ExportedChecksum{}
+	if c == nil {
+		return entries
+	}
+	algorithm := string(c.Algorithm)
+	if algorithm == "" {
+		algorithm = string(spec.SHA256)
+	}
+	for _, version := range slices.Sorted(maps.Keys(c.EmbeddedChecksums)) {
+		checksums := slices.Clone(c.EmbeddedChecksums[version])
+		slices.SortStableFunc(checksums, func(a, b spec.EmbeddedChecksum) int {
+			return strings.Compare(a.Filename, b.Filename)
+		})
+		for _, ec := range checksums {
+			entries = append(entries, ExportedChecksum{
+				Version:   version,
+				Filename:  ec.Filename,
+				Algorithm: algorithm,
+				Hash:      ec.Hash,
+				URLs:      ec.URLs,
+			})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,77 @@
+package checksums
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestExport(t *testing.T) {
+	c := &spec.ChecksumConfig{
+		EmbeddedChecksums: map[string][]spec.EmbeddedChecksum{
+			"v1.1.0": {{Filename: "tool_linux_amd64.tar.gz", Hash: "cccc"}},
+			"v1.0.0": {
+				{Filename: "tool_linux_amd64.tar.gz", Hash: "bbbb", URLs: []string{"https://mirror/a", "https://mirror/b"}},
+				{Filename: "tool_darwin_arm64.tar.gz", Hash: "aaaa"},
+			},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "csv",
+			want: `version,filename,algorithm,hash,urls
+v1.0.0,tool_darwin_arm64.tar.gz,sha256,aaaa,
+v1.0.0,tool_linux_amd64.tar.gz,sha256,bbbb,https://mirror/a https://mirror/b
+v1.1.0,tool_linux_amd64.tar.gz,sha256,cccc,
+`,
+		},
+		{
+			format: "json",
+			want: `[
+  {
+    "version": "v1.0.0",
+    "filename": "tool_darwin_arm64.tar.gz",
+    "algorithm": "sha256",
+    "hash": "aaaa"
+  },
+  {
+    "version": "v1.0.0",
+    "filename": "tool_linux_amd64.tar.gz",
+    "algorithm": "sha256",
+    "hash": "bbbb",
+    "urls": [
+      "https://mirror/a",
+      "https://mirror/b"
+    ]
+  },
+  {
+    "version": "v1.1.0",
+    "filename": "tool_linux_amd64.tar.gz",
+    "algorithm": "sha256",
+    "hash": "cccc"
+  }
+]
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, c, tt.format); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Export() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if err := Export(&bytes.Buffer{}, c, "xml"); err == nil {
+		t.Error("Export() expected error for unsupported format")
+	}
+}
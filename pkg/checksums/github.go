@@ -0,0 +1,136 @@
+package checksums
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/internal/httpclient"
+)
+
+// githubReleaseAssets represents the assets of a release in the GitHub API.
+type githubReleaseAssets struct {
+	Assets []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"assets"`
+}
+
+// githubReleaseListItem represents a release in the GitHub releases list API.
+type githubReleaseListItem struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// LatestReleaseTags returns the tags of the n newest published releases,
+// newest first. Drafts and, unless the spec opts in to them, prereleases are
+// skipped, as are tags without the spec's tag prefix. Fewer than n tags are returned if the repository does
+// not have that many releases.
+func (e *Embedder) LatestReleaseTags(n int) ([]string, error) {
+	if e.Spec == nil || e.Spec.Repo == "" {
+		return nil, fmt.Errorf("repository not specified in spec")
+	}
+	prefix := e.Spec.Version.Prefix()
+	prerelease := e.Spec.Version.IncludesPrereleases()
+	var tags []string
+	for page := 1; len(tags) < n; page++ {
+		releases, err := e.listReleases(page)
+		if err != nil {
+			return nil, err
+		}
+		if len(releases) == 0 {
+			break
+		}
+		for _, r := range releases {
+			if r.Draft || (r.Prerelease && !prerelease) || !strings.HasPrefix(r.TagName, prefix) {
+				continue
+			}
+			tags = append(tags, r.TagName)
+			if len(tags) == n {
+				break
+			}
+		}
+	}
+	return tags, nil
+}
+
+// listReleases returns one page of the releases of the spec's repository.
+func (e *Embedder) listReleases(page int) ([]githubReleaseListItem, error) {
+	listURL := fmt.Sprintf("%s/repos/%s/releases?per_page=100&page=%d", e.Spec.GitHubAPI(), e.Spec.Repo, page)
+	resp, err := e.client().Get(listURL, "application/vnd.github.v3+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpclient.StatusError(resp, fmt.Errorf("failed to list releases, status code: %d", resp.StatusCode))
+	}
+
+	var releases []githubReleaseListItem
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub API response: %w", err)
+	}
+	return releases, nil
+}
+
+// downloadReleaseFile downloads the release file filename to dest. If
+// a GitHub token is set and the spec uses GitHub releases, the file is fetched
+// through the GitHub API so that private repositories work, falling back to
+// the public download URL.
+func (e *Embedder) downloadReleaseFile(filename, dest string) error {
+	if httpclient.Token() != "" && e.Spec.Asset.DownloadURLTemplate == "" {
+		err := e.downloadReleaseFileViaAPI(filename, dest)
+		if err == nil {
+			return nil
+		}
+		log.Debugf("GitHub API download of %s failed, falling back to %s: %v", filename, e.releaseURL(filename), err)
+	}
+	return e.client().Download(e.releaseURL(filename), "", dest)
+}
+
+// releaseAssets returns the assets of the release e.Version.
+func (e *Embedder) releaseAssets() (*githubReleaseAssets, error) {
+	releaseURL := fmt.Sprintf("%s/repos/%s/releases/tags/%s", e.Spec.GitHubAPI(), e.Spec.Repo, url.PathEscape(e.Version))
+	resp, err := e.client().Get(releaseURL, "application/vnd.github.v3+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release %s: %w", e.Version, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpclient.StatusError(resp, fmt.Errorf("failed to get release %s, status code: %d", e.Version, resp.StatusCode))
+	}
+	var release githubReleaseAssets
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub API response: %w", err)
+	}
+	return &release, nil
+}
+
+func (e *Embedder) downloadReleaseFileViaAPI(filename, dest string) error {
+	release, err := e.releaseAssets()
+	if err != nil {
+		return err
+	}
+
+	assetURL := ""
+	for _, a := range release.Assets {
+		if a.Name == filename {
+			assetURL = a.URL
+			break
+		}
+	}
+	if assetURL == "" {
+		return fmt.Errorf("asset %s not found in release %s", filename, e.Version)
+	}
+
+	// The API redirects to the storage backend; net/http drops the
+	// Authorization header when following redirects to other hosts.
+	if err := e.client().Download(assetURL, "application/octet-stream", dest); err != nil {
+		return fmt.Errorf("failed to download asset %s: %w", filename, err)
+	}
+	return nil
+}
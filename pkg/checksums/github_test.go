@@ -0,0 +1,131 @@
+package checksums
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestDownloadReleaseFileViaAPI(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/o/private/releases/tags/v1.0.0":
+			fmt.Fprintf(w, `{"assets":[{"name":"other.tar.gz","url":"%[1]s/assets/1"},{"name":"tool.tar.gz","url":"%[1]s/assets/2"}]}`, srv.URL)
+		case "/assets/2":
+			if r.Header.Get("Accept") != "application/octet-stream" {
+				http.Error(w, "bad accept", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, "content")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	e := &Embedder{
+		Spec:    &spec.InstallSpec{Repo: "o/private", GitHubAPIURL: srv.URL},
+		Version: "v1.0.0",
+	}
+	dest := filepath.Join(t.TempDir(), "tool.tar.gz")
+	if err := e.downloadReleaseFileViaAPI("tool.tar.gz", dest); err != nil {
+		t.Fatalf("downloadReleaseFileViaAPI() error = %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Errorf("downloaded content = %q, want %q", got, "content")
+	}
+
+	if err := e.downloadReleaseFileViaAPI("missing.tar.gz", dest); err == nil {
+		t.Error("downloadReleaseFileViaAPI() expected error for missing asset")
+	}
+}
+
+func TestLatestReleaseTags(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/mono/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprint(w, `[{"tag_name":"cli/v1.2.0"},{"tag_name":"lib/v3.0.0"},{"tag_name":"cli/v1.1.0","prerelease":true}]`)
+		case "2":
+			fmt.Fprint(w, `[{"tag_name":"cli/v1.0.0"},{"tag_name":"cli/v0.9.0","draft":true}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer srv.Close()
+
+	e := &Embedder{
+		Spec: &spec.InstallSpec{
+			Repo:         "o/mono",
+			GitHubAPIURL: srv.URL,
+			Version:      &spec.VersionConfig{TagPrefix: "cli/"},
+		},
+	}
+	got, err := e.LatestReleaseTags(3)
+	if err != nil {
+		t.Fatalf("LatestReleaseTags() error = %v", err)
+	}
+	if want := []string{"cli/v1.2.0", "cli/v1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LatestReleaseTags() = %v, want %v", got, want)
+	}
+}
+
+func TestCalculateChecksumsFromReleaseAssets(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/tool/releases/tags/v1.0.0":
+			fmt.Fprintf(w, `{"assets":[{"name":"tool_linux_amd64.tar.gz","url":"%[1]s/assets/1"},{"name":"tool_linux_amd64_v2.tar.gz","url":"%[1]s/assets/2"},{"name":"checksums.txt","url":"%[1]s/assets/3"},{"name":"tool_linux_amd64.tar.gz.sig","url":"%[1]s/assets/4"}]}`, srv.URL)
+		case "/assets/1":
+			fmt.Fprint(w, "hello\n")
+		case "/assets/2":
+			fmt.Fprint(w, "")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	e := &Embedder{
+		Spec: &spec.InstallSpec{
+			Name:               "tool",
+			Repo:               "o/tool",
+			GitHubAPIURL:       srv.URL,
+			Asset:              spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}${EXT}", DefaultExtension: ".tar.gz"},
+			Checksums:          &spec.ChecksumConfig{Algorithm: "sha256", Template: "checksums.txt"},
+			SupportedPlatforms: []spec.Platform{{OS: "linux", Arch: "amd64"}},
+		},
+		Version: "v1.0.0",
+	}
+	got, err := e.calculateChecksums()
+	if err != nil {
+		t.Fatalf("calculateChecksums() error = %v", err)
+	}
+	want := map[string]string{
+		"tool_linux_amd64.tar.gz":    "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+		"tool_linux_amd64_v2.tar.gz": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("calculateChecksums() = %v, want %v", got, want)
+	}
+}
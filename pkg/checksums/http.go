@@ -0,0 +1,22 @@
+package checksums
+
+import (
+	"net/url"
+
+	"github.com/haya14busa/goinstaller/internal/httpclient"
+)
+
+// client returns the HTTP client of e. The GitHub token is sent only to the
+// GitHub hosts of the spec so that it does not leak to custom download
+// servers.
+func (e *Embedder) client() *httpclient.Client {
+	hosts := []string{}
+	if e.Spec != nil {
+		for _, base := range []string{e.Spec.GitHubAPI(), e.Spec.GitHubBase()} {
+			if b, err := url.Parse(base); err == nil && b.Host != "" {
+				hosts = append(hosts, b.Host)
+			}
+		}
+	}
+	return &httpclient.Client{GitHubHosts: hosts, CacheDir: e.CacheDir}
+}
@@ -0,0 +1,153 @@
+package checksums
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+// maxImageSymlinks limits the symlinks followed to the file in an image.
+const maxImageSymlinks = 8
+
+// ImagePlatform returns the platform of container images for p, e.g.
+// linux/arm/v7 for linux/armv7, matching the installer script.
+func ImagePlatform(p spec.Platform) v1.Platform {
+	if variant, ok := strings.CutPrefix(p.Arch, "armv"); ok {
+		return v1.Platform{OS: p.OS, Architecture: "arm", Variant: "v" + variant}
+	}
+	return v1.Platform{OS: p.OS, Architecture: p.Arch}
+}
+
+// ExtractImageFile returns the content of the file at filePath in the image
+// ref for platform p. Symlinks within the image are followed. Credentials are
+// read from the Docker config.
+func ExtractImageFile(ctx context.Context, ref string, p spec.Platform, filePath string) ([]byte, error) {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %s: %w", ref, err)
+	}
+	img, err := remote.Image(parsed,
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithPlatform(ImagePlatform(p)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image %s for %s: %w", ref, p, err)
+	}
+
+	target := path.Clean("/" + filePath)
+	for range maxImageSymlinks {
+		data, link, err := readImageFile(img, target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from image %s: %w", filePath, ref, err)
+		}
+		if link == "" {
+			return data, nil
+		}
+		if !path.IsAbs(link) {
+			link = path.Join(path.Dir(target), link)
+		}
+		target = path.Clean(link)
+	}
+	return nil, fmt.Errorf("too many symlinks to %s in image %s", filePath, ref)
+}
+
+// readImageFile returns the content of the regular file at the absolute
+// path filePath of the flattened filesystem of img, or its target if it is a
+// symlink.
+func readImageFile(img v1.Image, filePath string) (data []byte, link string, err error) {
+	rc := mutate.Extract(img)
+	defer rc.Close()
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, "", fmt.Errorf("%s not found", filePath)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if path.Clean("/"+hdr.Name) != filePath {
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			return data, "", err
+		case tar.TypeSymlink:
+			return nil, hdr.Linkname, nil
+		default:
+			return nil, "", fmt.Errorf("%s is not a regular file", filePath)
+		}
+	}
+}
+
+// calculateImageChecksums extracts the binary from the container image of
+// every linux platform and calculates its checksum, keyed by the asset
+// filename the installer stores it as.
+func (e *Embedder) calculateImageChecksums() (map[string]string, error) {
+	tempDir, err := os.MkdirTemp("", "binstaller-checksums")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	ref := e.Spec.Image.Reference(e.Version, e.Spec.VersionFromTag(e.Version))
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		checksums = make(map[string]string)
+	)
+	for _, p := range e.Platforms() {
+		if p.OS != "linux" {
+			log.Infof("Skipping %s: container images are only used on linux", p)
+			continue
+		}
+		filename, err := e.generateAssetFilename(p.OS, p.Arch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate asset filename for %s: %w", p, err)
+		}
+		wg.Add(1)
+		go func(p spec.Platform, filename string) {
+			defer wg.Done()
+			log.Infof("Extracting %s from %s for %s", e.Spec.Image.Path, ref, p)
+			data, err := ExtractImageFile(context.Background(), ref, p, e.Spec.Image.Path)
+			if err != nil {
+				log.Warnf("Failed to extract the binary for %s: %v", p, err)
+				return
+			}
+			binaryPath := filepath.Join(tempDir, filename)
+			if err := os.WriteFile(binaryPath, data, 0644); err != nil {
+				log.Warnf("Failed to write the binary for %s: %v", p, err)
+				return
+			}
+			hash, err := ComputeHash(binaryPath, string(e.Spec.Checksums.Algorithm))
+			if err != nil {
+				log.Warnf("Error calculating checksum: failed to compute hash for %s: %v", filename, err)
+				return
+			}
+			mu.Lock()
+			checksums[filename] = hash
+			mu.Unlock()
+		}(p, filename)
+	}
+	wg.Wait()
+
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("failed to calculate any checksums")
+	}
+	return checksums, nil
+}
@@ -0,0 +1,104 @@
+package checksums
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+// tarLayer returns a layer of the tar entries, where an entry with a
+// Linkname is a symlink.
+func tarLayer(t *testing.T, entries []tar.Header, contents map[string]string) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range entries {
+		hdr.Mode = 0755
+		if hdr.Linkname != "" {
+			hdr.Typeflag = tar.TypeSymlink
+		} else {
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(len(contents[hdr.Name]))
+		}
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(contents[hdr.Name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return static.NewLayer(buf.Bytes(), types.DockerLayer)
+}
+
+func TestExtractImageFile(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+	ref := strings.TrimPrefix(srv.URL, "http://") + "/owner/tool:v1.0.0"
+
+	base := tarLayer(t, []tar.Header{{Name: "usr/local/bin/tool"}}, map[string]string{"usr/local/bin/tool": "old"})
+	top := tarLayer(t, []tar.Header{
+		{Name: "./usr/local/bin/tool"},
+		{Name: "usr/bin/tool", Linkname: "../local/bin/tool"},
+		{Name: "bin/tool", Linkname: "/usr/bin/tool"},
+		{Name: "bin/loop", Linkname: "loop"},
+	}, map[string]string{"./usr/local/bin/tool": "new"})
+	img, err := mutate.AppendLayers(empty.Image, base, top)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.ParseReference(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	linux := spec.Platform{OS: "linux", Arch: "amd64"}
+	for _, path := range []string{"/usr/local/bin/tool", "usr/local/bin/tool", "/usr/bin/tool", "/bin/tool"} {
+		got, err := ExtractImageFile(ctx, ref, linux, path)
+		if err != nil {
+			t.Fatalf("ExtractImageFile(%s) error = %v", path, err)
+		}
+		if string(got) != "new" {
+			t.Errorf("ExtractImageFile(%s) = %q, want the file of the top layer", path, got)
+		}
+	}
+	for _, path := range []string{"/usr/local/bin/missing", "/bin/loop", "/usr/local/bin"} {
+		if _, err := ExtractImageFile(ctx, ref, linux, path); err == nil {
+			t.Errorf("ExtractImageFile(%s) error = nil, want error", path)
+		}
+	}
+}
+
+func TestImagePlatform(t *testing.T) {
+	tests := []struct {
+		p    spec.Platform
+		want v1.Platform
+	}{
+		{spec.Platform{OS: "linux", Arch: "amd64"}, v1.Platform{OS: "linux", Architecture: "amd64"}},
+		{spec.Platform{OS: "linux", Arch: "armv7"}, v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}},
+	}
+	for _, tt := range tests {
+		if got := ImagePlatform(tt.p); got.String() != tt.want.String() {
+			t.Errorf("ImagePlatform(%s) = %s, want %s", tt.p, got, tt.want)
+		}
+	}
+}
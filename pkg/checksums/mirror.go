@@ -0,0 +1,220 @@
+package checksums
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+// MirrorManifestName is the name of the manifest Mirror writes next to the
+// mirrored release files.
+const MirrorManifestName = "binstaller-mirror.json"
+
+// MirrorManifest describes a release mirrored by Mirror.
+type MirrorManifest struct {
+	Name  string       `json:"name"`
+	Repo  string       `json:"repo"`
+	Tag   string       `json:"tag"`
+	Files []MirrorFile `json:"files"`
+}
+
+// MirrorFile is a file of a mirrored release.
+type MirrorFile struct {
+	Filename  string             `json:"filename"`
+	Size      int64              `json:"size"`
+	Hash      string             `json:"hash"`
+	Algorithm spec.HashAlgorithm `json:"algorithm"`
+	// Verified reports whether Hash was checked against the embedded
+	// checksums or the checksums of the release. Checksum and signature
+	// files are mirrored unverified.
+	Verified bool `json:"verified"`
+}
+
+// Checksums returns the verified files as embedded checksums.
+func (m *MirrorManifest) Checksums() []spec.EmbeddedChecksum {
+	var checksums []spec.EmbeddedChecksum
+	for _, f := range m.Files {
+		if f.Verified {
+			checksums = append(checksums, spec.EmbeddedChecksum{Filename: f.Filename, Hash: f.Hash, Algorithm: f.Algorithm})
+		}
+	}
+	return checksums
+}
+
+// MirrorPath returns the path of the release files of tag in a mirror, laid
+// out like GitHub release download URLs so that the mirror can be used with
+// the --base-url option of installer scripts.
+func MirrorPath(repo, tag string) string {
+	return repo + "/releases/download/" + tag
+}
+
+// Mirror downloads the assets of the release e.Version to MirrorPath under
+// root, verifies them and writes a MirrorManifestName manifest next to them.
+// The assets are verified against the checksums embedded for the version, or
+// those acquired with e.Mode if none are embedded. The checksum files of the release and their signatures
+// are mirrored too, so that scripts without embedded checksums can verify
+// the mirrored assets.
+func (e *Embedder) Mirror(root string) (*MirrorManifest, error) {
+	if e.Spec == nil {
+		return nil, fmt.Errorf("InstallSpec cannot be nil")
+	}
+	if e.Mode == EmbedModeCalculate {
+		return nil, fmt.Errorf("calculate mode cannot verify the mirrored assets; embed their checksums or use another mode")
+	}
+	if e.Spec.Checksums == nil {
+		e.Spec.Checksums = &spec.ChecksumConfig{Algorithm: spec.SHA256}
+	}
+	version, err := e.resolveVersion(e.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve version: %w", err)
+	}
+	e.Version = version
+
+	expected, err := e.expectedChecksums()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(root, filepath.FromSlash(MirrorPath(e.Spec.Repo, e.Version)))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mirror directory: %w", err)
+	}
+
+	manifest := &MirrorManifest{Name: e.Spec.Name, Repo: e.Spec.Repo, Tag: e.Version}
+	for _, ec := range expected {
+		path := filepath.Join(dir, ec.Filename)
+		e.assetLog(ec.Filename).Infof("Downloading %s", e.releaseURL(ec.Filename))
+		if err := e.downloadReleaseFile(ec.Filename, path); err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", ec.Filename, err)
+		}
+		algorithm := e.Spec.Checksums.EntryAlgorithm(ec)
+		hash, err := ComputeHash(path, string(algorithm))
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(hash, ec.Hash) {
+			return nil, binsterr.Wrap(binsterr.ErrVerificationFailed, fmt.Errorf("checksum mismatch for %s@%s: expected %s, got %s", ec.Filename, e.Version, ec.Hash, hash))
+		}
+		if err := e.runVerifyPlugins(ec.Filename, path); err != nil {
+			return nil, err
+		}
+		f, err := mirrorFile(path, hash, algorithm)
+		if err != nil {
+			return nil, err
+		}
+		f.Verified = true
+		manifest.Files = append(manifest.Files, f)
+	}
+
+	if err := e.mirrorChecksumFiles(dir); err != nil {
+		return nil, err
+	}
+	// Checksum files and the signature files downloaded to verify them
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == MirrorManifestName || slices.ContainsFunc(manifest.Files, func(f MirrorFile) bool { return f.Filename == name }) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		hash, err := ComputeHash(path, string(spec.SHA256))
+		if err != nil {
+			return nil, err
+		}
+		f, err := mirrorFile(path, hash, spec.SHA256)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, f)
+	}
+	slices.SortFunc(manifest.Files, func(a, b MirrorFile) int {
+		return strings.Compare(a.Filename, b.Filename)
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, MirrorManifestName), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write mirror manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// expectedChecksums returns the checksums to verify the mirrored assets
+// with, sorted by filename.
+func (e *Embedder) expectedChecksums() ([]spec.EmbeddedChecksum, error) {
+	var expected []spec.EmbeddedChecksum
+	if embedded, ok := e.Spec.Checksums.EmbeddedChecksums[e.Version]; ok {
+		log.Infof("Verifying assets with the checksums embedded for %s", e.Version)
+		expected = slices.Clone(embedded)
+	} else {
+		log.Infof("Acquiring checksums using %s mode for version: %s", e.Mode, e.Version)
+		checksums, err := e.acquireChecksums()
+		if err != nil {
+			return nil, err
+		}
+		for filename, hash := range checksums {
+			expected = append(expected, spec.EmbeddedChecksum{Filename: filename, Hash: hash})
+		}
+	}
+	expected = slices.DeleteFunc(expected, func(ec spec.EmbeddedChecksum) bool {
+		if !e.Spec.Asset.AllowAsset(ec.Filename) {
+			e.assetLog(ec.Filename).Debugf("Skipping ignored asset: %s", ec.Filename)
+			return true
+		}
+		return false
+	})
+	if len(expected) == 0 {
+		return nil, fmt.Errorf("no assets to mirror for version %s", e.Version)
+	}
+	slices.SortFunc(expected, func(a, b spec.EmbeddedChecksum) int {
+		return strings.Compare(a.Filename, b.Filename)
+	})
+	return expected, nil
+}
+
+// mirrorChecksumFiles downloads the checksum files of the release to dir and
+// verifies their signatures, which downloads the signature files to dir as
+// well. Checksum files hosted outside the release are not mirrored.
+func (e *Embedder) mirrorChecksumFiles(dir string) error {
+	sources, err := e.checksumSources()
+	if err != nil {
+		return err
+	}
+	for _, src := range sources {
+		if spec.IsURLTemplate(src.Filename) {
+			log.Warnf("Checksum file %s is not hosted in the release and is not mirrored", src.Filename)
+			continue
+		}
+		path := filepath.Join(dir, src.Filename)
+		if _, err := os.Stat(path); err == nil {
+			continue // Already mirrored as an asset
+		}
+		log.Infof("Downloading %s", e.releaseURL(src.Filename))
+		if err := e.downloadReleaseFile(src.Filename, path); err != nil {
+			return fmt.Errorf("failed to download checksum file %s: %w", src.Filename, err)
+		}
+		if err := e.verifyChecksumSignature(src.Filename, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mirrorFile(path, hash string, algorithm spec.HashAlgorithm) (MirrorFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return MirrorFile{}, err
+	}
+	return MirrorFile{Filename: filepath.Base(path), Size: info.Size(), Hash: hash, Algorithm: algorithm}, nil
+}
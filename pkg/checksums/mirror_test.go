@@ -0,0 +1,109 @@
+package checksums
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestMirror(t *testing.T) {
+	files := map[string]string{
+		"/o/tool/releases/download/v1.0.0/tool_linux_amd64.tar.gz":  "linux",
+		"/o/tool/releases/download/v1.0.0/tool_darwin_arm64.tar.gz": "darwin",
+		"/o/tool/releases/download/v1.0.0/tool.sbom.json":           "sbom",
+	}
+	sum := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	files["/o/tool/releases/download/v1.0.0/checksums.txt"] = fmt.Sprintf("%s  tool_linux_amd64.tar.gz\n%s  tool_darwin_arm64.tar.gz\n%s  tool.sbom.json\n",
+		sum("linux"), sum("darwin"), sum("sbom"))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer srv.Close()
+
+	newEmbedder := func() *Embedder {
+		return &Embedder{
+			Mode:    EmbedModeDownload,
+			Version: "v1.0.0",
+			Spec: &spec.InstallSpec{
+				Name:          "tool",
+				Repo:          "o/tool",
+				GitHubBaseURL: srv.URL,
+				Asset:         spec.AssetConfig{IgnoreAssets: []string{"*.json"}},
+				Checksums:     &spec.ChecksumConfig{Template: "checksums.txt"},
+			},
+		}
+	}
+
+	root := t.TempDir()
+	got, err := newEmbedder().Mirror(root)
+	if err != nil {
+		t.Fatalf("Mirror() error = %v", err)
+	}
+	want := &MirrorManifest{
+		Name: "tool",
+		Repo: "o/tool",
+		Tag:  "v1.0.0",
+		Files: []MirrorFile{
+			{Filename: "checksums.txt", Size: int64(len(files["/o/tool/releases/download/v1.0.0/checksums.txt"])), Hash: sum(files["/o/tool/releases/download/v1.0.0/checksums.txt"]), Algorithm: spec.SHA256},
+			{Filename: "tool_darwin_arm64.tar.gz", Size: 6, Hash: sum("darwin"), Algorithm: spec.SHA256, Verified: true},
+			{Filename: "tool_linux_amd64.tar.gz", Size: 5, Hash: sum("linux"), Algorithm: spec.SHA256, Verified: true},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Mirror() = %+v, want %+v", got, want)
+	}
+
+	dir := filepath.Join(root, "o", "tool", "releases", "download", "v1.0.0")
+	data, err := os.ReadFile(filepath.Join(dir, MirrorManifestName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest MirrorManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&manifest, want) {
+		t.Errorf("manifest = %+v, want %+v", manifest, want)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "tool_linux_amd64.tar.gz")); err != nil || string(content) != "linux" {
+		t.Errorf("mirrored asset = %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tool.sbom.json")); err == nil {
+		t.Error("Mirror() mirrored an ignored asset")
+	}
+	if len(got.Checksums()) != 2 {
+		t.Errorf("Checksums() = %+v, want the 2 verified assets", got.Checksums())
+	}
+
+	// Embedded checksums take precedence over the release checksums
+	e := newEmbedder()
+	e.Spec.Checksums.EmbeddedChecksums = map[string][]spec.EmbeddedChecksum{
+		"v1.0.0": {{Filename: "tool_linux_amd64.tar.gz", Hash: sum("tampered")}},
+	}
+	if _, err := e.Mirror(t.TempDir()); err == nil {
+		t.Error("Mirror() expected error for checksum mismatch")
+	}
+
+	e = newEmbedder()
+	e.Mode = EmbedModeCalculate
+	if _, err := e.Mirror(t.TempDir()); err == nil {
+		t.Error("Mirror() expected error for calculate mode")
+	}
+}
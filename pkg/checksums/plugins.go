@@ -0,0 +1,48 @@
+package checksums
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
+	"github.com/haya14busa/goinstaller/pkg/verify"
+)
+
+// runVerifyPlugins runs the verify plugins of the spec against the asset
+// filename downloaded to path, as the installer script does after verifying
+// its checksum.
+func (e *Embedder) runVerifyPlugins(filename, path string) error {
+	if e.Spec.Verify == nil || len(e.Spec.Verify.Plugins) == 0 {
+		return nil
+	}
+	info := verify.AssetInfo{
+		Name:          e.Spec.Name,
+		Repo:          e.Spec.Repo,
+		Version:       e.Spec.VersionFromTag(e.Version),
+		Tag:           e.Version,
+		AssetFilename: filename,
+		AssetPath:     path,
+	}
+	if target, ok := e.assetTarget(filename); ok {
+		info.OS, info.Arch = strings.ToLower(target.OS), strings.ToLower(target.Arch)
+	}
+	if err := verify.RunPlugins(context.Background(), e.Spec.Verify.Plugins, info); err != nil {
+		return binsterr.Wrap(binsterr.ErrVerificationFailed, err)
+	}
+	return nil
+}
+
+// assetTarget returns the target whose asset candidates include filename.
+func (e *Embedder) assetTarget(filename string) (assetTarget, bool) {
+	for _, target := range e.assetTargets(e.Platforms()) {
+		candidates, err := e.assetCandidates(target)
+		if err != nil {
+			continue
+		}
+		if slices.Contains(candidates, filename) {
+			return target, true
+		}
+	}
+	return assetTarget{}, false
+}
@@ -0,0 +1,97 @@
+package checksums
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestVerifyPlugins(t *testing.T) {
+	files := map[string]string{
+		"/o/tool/releases/download/v1.0.0/tool_linux_amd64.tar.gz": "linux",
+	}
+	h := sha256.Sum256([]byte("linux"))
+	files["/o/tool/releases/download/v1.0.0/checksums.txt"] = hex.EncodeToString(h[:]) + "  tool_linux_amd64.tar.gz\n"
+	listRelease := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/repos/o/tool/releases/tags/v1.0.0" && listRelease {
+			fmt.Fprint(w, `{"assets":[{"name":"tool_linux_amd64.tar.gz"},{"name":"checksums.txt"}]}`)
+			return
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer srv.Close()
+
+	newEmbedder := func(mode EmbedMode, command string) *Embedder {
+		return &Embedder{
+			Mode:    mode,
+			Version: "v1.0.0",
+			Spec: &spec.InstallSpec{
+				Name:               "tool",
+				Repo:               "o/tool",
+				GitHubBaseURL:      srv.URL,
+				Asset:              spec.AssetConfig{Template: "${NAME}_${OS}_${ARCH}.tar.gz"},
+				Checksums:          &spec.ChecksumConfig{Template: "checksums.txt", Algorithm: spec.SHA256},
+				SupportedPlatforms: []spec.Platform{{OS: "linux", Arch: "amd64"}},
+				Verify:             &spec.VerifyConfig{Plugins: []spec.VerifyPlugin{{Name: "scan", Command: command}}},
+			},
+		}
+	}
+	runs := map[string]func(e *Embedder) error{
+		"mirror": func(e *Embedder) error {
+			_, err := e.Mirror(t.TempDir())
+			return err
+		},
+		"calculate from templates": func(e *Embedder) error {
+			listRelease = false
+			_, err := e.calculateChecksums()
+			return err
+		},
+		"calculate from release assets": func(e *Embedder) error {
+			listRelease = true
+			_, err := e.calculateChecksums()
+			return err
+		},
+	}
+	for name, run := range runs {
+		t.Run(name, func(t *testing.T) {
+			mode := EmbedModeCalculate
+			if name == "mirror" {
+				mode = EmbedModeDownload
+			}
+
+			out := filepath.Join(t.TempDir(), "out")
+			record := `sh -c 'echo "$BINSTALLER_OS/$BINSTALLER_ARCH $BINSTALLER_TAG $(basename "$1")" > ` + out + `' scan`
+			if err := run(newEmbedder(mode, record)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("plugin did not run: %v", err)
+			}
+			if want := "linux/amd64 v1.0.0 tool_linux_amd64.tar.gz"; strings.TrimSpace(string(got)) != want {
+				t.Errorf("plugin got %q, want %q", got, want)
+			}
+
+			err = run(newEmbedder(mode, "false"))
+			if !errors.Is(err, binsterr.ErrVerificationFailed) {
+				t.Errorf("failing plugin: got error %v, want a verification failure", err)
+			}
+		})
+	}
+}
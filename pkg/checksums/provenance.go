@@ -0,0 +1,144 @@
+package checksums
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// provenanceSuffixes are suffixes of release assets holding SLSA provenance
+// or Sigstore attestation bundles.
+var provenanceSuffixes = []string{".intoto.jsonl", ".sigstore.json", ".sigstore"}
+
+// dsseEnvelope is a DSSE envelope wrapping an in-toto statement.
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+}
+
+// provenanceDocument is either a DSSE envelope, as in SLSA provenance files,
+// or a Sigstore bundle containing one, as produced by GitHub attestations.
+type provenanceDocument struct {
+	dsseEnvelope
+	DSSEEnvelope *dsseEnvelope `json:"dsseEnvelope"`
+}
+
+// inTotoStatement is the part of an in-toto statement listing the artifacts
+// it is about.
+type inTotoStatement struct {
+	Subject []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+}
+
+// provenanceChecksums reads the subject digests of the release's provenance
+// files. The local file given by ChecksumFile is used if set; otherwise every
+// provenance or attestation bundle asset of the release is downloaded. The
+// assets themselves are never downloaded.
+func (e *Embedder) provenanceChecksums() (map[string]string, error) {
+	if e.ChecksumFile != "" {
+		log.Infof("Reading provenance subjects from file: %s", e.ChecksumFile)
+		data, err := os.ReadFile(e.ChecksumFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provenance file: %w", err)
+		}
+		return parseProvenanceSubjects(data, string(e.Spec.Checksums.Algorithm))
+	}
+
+	release, err := e.releaseAssets()
+	if err != nil {
+		return nil, err
+	}
+	tempDir, err := os.MkdirTemp("", "binstaller-provenance")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	checksums := make(map[string]string)
+	for _, a := range release.Assets {
+		if !hasProvenanceSuffix(a.Name) {
+			continue
+		}
+		dest := filepath.Join(tempDir, a.Name)
+		log.Infof("Downloading provenance %s", e.releaseURL(a.Name))
+		if err := e.downloadReleaseFile(a.Name, dest); err != nil {
+			return nil, fmt.Errorf("failed to download provenance %s: %w", a.Name, err)
+		}
+		data, err := os.ReadFile(dest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provenance %s: %w", a.Name, err)
+		}
+		subjects, err := parseProvenanceSubjects(data, string(e.Spec.Checksums.Algorithm))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse provenance %s: %w", a.Name, err)
+		}
+		for filename, hash := range subjects {
+			checksums[filename] = hash
+		}
+	}
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("no provenance subjects found in release %s", e.Version)
+	}
+	return checksums, nil
+}
+
+func hasProvenanceSuffix(filename string) bool {
+	for _, suffix := range provenanceSuffixes {
+		if strings.HasSuffix(filename, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProvenanceSubjects returns the subject digests for algorithm of the
+// in-toto statements in data, keyed by subject filename. data holds one or
+// more JSON documents, e.g. a JSON Lines provenance file or a bundle.
+func parseProvenanceSubjects(data []byte, algorithm string) (map[string]string, error) {
+	// in-toto digest sets name SHA-3 digests with an underscore
+	digestKey := strings.ReplaceAll(algorithm, "-", "_")
+	checksums := make(map[string]string)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc provenanceDocument
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid provenance document: %w", err)
+		}
+		env := &doc.dsseEnvelope
+		if doc.DSSEEnvelope != nil {
+			env = doc.DSSEEnvelope
+		}
+		if env.Payload == "" {
+			continue
+		}
+		payload, err := base64.StdEncoding.DecodeString(env.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid provenance payload: %w", err)
+		}
+		var statement inTotoStatement
+		if err := json.Unmarshal(payload, &statement); err != nil {
+			return nil, fmt.Errorf("invalid in-toto statement: %w", err)
+		}
+		for _, s := range statement.Subject {
+			hash, ok := s.Digest[digestKey]
+			if !ok {
+				log.Debugf("Skipping provenance subject %s without %s digest", s.Name, algorithm)
+				continue
+			}
+			checksums[path.Base(s.Name)] = strings.ToLower(hash)
+		}
+	}
+	return checksums, nil
+}
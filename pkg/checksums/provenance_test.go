@@ -0,0 +1,49 @@
+package checksums
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseProvenanceSubjects(t *testing.T) {
+	statement := `{"_type":"https://in-toto.io/Statement/v1","subject":[` +
+		`{"name":"tool_linux_amd64.tar.gz","digest":{"sha256":"AAAA","sha512":"bbbb"}},` +
+		`{"name":"dist/tool_darwin_arm64.tar.gz","digest":{"sha256":"cccc"}},` +
+		`{"name":"tool.sbom","digest":{"sha512":"dddd"}}]}`
+	payload := base64.StdEncoding.EncodeToString([]byte(statement))
+	want := map[string]string{
+		"tool_linux_amd64.tar.gz":  "aaaa",
+		"tool_darwin_arm64.tar.gz": "cccc",
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "slsa provenance jsonl",
+			data: fmt.Sprintf("{\"payloadType\":\"application/vnd.in-toto+json\",\"payload\":%q,\"signatures\":[]}\n", payload),
+		},
+		{
+			name: "sigstore bundle",
+			data: fmt.Sprintf("{\n  \"mediaType\": \"application/vnd.dev.sigstore.bundle.v0.3+json\",\n  \"dsseEnvelope\": {\"payloadType\": \"application/vnd.in-toto+json\", \"payload\": %q}\n}\n", payload),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProvenanceSubjects([]byte(tt.data), "sha256")
+			if err != nil {
+				t.Fatalf("parseProvenanceSubjects() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("parseProvenanceSubjects() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	if _, err := parseProvenanceSubjects([]byte(`{"payload":"!"}`), "sha256"); err == nil {
+		t.Error("parseProvenanceSubjects() expected error for invalid payload")
+	}
+}
@@ -0,0 +1,115 @@
+package checksums
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
+)
+
+// verifyChecksumSignature verifies the signature of the checksum file
+// checksumFilename downloaded to path, following the signature section of the
+// spec as the installer script does. It is a no-op unless signature
+// verification is enabled for the checksum file.
+func (e *Embedder) verifyChecksumSignature(checksumFilename, path string) error {
+	sig := e.Spec.Signature
+	if sig == nil || sig.Enabled == nil || !*sig.Enabled {
+		return nil
+	}
+	target := sig.Target
+	if target == "" && e.Spec.Checksums != nil && e.Spec.Checksums.Template != "" {
+		target = "checksum"
+	}
+	if target != "checksum" {
+		return nil
+	}
+
+	typ := sig.Type
+	if typ == "" {
+		typ = "cosign"
+	}
+	command := typ
+	if _, err := exec.LookPath(command); err != nil && typ == "signify" {
+		command = "signify-openbsd"
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		if sig.Require != nil && *sig.Require {
+			return fmt.Errorf("%s is required to verify the signature of %s but is not installed", typ, checksumFilename)
+		}
+		log.Warnf("%s not found, skipping signature verification of %s", typ, checksumFilename)
+		return nil
+	}
+
+	expand := strings.NewReplacer(
+		"${TARGET_FILENAME}", checksumFilename,
+		"${NAME}", e.Spec.Name,
+		"${REPO}", e.Spec.Repo,
+		"${TAG}", e.Version,
+		"${VERSION}", e.Spec.VersionFromTag(e.Version),
+	).Replace
+	sigTemplate := sig.SignatureTemplate
+	if sigTemplate == "" {
+		sigTemplate = "${TARGET_FILENAME}.sig"
+		if typ == "minisign" {
+			sigTemplate = "${TARGET_FILENAME}.minisig"
+		}
+	}
+	dir := filepath.Dir(path)
+	sigFilename := expand(sigTemplate)
+	sigPath := filepath.Join(dir, sigFilename)
+	log.Infof("Downloading signature %s", sigFilename)
+	if err := e.downloadReleaseFile(sigFilename, sigPath); err != nil {
+		return fmt.Errorf("failed to download signature %s: %w", sigFilename, err)
+	}
+
+	var args []string
+	switch typ {
+	case "minisign":
+		args = []string{"-V", "-q", "-P", sig.Key, "-x", sigPath, "-m", path}
+	case "signify":
+		pubPath := filepath.Join(dir, "signify.pub")
+		pub := fmt.Sprintf("untrusted comment: %s public key\n%s\n", e.Spec.Name, sig.Key)
+		if err := os.WriteFile(pubPath, []byte(pub), 0644); err != nil {
+			return fmt.Errorf("failed to write signify public key: %w", err)
+		}
+		args = []string{"-V", "-q", "-p", pubPath, "-x", sigPath, "-m", path}
+	default:
+		args = []string{"verify-blob"}
+		if sig.Key != "" {
+			args = append(args, "--key", sig.Key)
+		} else {
+			certTemplate := sig.CertificateTemplate
+			if certTemplate == "" {
+				certTemplate = "${TARGET_FILENAME}.pem"
+			}
+			certFilename := expand(certTemplate)
+			certPath := filepath.Join(dir, certFilename)
+			log.Infof("Downloading certificate %s", certFilename)
+			if err := e.downloadReleaseFile(certFilename, certPath); err != nil {
+				return fmt.Errorf("failed to download certificate %s: %w", certFilename, err)
+			}
+			args = append(args, "--certificate", certPath)
+			if sig.CertificateIdentityRegexp != "" {
+				args = append(args, "--certificate-identity-regexp", sig.CertificateIdentityRegexp)
+			} else {
+				args = append(args, "--certificate-identity", sig.CertificateIdentity)
+			}
+			args = append(args, "--certificate-oidc-issuer", sig.CertificateOIDCIssuer)
+		}
+		args = append(args, "--signature", sigPath, path)
+	}
+
+	log.Infof("Verifying signature of %s with %s", checksumFilename, typ)
+	cmd := exec.Command(command, args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return binsterr.Wrap(binsterr.ErrVerificationFailed, fmt.Errorf("signature verification failed for %s: %w", checksumFilename, err))
+	}
+	log.Infof("Signature verification successful for %s", checksumFilename)
+	return nil
+}
@@ -0,0 +1,81 @@
+package checksums
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestVerifyChecksumSignature(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as fake minisign")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/checksums.txt.minisig" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "signature")
+	}))
+	defer srv.Close()
+
+	// The fake minisign accepts only the "good" public key.
+	binDir := t.TempDir()
+	fake := "#!/bin/sh\n[ \"$4\" = good ]\n"
+	if err := os.WriteFile(filepath.Join(binDir, "minisign"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir)
+
+	checksumPath := filepath.Join(t.TempDir(), "checksums.txt")
+	if err := os.WriteFile(checksumPath, []byte("abc  tool.tar.gz\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	enabled := true
+	for _, tt := range []struct {
+		key     string
+		wantErr bool
+	}{
+		{"good", false},
+		{"bad", true},
+	} {
+		e := &Embedder{
+			Spec: &spec.InstallSpec{
+				Repo:      "o/r",
+				Asset:     spec.AssetConfig{DownloadURLTemplate: srv.URL + "/${ASSET_FILENAME}"},
+				Checksums: &spec.ChecksumConfig{Template: "checksums.txt"},
+				Signature: &spec.SignatureConfig{Enabled: &enabled, Type: "minisign", Key: tt.key},
+			},
+			Version: "v1.0.0",
+		}
+		err := e.verifyChecksumSignature("checksums.txt", checksumPath)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("verifyChecksumSignature() with key %s error = %v, wantErr %v", tt.key, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVerifyChecksumSignatureMissingVerifier(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	enabled, require := true, true
+	e := &Embedder{
+		Spec: &spec.InstallSpec{
+			Checksums: &spec.ChecksumConfig{Template: "checksums.txt"},
+			Signature: &spec.SignatureConfig{Enabled: &enabled, Type: "minisign", Key: "k"},
+		},
+	}
+	if err := e.verifyChecksumSignature("checksums.txt", "checksums.txt"); err != nil {
+		t.Errorf("verifyChecksumSignature() error = %v, want skipped", err)
+	}
+	e.Spec.Signature.Require = &require
+	if err := e.verifyChecksumSignature("checksums.txt", "checksums.txt"); err == nil {
+		t.Error("verifyChecksumSignature() expected error when the required verifier is missing")
+	}
+}
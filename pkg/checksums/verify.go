@@ -0,0 +1,91 @@
+package checksums
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+// Drift describes a difference between an embedded checksum and the release.
+type Drift struct {
+	Version  string
+	Filename string
+	// Embedded is the embedded hash, empty if the asset is not embedded.
+	Embedded string
+	// Actual is the hash of the release, empty if the asset is missing from
+	// the release.
+	Actual string
+}
+
+// Mismatch reports whether the embedded checksum disagrees with the release,
+// either because the hashes differ or because the asset is gone. Assets
+// that are merely not embedded yet are not mismatches.
+func (d Drift) Mismatch() bool {
+	return d.Embedded != ""
+}
+
+func (d Drift) String() string {
+	switch {
+	case d.Embedded == "":
+		return fmt.Sprintf("%s@%s: not embedded (release: %s)", d.Filename, d.Version, d.Actual)
+	case d.Actual == "":
+		return fmt.Sprintf("%s@%s: missing from release (embedded: %s)", d.Filename, d.Version, d.Embedded)
+	default:
+		return fmt.Sprintf("%s@%s: hash mismatch (embedded: %s, release: %s)", d.Filename, d.Version, d.Embedded, d.Actual)
+	}
+}
+
+// Verify acquires the checksums of e.Version with e.Mode again and compares
+// them with the embedded ones. The spec is not modified.
+func (e *Embedder) Verify() ([]Drift, error) {
+	if e.Spec == nil {
+		return nil, fmt.Errorf("InstallSpec cannot be nil")
+	}
+	if e.Spec.Checksums == nil || len(e.Spec.Checksums.EmbeddedChecksums) == 0 {
+		return nil, fmt.Errorf("spec has no embedded checksums to verify")
+	}
+	version, err := e.resolveVersion(e.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve version: %w", err)
+	}
+	e.Version = version
+	embedded, ok := e.Spec.Checksums.EmbeddedChecksums[e.Version]
+	if !ok {
+		return nil, fmt.Errorf("no checksums embedded for version %s", e.Version)
+	}
+
+	log.Infof("Verifying embedded checksums using %s mode for version: %s", e.Mode, e.Version)
+	actual, err := e.acquireChecksums()
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+	seen := make(map[string]bool)
+	algorithm := e.Spec.Checksums.EntryAlgorithm(spec.EmbeddedChecksum{})
+	for _, ec := range embedded {
+		seen[ec.Filename] = true
+		if a := e.Spec.Checksums.EntryAlgorithm(ec); a != algorithm {
+			e.assetLog(ec.Filename).Warnf("Skipping %s@%s: embedded %s checksum cannot be verified with %s", ec.Filename, e.Version, a, algorithm)
+			continue
+		}
+		hash, ok := actual[ec.Filename]
+		if ok && strings.EqualFold(hash, ec.Hash) {
+			continue
+		}
+		drifts = append(drifts, Drift{Version: e.Version, Filename: ec.Filename, Embedded: ec.Hash, Actual: hash})
+	}
+	for filename, hash := range actual {
+		if seen[filename] || !e.Spec.Asset.AllowAsset(filename) {
+			continue
+		}
+		drifts = append(drifts, Drift{Version: e.Version, Filename: filename, Actual: hash})
+	}
+	slices.SortFunc(drifts, func(a, b Drift) int {
+		return strings.Compare(a.Filename, b.Filename)
+	})
+	return drifts, nil
+}
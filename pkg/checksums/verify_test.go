@@ -0,0 +1,57 @@
+package checksums
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestVerify(t *testing.T) {
+	checksumFile := filepath.Join(t.TempDir(), "checksums.txt")
+	content := "aaaa  tool_linux_amd64.tar.gz\nffff  tool_darwin_arm64.tar.gz\ncccc  tool_windows_amd64.zip\n"
+	if err := os.WriteFile(checksumFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	installSpec := &spec.InstallSpec{
+		Name: "tool",
+		Repo: "o/tool",
+		Checksums: &spec.ChecksumConfig{
+			EmbeddedChecksums: map[string][]spec.EmbeddedChecksum{
+				"v1.0.0": {
+					{Filename: "tool_darwin_arm64.tar.gz", Hash: "bbbb"},
+					{Filename: "tool_freebsd_amd64.tar.gz", Hash: "dddd"},
+					{Filename: "tool_linux_amd64.tar.gz", Hash: "AAAA"},
+				},
+			},
+		},
+	}
+	e := &Embedder{
+		Mode:         EmbedModeChecksumFile,
+		Version:      "v1.0.0",
+		Spec:         installSpec,
+		ChecksumFile: checksumFile,
+	}
+	got, err := e.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	want := []Drift{
+		{Version: "v1.0.0", Filename: "tool_darwin_arm64.tar.gz", Embedded: "bbbb", Actual: "ffff"},
+		{Version: "v1.0.0", Filename: "tool_freebsd_amd64.tar.gz", Embedded: "dddd"},
+		{Version: "v1.0.0", Filename: "tool_windows_amd64.zip", Actual: "cccc"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Verify() = %+v, want %+v", got, want)
+	}
+	if len(installSpec.Checksums.EmbeddedChecksums["v1.0.0"]) != 3 {
+		t.Error("Verify() modified the embedded checksums")
+	}
+
+	e.Version = "v2.0.0"
+	if _, err := e.Verify(); err == nil {
+		t.Error("Verify() expected error for version without embedded checksums")
+	}
+}
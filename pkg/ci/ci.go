@@ -0,0 +1,238 @@
+// Package ci renders CI configuration installing a pinned release asset from
+// an InstallSpec and its embedded checksums: a POSIX shell snippet for any CI
+// system and a GitLab CI job template.
+package ci
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+var (
+	//go:embed snippet.sh.tmpl
+	snippetTemplate string
+	//go:embed gitlab-ci.yml.tmpl
+	gitlabTemplate string
+)
+
+// header marks exported files as generated.
+const header = "# Code generated by binst export. DO NOT EDIT.\n"
+
+// unameOS and unameArch map platforms of the spec to the output of uname -s
+// and uname -m on CI runners. Other platforms are skipped.
+var (
+	unameOS   = map[string]string{"linux": "Linux", "darwin": "Darwin", "freebsd": "FreeBSD"}
+	unameArch = map[string][]string{
+		"amd64": {"x86_64", "amd64"}, "arm64": {"aarch64", "arm64"}, "386": {"i386", "i686"},
+		"armv6": {"armv6l"}, "armv7": {"armv7l"}, "ppc64le": {"ppc64le"}, "s390x": {"s390x"}, "riscv64": {"riscv64"},
+	}
+)
+
+// sumCommands are the checksum commands by algorithm, with the shasum -a
+// fallback of macOS runners.
+var sumCommands = map[spec.HashAlgorithm]struct {
+	command string
+	bits    string
+}{
+	spec.SHA256: {"sha256sum", "256"},
+	spec.SHA512: {"sha512sum", "512"},
+}
+
+type snippetData struct {
+	Name       string
+	Version    string
+	SumCommand string
+	ShasumBits string
+	Sources    []source
+	Commands   []string
+}
+
+type source struct {
+	// Platform is the case pattern matching "$(uname -s)/$(uname -m)".
+	Platform string
+	URL      string
+	Hash     string
+}
+
+var jobNameInvalid = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// Snippet writes a POSIX shell snippet installing the Linux and macOS assets
+// of version for the runner platform to $BINDIR (default: ~/.local/bin) and
+// adding it to PATH. Linux platforms use their glibc asset. Every platform
+// must install the same binaries from the same kind of asset.
+func Snippet(w io.Writer, installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset) error {
+	snippet, err := renderSnippet(installSpec, version, assets)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, header+snippet)
+	return err
+}
+
+// GitLab writes a .gitlab-ci.yml to include, with an .install-<name> job
+// whose before_script runs the Snippet.
+func GitLab(w io.Writer, installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset) error {
+	snippet, err := renderSnippet(installSpec, version, assets)
+	if err != nil {
+		return err
+	}
+	return render(w, "gitlab-ci.yml", gitlabTemplate, map[string]string{
+		"Name":    installSpec.Name,
+		"Version": version,
+		"Job":     jobNameInvalid.ReplaceAllString(strings.ToLower(installSpec.Name), "-"),
+		"Snippet": strings.TrimSuffix(snippet, "\n"),
+	})
+}
+
+func renderSnippet(installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset) (string, error) {
+	data := snippetData{Name: installSpec.Name, Version: version}
+	var supported []checksums.PlatformAsset
+	for _, a := range assets {
+		uos, arches := unameOS[strings.ToLower(a.OS)], unameArch[strings.ToLower(a.Arch)]
+		if uos == "" || len(arches) == 0 {
+			continue
+		}
+		sum, ok := sumCommands[a.Algorithm]
+		if !ok {
+			return "", fmt.Errorf("ci export requires sha256 or sha512 checksums, but %s has a %s checksum", a.Filename, a.Algorithm)
+		}
+		if data.SumCommand != "" && data.SumCommand != sum.command {
+			return "", fmt.Errorf("ci export requires the same checksum algorithm for every platform")
+		}
+		data.SumCommand, data.ShasumBits = sum.command, sum.bits
+		patterns := make([]string, len(arches))
+		for i, arch := range arches {
+			patterns[i] = uos + "/" + arch
+		}
+		data.Sources = append(data.Sources, source{
+			Platform: strings.Join(patterns, " | "),
+			URL:      shellQuote(a.URL),
+			Hash:     a.Hash,
+		})
+		supported = append(supported, a)
+	}
+	if len(supported) == 0 {
+		return "", fmt.Errorf("no embedded checksums for Linux or macOS")
+	}
+	first := supported[0]
+	for _, a := range supported[1:] {
+		if assetKind(a) != assetKind(first) || !slices.EqualFunc(a.Binaries, first.Binaries, equalBinary) {
+			return "", fmt.Errorf("ci export requires every platform to install the same binaries, but %s and %s differ", first.Filename, a.Filename)
+		}
+	}
+	strip := 0
+	if u := installSpec.Unpack; u != nil && u.StripComponents != nil {
+		strip = *u.StripComponents
+	}
+	var err error
+	if data.Commands, err = installCommands(first, strip); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := render(&buf, "snippet", snippetTemplate, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func render(w io.Writer, name, text string, data any) error {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"indent": indent}).Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return nil
+}
+
+// indent indents the non-empty lines of s by n spaces.
+func indent(n int, s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = strings.Repeat(" ", n) + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// assetKind returns how a is unpacked: "raw", "tar", "zip" or "gz", or ""
+// if the format is not supported.
+func assetKind(a checksums.PlatformAsset) string {
+	name := strings.ToLower(a.Filename)
+	switch {
+	case a.Raw:
+		return "raw"
+	case strings.Contains(name, ".tar") || strings.HasSuffix(name, ".tgz") || strings.HasSuffix(name, ".tbz") || strings.HasSuffix(name, ".txz"):
+		return "tar"
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".gz"):
+		return "gz"
+	}
+	return ""
+}
+
+// installCommands returns the shell commands installing the binaries of a,
+// downloaded to "$tmp/asset", to "$bindir".
+func installCommands(a checksums.PlatformAsset, strip int) ([]string, error) {
+	var commands []string
+	switch assetKind(a) {
+	case "raw", "gz":
+		if len(a.Binaries) == 0 {
+			return nil, fmt.Errorf("no binaries to install from %s", a.Filename)
+		}
+		b := a.Binaries[0]
+		if assetKind(a) == "gz" {
+			commands = append(commands, `gunzip -c "$tmp/asset" >"$tmp/asset.bin"`, `install -m 755 "$tmp/asset.bin" "$bindir/"`+shellQuote(b.Name))
+		} else {
+			commands = append(commands, `install -m 755 "$tmp/asset" "$bindir/"`+shellQuote(b.Name))
+		}
+		return append(commands, aliasCommands(b)...), nil
+	case "tar":
+		commands = append(commands, `mkdir "$tmp/x"`, `tar -xf "$tmp/asset" -C "$tmp/x"`)
+	case "zip":
+		commands = append(commands, `mkdir "$tmp/x"`, `unzip -q "$tmp/asset" -d "$tmp/x"`)
+	default:
+		return nil, fmt.Errorf("ci export does not support the format of %s", a.Filename)
+	}
+	// Stripped components are matched by a glob, as with the installer
+	// script the binary paths are relative to them.
+	prefix := `"$tmp"/x/` + strings.Repeat("*/", strip)
+	for _, b := range a.Binaries {
+		commands = append(commands, fmt.Sprintf(`install -m 755 %s%s "$bindir/"%s`, prefix, shellQuote(b.Path), shellQuote(b.Name)))
+		commands = append(commands, aliasCommands(b)...)
+	}
+	return commands, nil
+}
+
+func aliasCommands(b spec.Binary) []string {
+	var commands []string
+	for _, alias := range b.Aliases {
+		commands = append(commands, fmt.Sprintf(`ln -sf %s "$bindir/"%s`, shellQuote(b.Name), shellQuote(alias)))
+	}
+	return commands
+}
+
+func equalBinary(a, b spec.Binary) bool {
+	return a.Name == b.Name && a.Path == b.Path && slices.Equal(a.Aliases, b.Aliases)
+}
+
+// shellQuote quotes s for the shell unless it only has safe characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./+@:") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
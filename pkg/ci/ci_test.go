@@ -0,0 +1,138 @@
+package ci
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+var testSpec = &spec.InstallSpec{Name: "tool", Repo: "owner/tool"}
+
+func testAssets(ext string) []checksums.PlatformAsset {
+	binaries := []spec.Binary{{Name: "tool", Path: "bin/tool", Aliases: []string{"t"}}}
+	var assets []checksums.PlatformAsset
+	for _, p := range []spec.Platform{{OS: "linux", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}, {OS: "windows", Arch: "amd64"}} {
+		filename := "tool_" + p.OS + "_" + p.Arch + ext
+		assets = append(assets, checksums.PlatformAsset{
+			OS: p.OS, Arch: p.Arch, Filename: filename,
+			URL:  "https://github.com/owner/tool/releases/download/v1.0.0/" + filename,
+			Hash: p.OS + p.Arch, Algorithm: spec.SHA256, Raw: ext == "", Binaries: binaries,
+		})
+	}
+	return assets
+}
+
+const wantSnippet = `# tool v1.0.0, installed to ${BINDIR:-$HOME/.local/bin} and
+# verified against its embedded checksum.
+(
+  set -eu
+  case "$(uname -s)/$(uname -m)" in
+  Linux/x86_64 | Linux/amd64) url=https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64.tar.gz; sum=linuxamd64 ;;
+  Darwin/aarch64 | Darwin/arm64) url=https://github.com/owner/tool/releases/download/v1.0.0/tool_darwin_arm64.tar.gz; sum=darwinarm64 ;;
+  *) echo "tool is not available for $(uname -s)/$(uname -m)" >&2; exit 1 ;;
+  esac
+  bindir="${BINDIR:-$HOME/.local/bin}"
+  tmp=$(mktemp -d)
+  trap 'rm -rf "$tmp"' EXIT
+  curl -sSfL -o "$tmp/asset" "$url" || wget -qO "$tmp/asset" "$url"
+  got=$({ sha256sum "$tmp/asset" 2>/dev/null || shasum -a 256 "$tmp/asset"; } | cut -d' ' -f1)
+  if [ "$got" != "$sum" ]; then
+    echo "tool: checksum mismatch: got $got, want $sum" >&2
+    exit 1
+  fi
+  mkdir -p "$bindir"
+  mkdir "$tmp/x"
+  tar -xf "$tmp/asset" -C "$tmp/x"
+  install -m 755 "$tmp"/x/bin/tool "$bindir/"tool
+  ln -sf tool "$bindir/"t
+)
+export PATH="${BINDIR:-$HOME/.local/bin}:$PATH"
+`
+
+func TestSnippet(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Snippet(&buf, testSpec, "v1.0.0", testAssets(".tar.gz")); err != nil {
+		t.Fatalf("Snippet() error = %v", err)
+	}
+	want := "# Code generated by binst export. DO NOT EDIT.\n" + wantSnippet
+	if got := buf.String(); got != want {
+		t.Errorf("Snippet() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	var buf bytes.Buffer
+	if err := GitLab(&buf, testSpec, "v1.0.0", testAssets(".tar.gz")); err != nil {
+		t.Fatalf("GitLab() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"#     extends: .install-tool\n",
+		".install-tool:\n  before_script:\n    - |\n      # tool v1.0.0, installed to",
+		"\n        set -eu\n",
+		"\n      export PATH=\"${BINDIR:-$HOME/.local/bin}:$PATH\"\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GitLab() =\n%s\nwant to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "\n      \n") {
+		t.Errorf("GitLab() =\n%s\nwant no whitespace-only lines", got)
+	}
+}
+
+func TestSnippetFormats(t *testing.T) {
+	strip := 1
+	stripSpec := &spec.InstallSpec{Name: "tool", Repo: "owner/tool", Unpack: &spec.UnpackConfig{StripComponents: &strip}}
+	tests := []struct {
+		name        string
+		installSpec *spec.InstallSpec
+		ext         string
+		want        []string
+	}{
+		{"raw", testSpec, "", []string{"  install -m 755 \"$tmp/asset\" \"$bindir/\"tool\n"}},
+		{"gzip", testSpec, ".gz", []string{"  gunzip -c \"$tmp/asset\" >\"$tmp/asset.bin\"\n"}},
+		{"zip with strip_components", stripSpec, ".zip", []string{
+			"  unzip -q \"$tmp/asset\" -d \"$tmp/x\"\n",
+			"  install -m 755 \"$tmp\"/x/*/bin/tool \"$bindir/\"tool\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Snippet(&buf, tt.installSpec, "v1.0.0", testAssets(tt.ext)); err != nil {
+				t.Fatalf("Snippet() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Snippet() =\n%s\nwant to contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestSnippetErrors(t *testing.T) {
+	mixed := testAssets(".tar.gz")
+	mixed[1].Filename = "tool_darwin_arm64.zip"
+	md5 := testAssets(".tar.gz")
+	md5[0].Algorithm = spec.MD5
+	tests := []struct {
+		name   string
+		assets []checksums.PlatformAsset
+	}{
+		{"mixed formats", mixed},
+		{"unsupported algorithm", md5},
+		{"windows only", testAssets(".tar.gz")[2:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Snippet(&bytes.Buffer{}, testSpec, "v1.0.0", tt.assets); err == nil {
+				t.Error("Snippet() expected error")
+			}
+		})
+	}
+}
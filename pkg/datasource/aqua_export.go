@@ -0,0 +1,354 @@
+package datasource
+
+import (
+	"bytes"
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// aquaRegistry is the aqua-registry registry.yaml written by
+// ExportAquaRegistry. It only has the fields binstaller can fill, in the
+// order aqua-registry uses.
+type aquaRegistry struct {
+	Packages []aquaPackage `yaml:"packages"`
+}
+
+type aquaPackage struct {
+	Type                string            `yaml:"type"`
+	RepoOwner           string            `yaml:"repo_owner"`
+	RepoName            string            `yaml:"repo_name"`
+	Description         string            `yaml:"description,omitempty"`
+	Asset               string            `yaml:"asset,omitempty"`
+	URL                 string            `yaml:"url,omitempty"`
+	Format              string            `yaml:"format,omitempty"`
+	Rosetta2            bool              `yaml:"rosetta2,omitempty"`
+	WindowsARMEmulation bool              `yaml:"windows_arm_emulation,omitempty"`
+	Replacements        map[string]string `yaml:"replacements,omitempty"`
+	Overrides           []*aquaOverride   `yaml:"overrides,omitempty"`
+	Files               []aquaFile        `yaml:"files,omitempty"`
+	Checksum            *aquaChecksum     `yaml:"checksum,omitempty"`
+	SupportedEnvs       []string          `yaml:"supported_envs,omitempty"`
+}
+
+type aquaOverride struct {
+	GOOS         string            `yaml:"goos,omitempty"`
+	GOArch       string            `yaml:"goarch,omitempty"`
+	Asset        string            `yaml:"asset,omitempty"`
+	URL          string            `yaml:"url,omitempty"`
+	Format       string            `yaml:"format,omitempty"`
+	Replacements map[string]string `yaml:"replacements,omitempty"`
+	Files        []aquaFile        `yaml:"files,omitempty"`
+	Checksum     *aquaChecksum     `yaml:"checksum,omitempty"`
+}
+
+type aquaFile struct {
+	Name string `yaml:"name"`
+	Src  string `yaml:"src,omitempty"`
+}
+
+type aquaChecksum struct {
+	Type      string `yaml:"type"`
+	Asset     string `yaml:"asset,omitempty"`
+	URL       string `yaml:"url,omitempty"`
+	Algorithm string `yaml:"algorithm"`
+}
+
+var placeholderPattern = regexp.MustCompile(`\$\{([A-Z0-9_]+)\}`)
+
+// aquaTemplateConverter converts InstallSpec templates to aqua templates, the
+// reverse of ConvertAquaTemplateToInstallSpec.
+type aquaTemplateConverter struct {
+	installSpec *spec.InstallSpec
+	// ext is the extension of the asset the template is used for. Raw
+	// binaries have format "raw", so ${EXT} is dropped instead of becoming
+	// ".raw".
+	ext string
+}
+
+func (c aquaTemplateConverter) convert(tmpl string) (string, error) {
+	owner, repo, _ := strings.Cut(c.installSpec.Repo, "/")
+	var unsupported []string
+	result := placeholderPattern.ReplaceAllStringFunc(tmpl, func(m string) string {
+		switch name := m[2 : len(m)-1]; name {
+		case "NAME":
+			return c.installSpec.Name
+		case "REPO_OWNER":
+			return owner
+		case "REPO_NAME":
+			return repo
+		case "TAG":
+			return "{{.Version}}"
+		case "VERSION":
+			return "{{trimV .Version}}"
+		case "OS":
+			if nc := c.installSpec.Asset.NamingConvention; nc != nil && nc.OS == "titlecase" {
+				return "{{title .OS}}"
+			}
+			return "{{.OS}}"
+		case "ARCH":
+			return "{{.Arch}}"
+		case "EXT":
+			if c.ext == "" {
+				return ""
+			}
+			return ".{{.Format}}"
+		case "ASSET_FILENAME":
+			return "{{.Asset}}"
+		default:
+			unsupported = append(unsupported, m)
+			return m
+		}
+	})
+	if len(unsupported) > 0 {
+		return "", errors.Errorf("placeholder %s in %q is not supported by aqua", strings.Join(unsupported, ", "), tmpl)
+	}
+	return result, nil
+}
+
+// ExportAquaRegistry renders an aqua-registry registry.yaml package entry for
+// installSpec, the reverse of AquaRegistryAdapter. Features aqua cannot
+// express, such as libc or distro rules and custom variables, are reported as
+// errors rather than silently dropped.
+func ExportAquaRegistry(installSpec *spec.InstallSpec, description string) ([]byte, error) {
+	owner, repo, ok := strings.Cut(installSpec.Repo, "/")
+	if !ok || owner == "" || repo == "" {
+		return nil, errors.Errorf("invalid repo: %q", installSpec.Repo)
+	}
+	if v := installSpec.Version; v != nil && (v.TagPrefix != "" || v.TagTemplate != "") {
+		return nil, errors.New("version.tag_prefix and version.tag_template are not supported by aqua")
+	}
+	asset := installSpec.Asset
+	pkg := aquaPackage{
+		Type:        "github_release",
+		RepoOwner:   owner,
+		RepoName:    repo,
+		Description: description,
+		Format:      aquaFormat(asset.DefaultExtension),
+	}
+	if err := setAquaAsset(installSpec, asset.Template, asset.DefaultExtension, &pkg.Asset, &pkg.URL); err != nil {
+		return nil, err
+	}
+	if pkg.URL != "" {
+		pkg.Type = "http"
+	}
+	if ae := asset.ArchEmulation; ae != nil {
+		pkg.Rosetta2 = ae.Rosetta2
+		pkg.WindowsARMEmulation = ae.WindowsArm64X64
+	}
+
+	var err error
+	if pkg.Files, err = aquaFiles(installSpec, asset.Binaries, asset.DefaultExtension); err != nil {
+		return nil, err
+	}
+	for _, rule := range asset.Rules {
+		if err := addAquaRule(installSpec, &pkg, rule); err != nil {
+			return nil, err
+		}
+	}
+	if c := installSpec.Checksums; c != nil && c.Template != "" {
+		if pkg.Checksum, err = aquaChecksumOf(installSpec, c.Template, c.Algorithm); err != nil {
+			return nil, err
+		}
+		for _, rule := range c.Rules {
+			if rule.When.Libc != "" || rule.When.Distro != "" {
+				return nil, errors.New("checksum rules on libc or distro are not supported by aqua")
+			}
+			if rule.When.OS == "" && rule.When.Arch == "" {
+				return nil, errors.New("checksum rules need an os or arch condition for aqua")
+			}
+			ov := findAquaOverride(&pkg, rule.When.OS, rule.When.Arch)
+			if ov.Checksum, err = aquaChecksumOf(installSpec, rule.Template, c.Algorithm); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, p := range installSpec.SupportedPlatforms {
+		pkg.SupportedEnvs = append(pkg.SupportedEnvs, p.OS+"/"+p.Arch)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(aquaRegistry{Packages: []aquaPackage{pkg}}); err != nil {
+		return nil, errors.Wrap(err, "failed to encode aqua registry")
+	}
+	if err := enc.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to encode aqua registry")
+	}
+	return buf.Bytes(), nil
+}
+
+// addAquaRule adds an asset rule to pkg. Rules that only rename an OS or
+// arch become replacements, the others overrides.
+func addAquaRule(installSpec *spec.InstallSpec, pkg *aquaPackage, rule spec.AssetRule) error {
+	when := rule.When
+	if when.Libc != "" || when.Distro != "" {
+		return errors.New("asset rules on libc or distro are not supported by aqua")
+	}
+	if len(rule.FallbackTemplates) > 0 {
+		return errors.New("asset fallback_templates are not supported by aqua")
+	}
+	onlyReplacement := rule.Template == "" && rule.Ext == "" && len(rule.Binaries) == 0
+	switch {
+	case onlyReplacement && when.OS != "" && when.Arch == "" && rule.OS != "" && rule.Arch == "":
+		pkg.Replacements = setReplacement(pkg.Replacements, when.OS, rule.OS)
+		return nil
+	case onlyReplacement && when.Arch != "" && when.OS == "" && rule.Arch != "" && rule.OS == "":
+		pkg.Replacements = setReplacement(pkg.Replacements, when.Arch, rule.Arch)
+		return nil
+	}
+
+	if when.OS == "" && when.Arch == "" {
+		return errors.New("asset rules need an os or arch condition for aqua")
+	}
+	ov := findAquaOverride(pkg, when.OS, when.Arch)
+	if rule.OS != "" {
+		if when.OS == "" {
+			return errors.New("asset rules overriding os need a when.os condition for aqua")
+		}
+		ov.Replacements = setReplacement(ov.Replacements, when.OS, rule.OS)
+	}
+	if rule.Arch != "" {
+		if when.Arch == "" {
+			return errors.New("asset rules overriding arch need a when.arch condition for aqua")
+		}
+		ov.Replacements = setReplacement(ov.Replacements, when.Arch, rule.Arch)
+	}
+	ext := installSpec.Asset.DefaultExtension
+	if rule.Ext != "" {
+		ext = rule.Ext
+		ov.Format = aquaFormat(ext)
+	}
+	if rule.Template != "" || rule.Ext != "" {
+		tmpl := cmp.Or(rule.Template, installSpec.Asset.Template)
+		if err := setAquaAsset(installSpec, tmpl, ext, &ov.Asset, &ov.URL); err != nil {
+			return err
+		}
+		// {{.Format}} already follows the override's format
+		if ov.Asset == pkg.Asset && ov.URL == pkg.URL {
+			ov.Asset, ov.URL = "", ""
+		}
+	}
+	if len(rule.Binaries) > 0 {
+		files, err := aquaFiles(installSpec, rule.Binaries, ext)
+		if err != nil {
+			return err
+		}
+		ov.Files = files
+	}
+	return nil
+}
+
+// setAquaAsset sets the asset template, or the URL template for specs
+// downloading from outside GitHub releases.
+func setAquaAsset(installSpec *spec.InstallSpec, tmpl, ext string, asset, url *string) error {
+	c := aquaTemplateConverter{installSpec: installSpec, ext: ext}
+	if dl := installSpec.Asset.DownloadURLTemplate; dl != "" {
+		converted, err := c.convert(strings.ReplaceAll(dl, "${ASSET_FILENAME}", tmpl))
+		if err != nil {
+			return err
+		}
+		*url = converted
+		return nil
+	}
+	converted, err := c.convert(tmpl)
+	if err != nil {
+		return err
+	}
+	*asset = converted
+	return nil
+}
+
+// aquaFiles converts binaries to aqua files. aqua does not strip archive
+// components, so with strip_components: 1 the paths are prefixed with the
+// asset name, the usual top-level directory of archives.
+func aquaFiles(installSpec *spec.InstallSpec, binaries []spec.Binary, ext string) ([]aquaFile, error) {
+	prefix := ""
+	if u := installSpec.Unpack; u != nil && u.StripComponents != nil {
+		switch *u.StripComponents {
+		case 0:
+		case 1:
+			prefix = "{{.AssetWithoutExt}}/"
+		default:
+			return nil, errors.Errorf("unpack.strip_components: %d is not supported by aqua", *u.StripComponents)
+		}
+	}
+	if len(binaries) == 0 && installSpec.Name != "" {
+		binaries = []spec.Binary{{Name: installSpec.Name, Path: installSpec.Name}}
+	}
+	c := aquaTemplateConverter{installSpec: installSpec, ext: ext}
+	files := make([]aquaFile, 0, len(binaries))
+	for _, b := range binaries {
+		f := aquaFile{Name: b.Name}
+		if ext != "" && (b.Path != b.Name || prefix != "") {
+			src, err := c.convert(prefix + b.Path)
+			if err != nil {
+				return nil, err
+			}
+			f.Src = src
+		}
+		files = append(files, f)
+	}
+	// aqua installs the repository name by default
+	_, repo, _ := strings.Cut(installSpec.Repo, "/")
+	if len(files) == 1 && files[0].Name == repo && files[0].Src == "" {
+		return nil, nil
+	}
+	return files, nil
+}
+
+func aquaChecksumOf(installSpec *spec.InstallSpec, tmpl string, algorithm spec.HashAlgorithm) (*aquaChecksum, error) {
+	switch algorithm {
+	case "":
+		algorithm = spec.SHA256
+	case spec.SHA256, spec.SHA512, spec.SHA1, spec.MD5:
+	default:
+		return nil, errors.Errorf("checksum algorithm %s is not supported by aqua", algorithm)
+	}
+	c := aquaTemplateConverter{installSpec: installSpec, ext: installSpec.Asset.DefaultExtension}
+	converted, err := c.convert(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if spec.IsURLTemplate(tmpl) {
+		return &aquaChecksum{Type: "http", URL: converted, Algorithm: string(algorithm)}, nil
+	}
+	return &aquaChecksum{Type: "github_release", Asset: converted, Algorithm: string(algorithm)}, nil
+}
+
+// findAquaOverride returns the override of pkg for goos and goarch, adding
+// it if there is none yet.
+func findAquaOverride(pkg *aquaPackage, goos, goarch string) *aquaOverride {
+	i := slices.IndexFunc(pkg.Overrides, func(ov *aquaOverride) bool {
+		return ov.GOOS == goos && ov.GOArch == goarch
+	})
+	if i >= 0 {
+		return pkg.Overrides[i]
+	}
+	ov := &aquaOverride{GOOS: goos, GOArch: goarch}
+	pkg.Overrides = append(pkg.Overrides, ov)
+	return ov
+}
+
+func setReplacement(m map[string]string, key, value string) map[string]string {
+	if m == nil {
+		m = make(map[string]string)
+	}
+	m[key] = value
+	return m
+}
+
+// aquaFormat returns the aqua format of an asset extension.
+func aquaFormat(ext string) string {
+	if ext == "" {
+		return "raw"
+	}
+	return strings.TrimPrefix(ext, ".")
+}
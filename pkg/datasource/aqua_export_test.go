@@ -0,0 +1,162 @@
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"gopkg.in/yaml.v3"
+)
+
+const sampleExportSpec = `
+name: gh
+repo: cli/cli
+asset:
+  template: gh_${VERSION}_${OS}_${ARCH}${EXT}
+  default_extension: .tar.gz
+  binaries:
+    - name: gh
+      path: bin/gh
+  rules:
+    - when:
+        os: darwin
+      os: macOS
+      ext: .zip
+    - when:
+        arch: amd64
+      arch: x86_64
+checksums:
+  algorithm: sha256
+  template: gh_${VERSION}_checksums.txt
+supported_platforms:
+  - os: linux
+    arch: amd64
+  - os: darwin
+    arch: arm64
+`
+
+func parseExportSpec(t *testing.T, s string) *spec.InstallSpec {
+	t.Helper()
+	var installSpec spec.InstallSpec
+	if err := yaml.Unmarshal([]byte(s), &installSpec); err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+	return &installSpec
+}
+
+func TestExportAquaRegistry(t *testing.T) {
+	got, err := ExportAquaRegistry(parseExportSpec(t, sampleExportSpec), "GitHub CLI")
+	if err != nil {
+		t.Fatalf("ExportAquaRegistry failed: %v", err)
+	}
+	want := `# yaml-language-server: $schema=https://raw.githubusercontent.com/aquaproj/aqua/main/json-schema/registry.json
+packages:
+  - type: github_release
+    repo_owner: cli
+    repo_name: cli
+    description: GitHub CLI
+    asset: gh_{{trimV .Version}}_{{.OS}}_{{.Arch}}.{{.Format}}
+    format: tar.gz
+    replacements:
+      amd64: x86_64
+    overrides:
+      - goos: darwin
+        format: zip
+        replacements:
+          darwin: macOS
+    files:
+      - name: gh
+        src: bin/gh
+    checksum:
+      type: github_release
+      asset: gh_{{trimV .Version}}_checksums.txt
+      algorithm: sha256
+    supported_envs:
+      - linux/amd64
+      - darwin/arm64
+`
+	if string(got) != want {
+		t.Errorf("ExportAquaRegistry:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExportAquaRegistry_RoundTrip(t *testing.T) {
+	registry, err := ExportAquaRegistry(parseExportSpec(t, sampleExportSpec), "")
+	if err != nil {
+		t.Fatalf("ExportAquaRegistry failed: %v", err)
+	}
+	adapter := NewAquaRegistryAdapterFromReader(bytes.NewReader(registry))
+	installSpec, err := adapter.GenerateInstallSpec(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateInstallSpec failed: %v", err)
+	}
+	if installSpec.Repo != "cli/cli" {
+		t.Errorf("Repo: got %q, want %q", installSpec.Repo, "cli/cli")
+	}
+	if want := "gh_${VERSION}_${OS}_${ARCH}${EXT}"; installSpec.Asset.Template != want {
+		t.Errorf("Asset.Template: got %q, want %q", installSpec.Asset.Template, want)
+	}
+	if want := "gh_${VERSION}_checksums.txt"; installSpec.Checksums == nil || installSpec.Checksums.Template != want {
+		t.Errorf("Checksums: got %+v, want template %q", installSpec.Checksums, want)
+	}
+	if len(installSpec.Asset.Binaries) != 1 || installSpec.Asset.Binaries[0].Path != "bin/gh" {
+		t.Errorf("Binaries: got %+v, want gh at bin/gh", installSpec.Asset.Binaries)
+	}
+}
+
+func TestExportAquaRegistry_StripComponents(t *testing.T) {
+	installSpec := parseExportSpec(t, sampleExportSpec+`
+unpack:
+  strip_components: 1
+`)
+	got, err := ExportAquaRegistry(installSpec, "")
+	if err != nil {
+		t.Fatalf("ExportAquaRegistry failed: %v", err)
+	}
+	if want := "src: '{{.AssetWithoutExt}}/bin/gh'"; !strings.Contains(string(got), want) {
+		t.Errorf("ExportAquaRegistry: got\n%s\nwant it to contain %q", got, want)
+	}
+}
+
+func TestExportAquaRegistry_Unsupported(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra string
+		want  string
+	}{
+		{
+			name: "libc rule",
+			extra: `
+    - when:
+        os: linux
+        libc: musl
+      template: gh_${VERSION}_linux-musl_${ARCH}${EXT}`,
+			want: "libc or distro",
+		},
+		{
+			name: "custom variable",
+			extra: `
+    - when:
+        os: windows
+      template: gh_${VERSION}_${OS}_${CUSTOM}${EXT}`,
+			want: "${CUSTOM}",
+		},
+		{
+			name: "rule without condition",
+			extra: `
+    - template: gh_${VERSION}${EXT}`,
+			want: "os or arch condition",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := strings.Replace(sampleExportSpec, "      arch: x86_64", "      arch: x86_64"+tt.extra, 1)
+			_, err := ExportAquaRegistry(parseExportSpec(t, s), "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ExportAquaRegistry: got error %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
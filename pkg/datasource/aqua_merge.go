@@ -40,5 +40,8 @@ func mergeVersionOverride(pkg registry.PackageInfo, vo registry.VersionOverride)
 	if vo.Rosetta2 != nil {
 		merged.Rosetta2 = *vo.Rosetta2
 	}
+	if vo.WindowsARMEmulation != nil {
+		merged.WindowsARMEmulation = *vo.WindowsARMEmulation
+	}
 	return merged
 }
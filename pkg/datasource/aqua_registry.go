@@ -10,6 +10,7 @@ import (
 
 	"github.com/aquaproj/aqua/v2/pkg/config/registry"
 	aquaexpr "github.com/aquaproj/aqua/v2/pkg/expr"
+	"github.com/haya14busa/goinstaller/internal/httpclient"
 	"github.com/haya14busa/goinstaller/pkg/spec"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
@@ -79,13 +80,14 @@ func mapToInstallSpec(p registry.PackageInfo) (*spec.InstallSpec, error) {
 		}
 		installSpec.Checksums = &spec.ChecksumConfig{
 			Template:  convertedChecksum,
-			Algorithm: p.Checksum.Algorithm,
+			Algorithm: spec.HashAlgorithm(p.Checksum.Algorithm),
 		}
 	}
 
-	if p.Rosetta2 {
+	if p.Rosetta2 || p.WindowsARMEmulation {
 		installSpec.Asset.ArchEmulation = &spec.ArchEmulation{
-			Rosetta2: true,
+			Rosetta2:        p.Rosetta2,
+			WindowsArm64X64: p.WindowsARMEmulation,
 		}
 	}
 
@@ -238,13 +240,13 @@ func (a *AquaRegistryAdapter) GenerateInstallSpec(ctx context.Context) (*spec.In
 			ref = "HEAD"
 		}
 		url := "https://raw.githubusercontent.com/aquaproj/aqua-registry/" + ref + "/pkgs/" + a.repo + "/registry.yaml"
-		resp, err := http.Get(url)
+		resp, err := (&httpclient.Client{}).Get(url, "")
 		if err != nil {
 			return nil, err
 		}
 		defer resp.Body.Close()
 		if resp.StatusCode != http.StatusOK {
-			return nil, errors.New("failed to fetch registry.yaml from GitHub: " + resp.Status)
+			return nil, httpclient.StatusError(resp, errors.New("failed to fetch registry.yaml from GitHub: "+resp.Status))
 		}
 		r = resp.Body
 	} else {
@@ -32,6 +32,10 @@ var extensions = []string{
 	"pkg",
 	"rar",
 	"tar",
+	"7z",
+	"deb",
+	"rpm",
+	"apk",
 }
 
 // formatToExtension converts a goreleaser archive format to a file extension.
@@ -4,32 +4,172 @@ import (
 	"bytes"
 	"context"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/aquaproj/aqua/v2/pkg/config"
 	"github.com/aquaproj/aqua/v2/pkg/controller"
+	"github.com/haya14busa/goinstaller/internal/httpclient"
 	"github.com/haya14busa/goinstaller/pkg/spec"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v3"
 )
 
 // GitHubAdapter implements SourceAdapter for GitHub release using `aqua
 // generatea-registry` internally. Note: No aqua CLI dependency.
 type GitHubAdapter struct {
-	repo string // Used for GitHub fetch, e.g. "owner/name"
+	repo         string   // Used for GitHub fetch, e.g. "owner/name"
+	apiURL       string   // GitHub Enterprise Server API URL. Empty for github.com
+	ignoreAssets []string // Glob patterns of release assets to exclude
+	preferAssets []string // Glob patterns of release assets kept even if ignored
 }
 
 // NewGitHubAdapter creates an adapter that generate aqua registry YAML from
 // GitHub release and then convert it to binstalelr's InstallSpec.
-func NewGitHubAdapter(repo string) *GitHubAdapter {
-	return &GitHubAdapter{repo: repo}
+// apiURL is the GitHub Enterprise Server API URL (e.g.
+// https://ghe.example.com/api/v3), or empty for github.com.
+// ignoreAssets and preferAssets are glob patterns with the same semantics as
+// the spec's asset.ignore_assets and asset.prefer_assets.
+func NewGitHubAdapter(repo, apiURL string, ignoreAssets, preferAssets []string) *GitHubAdapter {
+	return &GitHubAdapter{repo: repo, apiURL: apiURL, ignoreAssets: ignoreAssets, preferAssets: preferAssets}
 }
 
 func (g *GitHubAdapter) GenerateInstallSpec(ctx context.Context) (*spec.InstallSpec, error) {
 	param := &config.Param{Limit: 1}
+	if filter := assetFilterExpr(g.ignoreAssets, g.preferAssets); filter != "" {
+		path, err := writeGenerateConfig(filter)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(path)
+		param.GenerateConfigFilePath = path
+	}
+	// aqua reads the token from AQUA_GITHUB_TOKEN or GITHUB_TOKEN only
+	if token := httpclient.Token(); token != "" && os.Getenv("AQUA_GITHUB_TOKEN") == "" && os.Getenv("GITHUB_TOKEN") == "" {
+		os.Setenv("AQUA_GITHUB_TOKEN", token)
+	}
+	httpClient := httpclient.HTTPClient()
+	if g.enterprise() {
+		t, err := newEnterpriseTransport(g.apiURL, http.DefaultTransport)
+		if err != nil {
+			return nil, err
+		}
+		if os.Getenv("AQUA_GITHUB_TOKEN") == "" && os.Getenv("GITHUB_TOKEN") == "" {
+			log.Warn("GITHUB_TOKEN is not set; requests to GitHub Enterprise Server require a token")
+		}
+		httpClient.Transport = t
+	}
+	// aqua builds its GitHub client with oauth2, which uses the HTTP client
+	// from the context as its base transport.
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
 	logE := log.NewEntry(log.New())
 	var registry bytes.Buffer
-	ctrl := controller.InitializeGenerateRegistryCommandController(ctx, logE, param, http.DefaultClient, &registry)
+	ctrl := controller.InitializeGenerateRegistryCommandController(ctx, logE, param, httpClient, &registry)
 	if err := ctrl.GenerateRegistry(ctx, param, logE, g.repo); err != nil {
 		return nil, err
 	}
-	return genSpecFromRegistryYAML(ctx, &registry)
+	installSpec, err := genSpecFromRegistryYAML(ctx, &registry)
+	if err != nil {
+		return nil, err
+	}
+	if g.enterprise() {
+		installSpec.GitHubAPIURL = strings.TrimSuffix(g.apiURL, "/")
+	}
+	return installSpec, nil
+}
+
+func (g *GitHubAdapter) enterprise() bool {
+	return g.apiURL != "" && strings.TrimSuffix(g.apiURL, "/") != spec.DefaultGitHubAPIURL
+}
+
+// enterpriseTransport redirects requests for github.com and api.github.com
+// to a GitHub Enterprise Server, since aqua only talks to github.com.
+type enterpriseTransport struct {
+	base *url.URL // e.g. https://ghe.example.com
+	api  *url.URL // e.g. https://ghe.example.com/api/v3
+	rt   http.RoundTripper
+}
+
+func newEnterpriseTransport(apiURL string, rt http.RoundTripper) (*enterpriseTransport, error) {
+	api, err := url.Parse(strings.TrimSuffix(apiURL, "/"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid GitHub API URL %s", apiURL)
+	}
+	base, err := url.Parse(spec.GitHubBaseFromAPIURL(apiURL))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid GitHub API URL %s", apiURL)
+	}
+	return &enterpriseTransport{base: base, api: api, rt: rt}, nil
+}
+
+func (t *enterpriseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var target *url.URL
+	switch req.URL.Host {
+	case "api.github.com":
+		target = t.api
+	case "github.com":
+		target = t.base
+	default:
+		return t.rt.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	req.URL.Path = target.Path + req.URL.Path
+	if req.URL.RawPath != "" {
+		req.URL.RawPath = target.Path + req.URL.RawPath
+	}
+	req.Host = ""
+	return t.rt.RoundTrip(req)
+}
+
+// assetFilterExpr builds an aqua all_assets_filter expression which keeps
+// assets matching preferAssets and drops assets matching ignoreAssets.
+// It returns an empty string if there is nothing to filter.
+func assetFilterExpr(ignoreAssets, preferAssets []string) string {
+	// Malformed globs never match, so they are left out of the expression.
+	matchAny := func(globs []string) string {
+		var conds []string
+		for _, g := range globs {
+			if re, ok := spec.GlobToRegexp(g); ok {
+				conds = append(conds, "Asset matches "+strconv.Quote(re))
+			}
+		}
+		if len(conds) == 0 {
+			return ""
+		}
+		return "(" + strings.Join(conds, " || ") + ")"
+	}
+	ignore := matchAny(ignoreAssets)
+	if ignore == "" {
+		return ""
+	}
+	filter := "!" + ignore
+	if prefer := matchAny(preferAssets); prefer != "" {
+		filter = prefer + " || " + filter
+	}
+	return filter
+}
+
+// writeGenerateConfig writes a temporary `aqua gr` configuration file with the
+// given asset filter and returns its path.
+func writeGenerateConfig(assetFilter string) (string, error) {
+	data, err := yaml.Marshal(map[string]string{"all_assets_filter": assetFilter})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal generate config")
+	}
+	f, err := os.CreateTemp("", "binstaller-aqua-gr-*.yaml")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create generate config")
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", errors.Wrap(err, "failed to write generate config")
+	}
+	return f.Name(), nil
 }
@@ -0,0 +1,78 @@
+package datasource
+
+import (
+	"net/http"
+	"testing"
+
+	aquaexpr "github.com/aquaproj/aqua/v2/pkg/expr"
+)
+
+func TestAssetFilterExpr(t *testing.T) {
+	if got := assetFilterExpr(nil, []string{"*.tar.gz"}); got != "" {
+		t.Errorf("assetFilterExpr without ignore globs = %q, want empty", got)
+	}
+	if got := assetFilterExpr([]string{"[bad"}, nil); got != "" {
+		t.Errorf("assetFilterExpr with only malformed ignore globs = %q, want empty", got)
+	}
+
+	filter := assetFilterExpr([]string{"*.sbom", "*-debug*", "[bad"}, []string{"*-debug-symbols*"})
+	prog, err := aquaexpr.CompileAssetFilter(filter)
+	if err != nil {
+		t.Fatalf("CompileAssetFilter(%q) failed: %v", filter, err)
+	}
+	tests := []struct {
+		asset string
+		want  bool
+	}{
+		{"tool_linux_amd64.tar.gz", true},
+		{"tool_linux_amd64.tar.gz.sbom", false},
+		{"tool-debug_linux_amd64.tar.gz", false},
+		{"tool-debug-symbols_linux_amd64.tar.gz", true},
+	}
+	for _, tt := range tests {
+		got, err := aquaexpr.EvaluateAssetFilter(prog, tt.asset)
+		if err != nil {
+			t.Fatalf("EvaluateAssetFilter(%q) failed: %v", tt.asset, err)
+		}
+		if got != tt.want {
+			t.Errorf("filter(%q) = %v, want %v", tt.asset, got, tt.want)
+		}
+	}
+}
+
+type recordTransport struct{ urls []string }
+
+func (r *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.urls = append(r.urls, req.URL.String())
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestEnterpriseTransport(t *testing.T) {
+	rec := &recordTransport{}
+	tr, err := newEnterpriseTransport("https://ghe.example.com/api/v3/", rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: tr}
+	for _, u := range []string{
+		"https://api.github.com/repos/o/r/releases",
+		"https://github.com/o/r/releases/download/v1/a.tar.gz",
+		"https://example.com/other",
+	} {
+		resp, err := client.Get(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	want := []string{
+		"https://ghe.example.com/api/v3/repos/o/r/releases",
+		"https://ghe.example.com/o/r/releases/download/v1/a.tar.gz",
+		"https://example.com/other",
+	}
+	for i := range want {
+		if rec.urls[i] != want[i] {
+			t.Errorf("request %d went to %s, want %s", i, rec.urls[i], want[i])
+		}
+	}
+}
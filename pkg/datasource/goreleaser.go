@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"regexp"
 	"slices"
 	"strings"
@@ -16,6 +17,7 @@ import (
 	"github.com/goreleaser/goreleaser/v2/pkg/config"
 	gorelcontext "github.com/goreleaser/goreleaser/v2/pkg/context"
 	"github.com/goreleaser/goreleaser/v2/pkg/defaults"
+	"github.com/haya14busa/goinstaller/internal/httpclient"
 	"github.com/haya14busa/goinstaller/pkg/spec"
 	"github.com/pkg/errors"
 )
@@ -31,15 +33,19 @@ type goreleaserAdapter struct {
 	filePath     string
 	commit       string
 	nameOverride string
+	githubAPIURL string
 }
 
 // NewGoReleaserAdapter creates a new adapter for GoReleaser sources.
-func NewGoReleaserAdapter(repo, filePath, commit, nameOverride string) SourceAdapter {
+// githubAPIURL is the GitHub Enterprise Server API URL to load the config
+// from, or empty for github.com.
+func NewGoReleaserAdapter(repo, filePath, commit, nameOverride, githubAPIURL string) SourceAdapter {
 	return &goreleaserAdapter{
 		repo:         repo,
 		filePath:     filePath,
 		commit:       commit,
 		nameOverride: nameOverride,
+		githubAPIURL: githubAPIURL,
 	}
 }
 
@@ -50,7 +56,11 @@ func (a *goreleaserAdapter) GenerateInstallSpec(ctx context.Context) (*spec.Inst
 	log.Infof("generating InstallSpec using goreleaserAdapter")
 	log.Debugf("Fields - FilePath: %s, Repo: %s, NameOverride: %s", a.filePath, a.repo, a.nameOverride)
 
-	project, err := loadGoReleaserConfig(a.repo, a.filePath, a.commit)
+	rawBaseURL := "https://raw.githubusercontent.com"
+	if a.githubAPIURL != "" && strings.TrimSuffix(a.githubAPIURL, "/") != spec.DefaultGitHubAPIURL {
+		rawBaseURL = spec.GitHubBaseFromAPIURL(a.githubAPIURL) + "/raw"
+	}
+	project, err := loadGoReleaserConfig(a.repo, a.filePath, a.commit, rawBaseURL)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to load goreleaser config")
 	}
@@ -141,7 +151,7 @@ func mapToGoInstallerSpec(project *config.Project, nameOverride, repoOverride st
 		}
 		s.Checksums = &spec.ChecksumConfig{
 			Template:  checksumTemplate,
-			Algorithm: project.Checksum.Algorithm,
+			Algorithm: spec.HashAlgorithm(project.Checksum.Algorithm),
 		}
 	}
 
@@ -363,7 +373,7 @@ func translateTemplate(tmpl string) (string, error) {
 
 // loadGoReleaserConfig loads a goreleaser project configuration.
 // It tries logading from a local file, then falls back to loading from a GitHub repo.
-func loadGoReleaserConfig(repo, file, commitHash string) (project *config.Project, err error) {
+func loadGoReleaserConfig(repo, file, commitHash, rawBaseURL string) (project *config.Project, err error) {
 	// Try loading from local file if file is provided
 	if file != "" {
 		log.Infof("attempting to load goreleaser config from local file: %s", file)
@@ -383,7 +393,7 @@ func loadGoReleaserConfig(repo, file, commitHash string) (project *config.Projec
 			if configPath == "" {
 				continue
 			}
-			project, err = loadFromGitHub(repo, configPath, commitHash)
+			project, err = loadFromGitHub(rawBaseURL, repo, configPath, commitHash)
 			if err == nil {
 				log.Info("successfully loaded config from github")
 				return project, nil
@@ -398,7 +408,7 @@ func loadGoReleaserConfig(repo, file, commitHash string) (project *config.Projec
 
 // loadFromGitHub loads a project configuration from a GitHub repository.
 // Adapted from main.go, simplified commit handling for now.
-func loadFromGitHub(repo, configPath, specifiedCommitHash string) (*config.Project, error) {
+func loadFromGitHub(rawBaseURL, repo, configPath, specifiedCommitHash string) (*config.Project, error) {
 	log.Infof("loading config for %s at path %s from github", repo, configPath)
 
 	commitHash := "HEAD"
@@ -410,16 +420,22 @@ func loadFromGitHub(repo, configPath, specifiedCommitHash string) (*config.Proje
 	if configPath == "" {
 		return nil, errors.New("config path within repository must be specified")
 	}
-	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", repo, commitHash, configPath)
+	url := fmt.Sprintf("%s/%s/%s/%s", rawBaseURL, repo, commitHash, configPath)
 	log.Infof("fetching config from URL: %s", url)
-	resp, err := http.Get(url) // Basic GET, no token handling yet
+	// The token is sent to the raw host of github.com or of the GitHub
+	// Enterprise Server
+	client := &httpclient.Client{}
+	if u, err := neturl.Parse(rawBaseURL); err == nil {
+		client.GitHubHosts = []string{u.Host}
+	}
+	resp, err := client.Get(url, "")
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to fetch config from %s", url)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch config from %s: status %d", url, resp.StatusCode)
+		return nil, httpclient.StatusError(resp, fmt.Errorf("failed to fetch config from %s: status %d", url, resp.StatusCode))
 	}
 
 	// Read the content into a buffer first to allow parsing and potential hashing later
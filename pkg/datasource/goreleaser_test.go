@@ -27,6 +27,7 @@ func setupGoReleaserTest(t *testing.T, goreleaserConfigContent string) (*spec.In
 		tmpFile.Name(), // filePath
 		"",             // commit
 		"",             // nameOverride
+		"",             // githubAPIURL
 	)
 
 	installSpec, err := adapter.GenerateInstallSpec(context.Background())
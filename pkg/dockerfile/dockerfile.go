@@ -0,0 +1,176 @@
+// Package dockerfile renders a Dockerfile stage that downloads and verifies
+// a release asset from an InstallSpec and its embedded checksums, for
+// multi-platform images built with buildx.
+package dockerfile
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+//go:embed stage.Dockerfile.tmpl
+var stageTemplate string
+
+// BaseImage is the image the download stage runs in. Its busybox has the
+// wget, sha256sum, sha512sum, tar and unzip applets the stage uses.
+const BaseImage = "alpine:3.20"
+
+// sumCommands are the checksum commands of the base image by algorithm.
+var sumCommands = map[spec.HashAlgorithm]string{spec.SHA256: "sha256sum", spec.SHA512: "sha512sum"}
+
+type stageData struct {
+	Stage      string
+	Name       string
+	Version    string
+	BaseImage  string
+	SumCommand string
+	Sources    []source
+	Commands   []string
+}
+
+type source struct {
+	// Platform is the spec platform, e.g. "linux/armv7", which is
+	// TARGETOS/TARGETARCH with TARGETVARIANT appended for arm.
+	Platform string
+	URL      string
+	Hash     string
+}
+
+var stageNameInvalid = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// Stage writes a build stage installing the Linux assets of version for the
+// target platform to /out, to be copied into the image. The stage runs on
+// the build platform, so no emulation is needed. Every platform must install
+// the same binaries from the same kind of asset.
+func Stage(w io.Writer, installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset) error {
+	data := stageData{
+		Stage:     stageNameInvalid.ReplaceAllString(strings.ToLower(installSpec.Name), "-"),
+		Name:      installSpec.Name,
+		Version:   version,
+		BaseImage: BaseImage,
+	}
+	var linux []checksums.PlatformAsset
+	for _, a := range assets {
+		if strings.ToLower(a.OS) != "linux" {
+			continue
+		}
+		command, ok := sumCommands[a.Algorithm]
+		if !ok {
+			return fmt.Errorf("dockerfile export requires sha256 or sha512 checksums, but %s has a %s checksum", a.Filename, a.Algorithm)
+		}
+		if data.SumCommand != "" && data.SumCommand != command {
+			return fmt.Errorf("dockerfile export requires the same checksum algorithm for every platform")
+		}
+		data.SumCommand = command
+		data.Sources = append(data.Sources, source{
+			Platform: strings.ToLower(a.OS) + "/" + strings.ToLower(a.Arch),
+			URL:      shellQuote(a.URL),
+			Hash:     a.Hash,
+		})
+		linux = append(linux, a)
+	}
+	if len(linux) == 0 {
+		return fmt.Errorf("no embedded checksums for Linux")
+	}
+	first := linux[0]
+	for _, a := range linux[1:] {
+		if assetKind(a) != assetKind(first) || !slices.EqualFunc(a.Binaries, first.Binaries, equalBinary) {
+			return fmt.Errorf("dockerfile export requires every platform to install the same binaries, but %s and %s differ", first.Filename, a.Filename)
+		}
+	}
+	strip := 0
+	if u := installSpec.Unpack; u != nil && u.StripComponents != nil {
+		strip = *u.StripComponents
+	}
+	var err error
+	if data.Commands, err = installCommands(first, strip); err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("stage").Parse(stageTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse dockerfile template: %w", err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render dockerfile: %w", err)
+	}
+	return nil
+}
+
+// assetKind returns how a is unpacked: "raw", "tar", "zip" or "gz", or ""
+// if the format is not supported.
+func assetKind(a checksums.PlatformAsset) string {
+	name := strings.ToLower(a.Filename)
+	switch {
+	case a.Raw:
+		return "raw"
+	case strings.Contains(name, ".tar") || strings.HasSuffix(name, ".tgz") || strings.HasSuffix(name, ".tbz") || strings.HasSuffix(name, ".txz"):
+		return "tar"
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".gz"):
+		return "gz"
+	}
+	return ""
+}
+
+// installCommands returns the shell commands installing the binaries of a,
+// downloaded to /tmp/asset, to /out.
+func installCommands(a checksums.PlatformAsset, strip int) ([]string, error) {
+	var commands []string
+	switch assetKind(a) {
+	case "raw", "gz":
+		if len(a.Binaries) == 0 {
+			return nil, fmt.Errorf("no binaries to install from %s", a.Filename)
+		}
+		b := a.Binaries[0]
+		if assetKind(a) == "gz" {
+			commands = append(commands, "gunzip -c /tmp/asset >/tmp/asset.bin", "install -m 755 /tmp/asset.bin /out/"+shellQuote(b.Name))
+		} else {
+			commands = append(commands, "install -m 755 /tmp/asset /out/"+shellQuote(b.Name))
+		}
+		return append(commands, aliasCommands(b)...), nil
+	case "tar":
+		commands = append(commands, "mkdir /tmp/x", "tar -xf /tmp/asset -C /tmp/x")
+	case "zip":
+		commands = append(commands, "mkdir /tmp/x", "unzip -q /tmp/asset -d /tmp/x")
+	default:
+		return nil, fmt.Errorf("dockerfile export does not support the format of %s", a.Filename)
+	}
+	// Stripped components are matched by a glob, as with the installer
+	// script the binary paths are relative to them.
+	prefix := "/tmp/x/" + strings.Repeat("*/", strip)
+	for _, b := range a.Binaries {
+		commands = append(commands, fmt.Sprintf("install -m 755 %s%s /out/%s", prefix, shellQuote(b.Path), shellQuote(b.Name)))
+		commands = append(commands, aliasCommands(b)...)
+	}
+	return commands, nil
+}
+
+func aliasCommands(b spec.Binary) []string {
+	var commands []string
+	for _, alias := range b.Aliases {
+		commands = append(commands, fmt.Sprintf("ln -s %s /out/%s", shellQuote(b.Name), shellQuote(alias)))
+	}
+	return commands
+}
+
+func equalBinary(a, b spec.Binary) bool {
+	return a.Name == b.Name && a.Path == b.Path && slices.Equal(a.Aliases, b.Aliases)
+}
+
+// shellQuote quotes s for the shell unless it only has safe characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./+@:") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
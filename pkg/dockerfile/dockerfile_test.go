@@ -0,0 +1,113 @@
+package dockerfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+var testSpec = &spec.InstallSpec{Name: "tool", Repo: "owner/tool"}
+
+func testAssets(ext string) []checksums.PlatformAsset {
+	binaries := []spec.Binary{{Name: "tool", Path: "bin/tool", Aliases: []string{"t"}}}
+	var assets []checksums.PlatformAsset
+	for _, p := range []spec.Platform{{OS: "linux", Arch: "amd64"}, {OS: "linux", Arch: "armv7"}, {OS: "darwin", Arch: "arm64"}} {
+		filename := "tool_" + p.OS + "_" + p.Arch + ext
+		assets = append(assets, checksums.PlatformAsset{
+			OS: p.OS, Arch: p.Arch, Filename: filename,
+			URL:  "https://github.com/owner/tool/releases/download/v1.0.0/" + filename,
+			Hash: p.OS + p.Arch, Algorithm: spec.SHA256, Raw: ext == "", Binaries: binaries,
+		})
+	}
+	return assets
+}
+
+func TestStage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Stage(&buf, testSpec, "v1.0.0", testAssets(".tar.gz")); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	want := `# Code generated by binst export. DO NOT EDIT.
+# tool v1.0.0 for the target platform, verified against its
+# embedded checksum. Copy it into your image with:
+#
+#   COPY --from=tool /out/ /usr/local/bin/
+FROM --platform=$BUILDPLATFORM alpine:3.20 AS tool
+ARG TARGETOS
+ARG TARGETARCH
+ARG TARGETVARIANT
+RUN set -eu; \
+    platform="${TARGETOS}/${TARGETARCH}"; \
+    if [ "${TARGETARCH}" = arm ]; then platform="${platform}${TARGETVARIANT}"; fi; \
+    case "${platform}" in \
+      linux/amd64) url=https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64.tar.gz; sum=linuxamd64 ;; \
+      linux/armv7) url=https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_armv7.tar.gz; sum=linuxarmv7 ;; \
+      *) echo "tool is not available for ${platform}" >&2; exit 1 ;; \
+    esac; \
+    wget -qO /tmp/asset "${url}"; \
+    echo "${sum}  /tmp/asset" | sha256sum -c -; \
+    mkdir /out; \
+    mkdir /tmp/x; \
+    tar -xf /tmp/asset -C /tmp/x; \
+    install -m 755 /tmp/x/bin/tool /out/tool; \
+    ln -s tool /out/t; \
+    rm -rf /tmp/asset /tmp/asset.bin /tmp/x
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Stage() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStageFormats(t *testing.T) {
+	strip := 1
+	stripSpec := &spec.InstallSpec{Name: "My Tool", Repo: "owner/tool", Unpack: &spec.UnpackConfig{StripComponents: &strip}}
+	tests := []struct {
+		name        string
+		installSpec *spec.InstallSpec
+		ext         string
+		want        []string
+	}{
+		{"raw", testSpec, "", []string{"    install -m 755 /tmp/asset /out/tool; \\\n"}},
+		{"gzip", testSpec, ".gz", []string{"    gunzip -c /tmp/asset >/tmp/asset.bin; \\\n", "    install -m 755 /tmp/asset.bin /out/tool; \\\n"}},
+		{"zip with strip_components", stripSpec, ".zip", []string{
+			"AS my-tool\n",
+			"    unzip -q /tmp/asset -d /tmp/x; \\\n",
+			"    install -m 755 /tmp/x/*/bin/tool /out/tool; \\\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Stage(&buf, tt.installSpec, "v1.0.0", testAssets(tt.ext)); err != nil {
+				t.Fatalf("Stage() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Stage() output does not contain %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestUnsupportedAssets(t *testing.T) {
+	assets := testAssets(".tar.gz")
+	assets[0].Algorithm = spec.MD5
+	if err := Stage(&bytes.Buffer{}, testSpec, "v1.0.0", assets); err == nil {
+		t.Error("Stage() error = nil, want error for md5 checksums")
+	}
+	assets = testAssets(".tar.gz")
+	assets[1].Filename = "tool_linux_armv7.zip"
+	if err := Stage(&bytes.Buffer{}, testSpec, "v1.0.0", assets); err == nil {
+		t.Error("Stage() error = nil, want error for mixed archive formats")
+	}
+	if err := Stage(&bytes.Buffer{}, testSpec, "v1.0.0", testAssets(".tar.gz")[2:]); err == nil {
+		t.Error("Stage() error = nil, want error without Linux assets")
+	}
+	if err := Stage(&bytes.Buffer{}, testSpec, "v1.0.0", testAssets(".7z")); err == nil {
+		t.Error("Stage() error = nil, want error for 7z archives")
+	}
+}
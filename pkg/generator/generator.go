@@ -0,0 +1,98 @@
+// Package generator defines the interface of installer script generators
+// and a registry of them by format, so that new script formats such as
+// PowerShell can be added as separate packages without changing binst gen.
+//
+// Generators register themselves in an init function, like database/sql
+// drivers:
+//
+//	func init() {
+//		generator.Register("ps1", Generator{})
+//	}
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+// ScriptGenerator generates installer scripts of one format.
+type ScriptGenerator interface {
+	// Generate returns the installer of installSpec.
+	Generate(installSpec *spec.InstallSpec, opts Options) ([]byte, error)
+	// Extension is the file extension of the installers, e.g. ".sh".
+	Extension() string
+}
+
+// MultiGenerator is implemented by generators that can combine the tools of
+// a multi-tool spec into one installer.
+type MultiGenerator interface {
+	GenerateMulti(tools []spec.InstallSpec, opts Options) ([]byte, error)
+}
+
+// Options are the generation options that are not part of an InstallSpec.
+type Options struct {
+	// Templates are user-supplied templates applied on top of the built-in
+	// template, for template-based generators. Generators without templates
+	// return an error if any are given.
+	Templates []Template
+	// Target restricts the installer to one platform, with platform
+	// detection pre-resolved and only the embedded checksums of its assets,
+	// e.g. for Dockerfiles and CI images.
+	Target *spec.Platform
+}
+
+// Template is a user-supplied installer template.
+type Template struct {
+	Name string // e.g. the file name, used in error messages
+	Text string
+}
+
+var (
+	mu         sync.RWMutex
+	generators = make(map[string]ScriptGenerator)
+)
+
+// Register makes a generator available for format. It panics if format is
+// already registered or g is nil.
+func Register(format string, g ScriptGenerator) {
+	mu.Lock()
+	defer mu.Unlock()
+	if g == nil {
+		panic("generator: Register generator is nil")
+	}
+	if _, dup := generators[format]; dup {
+		panic("generator: Register called twice for format " + format)
+	}
+	generators[format] = g
+}
+
+// Lookup returns the generator registered for format.
+func Lookup(format string) (ScriptGenerator, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	g, ok := generators[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown script format %q (supported: %s)", format, strings.Join(formats(), ", "))
+	}
+	return g, nil
+}
+
+// Formats returns the sorted registered formats.
+func Formats() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return formats()
+}
+
+func formats() []string {
+	list := make([]string, 0, len(generators))
+	for format := range generators {
+		list = append(list, format)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+type fakeGenerator struct{}
+
+func (fakeGenerator) Generate(installSpec *spec.InstallSpec, opts Options) ([]byte, error) {
+	return []byte("echo " + installSpec.Name), nil
+}
+
+func (fakeGenerator) Extension() string { return ".fake" }
+
+func TestRegister(t *testing.T) {
+	Register("test-fake", fakeGenerator{})
+	g, err := Lookup("test-fake")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if got, _ := g.Generate(&spec.InstallSpec{Name: "tool"}, Options{}); string(got) != "echo tool" {
+		t.Errorf("Generate() = %q, want %q", got, "echo tool")
+	}
+	if formats := Formats(); !strings.Contains(strings.Join(formats, ","), "test-fake") {
+		t.Errorf("Formats() = %v, want it to contain test-fake", formats)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() did not panic for a duplicate format")
+		}
+	}()
+	Register("test-fake", fakeGenerator{})
+}
+
+func TestLookupUnknown(t *testing.T) {
+	if _, err := Lookup("no-such-format"); err == nil || !strings.Contains(err.Error(), "no-such-format") {
+		t.Errorf("Lookup() error = %v, want unknown format error", err)
+	}
+}
@@ -0,0 +1,257 @@
+// Package homebrew renders Homebrew formulae and casks from an InstallSpec
+// and its embedded checksums, so that brew taps can be kept in sync with the
+// installer script.
+package homebrew
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+//go:embed formula.rb.tmpl
+var formulaTemplate string
+
+//go:embed cask.rb.tmpl
+var caskTemplate string
+
+// Options are the package metadata that is not part of an InstallSpec.
+type Options struct {
+	// Desc is the one-line description. It is omitted if empty.
+	Desc string
+	// License is the SPDX license identifier. It is omitted if empty.
+	License string
+	// Homepage defaults to the GitHub repository URL.
+	Homepage string
+}
+
+// homebrewOS maps OS names of the spec to Homebrew's on_<os> blocks.
+var homebrewOS = map[string]string{"darwin": "macos", "linux": "linux"}
+
+// homebrewCPU maps architectures of the spec to Homebrew's on_<cpu> blocks.
+// Other architectures are not supported by Homebrew.
+var homebrewCPU = map[string]string{"amd64": "intel", "arm64": "arm"}
+
+type formulaData struct {
+	ClassName string
+	Desc      string
+	Homepage  string
+	Version   string
+	License   string
+	OSes      []formulaOS
+	Installs  []formulaInstall
+	Binaries  []spec.Binary
+}
+
+type formulaOS struct {
+	Name   string
+	Assets []checksums.PlatformAsset
+}
+
+// formulaInstall is the body of the install method for the platforms
+// matched by Condition.
+type formulaInstall struct {
+	Condition string
+	Lines     []string
+}
+
+type caskData struct {
+	Token    string
+	Name     string
+	Desc     string
+	Homepage string
+	Version  string
+	Assets   []checksums.PlatformAsset
+}
+
+type caskBinary struct {
+	Source string
+	Target string
+}
+
+// Formula writes a formula installing the macOS and Linux assets of version
+// to w. Homebrew extracts archives into a staging directory and enters it if
+// it is the only top-level entry, so binary paths must be relative to that
+// directory, as with strip_components: 1.
+func Formula(w io.Writer, installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset, opts Options) error {
+	assets, err := supportedAssets(assets, "darwin", "linux")
+	if err != nil {
+		return err
+	}
+	data := formulaData{
+		ClassName: className(installSpec.Name),
+		Desc:      opts.Desc,
+		Homepage:  homepage(installSpec, opts),
+		Version:   installSpec.VersionFromTag(version),
+		License:   opts.License,
+		Binaries:  assets[0].Binaries,
+	}
+	for _, osName := range []string{"darwin", "linux"} {
+		o := formulaOS{Name: homebrewOS[osName]}
+		for _, a := range assets {
+			if a.OS == osName {
+				o.Assets = append(o.Assets, a)
+			}
+		}
+		if len(o.Assets) > 0 {
+			data.OSes = append(data.OSes, o)
+		}
+	}
+	if allEqual(assets) {
+		data.Installs = []formulaInstall{{Lines: installLines(assets[0])}}
+	} else {
+		// e.g. raw binaries, whose filenames differ by platform
+		for _, a := range assets {
+			data.Installs = append(data.Installs, formulaInstall{Condition: condition(a), Lines: installLines(a)})
+		}
+	}
+	return render(w, "formula", formulaTemplate, data)
+}
+
+// Cask writes a cask installing the macOS assets of version to w. Unlike
+// formulae, casks do not enter the top-level directory of archives, so
+// binary paths are used as they are.
+func Cask(w io.Writer, installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset, opts Options) error {
+	assets, err := supportedAssets(assets, "darwin")
+	if err != nil {
+		return err
+	}
+	return render(w, "cask", caskTemplate, caskData{
+		Token:    strings.ToLower(installSpec.Name),
+		Name:     installSpec.Name,
+		Desc:     opts.Desc,
+		Homepage: homepage(installSpec, opts),
+		Version:  installSpec.VersionFromTag(version),
+		Assets:   assets,
+	})
+}
+
+// supportedAssets returns the sha256 assets of assets for the given OSes and
+// the architectures Homebrew supports.
+func supportedAssets(assets []checksums.PlatformAsset, oses ...string) ([]checksums.PlatformAsset, error) {
+	var supported []checksums.PlatformAsset
+	for _, a := range assets {
+		a.OS, a.Arch = strings.ToLower(a.OS), strings.ToLower(a.Arch)
+		if !slices.Contains(oses, a.OS) || homebrewCPU[a.Arch] == "" {
+			continue
+		}
+		if a.Algorithm != spec.SHA256 {
+			return nil, fmt.Errorf("homebrew requires sha256 checksums, but %s has a %s checksum", a.Filename, a.Algorithm)
+		}
+		supported = append(supported, a)
+	}
+	if len(supported) == 0 {
+		return nil, fmt.Errorf("no embedded checksums for %s on amd64 or arm64", strings.Join(oses, " or "))
+	}
+	return supported, nil
+}
+
+// installLines returns the statements of the install method for a.
+func installLines(a checksums.PlatformAsset) []string {
+	var lines []string
+	for i, b := range a.Binaries {
+		src := b.Path
+		if a.Raw {
+			// The asset is the executable itself
+			if i > 0 {
+				break
+			}
+			src = a.Filename
+		}
+		lines = append(lines, fmt.Sprintf("bin.install %s => %s", rubyString(src), rubyString(b.Name)))
+		for _, alias := range b.Aliases {
+			lines = append(lines, fmt.Sprintf("bin.install_symlink %s => %s", rubyString(b.Name), rubyString(alias)))
+		}
+	}
+	return lines
+}
+
+// allEqual reports whether every asset is installed the same way.
+func allEqual(assets []checksums.PlatformAsset) bool {
+	for _, a := range assets[1:] {
+		if !slices.Equal(installLines(a), installLines(assets[0])) {
+			return false
+		}
+	}
+	return true
+}
+
+// condition returns the Ruby condition matching the platform of a.
+func condition(a checksums.PlatformAsset) string {
+	osCheck := "OS.mac?"
+	if a.OS == "linux" {
+		osCheck = "OS.linux?"
+	}
+	return fmt.Sprintf("%s && Hardware::CPU.%s?", osCheck, homebrewCPU[a.Arch])
+}
+
+func caskBinaries(a checksums.PlatformAsset) []caskBinary {
+	var binaries []caskBinary
+	for i, b := range a.Binaries {
+		src := b.Path
+		if a.Raw {
+			// The asset is the executable itself
+			if i > 0 {
+				break
+			}
+			src = a.Filename
+		}
+		for _, target := range append([]string{b.Name}, b.Aliases...) {
+			binaries = append(binaries, caskBinary{Source: src, Target: target})
+		}
+	}
+	return binaries
+}
+
+func homepage(installSpec *spec.InstallSpec, opts Options) string {
+	if opts.Homepage != "" {
+		return opts.Homepage
+	}
+	return installSpec.GitHubBase() + "/" + installSpec.Repo
+}
+
+func render(w io.Writer, name, text string, data any) error {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"ruby":         rubyString,
+		"cpu":          func(arch string) string { return homebrewCPU[arch] },
+		"caskBinaries": caskBinaries,
+	}).Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return nil
+}
+
+// rubyString quotes s as a Ruby string literal without interpolation.
+func rubyString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `#`, `\#`).Replace(s) + `"`
+}
+
+var (
+	classNameSeparator = regexp.MustCompile(`[-_.\s]([a-zA-Z0-9])`)
+	classNameVersion   = regexp.MustCompile(`(.)@(\d)`)
+)
+
+// className converts a formula name to its class name the way Homebrew
+// does, e.g. "golangci-lint" to "GolangciLint".
+func className(name string) string {
+	s := strings.ToLower(name)
+	if s != "" {
+		s = strings.ToUpper(s[:1]) + s[1:]
+	}
+	s = classNameSeparator.ReplaceAllStringFunc(s, func(m string) string {
+		return strings.ToUpper(m[1:])
+	})
+	s = strings.ReplaceAll(s, "+", "x")
+	return classNameVersion.ReplaceAllString(s, "${1}AT${2}")
+}
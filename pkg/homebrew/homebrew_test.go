@@ -0,0 +1,141 @@
+package homebrew
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+var testSpec = &spec.InstallSpec{Name: "tool", Repo: "owner/tool"}
+
+func testAssets(raw bool) []checksums.PlatformAsset {
+	binaries := []spec.Binary{{Name: "tool", Path: "bin/tool", Aliases: []string{"t"}}}
+	var assets []checksums.PlatformAsset
+	for _, p := range []spec.Platform{{OS: "darwin", Arch: "arm64"}, {OS: "linux", Arch: "amd64"}, {OS: "linux", Arch: "386"}, {OS: "windows", Arch: "amd64"}} {
+		filename := "tool_" + p.OS + "_" + p.Arch
+		if !raw {
+			filename += ".tar.gz"
+		}
+		assets = append(assets, checksums.PlatformAsset{
+			OS: p.OS, Arch: p.Arch, Filename: filename,
+			URL:  "https://github.com/owner/tool/releases/download/v1.0.0/" + filename,
+			Hash: p.OS + p.Arch, Algorithm: spec.SHA256, Raw: raw, Binaries: binaries,
+		})
+	}
+	return assets
+}
+
+func TestFormula(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Formula(&buf, testSpec, "v1.0.0", testAssets(false), Options{Desc: `Say "hi" #{x}`, License: "MIT"}); err != nil {
+		t.Fatalf("Formula() error = %v", err)
+	}
+	want := `# Code generated by binst export. DO NOT EDIT.
+class Tool < Formula
+  desc "Say \"hi\" \#{x}"
+  homepage "https://github.com/owner/tool"
+  version "1.0.0"
+  license "MIT"
+
+  on_macos do
+    on_arm do
+      url "https://github.com/owner/tool/releases/download/v1.0.0/tool_darwin_arm64.tar.gz"
+      sha256 "darwinarm64"
+    end
+  end
+
+  on_linux do
+    on_intel do
+      url "https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64.tar.gz"
+      sha256 "linuxamd64"
+    end
+  end
+
+  def install
+    bin.install "bin/tool" => "tool"
+    bin.install_symlink "tool" => "t"
+  end
+
+  test do
+    assert_predicate bin/"tool", :executable?
+  end
+end
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Formula() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormulaRawBinaries(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Formula(&buf, testSpec, "v1.0.0", testAssets(true), Options{}); err != nil {
+		t.Fatalf("Formula() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"    if OS.mac? && Hardware::CPU.arm?\n      bin.install \"tool_darwin_arm64\" => \"tool\"\n",
+		"    elsif OS.linux? && Hardware::CPU.intel?\n      bin.install \"tool_linux_amd64\" => \"tool\"\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Formula() output does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "desc ") {
+		t.Errorf("Formula() output contains an empty desc:\n%s", got)
+	}
+}
+
+func TestCask(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Cask(&buf, testSpec, "v1.0.0", testAssets(false), Options{Homepage: "https://tool.example.com"}); err != nil {
+		t.Fatalf("Cask() error = %v", err)
+	}
+	want := `# Code generated by binst export. DO NOT EDIT.
+cask "tool" do
+  version "1.0.0"
+
+  on_arm do
+    url "https://github.com/owner/tool/releases/download/v1.0.0/tool_darwin_arm64.tar.gz"
+    sha256 "darwinarm64"
+    binary "bin/tool", target: "tool"
+    binary "bin/tool", target: "t"
+  end
+
+  name "tool"
+  homepage "https://tool.example.com"
+end
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Cask() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnsupportedAssets(t *testing.T) {
+	assets := testAssets(false)
+	assets[0].Algorithm = spec.SHA512
+	if err := Formula(&bytes.Buffer{}, testSpec, "v1.0.0", assets, Options{}); err == nil {
+		t.Error("Formula() error = nil, want error for sha512 checksums")
+	}
+	if err := Cask(&bytes.Buffer{}, testSpec, "v1.0.0", testAssets(false)[1:], Options{}); err == nil {
+		t.Error("Cask() error = nil, want error without macOS assets")
+	}
+}
+
+func TestClassName(t *testing.T) {
+	for name, want := range map[string]string{
+		"tool":          "Tool",
+		"golangci-lint": "GolangciLint",
+		"dua-cli":       "DuaCli",
+		"slsa_verifier": "SlsaVerifier",
+		"GH":            "Gh",
+		"c++tool":       "Cxxtool",
+		"node@18":       "NodeAT18",
+	} {
+		if got := className(name); got != want {
+			t.Errorf("className(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
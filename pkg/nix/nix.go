@@ -0,0 +1,199 @@
+// Package nix renders Nix derivations from an InstallSpec and its embedded
+// checksums, so that Nix users get a ready-made package for the prebuilt
+// release assets.
+package nix
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+//go:embed derivation.nix.tmpl
+var derivationTemplate string
+
+// Options are the package metadata that is not part of an InstallSpec.
+type Options struct {
+	// Desc is the one-line description. It is omitted if empty.
+	Desc string
+	// License is the SPDX license identifier. It is omitted if empty.
+	License string
+	// Homepage defaults to the GitHub repository URL.
+	Homepage string
+}
+
+// nixSystem maps platforms of the spec to Nix system doubles. Other
+// platforms are not supported by nixpkgs.
+var nixSystem = map[string]string{
+	"linux/amd64":  "x86_64-linux",
+	"linux/arm64":  "aarch64-linux",
+	"linux/386":    "i686-linux",
+	"linux/armv7":  "armv7l-linux",
+	"darwin/amd64": "x86_64-darwin",
+	"darwin/arm64": "aarch64-darwin",
+}
+
+type derivationData struct {
+	Name     string
+	Version  string
+	Desc     string
+	Homepage string
+	License  string
+	Sources  []source
+	// Unzip adds unzip to the arguments of the derivation.
+	Unzip bool
+	// Attributes are the attributes controlling the unpack phase.
+	Attributes []string
+	Install    []string
+	Binaries   []spec.Binary
+}
+
+type source struct {
+	System string
+	URL    string
+	Hash   string
+}
+
+// Derivation writes a derivation installing the Linux and macOS assets of
+// version to w, to be used with callPackage. Every platform must install the
+// same binaries from the same kind of asset. Archives are unpacked by the
+// stdenv, which enters the top-level directory as with strip_components: 1.
+func Derivation(w io.Writer, installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset, opts Options) error {
+	data := derivationData{
+		Name:     installSpec.Name,
+		Version:  installSpec.VersionFromTag(version),
+		Desc:     opts.Desc,
+		Homepage: opts.Homepage,
+		License:  opts.License,
+	}
+	if data.Homepage == "" {
+		data.Homepage = installSpec.GitHubBase() + "/" + installSpec.Repo
+	}
+	var supported []checksums.PlatformAsset
+	for _, a := range assets {
+		system := nixSystem[strings.ToLower(a.OS)+"/"+strings.ToLower(a.Arch)]
+		if system == "" {
+			continue
+		}
+		hash, err := sriHash(a)
+		if err != nil {
+			return err
+		}
+		data.Sources = append(data.Sources, source{System: system, URL: a.URL, Hash: hash})
+		supported = append(supported, a)
+	}
+	if len(supported) == 0 {
+		return fmt.Errorf("no embedded checksums for Linux or macOS")
+	}
+	first := supported[0]
+	for _, a := range supported[1:] {
+		if a.Raw != first.Raw || !slices.EqualFunc(a.Binaries, first.Binaries, equalBinary) {
+			return fmt.Errorf("nix export requires every platform to install the same binaries, but %s and %s differ", first.Filename, a.Filename)
+		}
+	}
+	data.Binaries = first.Binaries
+	if first.Raw {
+		data.Attributes = append(data.Attributes, "dontUnpack = true;")
+	} else {
+		for _, a := range supported {
+			if strings.HasSuffix(strings.ToLower(a.Filename), ".zip") {
+				data.Unzip = true
+			}
+		}
+		if data.Unzip {
+			data.Attributes = append(data.Attributes, "nativeBuildInputs = [ unzip ];")
+		}
+		strip := 0
+		if u := installSpec.Unpack; u != nil && u.StripComponents != nil {
+			strip = *u.StripComponents
+		}
+		if strip > 1 {
+			return fmt.Errorf("unpack.strip_components: %d is not supported by nix export", strip)
+		}
+		if strip == 0 {
+			data.Attributes = append(data.Attributes, `sourceRoot = ".";`)
+		}
+	}
+	data.Install = installLines(first.Raw, data.Binaries)
+	return render(w, data)
+}
+
+// installLines returns the installPhase commands installing binaries.
+func installLines(raw bool, binaries []spec.Binary) []string {
+	var lines []string
+	for i, b := range binaries {
+		src := shellQuote(b.Path)
+		if raw {
+			// The asset is the executable itself
+			if i > 0 {
+				break
+			}
+			src = "$src"
+		}
+		lines = append(lines, fmt.Sprintf("install -Dm755 %s $out/bin/%s", src, shellQuote(b.Name)))
+		for _, alias := range b.Aliases {
+			lines = append(lines, fmt.Sprintf("ln -s %s $out/bin/%s", shellQuote(b.Name), shellQuote(alias)))
+		}
+	}
+	return lines
+}
+
+func equalBinary(a, b spec.Binary) bool {
+	return a.Name == b.Name && a.Path == b.Path && slices.Equal(a.Aliases, b.Aliases)
+}
+
+// sriHash returns the checksum of a as a Subresource Integrity hash, the
+// format of the hash attribute of fetchurl.
+func sriHash(a checksums.PlatformAsset) (string, error) {
+	switch a.Algorithm {
+	case spec.SHA256, spec.SHA512:
+	default:
+		return "", fmt.Errorf("nix export requires sha256 or sha512 checksums, but %s has a %s checksum", a.Filename, a.Algorithm)
+	}
+	sum, err := hex.DecodeString(a.Hash)
+	if err != nil {
+		return "", fmt.Errorf("invalid checksum of %s: %w", a.Filename, err)
+	}
+	return string(a.Algorithm) + "-" + base64.StdEncoding.EncodeToString(sum), nil
+}
+
+func render(w io.Writer, data derivationData) error {
+	tmpl, err := template.New("derivation").Funcs(template.FuncMap{
+		"nix":      nixString,
+		"indented": indentedString,
+	}).Parse(derivationTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse derivation template: %w", err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render derivation: %w", err)
+	}
+	return nil
+}
+
+// nixString quotes s as a Nix string literal without interpolation.
+func nixString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `${`, `\${`).Replace(s) + `"`
+}
+
+// indentedString escapes s for use in a Nix indented string, the multi-line
+// string literal delimited by two single quotes.
+func indentedString(s string) string {
+	return strings.NewReplacer(`''`, `'''`, `${`, `''${`).Replace(s)
+}
+
+// shellQuote quotes s for the shell unless it only has safe characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./+@") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,138 @@
+package nix
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+var testSpec = &spec.InstallSpec{Name: "tool", Repo: "owner/tool"}
+
+// sha256 of the empty string
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func testAssets(ext string) []checksums.PlatformAsset {
+	binaries := []spec.Binary{{Name: "tool", Path: "bin/tool", Aliases: []string{"t"}}}
+	var assets []checksums.PlatformAsset
+	for _, p := range []spec.Platform{{OS: "linux", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}, {OS: "windows", Arch: "amd64"}} {
+		filename := "tool_" + p.OS + "_" + p.Arch + ext
+		assets = append(assets, checksums.PlatformAsset{
+			OS: p.OS, Arch: p.Arch, Filename: filename,
+			URL:  "https://github.com/owner/tool/releases/download/v1.0.0/" + filename,
+			Hash: emptySHA256, Algorithm: spec.SHA256, Raw: ext == "", Binaries: binaries,
+		})
+	}
+	return assets
+}
+
+func TestDerivation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Derivation(&buf, testSpec, "v1.0.0", testAssets(".tar.gz"), Options{Desc: `Say "hi" ${x}`, License: "MIT"}); err != nil {
+		t.Fatalf("Derivation() error = %v", err)
+	}
+	want := `# Code generated by binst export. DO NOT EDIT.
+{
+  lib,
+  stdenvNoCC,
+  fetchurl,
+}:
+
+let
+  sources = {
+    x86_64-linux = fetchurl {
+      url = "https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64.tar.gz";
+      hash = "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=";
+    };
+    aarch64-darwin = fetchurl {
+      url = "https://github.com/owner/tool/releases/download/v1.0.0/tool_darwin_arm64.tar.gz";
+      hash = "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=";
+    };
+  };
+in
+stdenvNoCC.mkDerivation {
+  pname = "tool";
+  version = "1.0.0";
+
+  src =
+    sources.${stdenvNoCC.hostPlatform.system}
+      or (throw "unsupported system: ${stdenvNoCC.hostPlatform.system}");
+
+  sourceRoot = ".";
+
+  installPhase = ''
+    runHook preInstall
+    install -Dm755 bin/tool $out/bin/tool
+    ln -s tool $out/bin/t
+    runHook postInstall
+  '';
+
+  meta = {
+    description = "Say \"hi\" \${x}";
+    homepage = "https://github.com/owner/tool";
+    license = lib.getLicenseFromSpdxId "MIT";
+    platforms = builtins.attrNames sources;
+    sourceProvenance = [ lib.sourceTypes.binaryNativeCode ];
+    mainProgram = "tool";
+  };
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Derivation() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDerivationRawBinaries(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Derivation(&buf, testSpec, "v1.0.0", testAssets(""), Options{}); err != nil {
+		t.Fatalf("Derivation() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"  dontUnpack = true;\n",
+		"    install -Dm755 $src $out/bin/tool\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Derivation() output does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "description") || strings.Contains(got, "sourceRoot") {
+		t.Errorf("Derivation() output contains an empty description or a sourceRoot:\n%s", got)
+	}
+}
+
+func TestDerivationZip(t *testing.T) {
+	strip := 1
+	installSpec := &spec.InstallSpec{Name: "tool", Repo: "owner/tool", Unpack: &spec.UnpackConfig{StripComponents: &strip}}
+	var buf bytes.Buffer
+	if err := Derivation(&buf, installSpec, "v1.0.0", testAssets(".zip"), Options{}); err != nil {
+		t.Fatalf("Derivation() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{"  unzip,\n", "  nativeBuildInputs = [ unzip ];\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Derivation() output does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "sourceRoot") {
+		t.Errorf("Derivation() output contains a sourceRoot with strip_components: 1:\n%s", got)
+	}
+}
+
+func TestUnsupportedAssets(t *testing.T) {
+	assets := testAssets(".tar.gz")
+	assets[0].Algorithm = spec.MD5
+	if err := Derivation(&bytes.Buffer{}, testSpec, "v1.0.0", assets, Options{}); err == nil {
+		t.Error("Derivation() error = nil, want error for md5 checksums")
+	}
+	assets = testAssets(".tar.gz")
+	assets[1].Raw = true
+	if err := Derivation(&bytes.Buffer{}, testSpec, "v1.0.0", assets, Options{}); err == nil {
+		t.Error("Derivation() error = nil, want error for mixed raw and archive assets")
+	}
+	if err := Derivation(&bytes.Buffer{}, testSpec, "v1.0.0", testAssets(".zip")[2:], Options{}); err == nil {
+		t.Error("Derivation() error = nil, want error without Linux or macOS assets")
+	}
+}
@@ -0,0 +1,196 @@
+// Package npm renders an npm package wrapping the installer script generated
+// from an InstallSpec, so that Node.js users can install the binaries with
+// "npm install -g".
+package npm
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"golang.org/x/mod/semver"
+)
+
+var (
+	//go:embed postinstall.js.tmpl
+	postinstallTemplate string
+	//go:embed bin.js.tmpl
+	binTemplate string
+)
+
+// Options are the package metadata that is not part of an InstallSpec.
+type Options struct {
+	// PackageName defaults to the name of the spec, e.g. "tool" or
+	// "@scope/tool".
+	PackageName string
+	// Desc is the one-line description. It is omitted if empty.
+	Desc string
+	// License is the SPDX license identifier. It is omitted if empty.
+	License string
+	// Homepage defaults to the GitHub repository URL.
+	Homepage string
+	// Script is the installer script generated from the spec, which the
+	// postinstall script runs to install the binaries.
+	Script []byte
+}
+
+// File is one file of the package.
+type File struct {
+	// Path is the slash-separated path relative to the package root.
+	Path    string
+	Content []byte
+}
+
+// nodeOS and nodeCPU map platforms of the spec to the values of
+// process.platform and process.arch used by the os and cpu fields of
+// package.json. Other platforms are not supported by Node.js.
+var (
+	nodeOS = map[string]string{
+		"linux": "linux", "darwin": "darwin", "windows": "win32", "freebsd": "freebsd",
+		"openbsd": "openbsd", "android": "android", "aix": "aix", "solaris": "sunos", "illumos": "sunos",
+	}
+	nodeCPU = map[string]string{
+		"amd64": "x64", "arm64": "arm64", "386": "ia32", "arm": "arm", "armv6": "arm", "armv7": "arm",
+		"ppc64": "ppc64", "ppc64le": "ppc64", "s390x": "s390x", "riscv64": "riscv64", "loong64": "loong64",
+		"mips": "mips", "mipsle": "mipsel",
+	}
+)
+
+// packageNameRe matches valid npm package names.
+var packageNameRe = regexp.MustCompile(`^(@[a-z0-9-~][a-z0-9-._~]*/)?[a-z0-9-~][a-z0-9-._~]*$`)
+
+type packageJSON struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Description string            `json:"description,omitempty"`
+	License     string            `json:"license,omitempty"`
+	Homepage    string            `json:"homepage"`
+	Repository  repository        `json:"repository"`
+	Bin         map[string]string `json:"bin"`
+	Scripts     map[string]string `json:"scripts"`
+	Files       []string          `json:"files"`
+	OS          []string          `json:"os,omitempty"`
+	CPU         []string          `json:"cpu,omitempty"`
+}
+
+type repository struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Package returns the files of the npm package of version: package.json, the
+// installer script, a postinstall script running it to install the binaries
+// to vendor/, and a bin/<name>.js shim for each binary. The os and cpu
+// fields list the platforms of the assets, unless the script can fall back
+// to go install.
+func Package(installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset, opts Options) ([]File, error) {
+	if len(opts.Script) == 0 {
+		return nil, fmt.Errorf("npm package requires the installer script")
+	}
+	if opts.PackageName == "" {
+		opts.PackageName = installSpec.Name
+	}
+	if !packageNameRe.MatchString(opts.PackageName) {
+		return nil, fmt.Errorf("invalid npm package name %q: use --package-identifier to set a lowercase name", opts.PackageName)
+	}
+	if opts.Homepage == "" {
+		opts.Homepage = installSpec.GitHubBase() + "/" + installSpec.Repo
+	}
+	packageVersion := installSpec.VersionFromTag(version)
+	// npm requires full MAJOR.MINOR.PATCH versions, which are canonical
+	// semver apart from build metadata
+	if v := "v" + packageVersion; !semver.IsValid(v) || semver.Canonical(v) != strings.SplitN(v, "+", 2)[0] {
+		return nil, fmt.Errorf("version %s is not a valid npm version", packageVersion)
+	}
+	goInstall := installSpec.Install != nil && installSpec.Install.GoModule != ""
+
+	pkg := packageJSON{
+		Name:        opts.PackageName,
+		Version:     packageVersion,
+		Description: opts.Desc,
+		License:     opts.License,
+		Homepage:    opts.Homepage,
+		Repository:  repository{Type: "git", URL: "git+" + installSpec.GitHubBase() + "/" + installSpec.Repo + ".git"},
+		Bin:         make(map[string]string),
+		Scripts:     map[string]string{"postinstall": "node postinstall.js"},
+		Files:       []string{"bin/", "install.sh", "postinstall.js"},
+	}
+	var binaries []spec.Binary
+	for _, a := range assets {
+		if !goInstall {
+			if nos := nodeOS[strings.ToLower(a.OS)]; nos != "" && !slices.Contains(pkg.OS, nos) {
+				pkg.OS = append(pkg.OS, nos)
+			}
+			if cpu := nodeCPU[strings.ToLower(a.Arch)]; cpu != "" && !slices.Contains(pkg.CPU, cpu) {
+				pkg.CPU = append(pkg.CPU, cpu)
+			}
+		}
+		for _, b := range a.Binaries {
+			if !slices.ContainsFunc(binaries, func(x spec.Binary) bool { return x.Name == b.Name }) {
+				binaries = append(binaries, b)
+			}
+		}
+	}
+	slices.Sort(pkg.OS)
+	slices.Sort(pkg.CPU)
+	if len(binaries) == 0 {
+		return nil, fmt.Errorf("no binaries to install")
+	}
+
+	funcs := template.FuncMap{"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}}
+	render := func(name, text string, data any) ([]byte, error) {
+		t, err := template.New(name).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	postinstall, err := render("postinstall.js", postinstallTemplate, map[string]any{
+		"Name":      installSpec.Name,
+		"Tag":       version,
+		"GoInstall": goInstall,
+	})
+	if err != nil {
+		return nil, err
+	}
+	files := []File{
+		{Path: "install.sh", Content: opts.Script},
+		{Path: "postinstall.js", Content: postinstall},
+	}
+	for _, b := range binaries {
+		shim := "bin/" + b.Name + ".js"
+		content, err := render(shim, binTemplate, map[string]any{
+			"Name":        b.Name,
+			"WindowsName": spec.ExecutableName("windows", b.Name),
+		})
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Path: shim, Content: content})
+		for _, name := range append([]string{b.Name}, b.Aliases...) {
+			pkg.Bin[name] = shim
+		}
+	}
+
+	data, err := json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode package.json: %w", err)
+	}
+	files = append([]File{{Path: "package.json", Content: append(data, '\n')}}, files...)
+	return files, nil
+}
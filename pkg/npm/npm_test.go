@@ -0,0 +1,122 @@
+package npm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+var testSpec = &spec.InstallSpec{Name: "tool", Repo: "owner/tool"}
+
+func testAssets() []checksums.PlatformAsset {
+	binaries := []spec.Binary{{Name: "tool", Path: "bin/tool", Aliases: []string{"t"}}}
+	return []checksums.PlatformAsset{
+		{OS: "linux", Arch: "amd64", Filename: "tool_linux_amd64.tar.gz", Binaries: binaries},
+		{OS: "linux", Arch: "arm64", Filename: "tool_linux_arm64.tar.gz", Binaries: binaries},
+		{OS: "darwin", Arch: "arm64", Filename: "tool_darwin_arm64.tar.gz", Binaries: binaries},
+		{OS: "windows", Arch: "amd64", Filename: "tool_windows_amd64.zip", Binaries: binaries},
+		{OS: "plan9", Arch: "amd64", Filename: "tool_plan9_amd64.tar.gz", Binaries: binaries},
+	}
+}
+
+func TestPackage(t *testing.T) {
+	files, err := Package(testSpec, "v1.2.3", testAssets(), Options{License: "MIT", Script: []byte("#!/bin/sh\n")})
+	if err != nil {
+		t.Fatalf("Package() error = %v", err)
+	}
+	got := make(map[string]string)
+	var paths []string
+	for _, f := range files {
+		got[f.Path] = string(f.Content)
+		paths = append(paths, f.Path)
+	}
+	if want := "package.json install.sh postinstall.js bin/tool.js"; strings.Join(paths, " ") != want {
+		t.Errorf("Package() files = %v, want %s", paths, want)
+	}
+
+	wantPackageJSON := `{
+  "name": "tool",
+  "version": "1.2.3",
+  "license": "MIT",
+  "homepage": "https://github.com/owner/tool",
+  "repository": {
+    "type": "git",
+    "url": "git+https://github.com/owner/tool.git"
+  },
+  "bin": {
+    "t": "bin/tool.js",
+    "tool": "bin/tool.js"
+  },
+  "scripts": {
+    "postinstall": "node postinstall.js"
+  },
+  "files": [
+    "bin/",
+    "install.sh",
+    "postinstall.js"
+  ],
+  "os": [
+    "darwin",
+    "linux",
+    "win32"
+  ],
+  "cpu": [
+    "arm64",
+    "x64"
+  ]
+}
+`
+	if got["package.json"] != wantPackageJSON {
+		t.Errorf("package.json mismatch\ngot:\n%s\nwant:\n%s", got["package.json"], wantPackageJSON)
+	}
+	if got["install.sh"] != "#!/bin/sh\n" {
+		t.Errorf("install.sh = %q", got["install.sh"])
+	}
+	if want := `const args = ["install.sh", "-b", "vendor", "v1.2.3"];`; !strings.Contains(got["postinstall.js"], want) {
+		t.Errorf("postinstall.js = %s\nwant to contain %s", got["postinstall.js"], want)
+	}
+	if want := `const exe = process.platform === "win32" ? "tool.exe" : "tool";`; !strings.Contains(got["bin/tool.js"], want) {
+		t.Errorf("bin/tool.js = %s\nwant to contain %s", got["bin/tool.js"], want)
+	}
+}
+
+func TestPackageGoInstall(t *testing.T) {
+	s := &spec.InstallSpec{Name: "tool", Repo: "owner/tool", Install: &spec.InstallConfig{GoModule: "github.com/owner/tool"}}
+	files, err := Package(s, "v1.2.3", testAssets(), Options{PackageName: "@owner/tool", Script: []byte("#!/bin/sh\n")})
+	if err != nil {
+		t.Fatalf("Package() error = %v", err)
+	}
+	packageJSON := string(files[0].Content)
+	if !strings.Contains(packageJSON, `"name": "@owner/tool"`) {
+		t.Errorf("package.json = %s\nwant the scoped package name", packageJSON)
+	}
+	if strings.Contains(packageJSON, `"os"`) || strings.Contains(packageJSON, `"cpu"`) {
+		t.Errorf("package.json = %s\nwant no platform restriction with go install", packageJSON)
+	}
+	if want := `"vendor", "--go-install", "v1.2.3"]`; !strings.Contains(string(files[2].Content), want) {
+		t.Errorf("postinstall.js = %s\nwant to contain %s", files[2].Content, want)
+	}
+}
+
+func TestPackageErrors(t *testing.T) {
+	script := []byte("#!/bin/sh\n")
+	tests := []struct {
+		name    string
+		version string
+		opts    Options
+	}{
+		{"no script", "v1.2.3", Options{}},
+		{"invalid name", "v1.2.3", Options{PackageName: "Tool", Script: script}},
+		{"partial version", "v1.2", Options{Script: script}},
+		{"non-semver version", "nightly", Options{Script: script}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Package(testSpec, tt.version, testAssets(), tt.opts); err == nil {
+				t.Error("Package() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// GHPages commits files to a branch (usually gh-pages) of the git repository
+// in the current working directory and pushes it to the remote.
+type GHPages struct {
+	Remote  string // Default: origin
+	Branch  string // Default: gh-pages
+	Message string // Commit message
+}
+
+// Publish writes files (keyed by path relative to the branch root) to the
+// branch, commits them and pushes. Nothing is committed if the files are
+// unchanged.
+func (g *GHPages) Publish(ctx context.Context, files map[string][]byte) error {
+	remote := g.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+	branch := g.Branch
+	if branch == "" {
+		branch = "gh-pages"
+	}
+
+	worktree, err := os.MkdirTemp("", "binstaller-gh-pages")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(worktree)
+
+	// Check out the existing branch, or start an orphan branch if it does not exist yet.
+	exists := runGit(ctx, "", "fetch", remote, branch) == nil
+	args := []string{"worktree", "add", "--detach", worktree}
+	if exists {
+		args = append(args, "FETCH_HEAD")
+	}
+	if err := runGit(ctx, "", args...); err != nil {
+		return err
+	}
+	defer runGit(context.Background(), "", "worktree", "remove", "--force", worktree)
+	if !exists {
+		log.Infof("Branch %s not found on %s, creating it", branch, remote)
+		if err := runGit(ctx, worktree, "checkout", "--orphan", branch); err != nil {
+			return err
+		}
+		if err := runGit(ctx, worktree, "rm", "-rfq", "--ignore-unmatch", "."); err != nil {
+			return err
+		}
+	}
+
+	for name, data := range files {
+		dst := filepath.Join(worktree, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(dst, data, 0755); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if err := runGit(ctx, worktree, "add", "--", name); err != nil {
+			return err
+		}
+	}
+
+	if err := runGit(ctx, worktree, "diff", "--cached", "--quiet"); err == nil {
+		log.Infof("No changes to publish on %s", branch)
+		return nil
+	}
+	msg := g.Message
+	if msg == "" {
+		msg = "Update installer script"
+	}
+	if err := runGit(ctx, worktree, "commit", "-q", "-m", msg); err != nil {
+		return err
+	}
+	log.Infof("Pushing to %s %s", remote, branch)
+	return runGit(ctx, worktree, "push", remote, "HEAD:refs/heads/"+branch)
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	log.Debugf("git %s", strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package publish
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupGHPagesRepo creates a git repository with a bare "origin" remote and
+// changes the working directory to it. It returns the remote path.
+func setupGHPagesRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	local := filepath.Join(dir, "local")
+	git(t, dir, "init", "-q", "--bare", remote)
+	git(t, dir, "init", "-q", local)
+	git(t, local, "commit", "-q", "--allow-empty", "-m", "init")
+	git(t, local, "remote", "add", "origin", remote)
+	t.Chdir(local)
+	return remote
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// assertNoWorktree fails if Publish left a worktree behind.
+func assertNoWorktree(t *testing.T) {
+	t.Helper()
+	if got := git(t, ".", "worktree", "list", "--porcelain"); strings.Count(got, "worktree ") != 1 {
+		t.Errorf("git worktree list = %q, want only the main worktree", got)
+	}
+}
+
+func TestGHPages_Publish(t *testing.T) {
+	remote := setupGHPagesRepo(t)
+	ctx := context.Background()
+	g := &GHPages{Message: "Publish installer"}
+
+	// The first publish creates the branch.
+	if err := g.Publish(ctx, map[string][]byte{"install.sh": []byte("v1\n")}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	assertNoWorktree(t)
+	if got := git(t, remote, "show", "gh-pages:install.sh"); got != "v1" {
+		t.Errorf("install.sh = %q, want v1", got)
+	}
+	if got := git(t, remote, "log", "-1", "--format=%s", "gh-pages"); got != "Publish installer" {
+		t.Errorf("commit message = %q", got)
+	}
+	first := git(t, remote, "rev-parse", "gh-pages")
+
+	// Unchanged files do not create a commit.
+	if err := g.Publish(ctx, map[string][]byte{"install.sh": []byte("v1\n")}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if got := git(t, remote, "rev-parse", "gh-pages"); got != first {
+		t.Errorf("gh-pages moved to %s on unchanged files", got)
+	}
+
+	// Later publishes build on the existing branch.
+	if err := g.Publish(ctx, map[string][]byte{"tools/tool.sh": []byte("tool\n")}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	assertNoWorktree(t)
+	if got := git(t, remote, "rev-parse", "gh-pages~1"); got != first {
+		t.Errorf("gh-pages~1 = %s, want %s", got, first)
+	}
+	if got := git(t, remote, "ls-tree", "-r", "--name-only", "gh-pages"); got != "install.sh\ntools/tool.sh" {
+		t.Errorf("gh-pages files = %q", got)
+	}
+}
+
+func TestGHPages_PublishRemovesWorktreeOnError(t *testing.T) {
+	setupGHPagesRepo(t)
+	// The branch does not exist and cannot be created with an invalid name.
+	g := &GHPages{Branch: "bad..name"}
+	if err := g.Publish(context.Background(), map[string][]byte{"install.sh": []byte("v1\n")}); err == nil {
+		t.Fatal("Publish() error = nil, want error for invalid branch name")
+	}
+	assertNoWorktree(t)
+}
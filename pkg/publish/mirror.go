@@ -0,0 +1,97 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// Bucket is an object storage location release files are mirrored to: an
+// s3:// or gs:// URL, or a local directory (optionally as a file:// URL),
+// e.g. one served by a web server.
+type Bucket struct {
+	URL string
+}
+
+// Upload copies the files under dir to the bucket, keeping their paths
+// relative to dir. S3 and GCS uploads use the aws and gcloud CLIs, so their
+// credentials and configuration apply.
+func (b *Bucket) Upload(ctx context.Context, dir string) error {
+	u, err := url.Parse(b.URL)
+	if err != nil {
+		return fmt.Errorf("invalid bucket URL %s: %w", b.URL, err)
+	}
+	switch u.Scheme {
+	case "s3":
+		return runUpload(ctx, "aws", "s3", "cp", "--recursive", "--only-show-errors", dir, b.URL)
+	case "gs":
+		return runUpload(ctx, "gcloud", "storage", "cp", "--recursive", dir+"/*", b.URL)
+	case "file":
+		return copyDir(dir, u.Path)
+	case "":
+		return copyDir(dir, b.URL)
+	default:
+		return fmt.Errorf("unsupported bucket URL %s: must be s3://, gs://, file:// or a local path", b.URL)
+	}
+}
+
+// PublicURL returns the URL the bucket contents are served at, i.e. the
+// virtual-hosted style URL of S3 buckets and the public URL of GCS buckets.
+// Local directories have no public URL.
+func (b *Bucket) PublicURL() (string, error) {
+	u, err := url.Parse(b.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid bucket URL %s: %w", b.URL, err)
+	}
+	prefix := strings.TrimSuffix(u.Path, "/")
+	switch u.Scheme {
+	case "s3":
+		return "https://" + u.Host + ".s3.amazonaws.com" + prefix, nil
+	case "gs":
+		return "https://storage.googleapis.com/" + u.Host + prefix, nil
+	default:
+		return "", fmt.Errorf("no public URL for %s; specify the URL the mirror is served at", b.URL)
+	}
+}
+
+func runUpload(ctx context.Context, name string, args ...string) error {
+	log.Infof("Running %s %s", name, strings.Join(args, " "))
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w: %s", name, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// copyDir copies the files under src to dst.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		log.Debugf("Copying %s to %s", rel, target)
+		return os.WriteFile(target, data, 0644)
+	})
+}
@@ -0,0 +1,61 @@
+package publish
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBucketUploadLocal(t *testing.T) {
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "o", "tool"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "o", "tool", "tool.tar.gz"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "mirror")
+	fileDir := filepath.Join(t.TempDir(), "mirror")
+	for bucketURL, dst := range map[string]string{dir: dir, "file://" + fileDir: fileDir} {
+		b := &Bucket{URL: bucketURL}
+		if err := b.Upload(context.Background(), src); err != nil {
+			t.Fatalf("Upload(%s) error = %v", bucketURL, err)
+		}
+		got, err := os.ReadFile(filepath.Join(dst, "o", "tool", "tool.tar.gz"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "content" {
+			t.Errorf("uploaded content = %q, want %q", got, "content")
+		}
+	}
+
+	if err := (&Bucket{URL: "ftp://example.com/mirror"}).Upload(context.Background(), src); err == nil {
+		t.Error("Upload() expected error for unsupported scheme")
+	}
+}
+
+func TestBucketPublicURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "s3://bucket/mirror/", want: "https://bucket.s3.amazonaws.com/mirror"},
+		{url: "s3://bucket", want: "https://bucket.s3.amazonaws.com"},
+		{url: "gs://bucket/mirror", want: "https://storage.googleapis.com/bucket/mirror"},
+		{url: "/srv/mirror", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := (&Bucket{URL: tt.url}).PublicURL()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("PublicURL(%s) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("PublicURL(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
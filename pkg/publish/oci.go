@@ -0,0 +1,197 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Media types of binstaller OCI artifacts. The artifacts follow the OCI
+// image manifest guidance for artifacts, so they can also be pushed and
+// pulled with ORAS and signed with cosign by digest.
+const (
+	OCIArtifactType    = "application/vnd.binstaller.artifact.v1"
+	OCISpecMediaType   = "application/vnd.binstaller.spec.v1+yaml"
+	OCIScriptMediaType = "application/vnd.binstaller.script.v1+sh"
+
+	ociEmptyMediaType = "application/vnd.oci.empty.v1+json"
+	ociTitle          = "org.opencontainers.image.title"
+)
+
+// ociEmptyConfig is the content of the empty config of artifacts.
+var ociEmptyConfig = []byte("{}")
+
+// OCIFile is a file stored as a layer of an OCI artifact.
+type OCIFile struct {
+	Name      string // File name, stored as the org.opencontainers.image.title annotation
+	MediaType string // e.g. OCISpecMediaType
+	Data      []byte
+}
+
+// OCIArtifact is an artifact pulled from a registry.
+type OCIArtifact struct {
+	Digest      string // Digest of the manifest, e.g. sha256:...
+	Files       []OCIFile
+	Annotations map[string]string
+}
+
+// OCIRegistry pushes InstallSpec files and installer scripts to an OCI
+// registry and pulls them back. Credentials are read from the Docker config,
+// as with "docker login".
+type OCIRegistry struct {
+	Insecure bool           // Allow plain HTTP registries
+	Keychain authn.Keychain // Default: authn.DefaultKeychain
+}
+
+// ociManifest is an OCI image manifest with the artifactType field, which
+// v1.Manifest does not have.
+type ociManifest struct {
+	SchemaVersion int64             `json:"schemaVersion"`
+	MediaType     types.MediaType   `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        v1.Descriptor     `json:"config"`
+	Layers        []v1.Descriptor   `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// rawManifest implements remote.Taggable.
+type rawManifest []byte
+
+func (m rawManifest) RawManifest() ([]byte, error) { return m, nil }
+
+func (rawManifest) MediaType() (types.MediaType, error) { return types.OCIManifestSchema1, nil }
+
+// Push uploads files as one artifact to ref, e.g.
+// ghcr.io/owner/specs/tool:v1.0.0, and returns the digest of its manifest.
+// The artifact does not record when it was pushed, so pushing the same files
+// twice yields the same digest.
+func (r *OCIRegistry) Push(ctx context.Context, ref string, files []OCIFile, annotations map[string]string) (string, error) {
+	tag, err := name.ParseReference(ref, r.nameOptions()...)
+	if err != nil {
+		return "", fmt.Errorf("invalid reference %s: %w", ref, err)
+	}
+	opts := r.remoteOptions(ctx)
+
+	config, err := pushBlob(tag.Context(), static.NewLayer(ociEmptyConfig, ociEmptyMediaType), opts)
+	if err != nil {
+		return "", err
+	}
+	m := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		ArtifactType:  OCIArtifactType,
+		Config:        config,
+		Layers:        make([]v1.Descriptor, 0, len(files)),
+		Annotations:   annotations,
+	}
+	for _, f := range files {
+		desc, err := pushBlob(tag.Context(), static.NewLayer(f.Data, types.MediaType(f.MediaType)), opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to push %s: %w", f.Name, err)
+		}
+		desc.Annotations = map[string]string{ociTitle: f.Name}
+		m.Layers = append(m.Layers, desc)
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	if err := remote.Put(tag, rawManifest(raw), opts...); err != nil {
+		return "", fmt.Errorf("failed to push manifest to %s: %w", ref, err)
+	}
+	digest, _, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	return digest.String(), nil
+}
+
+// pushBlob uploads layer unless the repository already has it.
+func pushBlob(repo name.Repository, layer v1.Layer, opts []remote.Option) (v1.Descriptor, error) {
+	if err := remote.WriteLayer(repo, layer, opts...); err != nil {
+		return v1.Descriptor{}, err
+	}
+	digest, err := layer.Digest()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	size, err := layer.Size()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	mediaType, err := layer.MediaType()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{MediaType: mediaType, Digest: digest, Size: size}, nil
+}
+
+// Pull downloads the artifact at ref, a tag or a digest such as
+// ghcr.io/owner/specs/tool@sha256:.... Every file is verified against the
+// digest in the manifest.
+func (r *OCIRegistry) Pull(ctx context.Context, ref string) (*OCIArtifact, error) {
+	parsed, err := name.ParseReference(ref, r.nameOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reference %s: %w", ref, err)
+	}
+	opts := r.remoteOptions(ctx)
+	desc, err := remote.Get(parsed, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest of %s: %w", ref, err)
+	}
+	var m ociManifest
+	if err := json.Unmarshal(desc.Manifest, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest of %s: %w", ref, err)
+	}
+	if m.ArtifactType != OCIArtifactType {
+		return nil, fmt.Errorf("%s is not a binstaller artifact (artifact type %q)", ref, m.ArtifactType)
+	}
+
+	artifact := &OCIArtifact{Digest: desc.Digest.String(), Annotations: m.Annotations}
+	for _, l := range m.Layers {
+		filename := l.Annotations[ociTitle]
+		if filename == "" || filename != path.Base(filename) || filename == "." || filename == ".." {
+			return nil, fmt.Errorf("invalid file name %q in %s", filename, ref)
+		}
+		layer, err := remote.Layer(parsed.Context().Digest(l.Digest.String()), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", filename, err)
+		}
+		rc, err := layer.Compressed()
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", filename, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", filename, err)
+		}
+		artifact.Files = append(artifact.Files, OCIFile{Name: filename, MediaType: string(l.MediaType), Data: data})
+	}
+	return artifact, nil
+}
+
+func (r *OCIRegistry) nameOptions() []name.Option {
+	if r.Insecure {
+		return []name.Option{name.Insecure}
+	}
+	return nil
+}
+
+func (r *OCIRegistry) remoteOptions(ctx context.Context) []remote.Option {
+	keychain := r.Keychain
+	if keychain == nil {
+		keychain = authn.DefaultKeychain
+	}
+	return []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain)}
+}
@@ -0,0 +1,79 @@
+package publish
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+func TestOCIRegistry_PushPull(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+	repo := strings.TrimPrefix(srv.URL, "http://") + "/specs/tool"
+	r := &OCIRegistry{Keychain: authn.NewMultiKeychain()}
+	ctx := context.Background()
+
+	files := []OCIFile{
+		{Name: ".binstaller.yml", MediaType: OCISpecMediaType, Data: []byte("schema: v1\nname: tool\n")},
+		{Name: "install.sh", MediaType: OCIScriptMediaType, Data: []byte("#!/bin/sh\n")},
+	}
+	annotations := map[string]string{"org.opencontainers.image.source": "https://github.com/owner/tool"}
+	digest, err := r.Push(ctx, repo+":v1.0.0", files, annotations)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if !strings.HasPrefix(digest, "sha256:") {
+		t.Errorf("Push() digest = %q", digest)
+	}
+	again, err := r.Push(ctx, repo+":latest", files, annotations)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if again != digest {
+		t.Errorf("Push() of the same files = %s, want %s", again, digest)
+	}
+
+	for _, ref := range []string{repo + ":v1.0.0", repo + "@" + digest} {
+		artifact, err := r.Pull(ctx, ref)
+		if err != nil {
+			t.Fatalf("Pull(%s) error = %v", ref, err)
+		}
+		if artifact.Digest != digest {
+			t.Errorf("Pull(%s) digest = %s, want %s", ref, artifact.Digest, digest)
+		}
+		if !reflect.DeepEqual(artifact.Files, files) {
+			t.Errorf("Pull(%s) files = %+v, want %+v", ref, artifact.Files, files)
+		}
+		if !reflect.DeepEqual(artifact.Annotations, annotations) {
+			t.Errorf("Pull(%s) annotations = %v, want %v", ref, artifact.Annotations, annotations)
+		}
+	}
+
+	if _, err := r.Pull(ctx, repo+":missing"); err == nil {
+		t.Error("Pull() error = nil, want error for a missing tag")
+	}
+	if _, err := r.Push(ctx, "Invalid Ref", files, nil); err == nil {
+		t.Error("Push() error = nil, want error for an invalid reference")
+	}
+}
+
+func TestOCIRegistry_PullInvalidFileName(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+	repo := strings.TrimPrefix(srv.URL, "http://") + "/specs/tool"
+	r := &OCIRegistry{Keychain: authn.NewMultiKeychain()}
+	ctx := context.Background()
+
+	files := []OCIFile{{Name: "../.bashrc", MediaType: OCIScriptMediaType, Data: []byte("echo pwned\n")}}
+	if _, err := r.Push(ctx, repo+":evil", files, nil); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if _, err := r.Pull(ctx, repo+":evil"); err == nil {
+		t.Error("Pull() error = nil, want error for a file name with a path")
+	}
+}
@@ -0,0 +1,136 @@
+// Package publish distributes generated installer scripts, either as GitHub
+// release assets or by committing them to a gh-pages branch, and specs as
+// OCI artifacts in container registries.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/internal/httpclient"
+)
+
+const defaultGitHubAPIURL = "https://api.github.com"
+
+// SHA256Sum returns the checksum file of data named name, in the format of
+// sha256sum so that it can be checked with "sha256sum -c".
+func SHA256Sum(name string, data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n")
+}
+
+// ReleaseUploader uploads files as assets of an existing GitHub release.
+type ReleaseUploader struct {
+	Repo   string // GitHub owner/repo
+	Tag    string // Release tag
+	Token  string // GitHub token with contents:write permission
+	APIURL string // Default: https://api.github.com
+	Client *http.Client
+}
+
+type releaseAsset struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type release struct {
+	ID        int64          `json:"id"`
+	UploadURL string         `json:"upload_url"`
+	Assets    []releaseAsset `json:"assets"`
+}
+
+// Upload uploads data as a release asset with the given name. An existing
+// asset with the same name is replaced.
+func (u *ReleaseUploader) Upload(ctx context.Context, name string, data []byte) error {
+	if u.Token == "" {
+		return fmt.Errorf("GitHub token is required to upload release assets")
+	}
+	rel, err := u.getRelease(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range rel.Assets {
+		if a.Name != name {
+			continue
+		}
+		log.Infof("Deleting existing release asset %s", name)
+		deleteURL := fmt.Sprintf("%s/repos/%s/releases/assets/%d", u.apiURL(), u.Repo, a.ID)
+		resp, err := u.do(ctx, http.MethodDelete, deleteURL, "", nil)
+		if err != nil {
+			return fmt.Errorf("failed to delete existing asset %s: %w", name, err)
+		}
+		resp.Body.Close()
+	}
+
+	// upload_url is a URI template like https://uploads.github.com/repos/o/r/releases/1/assets{?name,label}
+	uploadURL, _, _ := strings.Cut(rel.UploadURL, "{")
+	uploadURL += "?name=" + url.QueryEscape(name)
+	log.Infof("Uploading %s to release %s of %s", name, u.Tag, u.Repo)
+	resp, err := u.do(ctx, http.MethodPost, uploadURL, "application/octet-stream", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to upload asset %s: %w", name, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (u *ReleaseUploader) getRelease(ctx context.Context) (*release, error) {
+	releaseURL := fmt.Sprintf("%s/repos/%s/releases/tags/%s", u.apiURL(), u.Repo, url.PathEscape(u.Tag))
+	resp, err := u.do(ctx, http.MethodGet, releaseURL, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release %s: %w", u.Tag, err)
+	}
+	defer resp.Body.Close()
+	var rel release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("failed to parse release response: %w", err)
+	}
+	if rel.UploadURL == "" {
+		return nil, fmt.Errorf("release %s has no upload URL", u.Tag)
+	}
+	return &rel, nil
+}
+
+// do sends an authenticated request and returns an error for non-2xx responses.
+func (u *ReleaseUploader) do(ctx context.Context, method, target, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "Bearer "+u.Token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	client := u.Client
+	if client == nil {
+		client = httpclient.HTTPClient()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s: %s", method, target, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (u *ReleaseUploader) apiURL() string {
+	if u.APIURL != "" {
+		return strings.TrimSuffix(u.APIURL, "/")
+	}
+	return defaultGitHubAPIURL
+}
@@ -0,0 +1,66 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReleaseUploader_Upload(t *testing.T) {
+	var deleted bool
+	var uploaded string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization header = %q", got)
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/releases/tags/v1.0.0":
+			fmt.Fprintf(w, `{"id":1,"upload_url":"%s/upload/repos/owner/repo/releases/1/assets{?name,label}","assets":[{"id":42,"name":"install.sh"}]}`, srv.URL)
+		case r.Method == http.MethodDelete && r.URL.Path == "/repos/owner/repo/releases/assets/42":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/repos/owner/repo/releases/1/assets":
+			if got := r.URL.Query().Get("name"); got != "install.sh" {
+				t.Errorf("upload name = %q", got)
+			}
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	u := &ReleaseUploader{Repo: "owner/repo", Tag: "v1.0.0", Token: "test-token", APIURL: srv.URL}
+	if err := u.Upload(context.Background(), "install.sh", []byte("#!/bin/sh\n")); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if !deleted {
+		t.Error("existing asset was not deleted")
+	}
+	if uploaded != "#!/bin/sh\n" {
+		t.Errorf("uploaded body = %q", uploaded)
+	}
+}
+
+func TestReleaseUploader_Upload_NoToken(t *testing.T) {
+	u := &ReleaseUploader{Repo: "owner/repo", Tag: "v1.0.0"}
+	if err := u.Upload(context.Background(), "install.sh", nil); err == nil {
+		t.Error("expected error without token")
+	}
+}
+
+func TestSHA256Sum(t *testing.T) {
+	got := string(SHA256Sum("install.sh", []byte("#!/bin/sh\n")))
+	want := "a8076d3d28d21e02012b20eaf7dbf75409a6277134439025f282e368e3305abf  install.sh\n"
+	if got != want {
+		t.Errorf("SHA256Sum() = %q, want %q", got, want)
+	}
+}
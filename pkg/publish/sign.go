@@ -0,0 +1,107 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Interactive connects the signing tools to the terminal so that their
+// prompts, such as the browser login of keyless cosign or the key password
+// of minisign, reach the user. Otherwise their output is only reported when
+// signing fails. binst sign sets it.
+var Interactive bool
+
+// runSigner runs the signing command cmd.
+func runSigner(cmd *exec.Cmd) error {
+	if Interactive {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// CosignSignBlob signs data with `cosign sign-blob` (keyless unless key is
+// set) and returns the signature and certificate contents. The certificate
+// is empty when signing with a key.
+func CosignSignBlob(ctx context.Context, data []byte, key string) (sig, cert []byte, err error) {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return nil, nil, fmt.Errorf("cosign is required for signing: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "binstaller-sign")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	blob := filepath.Join(dir, "blob")
+	sigPath := filepath.Join(dir, "blob.sig")
+	certPath := filepath.Join(dir, "blob.pem")
+	if err := os.WriteFile(blob, data, 0644); err != nil {
+		return nil, nil, fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	args := []string{"sign-blob", "--yes", "--output-signature", sigPath}
+	if key != "" {
+		args = append(args, "--key", key)
+	} else {
+		args = append(args, "--output-certificate", certPath)
+	}
+	args = append(args, blob)
+
+	if err := runSigner(exec.CommandContext(ctx, "cosign", args...)); err != nil {
+		return nil, nil, fmt.Errorf("cosign sign-blob failed: %w", err)
+	}
+
+	sig, err = os.ReadFile(sigPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	if key == "" {
+		cert, err = os.ReadFile(certPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read certificate: %w", err)
+		}
+	}
+	return sig, cert, nil
+}
+
+// MinisignSign signs data with `minisign -S` using the secret key file key
+// and returns the signature contents. Unless the key is unencrypted, minisign
+// prompts for its password, which requires Interactive.
+func MinisignSign(ctx context.Context, data []byte, key string) ([]byte, error) {
+	if _, err := exec.LookPath("minisign"); err != nil {
+		return nil, fmt.Errorf("minisign is required for signing: %w", err)
+	}
+	dir, err := os.MkdirTemp("", "binstaller-sign")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	blob := filepath.Join(dir, "blob")
+	sigPath := filepath.Join(dir, "blob.minisig")
+	if err := os.WriteFile(blob, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	if err := runSigner(exec.CommandContext(ctx, "minisign", "-S", "-s", key, "-m", blob, "-x", sigPath)); err != nil {
+		return nil, fmt.Errorf("minisign -S failed: %w", err)
+	}
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	return sig, nil
+}
@@ -0,0 +1,20 @@
+package publish
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRunSigner(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	if err := runSigner(exec.Command("sh", "-c", "echo signed >&2")); err != nil {
+		t.Errorf("runSigner() error = %v", err)
+	}
+	err := runSigner(exec.Command("sh", "-c", "echo wrong password >&2; exit 1"))
+	if err == nil || !strings.Contains(err.Error(), "wrong password") {
+		t.Errorf("runSigner() error = %v, want the stderr of the failed signer", err)
+	}
+}
@@ -0,0 +1,187 @@
+// Package readme renders the installation section of a README from an
+// InstallSpec: the install command, the supported platforms and how
+// downloads are verified.
+package readme
+
+import (
+	"cmp"
+	_ "embed"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+//go:embed section.md.tmpl
+var sectionTemplate string
+
+// Options are the install methods that are not part of an InstallSpec.
+type Options struct {
+	// ScriptURL is where the installer script is published. It defaults to
+	// install.sh on the default branch of the repository.
+	ScriptURL string
+	// OneLiner is a checksum-pinned install command for ScriptURL, as
+	// generated by binst gen --one-liner. If empty, the script is piped to sh
+	// as it is.
+	OneLiner string
+	// HomebrewTap is the GitHub repository of a Homebrew tap with a formula
+	// of the tool, e.g. "owner/homebrew-tap".
+	HomebrewTap string
+	// ScoopBucket is the GitHub repository of a Scoop bucket with a manifest
+	// of the tool, e.g. "owner/scoop-bucket".
+	ScoopBucket string
+}
+
+// osNames are the display names of OSes of the spec.
+var osNames = map[string]string{
+	"linux":     "Linux",
+	"darwin":    "macOS",
+	"windows":   "Windows",
+	"freebsd":   "FreeBSD",
+	"netbsd":    "NetBSD",
+	"openbsd":   "OpenBSD",
+	"dragonfly": "DragonFly BSD",
+	"solaris":   "Solaris",
+	"illumos":   "illumos",
+	"android":   "Android",
+}
+
+type sectionData struct {
+	Name         string
+	Command      string
+	CommandArgs  string
+	Platforms    []platformRow
+	Verification []string
+	Homebrew     string
+	ScoopBucket  string
+	ScoopURL     string
+}
+
+type platformRow struct {
+	OS    string
+	Archs string
+}
+
+// Section writes the installation section of installSpec in Markdown to w.
+func Section(w io.Writer, installSpec *spec.InstallSpec, opts Options) error {
+	if len(installSpec.Tools) > 0 {
+		return fmt.Errorf("markdown export does not support multi-tool specs")
+	}
+	data := sectionData{
+		Name:         installSpec.Name,
+		Platforms:    platformRows((&checksums.Embedder{Spec: installSpec}).Platforms()),
+		Verification: verification(installSpec),
+	}
+	scriptURL := opts.ScriptURL
+	if scriptURL == "" {
+		scriptURL = "https://raw.githubusercontent.com/" + installSpec.Repo + "/HEAD/install.sh"
+	}
+	// The pinned one-liner passes the script arguments to the verifier,
+	// which runs the script with them.
+	data.Command, data.CommandArgs = opts.OneLiner, opts.OneLiner+" -b /usr/local/bin "+exampleTag(installSpec)
+	if opts.OneLiner == "" {
+		data.Command = "curl -sSfL " + scriptURL + " | sh"
+		data.CommandArgs = data.Command + " -s -- -b /usr/local/bin " + exampleTag(installSpec)
+	}
+	if opts.HomebrewTap != "" {
+		owner, tap, ok := strings.Cut(opts.HomebrewTap, "/")
+		if !ok || owner == "" || tap == "" {
+			return fmt.Errorf("invalid Homebrew tap %q: want owner/repo", opts.HomebrewTap)
+		}
+		data.Homebrew = owner + "/" + strings.TrimPrefix(tap, "homebrew-") + "/" + installSpec.Name
+	}
+	if opts.ScoopBucket != "" {
+		_, bucket, ok := strings.Cut(opts.ScoopBucket, "/")
+		if !ok || bucket == "" {
+			return fmt.Errorf("invalid Scoop bucket %q: want owner/repo", opts.ScoopBucket)
+		}
+		data.ScoopBucket = bucket
+		data.ScoopURL = installSpec.GitHubBase() + "/" + opts.ScoopBucket
+	}
+
+	tmpl, err := template.New("section").Parse(sectionTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse markdown template: %w", err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render markdown: %w", err)
+	}
+	return nil
+}
+
+// platformRows groups platforms by OS, in the order OSes first appear.
+func platformRows(platforms []spec.Platform) []platformRow {
+	var oses []string
+	archs := make(map[string][]string)
+	for _, p := range platforms {
+		osName := strings.ToLower(p.OS)
+		if _, ok := archs[osName]; !ok {
+			oses = append(oses, osName)
+		}
+		if !slices.Contains(archs[osName], p.Arch) {
+			archs[osName] = append(archs[osName], p.Arch)
+		}
+	}
+	rows := make([]platformRow, 0, len(oses))
+	for _, osName := range oses {
+		name, ok := osNames[osName]
+		if !ok {
+			name = osName
+		}
+		rows = append(rows, platformRow{OS: name, Archs: strings.Join(archs[osName], ", ")})
+	}
+	return rows
+}
+
+// verification returns a sentence for every way the installer verifies
+// downloads.
+func verification(installSpec *spec.InstallSpec) []string {
+	var lines []string
+	if c := installSpec.Checksums; c != nil && (c.Template != "" || len(c.EmbeddedChecksums) > 0) {
+		algorithm := strings.ToUpper(string(cmp.Or(c.Algorithm, spec.SHA256)))
+		line := fmt.Sprintf("Downloads are verified against the %s checksums published with each release.", algorithm)
+		if n := len(c.EmbeddedChecksums); n > 0 {
+			line = fmt.Sprintf("Downloads are verified against %s checksums. The checksums of %d releases are embedded in the installer itself.", algorithm, n)
+		}
+		lines = append(lines, line)
+	}
+	if a := installSpec.Attestation; a != nil && isTrue(a.Enabled) {
+		lines = append(lines,
+			requirement("The GitHub artifact attestation of the asset is verified with the GitHub CLI", "it", a.Require),
+			fmt.Sprintf("To verify a downloaded asset yourself, run `gh attestation verify <asset> --repo %s`.", installSpec.Repo))
+	}
+	if s := installSpec.Signature; s != nil && isTrue(s.Enabled) {
+		verifier := cmp.Or(s.Type, "cosign")
+		lines = append(lines, requirement("The release signature is verified with "+verifier, verifier, s.Require))
+	}
+	if p := installSpec.Provenance; p != nil && isTrue(p.Enabled) {
+		lines = append(lines, requirement("The SLSA provenance of the asset is verified with slsa-verifier", "it", p.Require))
+	}
+	return lines
+}
+
+// requirement describes a verification that runs when its tool is
+// installed, and fails the installation without the tool if require is set.
+func requirement(what, tool string, require *bool) string {
+	if isTrue(require) {
+		return fmt.Sprintf("%s. The installation fails if %s is not installed.", what, tool)
+	}
+	return fmt.Sprintf("%s if %s is installed.", what, tool)
+}
+
+// exampleTag returns the tag used in the example of installing a specific
+// version.
+func exampleTag(installSpec *spec.InstallSpec) string {
+	if v := installSpec.DefaultVersion; v != "" && v != "latest" {
+		return v
+	}
+	return "v1.2.3"
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
@@ -0,0 +1,96 @@
+package readme
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func TestSection(t *testing.T) {
+	enabled := true
+	installSpec := &spec.InstallSpec{
+		Name:               "tool",
+		Repo:               "owner/tool",
+		DefaultVersion:     "v1.0.0",
+		Checksums:          &spec.ChecksumConfig{Template: "checksums.txt"},
+		Attestation:        &spec.AttestationConfig{Enabled: &enabled},
+		SupportedPlatforms: []spec.Platform{{OS: "linux", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}, {OS: "linux", Arch: "arm64"}},
+	}
+	var buf bytes.Buffer
+	if err := Section(&buf, installSpec, Options{HomebrewTap: "owner/homebrew-tap"}); err != nil {
+		t.Fatalf("Section() error = %v", err)
+	}
+	want := "## Installation\n" +
+		"\n" +
+		"Install the latest release of tool:\n" +
+		"\n" +
+		"```sh\n" +
+		"curl -sSfL https://raw.githubusercontent.com/owner/tool/HEAD/install.sh | sh\n" +
+		"```\n" +
+		"\n" +
+		"To install to another directory or a specific version, pass `-b` and the tag:\n" +
+		"\n" +
+		"```sh\n" +
+		"curl -sSfL https://raw.githubusercontent.com/owner/tool/HEAD/install.sh | sh -s -- -b /usr/local/bin v1.0.0\n" +
+		"```\n" +
+		"\n" +
+		"### Supported platforms\n" +
+		"\n" +
+		"| OS | Architectures |\n" +
+		"| --- | --- |\n" +
+		"| Linux | amd64, arm64 |\n" +
+		"| macOS | arm64 |\n" +
+		"\n" +
+		"### Verification\n" +
+		"\n" +
+		"Downloads are verified against the SHA256 checksums published with each release.\n" +
+		"The GitHub artifact attestation of the asset is verified with the GitHub CLI if it is installed.\n" +
+		"To verify a downloaded asset yourself, run `gh attestation verify <asset> --repo owner/tool`.\n" +
+		"\n" +
+		"### Other package managers\n" +
+		"\n" +
+		"Homebrew:\n" +
+		"\n" +
+		"```sh\n" +
+		"brew install owner/tap/tool\n" +
+		"```\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Section() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSectionOneLiner(t *testing.T) {
+	installSpec := &spec.InstallSpec{Name: "tool", Repo: "owner/tool"}
+	var buf bytes.Buffer
+	if err := Section(&buf, installSpec, Options{OneLiner: "curl -sSfL 'u' | sh -c 'v' sh", ScoopBucket: "owner/scoop-bucket"}); err != nil {
+		t.Fatalf("Section() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"```sh\ncurl -sSfL 'u' | sh -c 'v' sh\n```\n",
+		"curl -sSfL 'u' | sh -c 'v' sh -b /usr/local/bin v1.2.3\n",
+		// Common platforms are listed without supported_platforms
+		"| Linux | amd64, arm64 |\n",
+		"scoop bucket add scoop-bucket https://github.com/owner/scoop-bucket\nscoop install scoop-bucket/tool\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Section() output does not contain %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"### Verification", "brew install"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("Section() output contains %q:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestSectionInvalidOptions(t *testing.T) {
+	installSpec := &spec.InstallSpec{Name: "tool", Repo: "owner/tool"}
+	for _, opts := range []Options{{HomebrewTap: "tap"}, {ScoopBucket: "owner/"}} {
+		if err := Section(&bytes.Buffer{}, installSpec, opts); err == nil {
+			t.Errorf("Section(%+v) error = nil, want error", opts)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package spec
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// AllowAsset reports whether an asset filename may be selected according to
+// the ignore_assets and prefer_assets glob lists. Assets matching
+// prefer_assets are always allowed; otherwise assets matching ignore_assets
+// are rejected.
+func (a *AssetConfig) AllowAsset(filename string) bool {
+	if matchAnyGlob(a.PreferAssets, filename) {
+		return true
+	}
+	return !matchAnyGlob(a.IgnoreAssets, filename)
+}
+
+// matchAnyGlob reports whether name matches any of the glob patterns.
+// Patterns use path.Match syntax. Malformed patterns never match.
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, err := path.Match(p, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GlobToRegexp converts a path.Match style glob pattern to an anchored
+// regular expression, for consumers that only accept regular expressions.
+// It reports false for malformed patterns, which never match.
+func GlobToRegexp(glob string) (string, bool) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := globClassEnd(glob, i)
+			class := glob[i+1 : end]
+			b.WriteByte('[')
+			if strings.HasPrefix(class, "^") {
+				b.WriteByte('^')
+				class = class[1:]
+			}
+			for j := 0; j < len(class); j++ {
+				switch {
+				case class[j] == '-' && j > 0:
+					b.WriteByte('-')
+				case class[j] == '\\':
+					j++
+					fallthrough
+				default:
+					if strings.IndexByte(`\[]^-`, class[j]) >= 0 {
+						b.WriteByte('\\')
+					}
+					b.WriteByte(class[j])
+				}
+			}
+			b.WriteByte(']')
+			i = end
+		case '\\':
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return b.String(), true
+}
+
+// GlobToCasePattern converts a path.Match style glob pattern to a pattern of a
+// shell case statement, for generated scripts. Characters without a special
+// meaning in the glob are escaped. It reports false for malformed patterns,
+// which never match.
+func GlobToCasePattern(glob string) (string, bool) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*', '?':
+			b.WriteByte(c)
+		case '[':
+			end := globClassEnd(glob, i)
+			class := glob[i+1 : end]
+			b.WriteByte('[')
+			if strings.HasPrefix(class, "^") {
+				b.WriteByte('!')
+				class = class[1:]
+			}
+			for j := 0; j < len(class); j++ {
+				switch {
+				case class[j] == '-' && j > 0:
+					b.WriteByte('-')
+				case class[j] == '\\':
+					j++
+					b.WriteString(`\` + string(class[j]))
+				default:
+					b.WriteString(escapeShellChar(class[j]))
+				}
+			}
+			b.WriteByte(']')
+			i = end
+		case '\\':
+			i++
+			b.WriteString(escapeShellChar(glob[i]))
+		default:
+			b.WriteString(escapeShellChar(c))
+		}
+	}
+	return b.String(), true
+}
+
+// globClassEnd returns the index of the ']' closing the character class that
+// starts at glob[start]. glob must be a well-formed pattern.
+func globClassEnd(glob string, start int) int {
+	end := start + 1
+	for ; glob[end] != ']'; end++ {
+		if glob[end] == '\\' {
+			end++
+		}
+	}
+	return end
+}
+
+// escapeShellChar escapes c with a backslash unless it is alphanumeric or
+// one of "._-".
+func escapeShellChar(c byte) string {
+	if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("._-", c) >= 0 {
+		return string(c)
+	}
+	return `\` + string(c)
+}
@@ -0,0 +1,102 @@
+package spec
+
+import (
+	"path"
+	"regexp"
+	"testing"
+)
+
+func TestAssetConfig_AllowAsset(t *testing.T) {
+	asset := AssetConfig{
+		IgnoreAssets: []string{"*.sbom", "*-debug*", "*.sbom.json"},
+		PreferAssets: []string{"tool-debug-symbols.tar.gz"},
+	}
+	tests := []struct {
+		filename string
+		want     bool
+	}{
+		{"tool_1.0.0_linux_amd64.tar.gz", true},
+		{"tool_1.0.0_linux_amd64.tar.gz.sbom", false},
+		{"tool_1.0.0_linux_amd64.sbom.json", false},
+		{"tool-debug_1.0.0_linux_amd64.tar.gz", false},
+		{"tool-debug-symbols.tar.gz", true},
+	}
+	for _, tt := range tests {
+		if got := asset.AllowAsset(tt.filename); got != tt.want {
+			t.Errorf("AllowAsset(%q) = %v, want %v", tt.filename, got, tt.want)
+		}
+	}
+
+	var empty AssetConfig
+	if !empty.AllowAsset("anything.tar.gz") {
+		t.Error("AllowAsset with no globs should allow everything")
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob string
+		name string
+	}{
+		{"*.sbom", "tool.tar.gz.sbom"},
+		{"*.sbom", "tool.tar.gz"},
+		{"tool_?.zip", "tool_1.zip"},
+		{"tool_?.zip", "tool_12.zip"},
+		{"tool_[0-9].zip", "tool_7.zip"},
+		{"tool_[^0-9].zip", "tool_7.zip"},
+		{"tool_[^0-9].zip", "tool_a.zip"},
+		{"tool_[!0-9].zip", "tool_7.zip"},
+		{"tool_[!0-9].zip", "tool_!.zip"},
+		{"tool_[!0-9].zip", "tool_a.zip"},
+		{`tool_[\]^\-].zip`, "tool_].zip"},
+		{`tool_[\]^\-].zip`, "tool_^.zip"},
+		{`tool_[\]^\-].zip`, "tool_a.zip"},
+		{"tool_[[:a].zip", "tool_:.zip"},
+		{"tool.(x)+", "tool.(x)+"},
+		{`tool\*`, "tool*"},
+		{`tool\*`, "tools"},
+	}
+	for _, tt := range tests {
+		want, err := path.Match(tt.glob, tt.name)
+		if err != nil {
+			t.Fatalf("path.Match(%q) failed: %v", tt.glob, err)
+		}
+		expr, ok := GlobToRegexp(tt.glob)
+		if !ok {
+			t.Errorf("GlobToRegexp(%q) reported a malformed pattern", tt.glob)
+			continue
+		}
+		re := regexp.MustCompile(expr)
+		if got := re.MatchString(tt.name); got != want {
+			t.Errorf("GlobToRegexp(%q) = %q matching %q = %v, want %v as path.Match", tt.glob, expr, tt.name, got, want)
+		}
+	}
+
+	if expr, ok := GlobToRegexp("[bad"); ok {
+		t.Errorf("GlobToRegexp(%q) = %q, want a malformed pattern", "[bad", expr)
+	}
+}
+
+func TestGlobToCasePattern(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+		ok   bool
+	}{
+		{"*.sbom", "*.sbom", true},
+		{"*-debug*", "*-debug*", true},
+		{"tool_?.zip", "tool_?.zip", true},
+		{"tool_[^0-9].zip", "tool_[!0-9].zip", true},
+		{"tool_[!a].zip", `tool_[\!a].zip`, true},
+		{`tool_[\]a].zip`, `tool_[\]a].zip`, true},
+		{"tool (x)|$y", `tool\ \(x\)\|\$y`, true},
+		{`tool\*`, `tool\*`, true},
+		{"[bad", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := GlobToCasePattern(tt.glob)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("GlobToCasePattern(%q) = %q, %v, want %q, %v", tt.glob, got, ok, tt.want, tt.ok)
+		}
+	}
+}
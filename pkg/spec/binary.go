@@ -0,0 +1,13 @@
+package spec
+
+import "strings"
+
+// ExecutableName returns name with the ".exe" suffix executables need on
+// Windows when os is "windows" and name does not have it yet. Names for other
+// OSes are returned as is.
+func ExecutableName(os, name string) string {
+	if os != "windows" || strings.HasSuffix(name, ".exe") {
+		return name
+	}
+	return name + ".exe"
+}
@@ -0,0 +1,18 @@
+package spec
+
+import "testing"
+
+func TestExecutableName(t *testing.T) {
+	tests := []struct {
+		os, name, want string
+	}{
+		{os: "windows", name: "tool", want: "tool.exe"},
+		{os: "windows", name: "tool.exe", want: "tool.exe"},
+		{os: "linux", name: "tool", want: "tool"},
+	}
+	for _, tt := range tests {
+		if got := ExecutableName(tt.os, tt.name); got != tt.want {
+			t.Errorf("ExecutableName(%q, %q) = %q, want %q", tt.os, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// BinDirDefaultKey is the BinDir key used for OSes without their own entry.
+const BinDirDefaultKey = "default"
+
+// BinDir is the default installation directory of the installer. It is
+// either one directory for every OS:
+//
+//	default_bin_dir: ${HOME}/.local/bin
+//
+// or a mapping of OS to directory, with "default" used for the other OSes:
+//
+//	default_bin_dir:
+//	  windows: ${LOCALAPPDATA}/Programs/bin
+//	  darwin: /usr/local/bin
+//	  default: ${HOME}/.local/bin
+type BinDir map[string]string
+
+// UnmarshalYAML accepts a plain string as the directory for every OS.
+func (b *BinDir) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var dir string
+	if err := unmarshal(&dir); err == nil {
+		*b = BinDir{BinDirDefaultKey: dir}
+		return nil
+	}
+	var m map[string]string
+	if err := unmarshal(&m); err != nil {
+		return err
+	}
+	*b = m
+	return nil
+}
+
+// MarshalYAML writes a directory shared by every OS back as a plain string.
+func (b BinDir) MarshalYAML() (interface{}, error) {
+	if len(b) == 1 && b[BinDirDefaultKey] != "" {
+		return b[BinDirDefaultKey], nil
+	}
+	return map[string]string(b), nil
+}
+
+// Default returns the directory of OSes without their own entry.
+func (b BinDir) Default() string {
+	return b[BinDirDefaultKey]
+}
+
+// PerOS returns the OSes with their own directory, sorted.
+func (b BinDir) PerOS() []string {
+	var oses []string
+	for os := range b {
+		if os != BinDirDefaultKey {
+			oses = append(oses, os)
+		}
+	}
+	sort.Strings(oses)
+	return oses
+}
+
+var binDirOSRegexp = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// Validate checks that the OS keys are plain lowercase OS names as reported
+// by the installer, e.g. "linux" or "windows".
+func (b BinDir) Validate() error {
+	for os := range b {
+		if !binDirOSRegexp.MatchString(os) {
+			return fmt.Errorf("invalid OS %q in default_bin_dir", os)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package spec
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBinDirYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    BinDir
+		wantOut string
+	}{
+		{
+			name:    "string",
+			yaml:    "default_bin_dir: /opt/bin\n",
+			want:    BinDir{"default": "/opt/bin"},
+			wantOut: "default_bin_dir: /opt/bin\n",
+		},
+		{
+			name:    "per OS",
+			yaml:    "default_bin_dir:\n    default: ${HOME}/.local/bin\n    windows: ${LOCALAPPDATA}/Programs/bin\n",
+			want:    BinDir{"default": "${HOME}/.local/bin", "windows": "${LOCALAPPDATA}/Programs/bin"},
+			wantOut: "default_bin_dir:\n    default: ${HOME}/.local/bin\n    windows: ${LOCALAPPDATA}/Programs/bin\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s struct {
+				DefaultBinDir BinDir `yaml:"default_bin_dir"`
+			}
+			if err := yaml.Unmarshal([]byte(tt.yaml), &s); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if len(s.DefaultBinDir) != len(tt.want) {
+				t.Fatalf("BinDir = %v, want %v", s.DefaultBinDir, tt.want)
+			}
+			for os, dir := range tt.want {
+				if s.DefaultBinDir[os] != dir {
+					t.Errorf("BinDir[%s] = %q, want %q", os, s.DefaultBinDir[os], dir)
+				}
+			}
+			out, err := yaml.Marshal(s)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(out) != tt.wantOut {
+				t.Errorf("Marshal() = %q, want %q", out, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestBinDirPerOS(t *testing.T) {
+	b := BinDir{"default": "/a", "windows": "/b", "darwin": "/c"}
+	got := b.PerOS()
+	if len(got) != 2 || got[0] != "darwin" || got[1] != "windows" {
+		t.Errorf("PerOS() = %v, want [darwin windows]", got)
+	}
+	if err := b.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (BinDir{"Windows) rm -rf": "/b"}).Validate(); err == nil {
+		t.Error("Validate() error = nil, want error for invalid OS")
+	}
+}
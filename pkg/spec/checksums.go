@@ -0,0 +1,47 @@
+package spec
+
+import (
+	"path"
+	"strings"
+)
+
+// IsURLTemplate reports whether the checksum template t is a full URL rather
+// than a release asset name.
+func IsURLTemplate(t string) bool {
+	return strings.HasPrefix(t, "https://") || strings.HasPrefix(t, "http://")
+}
+
+// URLFilename returns the filename part of the URL u, without query string.
+func URLFilename(u string) string {
+	u, _, _ = strings.Cut(u, "?")
+	return path.Base(u)
+}
+
+// HasURLTemplate reports whether the checksum template or any checksum rule
+// template is a full URL.
+func (c *ChecksumConfig) HasURLTemplate() bool {
+	if c == nil {
+		return false
+	}
+	if IsURLTemplate(c.Template) {
+		return true
+	}
+	for _, rule := range c.Rules {
+		if IsURLTemplate(rule.Template) {
+			return true
+		}
+	}
+	return false
+}
+
+// EntryAlgorithm returns the algorithm of the embedded checksum ec, which
+// defaults to the algorithm of c.
+func (c *ChecksumConfig) EntryAlgorithm(ec EmbeddedChecksum) HashAlgorithm {
+	if ec.Algorithm != "" {
+		return ec.Algorithm
+	}
+	if c == nil || c.Algorithm == "" {
+		return SHA256
+	}
+	return c.Algorithm
+}
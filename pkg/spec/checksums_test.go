@@ -0,0 +1,49 @@
+package spec
+
+import "testing"
+
+func TestChecksumConfigHasURLTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *ChecksumConfig
+		want bool
+	}{
+		{name: "nil", c: nil, want: false},
+		{name: "asset name", c: &ChecksumConfig{Template: "checksums.txt"}, want: false},
+		{name: "url", c: &ChecksumConfig{Template: "https://example.com/${VERSION}/SHA256SUMS"}, want: true},
+		{name: "rule url", c: &ChecksumConfig{Template: "checksums.txt", Rules: []ChecksumRule{{Template: "http://example.com/sums"}}}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.HasURLTemplate(); got != tt.want {
+				t.Errorf("HasURLTemplate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLFilename(t *testing.T) {
+	if got := URLFilename("https://cdn.example.com/tool/v1.0.0/SHA256SUMS?sig=abc/def"); got != "SHA256SUMS" {
+		t.Errorf("URLFilename() = %q, want %q", got, "SHA256SUMS")
+	}
+}
+
+func TestEntryAlgorithm(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *ChecksumConfig
+		ec   EmbeddedChecksum
+		want HashAlgorithm
+	}{
+		{name: "nil config", c: nil, want: SHA256},
+		{name: "config default", c: &ChecksumConfig{Algorithm: SHA512}, want: SHA512},
+		{name: "entry override", c: &ChecksumConfig{Algorithm: SHA512}, ec: EmbeddedChecksum{Algorithm: BLAKE3}, want: BLAKE3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.EntryAlgorithm(tt.ec); got != tt.want {
+				t.Errorf("EntryAlgorithm() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
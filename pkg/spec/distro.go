@@ -0,0 +1,45 @@
+package spec
+
+import (
+	"regexp"
+	"strings"
+)
+
+// distroPattern matches the ${DISTRO} values the installer script detects:
+// ID followed by VERSION_ID of /etc/os-release, e.g. "ubuntu20.04".
+var distroPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ValidDistro reports whether distro can be used in the distro rule
+// condition.
+func ValidDistro(distro string) bool {
+	return distroPattern.MatchString(distro)
+}
+
+// UsesDistro reports whether the asset naming depends on the Linux
+// distribution, either through a ${DISTRO} placeholder in a template or a
+// rule keyed on distro.
+func (a *AssetConfig) UsesDistro() bool {
+	if strings.Contains(a.Template, "${DISTRO}") {
+		return true
+	}
+	for _, rule := range a.Rules {
+		if rule.When.Distro != "" || strings.Contains(rule.Template, "${DISTRO}") {
+			return true
+		}
+	}
+	return false
+}
+
+// Distros returns the distributions named by asset rules, in rule order and
+// without duplicates.
+func (a *AssetConfig) Distros() []string {
+	var distros []string
+	seen := make(map[string]bool)
+	for _, rule := range a.Rules {
+		if d := rule.When.Distro; d != "" && !seen[d] {
+			seen[d] = true
+			distros = append(distros, d)
+		}
+	}
+	return distros
+}
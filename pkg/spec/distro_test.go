@@ -0,0 +1,54 @@
+package spec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUsesDistro(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset AssetConfig
+		want  bool
+	}{
+		{"none", AssetConfig{Template: "${NAME}_${OS}_${ARCH}${EXT}"}, false},
+		{"template", AssetConfig{Template: "${NAME}-${ARCH}-${DISTRO}${EXT}"}, true},
+		{"rule condition", AssetConfig{Rules: []AssetRule{{When: PlatformCondition{Distro: "ubuntu20.04"}}}}, true},
+		{"rule template", AssetConfig{Rules: []AssetRule{{Template: "${NAME}-${DISTRO}"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.asset.UsesDistro(); got != tt.want {
+				t.Errorf("UsesDistro() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistros(t *testing.T) {
+	asset := AssetConfig{Rules: []AssetRule{
+		{When: PlatformCondition{Distro: "ubuntu20.04"}},
+		{When: PlatformCondition{OS: "darwin"}},
+		{When: PlatformCondition{Distro: "amzn2", Arch: "arm64"}},
+		{When: PlatformCondition{Distro: "ubuntu20.04", Arch: "arm64"}},
+	}}
+	want := []string{"ubuntu20.04", "amzn2"}
+	if got := asset.Distros(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Distros() = %q, want %q", got, want)
+	}
+}
+
+func TestValidDistro(t *testing.T) {
+	for distro, want := range map[string]bool{
+		"ubuntu20.04":     true,
+		"amzn2":           true,
+		"opensuse-leap15": true,
+		"":                false,
+		"Ubuntu":          false,
+		"ubuntu'; rm":     false,
+	} {
+		if got := ValidDistro(distro); got != want {
+			t.Errorf("ValidDistro(%q) = %v, want %v", distro, got, want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package spec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+)
+
+// keySegment is one element of a dotted spec key like "asset.rules[0].ext".
+type keySegment struct {
+	name  string
+	index *uint
+}
+
+// parseKey splits a dotted key with optional [N] indexes into segments.
+func parseKey(key string) ([]keySegment, error) {
+	if key == "" {
+		return nil, errors.New("empty key")
+	}
+	var segs []keySegment
+	for _, part := range strings.Split(key, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name == "" {
+			return nil, fmt.Errorf("invalid key %q", key)
+		}
+		segs = append(segs, keySegment{name: name})
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("invalid index in key %q", key)
+			}
+			n, err := strconv.ParseUint(idx, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q in key %q", idx, key)
+			}
+			i := uint(n)
+			segs = append(segs, keySegment{index: &i})
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return segs, nil
+}
+
+func buildPath(segs []keySegment) *yaml.Path {
+	b := (&yaml.PathBuilder{}).Root()
+	for _, s := range segs {
+		if s.index != nil {
+			b = b.Index(*s.index)
+		} else {
+			b = b.Child(s.name)
+		}
+	}
+	return b.Build()
+}
+
+// GetValue returns the node at key (e.g. "asset.template") in the spec AST.
+func GetValue(file *ast.File, key string) (ast.Node, error) {
+	segs, err := parseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	node, err := buildPath(segs).FilterFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("key %q not found: %w", key, err)
+	}
+	return node, nil
+}
+
+// SetValue sets key to value in the spec AST, creating missing parent
+// mappings. Comments and ordering of unrelated nodes are preserved.
+func SetValue(file *ast.File, key string, value any) error {
+	segs, err := parseKey(key)
+	if err != nil {
+		return err
+	}
+	last := segs[len(segs)-1]
+	if last.index != nil {
+		node, err := yaml.ValueToNode(value, yaml.IndentSequence(true))
+		if err != nil {
+			return err
+		}
+		if _, err := buildPath(segs).FilterFile(file); err != nil {
+			return fmt.Errorf("key %q not found: %w", key, err)
+		}
+		return buildPath(segs).ReplaceWithNode(file, node)
+	}
+
+	// Find the deepest existing ancestor mapping and merge the missing
+	// part of the key into it.
+	for i := len(segs) - 1; i >= 0; i-- {
+		parent := buildPath(segs[:i])
+		if _, err := parent.FilterFile(file); err != nil {
+			if i > 0 && segs[i-1].index != nil {
+				return fmt.Errorf("key %q not found: %w", key, err)
+			}
+			continue
+		}
+		var v any = value
+		for j := len(segs) - 1; j >= i; j-- {
+			v = yaml.MapSlice{{Key: segs[j].name, Value: v}}
+		}
+		node, err := yaml.ValueToNode(v, yaml.IndentSequence(true))
+		if err != nil {
+			return err
+		}
+		return parent.MergeFromNode(file, node)
+	}
+	return fmt.Errorf("failed to set key %q", key)
+}
+
+// AddValue appends value to the sequence at key, creating the sequence if it
+// does not exist.
+func AddValue(file *ast.File, key string, value any) error {
+	segs, err := parseKey(key)
+	if err != nil {
+		return err
+	}
+	p := buildPath(segs)
+	node, err := p.FilterFile(file)
+	if err != nil {
+		return SetValue(file, key, []any{value})
+	}
+	if node.Type() != ast.SequenceType {
+		return fmt.Errorf("key %q is not a list", key)
+	}
+	seq, err := yaml.ValueToNode([]any{value}, yaml.IndentSequence(true))
+	if err != nil {
+		return err
+	}
+	return p.MergeFromNode(file, seq)
+}
+
+// MergeValue merges the mapping value into the mapping at key ("" for the
+// root) of the spec AST. Nested mappings are merged recursively and other
+// values replace the existing ones, so that keys only in the AST and comments
+// are preserved.
+func MergeValue(file *ast.File, key string, value yaml.MapSlice) error {
+	for _, item := range value {
+		if name, ok := item.Key.(string); !ok || name == "" || strings.ContainsAny(name, ".[]") {
+			// Keys that cannot be addressed, e.g. versions of embedded
+			// checksums, replace their whole mapping
+			if key == "" {
+				return fmt.Errorf("cannot merge key %v", item.Key)
+			}
+			return SetValue(file, key, value)
+		}
+	}
+	for _, item := range value {
+		child := item.Key.(string)
+		if key != "" {
+			child = key + "." + child
+		}
+		if node, err := GetValue(file, child); err == nil {
+			if m, ok := item.Value.(yaml.MapSlice); ok && node.Type() == ast.MappingType {
+				if err := MergeValue(file, child, m); err != nil {
+					return err
+				}
+				continue
+			}
+			if equalNodeValue(node, item.Value) {
+				continue // Keep the comments of unchanged values
+			}
+		}
+		if err := SetValue(file, child, item.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// equalNodeValue reports whether node holds value.
+func equalNodeValue(node ast.Node, value any) bool {
+	var v any
+	if err := yaml.NodeToValue(node, &v, yaml.UseOrderedMap()); err != nil {
+		return false
+	}
+	a, err := yaml.Marshal(v)
+	if err != nil {
+		return false
+	}
+	b, err := yaml.Marshal(value)
+	return err == nil && bytes.Equal(a, b)
+}
+
+// ParseValue parses a command line value as YAML so that booleans, numbers,
+// lists and mappings can be given. For supported_platforms, "os/arch"
+// shorthand is converted to a platform mapping.
+func ParseValue(key, raw string) (any, error) {
+	if strings.HasPrefix(key, "supported_platforms") {
+		if goos, goarch, ok := strings.Cut(raw, "/"); ok && !strings.ContainsAny(raw, "{:") {
+			return yaml.MapSlice{{Key: "os", Value: goos}, {Key: "arch", Value: goarch}}, nil
+		}
+	}
+	var v any
+	if err := yaml.UnmarshalWithOptions([]byte(raw), &v, yaml.UseOrderedMap()); err != nil {
+		return nil, fmt.Errorf("failed to parse value %q: %w", raw, err)
+	}
+	if v == nil {
+		return raw, nil
+	}
+	return v, nil
+}
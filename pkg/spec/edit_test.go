@@ -0,0 +1,133 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+)
+
+const editTestSpec = `# tool spec
+name: tool # binary name
+repo: owner/tool
+asset:
+  template: ${NAME}_${OS}_${ARCH}${EXT}
+  rules:
+    - when:
+        os: windows
+      ext: .zip
+supported_platforms:
+  - os: linux
+    arch: amd64
+`
+
+func TestEditSpec(t *testing.T) {
+	file, err := parser.ParseBytes([]byte(editTestSpec), parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		op    func() error
+		label string
+	}{
+		{func() error { return SetValue(file, "asset.template", "${NAME}-${OS}${EXT}") }, "set existing"},
+		{func() error { return SetValue(file, "asset.rules[0].ext", ".7z") }, "set indexed"},
+		{func() error { return SetValue(file, "unpack.strip_components", uint64(1)) }, "set missing parent"},
+		{func() error {
+			v, err := ParseValue("supported_platforms", "linux/riscv64")
+			if err != nil {
+				return err
+			}
+			return AddValue(file, "supported_platforms", v)
+		}, "add platform"},
+		{func() error { return AddValue(file, "asset.ignore_assets", "*.sbom") }, "add to missing list"},
+	}
+	for _, s := range steps {
+		if err := s.op(); err != nil {
+			t.Fatalf("%s: %v", s.label, err)
+		}
+	}
+
+	want := `# tool spec
+name: tool # binary name
+repo: owner/tool
+asset:
+  template: ${NAME}-${OS}${EXT}
+  rules:
+    - when:
+        os: windows
+      ext: .7z
+  ignore_assets:
+    - "*.sbom"
+supported_platforms:
+  - os: linux
+    arch: amd64
+  - os: linux
+    arch: riscv64
+unpack:
+  strip_components: 1
+`
+	if got := file.String(); got != want {
+		t.Errorf("edited spec mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	node, err := GetValue(file, "asset.rules[0].when.os")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.String() != "windows" {
+		t.Errorf("GetValue = %q, want windows", node.String())
+	}
+	if _, err := GetValue(file, "nope"); err == nil {
+		t.Error("expected error for missing key")
+	}
+	if err := AddValue(file, "asset.template", "x"); err == nil {
+		t.Error("expected error adding to a non-list key")
+	}
+}
+
+func TestMergeValue(t *testing.T) {
+	file, err := parser.ParseBytes([]byte(editTestSpec), parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := yaml.MapSlice{
+		{Key: "name", Value: "tool"},
+		{Key: "repo", Value: "owner/tool2"},
+		{Key: "asset", Value: yaml.MapSlice{
+			{Key: "template", Value: "${NAME}-${OS}${EXT}"},
+			{Key: "default_extension", Value: ".tar.gz"},
+		}},
+		{Key: "checksums", Value: yaml.MapSlice{
+			{Key: "embedded_checksums", Value: yaml.MapSlice{
+				{Key: "v1.0.0", Value: []any{yaml.MapSlice{{Key: "filename", Value: "tool.tar.gz"}}}},
+			}},
+		}},
+	}
+	if err := MergeValue(file, "", value); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# tool spec
+name: tool # binary name
+repo: owner/tool2
+asset:
+  template: ${NAME}-${OS}${EXT}
+  rules:
+    - when:
+        os: windows
+      ext: .zip
+  default_extension: .tar.gz
+supported_platforms:
+  - os: linux
+    arch: amd64
+checksums:
+  embedded_checksums:
+    v1.0.0:
+      - filename: tool.tar.gz
+`
+	if got := file.String(); got != want {
+		t.Errorf("MergeValue() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+package spec
+
+// NamingCase is the casing applied to the ${OS} and ${ARCH} placeholders.
+type NamingCase string
+
+// Supported naming cases.
+const (
+	NamingLowercase NamingCase = "lowercase"
+	NamingTitlecase NamingCase = "titlecase"
+)
+
+// HashAlgorithm is a checksum algorithm.
+type HashAlgorithm string
+
+// Supported checksum algorithms.
+const (
+	SHA256   HashAlgorithm = "sha256"
+	SHA512   HashAlgorithm = "sha512"
+	SHA1     HashAlgorithm = "sha1"
+	MD5      HashAlgorithm = "md5"
+	SHA3_256 HashAlgorithm = "sha3-256"
+	BLAKE2b  HashAlgorithm = "blake2b" // BLAKE2b-512, as printed by b2sum
+	BLAKE3   HashAlgorithm = "blake3"
+)
+
+// HashAlgorithms lists the supported checksum algorithms.
+var HashAlgorithms = []HashAlgorithm{SHA256, SHA512, SHA1, MD5, SHA3_256, BLAKE2b, BLAKE3}
+
+// Valid reports whether a is a supported checksum algorithm.
+func (a HashAlgorithm) Valid() bool {
+	for _, v := range HashAlgorithms {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package spec
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/haya14busa/goinstaller/internal/httpclient"
+)
+
+// maxExtendsDepth bounds chains of extends to catch cycles through URLs that
+// resolve to the same spec under different names.
+const maxExtendsDepth = 10
+
+// ResolveExtends merges spec YAML data loaded from location (a file path or
+// URL) onto the base spec named by its "extends" key, recursively. Mappings
+// are merged key by key with the extending spec taking precedence; lists and
+// scalars are replaced as a whole. Relative extends are resolved against
+// location. Data without "extends" is returned unchanged.
+func ResolveExtends(data []byte, location string) ([]byte, error) {
+	merged, extended, err := resolveExtends(data, location, nil)
+	if err != nil || !extended {
+		return data, err
+	}
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged spec: %w", err)
+	}
+	return out, nil
+}
+
+func resolveExtends(data []byte, location string, seen []string) (map[string]interface{}, bool, error) {
+	if len(seen) >= maxExtendsDepth {
+		return nil, false, fmt.Errorf("extends chain is too deep: %s", strings.Join(seen, " -> "))
+	}
+	for _, s := range seen {
+		if s == location {
+			return nil, false, fmt.Errorf("extends cycle: %s -> %s", strings.Join(seen, " -> "), location)
+		}
+	}
+	seen = append(seen, location)
+
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, false, fmt.Errorf("failed to parse spec %s: %w", location, err)
+	}
+	parent, ok := m["extends"]
+	if !ok {
+		return m, false, nil
+	}
+	delete(m, "extends")
+	parentRef, ok := parent.(string)
+	if !ok || parentRef == "" {
+		return nil, false, fmt.Errorf("extends in %s must be a path or URL", location)
+	}
+
+	parentLocation := resolveSpecLocation(location, parentRef)
+	parentData, err := readSpecLocation(parentLocation)
+	if err != nil {
+		return nil, false, err
+	}
+	base, _, err := resolveExtends(parentData, parentLocation, seen)
+	if err != nil {
+		return nil, false, err
+	}
+	return mergeSpecMaps(base, m), true, nil
+}
+
+// resolveSpecLocation resolves ref relative to the spec at location.
+func resolveSpecLocation(location, ref string) string {
+	if isURL(ref) || filepath.IsAbs(ref) {
+		return ref
+	}
+	if isURL(location) {
+		base, err := url.Parse(location)
+		if err == nil {
+			if r, err := url.Parse(ref); err == nil {
+				return base.ResolveReference(r).String()
+			}
+		}
+		return ref
+	}
+	if location == "" || location == "-" {
+		return ref
+	}
+	return filepath.Join(filepath.Dir(location), ref)
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+func readSpecLocation(location string) ([]byte, error) {
+	if !isURL(location) {
+		data, err := os.ReadFile(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read base spec %s: %w", location, err)
+		}
+		return data, nil
+	}
+	resp, err := (&httpclient.Client{}).Get(location, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download base spec %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpclient.StatusError(resp, fmt.Errorf("failed to download base spec %s, status code: %d", location, resp.StatusCode))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base spec %s: %w", location, err)
+	}
+	return data, nil
+}
+
+// mergeSpecMaps returns base overridden by override. Nested mappings are
+// merged recursively.
+func mergeSpecMaps(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		baseMap, baseOK := merged[k].(map[string]interface{})
+		overrideMap, overrideOK := v.(map[string]interface{})
+		if baseOK && overrideOK {
+			merged[k] = mergeSpecMaps(baseMap, overrideMap)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
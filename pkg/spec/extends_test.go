@@ -0,0 +1,97 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolveExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	writeFile("base/org.yml", `
+schema: v1
+default_bin_dir: /opt/bin
+asset:
+  naming_convention:
+    os: titlecase
+  default_extension: .tar.gz
+attestation:
+  enabled: true
+  require: true
+`)
+	child := writeFile("tool.yml", `
+extends: base/org.yml
+name: tool
+repo: owner/tool
+asset:
+  template: ${NAME}_${OS}_${ARCH}${EXT}
+  default_extension: .tgz
+`)
+	data, err := os.ReadFile(child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, err := ResolveExtends(data, child)
+	if err != nil {
+		t.Fatalf("ResolveExtends() error = %v", err)
+	}
+	var s InstallSpec
+	if err := yaml.Unmarshal(merged, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Extends != "" {
+		t.Errorf("Extends = %q, want it removed", s.Extends)
+	}
+	if s.DefaultBinDir.Default() != "/opt/bin" || s.Repo != "owner/tool" {
+		t.Errorf("top-level fields not merged: bin dir %q, repo %q", s.DefaultBinDir.Default(), s.Repo)
+	}
+	if s.Asset.NamingConvention == nil || s.Asset.NamingConvention.OS != "titlecase" {
+		t.Errorf("asset.naming_convention not inherited: %+v", s.Asset.NamingConvention)
+	}
+	if s.Asset.DefaultExtension != ".tgz" || s.Asset.Template != "${NAME}_${OS}_${ARCH}${EXT}" {
+		t.Errorf("asset overrides not applied: %+v", s.Asset)
+	}
+	if s.Attestation == nil || s.Attestation.Require == nil || !*s.Attestation.Require {
+		t.Errorf("attestation not inherited: %+v", s.Attestation)
+	}
+}
+
+func TestResolveExtendsCycle(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yml")
+	if err := os.WriteFile(a, []byte("extends: b.yml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.yml"), []byte("extends: a.yml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ResolveExtends([]byte("extends: a.yml\n"), filepath.Join(dir, "c.yml"))
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("ResolveExtends() error = %v, want cycle error", err)
+	}
+}
+
+func TestResolveExtendsNoop(t *testing.T) {
+	data := []byte("# comment\nname: tool\n")
+	got, err := ResolveExtends(data, "tool.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("ResolveExtends() = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,42 @@
+package spec
+
+import "strings"
+
+// Default GitHub URLs used when the spec does not target a GitHub Enterprise
+// Server.
+const (
+	DefaultGitHubBaseURL = "https://github.com"
+	DefaultGitHubAPIURL  = "https://api.github.com"
+)
+
+// GitHubBase returns the GitHub web URL without a trailing slash.
+func (s *InstallSpec) GitHubBase() string {
+	if s.GitHubBaseURL != "" {
+		return strings.TrimSuffix(s.GitHubBaseURL, "/")
+	}
+	if s.GitHubAPIURL != "" {
+		return GitHubBaseFromAPIURL(s.GitHubAPIURL)
+	}
+	return DefaultGitHubBaseURL
+}
+
+// GitHubAPI returns the GitHub REST API URL without a trailing slash.
+func (s *InstallSpec) GitHubAPI() string {
+	if s.GitHubAPIURL != "" {
+		return strings.TrimSuffix(s.GitHubAPIURL, "/")
+	}
+	if s.GitHubBaseURL != "" {
+		return strings.TrimSuffix(s.GitHubBaseURL, "/") + "/api/v3"
+	}
+	return DefaultGitHubAPIURL
+}
+
+// GitHubBaseFromAPIURL derives the web URL from a GitHub API URL, e.g.
+// "https://ghe.example.com" from "https://ghe.example.com/api/v3".
+func GitHubBaseFromAPIURL(apiURL string) string {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	if apiURL == DefaultGitHubAPIURL {
+		return DefaultGitHubBaseURL
+	}
+	return strings.TrimSuffix(apiURL, "/api/v3")
+}
@@ -0,0 +1,40 @@
+package spec
+
+import "testing"
+
+func TestGitHubURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     InstallSpec
+		wantBase string
+		wantAPI  string
+	}{
+		{
+			name:     "default",
+			wantBase: "https://github.com",
+			wantAPI:  "https://api.github.com",
+		},
+		{
+			name:     "base url",
+			spec:     InstallSpec{GitHubBaseURL: "https://ghe.example.com/"},
+			wantBase: "https://ghe.example.com",
+			wantAPI:  "https://ghe.example.com/api/v3",
+		},
+		{
+			name:     "api url",
+			spec:     InstallSpec{GitHubAPIURL: "https://ghe.example.com/api/v3"},
+			wantBase: "https://ghe.example.com",
+			wantAPI:  "https://ghe.example.com/api/v3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.GitHubBase(); got != tt.wantBase {
+				t.Errorf("GitHubBase() = %q, want %q", got, tt.wantBase)
+			}
+			if got := tt.spec.GitHubAPI(); got != tt.wantAPI {
+				t.Errorf("GitHubAPI() = %q, want %q", got, tt.wantAPI)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package spec
+
+import "strings"
+
+// C library variants used for the ${LIBC} placeholder and the libc rule
+// condition.
+const (
+	LibcGNU  = "gnu"
+	LibcMusl = "musl"
+)
+
+// UsesLibc reports whether the asset naming depends on the C library, either
+// through a ${LIBC} placeholder in a template or a rule keyed on libc.
+func (a *AssetConfig) UsesLibc() bool {
+	if strings.Contains(a.Template, "${LIBC}") {
+		return true
+	}
+	for _, rule := range a.Rules {
+		if rule.When.Libc != "" || strings.Contains(rule.Template, "${LIBC}") {
+			return true
+		}
+	}
+	return false
+}
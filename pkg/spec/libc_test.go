@@ -0,0 +1,23 @@
+package spec
+
+import "testing"
+
+func TestUsesLibc(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset AssetConfig
+		want  bool
+	}{
+		{"none", AssetConfig{Template: "${NAME}_${OS}_${ARCH}${EXT}"}, false},
+		{"template", AssetConfig{Template: "${NAME}-${ARCH}-unknown-linux-${LIBC}${EXT}"}, true},
+		{"rule condition", AssetConfig{Rules: []AssetRule{{When: PlatformCondition{Libc: LibcMusl}}}}, true},
+		{"rule template", AssetConfig{Rules: []AssetRule{{Template: "${NAME}-${LIBC}"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.asset.UsesLibc(); got != tt.want {
+				t.Errorf("UsesLibc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
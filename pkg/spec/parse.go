@@ -0,0 +1,129 @@
+package spec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/haya14busa/goinstaller/pkg/binsterr"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaV2 is the strict spec schema version.
+const SchemaV2 = "v2"
+
+// Parse unmarshals spec YAML. Specs declaring "schema: v2" are parsed
+// strictly: unknown fields and unsupported enum values are errors reported
+// with their line numbers. Other schemas are parsed leniently for
+// compatibility. Errors are binsterr.ErrSpecInvalid.
+func Parse(data []byte) (*InstallSpec, error) {
+	s, err := parse(data)
+	if err != nil {
+		return nil, binsterr.Wrap(binsterr.ErrSpecInvalid, err)
+	}
+	return s, nil
+}
+
+func parse(data []byte) (*InstallSpec, error) {
+	var header struct {
+		Schema string `yaml:"schema"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	var s InstallSpec
+	if header.Schema != SchemaV2 {
+		if err := yaml.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		return &s, nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid %s spec: %w", SchemaV2, err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if err := validateEnums(&root); err != nil {
+		return nil, fmt.Errorf("invalid %s spec: %w", SchemaV2, err)
+	}
+	return &s, nil
+}
+
+// enumFields lists the enum-typed spec fields checked by strict parsing.
+var enumFields = []struct {
+	path    []string
+	allowed []string
+}{
+	{[]string{"asset", "naming_convention", "os"}, []string{string(NamingLowercase), string(NamingTitlecase)}},
+	{[]string{"asset", "naming_convention", "arch"}, []string{string(NamingLowercase)}},
+	{[]string{"checksums", "algorithm"}, hashAlgorithmNames()},
+}
+
+func hashAlgorithmNames() []string {
+	names := make([]string, 0, len(HashAlgorithms))
+	for _, a := range HashAlgorithms {
+		names = append(names, string(a))
+	}
+	return names
+}
+
+// validateEnums checks enum fields of the spec document root and of each
+// entry of a multi-tool spec.
+func validateEnums(root *yaml.Node) error {
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	specs := []*yaml.Node{doc}
+	if tools := lookupNode(doc, "tools"); tools != nil && tools.Kind == yaml.SequenceNode {
+		specs = append(specs, tools.Content...)
+	}
+	for _, s := range specs {
+		for _, f := range enumFields {
+			n := lookupNode(s, f.path...)
+			if n == nil || n.Value == "" {
+				continue
+			}
+			if !containsString(f.allowed, n.Value) {
+				return fmt.Errorf("line %d: unsupported %s %q (want one of: %s)",
+					n.Line, strings.Join(f.path, "."), n.Value, strings.Join(f.allowed, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// lookupNode returns the value node at the mapping key path, or nil.
+func lookupNode(n *yaml.Node, path ...string) *yaml.Node {
+	for _, key := range path {
+		if n == nil || n.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				next = n.Content[i+1]
+				break
+			}
+		}
+		n = next
+	}
+	return n
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
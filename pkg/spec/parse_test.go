@@ -0,0 +1,52 @@
+package spec
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "v1 ignores unknown fields",
+			yaml: "schema: v1\nrepo: o/r\nunknown: true\nasset:\n  naming_convention:\n    os: UPPER\n",
+		},
+		{
+			name: "v2 valid",
+			yaml: "schema: v2\nrepo: o/r\nasset:\n  template: ${NAME}\n  naming_convention:\n    os: titlecase\nchecksums:\n  algorithm: sha512\n",
+		},
+		{
+			name:    "v2 unknown field",
+			yaml:    "schema: v2\nrepo: o/r\nasset:\n  templete: ${NAME}\n",
+			wantErr: "line 4: field templete not found",
+		},
+		{
+			name:    "v2 bad naming convention",
+			yaml:    "schema: v2\nrepo: o/r\nasset:\n  naming_convention:\n    os: UPPER\n",
+			wantErr: `line 5: unsupported asset.naming_convention.os "UPPER"`,
+		},
+		{
+			name:    "v2 bad algorithm in tool",
+			yaml:    "schema: v2\ntools:\n  - repo: o/r\n    checksums:\n      algorithm: crc32\n",
+			wantErr: `line 5: unsupported checksums.algorithm "crc32"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package spec
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"gopkg.in/yaml.v3"
+)
+
+// renovatePrefix starts the comments Renovate custom regex managers match to
+// find the dependency of the pinned version on the next line.
+const renovatePrefix = "renovate:"
+
+// versionRangeRe matches version ranges such as ^1.2, ~1.2.3 or 1.x, as
+// is_version_range of the installer script does.
+var versionRangeRe = regexp.MustCompile(`^[~^]|(^|[.])[xX*]([.]|$)`)
+
+// RenovateComment returns the Renovate annotation of the pinned default
+// version, e.g. "renovate: datasource=github-releases depName=owner/repo".
+// It returns "" if the spec installs the latest release or a version range,
+// or does not take versions from GitHub releases.
+func (s *InstallSpec) RenovateComment() string {
+	v := s.DefaultVersion
+	if s.Repo == "" || v == "" || v == "latest" || versionRangeRe.MatchString(v) {
+		return ""
+	}
+	if s.Version != nil && s.Version.Source != "" && s.Version.Source != "github" {
+		return ""
+	}
+	comment := renovatePrefix + " datasource=github-releases depName=" + s.Repo
+	if base := s.GitHubBase(); base != DefaultGitHubBaseURL {
+		comment += " registryUrl=" + base
+	}
+	return comment
+}
+
+// Marshal encodes the spec as YAML, with the RenovateComment above pinned
+// default versions.
+func Marshal(s *InstallSpec) ([]byte, error) {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	if !s.hasRenovateComment() {
+		return data, nil
+	}
+	file, err := parser.ParseBytes(data, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	AnnotateRenovate(file, s)
+	out := file.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return []byte(out), nil
+}
+
+func (s *InstallSpec) hasRenovateComment() bool {
+	if s.RenovateComment() != "" {
+		return true
+	}
+	for i := range s.Tools {
+		if s.Tools[i].RenovateComment() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotateRenovate puts the RenovateComment of the spec and of each tool of a
+// multi-tool spec above their default_version in the spec AST. Renovate
+// comments of versions that are no longer pinned are removed; other comments
+// are kept.
+func AnnotateRenovate(file *ast.File, s *InstallSpec) {
+	for _, doc := range file.Docs {
+		annotateRenovate(doc.Body, s)
+	}
+}
+
+func annotateRenovate(node ast.Node, s *InstallSpec) {
+	for _, mv := range mappingValues(node) {
+		switch mv.Key.String() {
+		case "default_version":
+			setRenovateComment(mv, s.RenovateComment())
+		case "tools":
+			seq, ok := mv.Value.(*ast.SequenceNode)
+			if !ok {
+				continue
+			}
+			for i, v := range seq.Values {
+				if i < len(s.Tools) {
+					annotateRenovate(v, &s.Tools[i])
+				}
+			}
+		}
+	}
+}
+
+// mappingValues returns the key-value pairs of a mapping node.
+func mappingValues(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}
+	}
+	return nil
+}
+
+func setRenovateComment(mv *ast.MappingValueNode, comment string) {
+	var tokens []*token.Token
+	if group := mv.GetComment(); group != nil {
+		for _, c := range group.Comments {
+			if !strings.HasPrefix(strings.TrimSpace(c.Token.Value), renovatePrefix) {
+				tokens = append(tokens, c.Token)
+			}
+		}
+	}
+	if comment != "" {
+		pos := *mv.Key.GetToken().Position
+		tokens = append(tokens, token.New(" "+comment, "# "+comment, &pos))
+	}
+	if len(tokens) == 0 {
+		_ = mv.SetComment(nil)
+		return
+	}
+	_ = mv.SetComment(ast.CommentGroup(tokens))
+}
@@ -0,0 +1,112 @@
+package spec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml/parser"
+)
+
+func TestRenovateComment(t *testing.T) {
+	tests := []struct {
+		name string
+		spec InstallSpec
+		want string
+	}{
+		{"pinned", InstallSpec{Repo: "owner/tool", DefaultVersion: "v1.2.3"},
+			"renovate: datasource=github-releases depName=owner/tool"},
+		{"GHES", InstallSpec{Repo: "owner/tool", DefaultVersion: "v1.2.3", GitHubAPIURL: "https://ghe.example.com/api/v3"},
+			"renovate: datasource=github-releases depName=owner/tool registryUrl=https://ghe.example.com"},
+		{"latest", InstallSpec{Repo: "owner/tool", DefaultVersion: "latest"}, ""},
+		{"unset", InstallSpec{Repo: "owner/tool"}, ""},
+		{"range", InstallSpec{Repo: "owner/tool", DefaultVersion: "^1.2"}, ""},
+		{"wildcard", InstallSpec{Repo: "owner/tool", DefaultVersion: "1.x"}, ""},
+		{"static versions", InstallSpec{Repo: "owner/tool", DefaultVersion: "v1.2.3", Version: &VersionConfig{Source: "static"}}, ""},
+		{"no repo", InstallSpec{DefaultVersion: "v1.2.3"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.RenovateComment(); got != tt.want {
+				t.Errorf("RenovateComment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	s := &InstallSpec{
+		Name:           "tool",
+		Repo:           "owner/tool",
+		DefaultVersion: "v1.2.3",
+		Tools: []InstallSpec{
+			{Name: "a", Repo: "owner/a", DefaultVersion: "v2.0.0"},
+			{Name: "b", Repo: "owner/b", DefaultVersion: "latest"},
+		},
+	}
+	data, err := Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		"# renovate: datasource=github-releases depName=owner/tool\ndefault_version: v1.2.3\n",
+		"      # renovate: datasource=github-releases depName=owner/a\n      default_version: v2.0.0\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Marshal() = %s\nwant to contain %q", got, want)
+		}
+	}
+	if strings.Count(got, "renovate:") != 2 {
+		t.Errorf("Marshal() = %s\nwant 2 Renovate comments", got)
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if parsed.DefaultVersion != "v1.2.3" || parsed.Tools[0].DefaultVersion != "v2.0.0" {
+		t.Errorf("Parse(Marshal()) = %+v", parsed)
+	}
+
+	s.DefaultVersion = "latest"
+	s.Tools = nil
+	data, err = Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), "renovate:") {
+		t.Errorf("Marshal() = %s\nwant no Renovate comment", data)
+	}
+}
+
+func TestAnnotateRenovate(t *testing.T) {
+	src := `name: tool
+repo: owner/tool
+# Bump with care
+# renovate: datasource=github-releases depName=owner/old
+default_version: v1.2.3 # pinned
+`
+	file, err := parser.ParseBytes([]byte(src), parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AnnotateRenovate(file, &InstallSpec{Repo: "owner/tool", DefaultVersion: "v1.2.3"})
+	want := `name: tool
+repo: owner/tool
+# Bump with care
+# renovate: datasource=github-releases depName=owner/tool
+default_version: v1.2.3 # pinned
+`
+	if got := file.String(); got != want {
+		t.Errorf("annotated spec mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	AnnotateRenovate(file, &InstallSpec{Repo: "owner/tool", DefaultVersion: "latest"})
+	want = `name: tool
+repo: owner/tool
+# Bump with care
+default_version: v1.2.3 # pinned
+`
+	if got := file.String(); got != want {
+		t.Errorf("annotated spec mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -4,16 +4,49 @@ import "strings"
 
 // InstallSpec defines the v1 configuration schema for binstaller.
 type InstallSpec struct {
-	Schema             string             `yaml:"schema,omitempty"`          // Default: "v1"
-	Name               string             `yaml:"name,omitempty"`            // Optiona. Binary name
-	Repo               string             `yaml:"repo"`                      // GitHub owner/repo (e.g., "owner/repo")
-	DefaultVersion     string             `yaml:"default_version,omitempty"` // Default: "latest"
-	DefaultBinDir      string             `yaml:"default_bin_dir,omitempty"` // Default: "${BINSTALLER_BIN} or ${HOME}/.local/bin"
-	Asset              AssetConfig        `yaml:"asset"`
-	Checksums          *ChecksumConfig    `yaml:"checksums,omitempty"`
-	Attestation        *AttestationConfig `yaml:"attestation,omitempty"`
-	Unpack             *UnpackConfig      `yaml:"unpack,omitempty"`
-	SupportedPlatforms []Platform         `yaml:"supported_platforms,omitempty"`
+	Schema             string              `yaml:"schema,omitempty"`          // Default: "v1"
+	Extends            string              `yaml:"extends,omitempty"`         // Path or URL of a base spec this spec overrides
+	Name               string              `yaml:"name,omitempty"`            // Optiona. Binary name
+	Repo               string              `yaml:"repo"`                      // GitHub owner/repo (e.g., "owner/repo")
+	DefaultVersion     string              `yaml:"default_version,omitempty"` // Default: "latest"
+	DefaultBinDir      BinDir              `yaml:"default_bin_dir,omitempty"` // Default: "${BINSTALLER_BIN} or ${HOME}/.local/bin"
+	GitHubBaseURL      string              `yaml:"github_base_url,omitempty"` // GitHub Enterprise Server URL. Default: "https://github.com"
+	GitHubAPIURL       string              `yaml:"github_api_url,omitempty"`  // Default: "https://api.github.com" or "${github_base_url}/api/v3"
+	Version            *VersionConfig      `yaml:"version,omitempty"`
+	Variables          map[string]Variable `yaml:"variables,omitempty"` // Custom ${NAME} placeholders for asset and checksum templates
+	Asset              AssetConfig         `yaml:"asset"`
+	Checksums          *ChecksumConfig     `yaml:"checksums,omitempty"`
+	Attestation        *AttestationConfig  `yaml:"attestation,omitempty"`
+	Signature          *SignatureConfig    `yaml:"signature,omitempty"`
+	Provenance         *ProvenanceConfig   `yaml:"provenance,omitempty"`
+	Download           *DownloadConfig     `yaml:"download,omitempty"`
+	Unpack             *UnpackConfig       `yaml:"unpack,omitempty"`
+	Image              *ImageConfig        `yaml:"image,omitempty"` // Install from a container image instead of a release asset
+	Install            *InstallConfig      `yaml:"install,omitempty"`
+	PostInstall        *PostInstallConfig  `yaml:"post_install,omitempty"`
+	Verify             *VerifyConfig       `yaml:"verify,omitempty"`
+	SupportedPlatforms []Platform          `yaml:"supported_platforms,omitempty"`
+	// Tools makes this a multi-tool spec: each entry is a complete spec of
+	// one tool and the other top-level fields except schema are ignored.
+	Tools []InstallSpec `yaml:"tools,omitempty"`
+}
+
+// VersionConfig controls how the "latest" version is resolved.
+type VersionConfig struct {
+	Source    string   `yaml:"source,omitempty"`     // "github" | "static" | "url", Default: "github"
+	Static    []string `yaml:"static,omitempty"`     // static: known tags, newest first
+	LatestURL string   `yaml:"latest_url,omitempty"` // url: URL whose body is the latest tag (e.g. https://dl.example.com/stable.txt)
+	// TagPrefix is the part of release tags before the version, for repos
+	// tagging like "cli/v1.2.3". Latest release lookup only considers tags
+	// with this prefix.
+	TagPrefix string `yaml:"tag_prefix,omitempty"`
+	// TagTemplate builds the tag from ${VERSION} when a bare version is
+	// requested (e.g. "cli/v${VERSION}"). The text before ${VERSION} is used
+	// as the tag prefix when tag_prefix is not set.
+	TagTemplate string `yaml:"tag_template,omitempty"`
+	// Prerelease makes latest release lookup consider prereleases, for tools
+	// whose users track release candidates.
+	Prerelease bool `yaml:"prerelease,omitempty"`
 }
 
 // Platform defines a supported OS/Arch combination.
@@ -24,12 +57,19 @@ type Platform struct {
 
 // AssetConfig describes how to construct download URLs and names.
 type AssetConfig struct {
-	Template         string            `yaml:"template"` // Filename template
-	DefaultExtension string            `yaml:"default_extension,omitempty"`
-	Binaries         []Binary          `yaml:"binaries,omitempty"` // binary name and path
-	Rules            []AssetRule       `yaml:"rules,omitempty"`
-	NamingConvention *NamingConvention `yaml:"naming_convention,omitempty"`
-	ArchEmulation    *ArchEmulation    `yaml:"arch_emulation,omitempty"`
+	Template string `yaml:"template"` // Filename template
+	// Download URL template for non-GitHub hosting, e.g.
+	// "https://dl.example.com/${NAME}/${VERSION}/${ASSET_FILENAME}".
+	// Default: "https://github.com/${REPO}/releases/download/${TAG}/${ASSET_FILENAME}"
+	DownloadURLTemplate string            `yaml:"download_url_template,omitempty"`
+	DefaultExtension    string            `yaml:"default_extension,omitempty"` // Empty for raw binaries, which get an ".exe" suffix on Windows
+	Binaries            []Binary          `yaml:"binaries,omitempty"`          // binary name and path
+	ExtraFiles          []ExtraFile       `yaml:"extra_files,omitempty"`       // Auxiliary files such as completions and man pages
+	Rules               []AssetRule       `yaml:"rules,omitempty"`
+	NamingConvention    *NamingConvention `yaml:"naming_convention,omitempty"`
+	ArchEmulation       *ArchEmulation    `yaml:"arch_emulation,omitempty"`
+	IgnoreAssets        []string          `yaml:"ignore_assets,omitempty"` // Glob patterns of asset filenames to never select
+	PreferAssets        []string          `yaml:"prefer_assets,omitempty"` // Glob patterns of asset filenames that win over ignore_assets
 }
 
 // AssetRule defines overrides for specific platforms.
@@ -40,42 +80,70 @@ type AssetRule struct {
 	Arch     string            `yaml:"arch,omitempty"`     // Optional override ARCH
 	Ext      string            `yaml:"ext,omitempty"`      // Optional override extension
 	Binaries []Binary          `yaml:"binaries,omitempty"` // Optional override binary name and path
+	// FallbackTemplates are tried in order when the asset named by the
+	// template does not exist, e.g. because naming changed across releases.
+	FallbackTemplates []string `yaml:"fallback_templates,omitempty"`
 }
 
-// Binary defines overrides for specific binary namd and path to binary from extracted directory
+// Binary defines overrides for specific binary namd and path to binary from extracted directory.
+// On Windows, ".exe" is appended to Name and Path unless they already have it.
 type Binary struct {
-	Name string `yaml:"name"`
-	Path string `yaml:"path"`
+	Name    string   `yaml:"name"`
+	Path    string   `yaml:"path"`
+	Aliases []string `yaml:"aliases,omitempty"` // Additional names symlinked to the binary in the bin dir
+}
+
+// ExtraFile is an auxiliary file in the archive, such as a shell completion,
+// man page or license, installed alongside the binaries.
+type ExtraFile struct {
+	Src  string `yaml:"src"`  // Path in the extracted archive. Glob patterns are allowed, e.g. "completions/*"
+	Dest string `yaml:"dest"` // Destination directory, relative to the install prefix (parent of the bin dir) unless absolute, e.g. "share/man/man1"
 }
 
 // PlatformCondition specifies conditions for an AssetRule.
 type PlatformCondition struct {
 	OS   string `yaml:"os,omitempty"`
 	Arch string `yaml:"arch,omitempty"`
+	Libc string `yaml:"libc,omitempty"` // "gnu" | "musl" (linux only)
+	// Distro is the Linux distribution as ID and VERSION_ID of /etc/os-release,
+	// e.g. "ubuntu20.04" or "amzn2"; exposed as ${DISTRO}. (linux only)
+	Distro string `yaml:"distro,omitempty"`
 }
 
 // NamingConvention controls the casing of placeholders.
 type NamingConvention struct {
-	OS   string `yaml:"os,omitempty"`   // "lowercase" | "titlecase", Default: "lowercase"
-	Arch string `yaml:"arch,omitempty"` // "lowercase", Default: "lowercase"
+	OS   NamingCase `yaml:"os,omitempty"`   // "lowercase" | "titlecase", Default: "lowercase"
+	Arch NamingCase `yaml:"arch,omitempty"` // "lowercase", Default: "lowercase"
 }
 
 // ArchEmulation controls options of arch emulation.
 type ArchEmulation struct {
-	Rosetta2 bool `yaml:"rosetta2,omitempty"` // If true, use amd64 as ARCH instead of arm64 if Rosetta2 is available
+	Rosetta2        bool `yaml:"rosetta2,omitempty"`          // If true, use amd64 as ARCH instead of arm64 if Rosetta2 is available
+	WindowsArm64X64 bool `yaml:"windows_arm64_x64,omitempty"` // If true, use amd64 as ARCH on windows/arm64 unless a native asset is supported
 }
 
 // ChecksumConfig defines how to verify checksums.
 type ChecksumConfig struct {
-	Algorithm         string                        `yaml:"algorithm,omitempty"`          // Default: "sha256"
-	Template          string                        `yaml:"template,omitempty"`           // Checksum filename template
+	Algorithm         HashAlgorithm                 `yaml:"algorithm,omitempty"`          // Default: "sha256"
+	Template          string                        `yaml:"template,omitempty"`           // Checksum filename template, or a full http(s) URL template for checksums hosted outside the release
+	Rules             []ChecksumRule                `yaml:"rules,omitempty"`              // Per-platform checksum filename templates
 	EmbeddedChecksums map[string][]EmbeddedChecksum `yaml:"embedded_checksums,omitempty"` // Keyed by version string
+	RequireEmbedded   *bool                         `yaml:"require_embedded,omitempty"`   // Default: false. If true, fail when no checksum is embedded for the asset
+}
+
+// ChecksumRule overrides the checksum filename template for matching
+// platforms, for projects publishing one checksum file per OS or arch.
+type ChecksumRule struct {
+	When     PlatformCondition `yaml:"when"`
+	Template string            `yaml:"template"`
 }
 
 // EmbeddedChecksum holds pre-verified checksum information.
 type EmbeddedChecksum struct {
-	Filename string `yaml:"filename"` // Asset filename
-	Hash     string `yaml:"hash"`     // Checksum hash
+	Filename  string        `yaml:"filename"`            // Asset filename
+	Hash      string        `yaml:"hash"`                // Checksum hash
+	Algorithm HashAlgorithm `yaml:"algorithm,omitempty"` // Default: checksums.algorithm
+	URLs      []string      `yaml:"urls,omitempty"`      // Optional alternate download URLs (mirrors), tried in order
 }
 
 // AttestationConfig defines settings for attestation verification.
@@ -85,9 +153,167 @@ type AttestationConfig struct {
 	VerifyFlags string `yaml:"verify_flags,omitempty"` // Additional flags for 'gh attestation verify'
 }
 
+// SignatureConfig defines settings for signature verification with cosign,
+// minisign or signify. Signature and certificate templates are release asset
+// names and may use ${NAME}, ${VERSION}, ${TAG}, ${OS}, ${ARCH},
+// ${ASSET_FILENAME}, ${CHECKSUM_FILENAME} and ${TARGET_FILENAME} (the file
+// being verified).
+type SignatureConfig struct {
+	Enabled                   *bool  `yaml:"enabled,omitempty"`                     // Default: false
+	Require                   *bool  `yaml:"require,omitempty"`                     // Default: false. If true, fail when the verifier is not installed
+	Type                      string `yaml:"type,omitempty"`                        // "cosign" | "minisign" | "signify", Default: "cosign"
+	Target                    string `yaml:"target,omitempty"`                      // "asset" | "checksum", Default: "checksum" if checksums.template is set, else "asset"
+	Key                       string `yaml:"key,omitempty"`                         // cosign: public key reference (e.g. URL), keyless if empty. minisign/signify: base64 public key (required)
+	CertificateIdentity       string `yaml:"certificate_identity,omitempty"`        // Keyless: expected certificate identity
+	CertificateIdentityRegexp string `yaml:"certificate_identity_regexp,omitempty"` // Keyless: expected certificate identity regexp
+	CertificateOIDCIssuer     string `yaml:"certificate_oidc_issuer,omitempty"`     // Keyless: expected OIDC issuer, e.g. https://token.actions.githubusercontent.com
+	SignatureTemplate         string `yaml:"signature_template,omitempty"`          // Default: "${TARGET_FILENAME}.minisig" for minisign, else "${TARGET_FILENAME}.sig"
+	CertificateTemplate       string `yaml:"certificate_template,omitempty"`        // Keyless: Default: "${TARGET_FILENAME}.pem"
+}
+
+// ProvenanceConfig defines settings for SLSA provenance verification with
+// slsa-verifier, for projects using the SLSA GitHub generators.
+type ProvenanceConfig struct {
+	Enabled   *bool  `yaml:"enabled,omitempty"`    // Default: false
+	Require   *bool  `yaml:"require,omitempty"`    // Default: false. If true, fail when slsa-verifier is not installed
+	Template  string `yaml:"template,omitempty"`   // Provenance asset name template, Default: "${ASSET_FILENAME}.intoto.jsonl"
+	SourceURI string `yaml:"source_uri,omitempty"` // Expected source repository, Default: "github.com/${REPO}"
+	BuilderID string `yaml:"builder_id,omitempty"` // Optional expected builder ID
+}
+
+// DownloadConfig controls how the installer downloads files. Users can
+// override the settings with --retries/--timeout or BINSTALLER_RETRIES and
+// BINSTALLER_TIMEOUT.
+type DownloadConfig struct {
+	Retries *int `yaml:"retries,omitempty"` // Default: 3. Retries after transient failures, with exponential backoff
+	Timeout int  `yaml:"timeout,omitempty"` // Seconds a single download may take, Default: 0 (no limit)
+}
+
+// DefaultDownloadRetries is the number of download retries if not configured.
+const DefaultDownloadRetries = 3
+
+// RetryCount returns the configured number of download retries. It is safe
+// to call on a nil DownloadConfig.
+func (d *DownloadConfig) RetryCount() int {
+	if d == nil || d.Retries == nil {
+		return DefaultDownloadRetries
+	}
+	return *d.Retries
+}
+
+// TimeoutSeconds returns the configured download timeout, 0 for no limit. It
+// is safe to call on a nil DownloadConfig.
+func (d *DownloadConfig) TimeoutSeconds() int {
+	if d == nil {
+		return 0
+	}
+	return d.Timeout
+}
+
+// InstallConfig controls how binaries are placed in the bin dir.
+type InstallConfig struct {
+	// If true, install binaries as NAME-VERSION and symlink NAME to it, so
+	// that previous versions are kept for easy rollback.
+	Versioned bool `yaml:"versioned,omitempty"`
+	// Command printing the version of the installed binary, which is
+	// available as ${BINARY}, e.g. "${BINARY} --version". If set, the
+	// installer exits early when the binary already reports the requested
+	// version.
+	VersionCommand string `yaml:"version_command,omitempty"`
+	// Extended regular expression matching the version in the output of
+	// VersionCommand. Matches are compared with the requested version,
+	// ignoring a leading "v". Default: "[0-9]+(\.[0-9A-Za-z]+)+(-[0-9A-Za-z.]+)?"
+	VersionRegex string `yaml:"version_regex,omitempty"`
+	// Go package built with "go install PACKAGE@vVERSION" when the release
+	// has no asset for the platform and the user opts in with --go-install,
+	// e.g. "github.com/owner/repo/cmd/tool".
+	GoModule string `yaml:"go_module,omitempty"`
+	// Command printing the completion script of ${COMPLETION_SHELL} with the
+	// installed binary available as ${BINARY}, e.g.
+	// "${BINARY} completion ${COMPLETION_SHELL}". If set, the installer
+	// installs completions into the standard directories under the install
+	// prefix when run with --completions.
+	CompletionCommand string `yaml:"completion_command,omitempty"`
+	// Shells to install completions for: bash, zsh or fish.
+	// Default: [bash, zsh, fish]
+	CompletionShells []string `yaml:"completion_shells,omitempty"`
+}
+
+// CompletionShells are the shells the installer can install completions for.
+var CompletionShells = []string{"bash", "zsh", "fish"}
+
+// Completions returns the shells to install completions for, or nil if no
+// completion command is configured. It is safe to call on a nil
+// InstallConfig.
+func (i *InstallConfig) Completions() []string {
+	if i == nil || i.CompletionCommand == "" {
+		return nil
+	}
+	if len(i.CompletionShells) > 0 {
+		return i.CompletionShells
+	}
+	return CompletionShells
+}
+
+// PostInstallConfig defines commands run by the installer after the
+// binaries are installed. Hooks are opt-in: they only run if Enabled is true,
+// and users can skip them by setting BINSTALLER_SKIP_POST_INSTALL.
+type PostInstallConfig struct {
+	Enabled *bool             `yaml:"enabled,omitempty"` // Default: false
+	Hooks   []PostInstallHook `yaml:"hooks,omitempty"`
+}
+
+// PostInstallHook is a single post-install step. Run commands may use
+// ${NAME}, ${VERSION}, ${TAG}, ${OS}, ${ARCH} and ${BINDIR}, e.g.
+// "${BINDIR}/${NAME} completion bash > ~/.bash_completion.d/${NAME}".
+type PostInstallHook struct {
+	Name        string `yaml:"name,omitempty"`         // Human readable name used in logs
+	Run         string `yaml:"run,omitempty"`          // Shell command to run
+	Message     string `yaml:"message,omitempty"`      // Message to print to the user
+	IgnoreError bool   `yaml:"ignore_error,omitempty"` // If true, a failing command only logs a warning
+}
+
 // UnpackConfig controls how archives are extracted.
 type UnpackConfig struct {
 	StripComponents *int `yaml:"strip_components,omitempty"` // Default: 0
+	// If true, the installer extracts tarballs by piping them through
+	// gzip, xz or bzip2 into plain tar, which works with minimal tar
+	// implementations such as BusyBox tar on Alpine. Default: false
+	Portable bool `yaml:"portable,omitempty"`
+}
+
+// ImageConfig installs the binary from a container image, for projects that
+// only ship binaries inside images. The installer extracts Path from the
+// linux image of the platform with crane or skopeo and installs it as a raw
+// binary named by the asset template, which is also the filename of its
+// embedded checksums.
+type ImageConfig struct {
+	// Image reference, e.g. "ghcr.io/owner/tool:${TAG}". ${VERSION} and
+	// ${TAG} are expanded.
+	Ref string `yaml:"ref"`
+	// Absolute path of the binary inside the image, e.g. "/usr/local/bin/tool"
+	Path string `yaml:"path"`
+}
+
+// Reference returns the image reference of the release tag.
+func (c *ImageConfig) Reference(tag, version string) string {
+	return strings.NewReplacer("${TAG}", tag, "${VERSION}", version).Replace(c.Ref)
+}
+
+// VerifyConfig defines additional verification steps for downloaded assets.
+type VerifyConfig struct {
+	Plugins []VerifyPlugin `yaml:"plugins,omitempty"`
+}
+
+// VerifyPlugin is an external command run against each downloaded asset.
+// The command receives the asset path as its last argument, asset metadata
+// as BINSTALLER_* environment variables and as a JSON object on stdin.
+// A non-zero exit status aborts the installation. binst mirror and
+// embed-checksums --mode calculate run them against the assets they download.
+type VerifyPlugin struct {
+	Name     string `yaml:"name"`               // Human readable name used in logs
+	Command  string `yaml:"command"`            // Shell command line, e.g. "clamscan --no-summary"
+	Required *bool  `yaml:"required,omitempty"` // Default: true. If false, skip when the command is not installed
 }
 
 // Default values for pointers
@@ -98,17 +324,20 @@ func (s *InstallSpec) SetDefaults() {
 	if s.DefaultVersion == "" {
 		s.DefaultVersion = "latest"
 	}
-	if s.DefaultBinDir == "" {
-		s.DefaultBinDir = "${BINSTALLER_BIN:-${HOME}/.local/bin}"
+	if s.DefaultBinDir.Default() == "" {
+		if s.DefaultBinDir == nil {
+			s.DefaultBinDir = BinDir{}
+		}
+		s.DefaultBinDir[BinDirDefaultKey] = "${BINSTALLER_BIN:-${HOME}/.local/bin}"
 	}
 	if s.Asset.NamingConvention == nil {
 		s.Asset.NamingConvention = &NamingConvention{}
 	}
 	if s.Asset.NamingConvention.OS == "" {
-		s.Asset.NamingConvention.OS = "lowercase"
+		s.Asset.NamingConvention.OS = NamingLowercase
 	}
 	if s.Asset.NamingConvention.Arch == "" {
-		s.Asset.NamingConvention.Arch = "lowercase"
+		s.Asset.NamingConvention.Arch = NamingLowercase
 	}
 	if s.Name == "" && s.Repo != "" {
 		sp := strings.SplitN(s.Repo, "/", 2)
@@ -129,8 +358,64 @@ func (s *InstallSpec) SetDefaults() {
 	}
 	if s.Checksums != nil {
 		if s.Checksums.Algorithm == "" {
-			s.Checksums.Algorithm = "sha256"
+			s.Checksums.Algorithm = SHA256
+		}
+	}
+	if s.Verify != nil {
+		for i := range s.Verify.Plugins {
+			if s.Verify.Plugins[i].Required == nil {
+				required := true
+				s.Verify.Plugins[i].Required = &required
+			}
+		}
+	}
+	if s.Signature != nil {
+		if s.Signature.Enabled == nil {
+			enabled := false
+			s.Signature.Enabled = &enabled
 		}
+		if s.Signature.Require == nil {
+			require := false
+			s.Signature.Require = &require
+		}
+		if s.Signature.Type == "" {
+			s.Signature.Type = "cosign"
+		}
+		if s.Signature.SignatureTemplate == "" {
+			s.Signature.SignatureTemplate = "${TARGET_FILENAME}.sig"
+			if s.Signature.Type == "minisign" {
+				s.Signature.SignatureTemplate = "${TARGET_FILENAME}.minisig"
+			}
+		}
+		if s.Signature.Target == "" {
+			s.Signature.Target = "asset"
+			if s.Checksums != nil && s.Checksums.Template != "" {
+				s.Signature.Target = "checksum"
+			}
+		}
+	}
+	if s.Version != nil && s.Version.Source == "" {
+		s.Version.Source = "github"
+	}
+	if s.Provenance != nil {
+		if s.Provenance.Enabled == nil {
+			enabled := false
+			s.Provenance.Enabled = &enabled
+		}
+		if s.Provenance.Require == nil {
+			require := false
+			s.Provenance.Require = &require
+		}
+		if s.Provenance.Template == "" {
+			s.Provenance.Template = "${ASSET_FILENAME}.intoto.jsonl"
+		}
+		if s.Provenance.SourceURI == "" {
+			s.Provenance.SourceURI = "github.com/${REPO}"
+		}
+	}
+	if s.PostInstall != nil && s.PostInstall.Enabled == nil {
+		enabled := false
+		s.PostInstall.Enabled = &enabled
 	}
 	if s.Attestation != nil {
 		if s.Attestation.Enabled == nil {
@@ -0,0 +1,63 @@
+package spec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParsePlatform parses a platform in "os/arch" form, e.g. "linux/amd64".
+func ParsePlatform(s string) (Platform, error) {
+	os, arch, ok := strings.Cut(strings.ToLower(s), "/")
+	if !ok || os == "" || arch == "" || strings.Contains(arch, "/") {
+		return Platform{}, fmt.Errorf("invalid platform %q: want os/arch, e.g. linux/amd64", s)
+	}
+	return Platform{OS: os, Arch: arch}, nil
+}
+
+// String returns the platform in "os/arch" form.
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// ForPlatform returns a copy of the spec that only supports p, for
+// installers with platform detection pre-resolved. Asset and checksum rules
+// of other platforms are dropped, and the OS and arch conditions of the
+// remaining ones removed. Arch emulation is disabled, as the platform is
+// fixed. Embedded checksums are kept as they are.
+func (s *InstallSpec) ForPlatform(p Platform) *InstallSpec {
+	t := *s
+	t.SupportedPlatforms = []Platform{p}
+	t.Asset.ArchEmulation = nil
+	t.Asset.Rules = nil
+	for _, rule := range s.Asset.Rules {
+		if rule.When.matchesPlatform(p) {
+			rule.When.OS, rule.When.Arch = "", ""
+			t.Asset.Rules = append(t.Asset.Rules, rule)
+		}
+	}
+	if s.Checksums != nil {
+		c := *s.Checksums
+		c.Rules = nil
+		for _, rule := range s.Checksums.Rules {
+			if rule.When.matchesPlatform(p) {
+				rule.When.OS, rule.When.Arch = "", ""
+				c.Rules = append(c.Rules, rule)
+			}
+		}
+		t.Checksums = &c
+	}
+	if len(s.DefaultBinDir) > 0 {
+		dir, ok := s.DefaultBinDir[p.OS]
+		if !ok {
+			dir = s.DefaultBinDir.Default()
+		}
+		t.DefaultBinDir = BinDir{BinDirDefaultKey: dir}
+	}
+	return &t
+}
+
+// matchesPlatform reports whether the OS and arch conditions of c match p.
+// Libc and distro conditions are left to the installer.
+func (c PlatformCondition) matchesPlatform(p Platform) bool {
+	return (c.OS == "" || c.OS == p.OS) && (c.Arch == "" || c.Arch == p.Arch)
+}
@@ -0,0 +1,90 @@
+package spec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Platform
+		wantErr bool
+	}{
+		{in: "linux/amd64", want: Platform{OS: "linux", Arch: "amd64"}},
+		{in: "Darwin/ARM64", want: Platform{OS: "darwin", Arch: "arm64"}},
+		{in: "linux", wantErr: true},
+		{in: "linux/", wantErr: true},
+		{in: "/amd64", wantErr: true},
+		{in: "linux/arm/v7", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParsePlatform(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePlatform(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePlatform(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			if !tt.wantErr && got.String() != tt.want.OS+"/"+tt.want.Arch {
+				t.Errorf("String() = %q", got.String())
+			}
+		})
+	}
+}
+
+func TestForPlatform(t *testing.T) {
+	s := &InstallSpec{
+		Name:               "tool",
+		SupportedPlatforms: []Platform{{OS: "linux", Arch: "amd64"}, {OS: "windows", Arch: "amd64"}},
+		DefaultBinDir:      BinDir{BinDirDefaultKey: "${HOME}/.local/bin", "windows": "${LOCALAPPDATA}/bin"},
+		Asset: AssetConfig{
+			Template:      "${NAME}_${OS}_${ARCH}${EXT}",
+			ArchEmulation: &ArchEmulation{Rosetta2: true},
+			Rules: []AssetRule{
+				{When: PlatformCondition{OS: "windows"}, Ext: ".zip"},
+				{When: PlatformCondition{Arch: "amd64"}, Arch: "x86_64"},
+				{When: PlatformCondition{OS: "linux", Libc: "musl"}, Ext: ".musl.tar.gz"},
+			},
+		},
+		Checksums: &ChecksumConfig{
+			Template: "checksums.txt",
+			Rules:    []ChecksumRule{{When: PlatformCondition{OS: "windows"}, Template: "checksums_windows.txt"}},
+		},
+	}
+
+	got := s.ForPlatform(Platform{OS: "linux", Arch: "amd64"})
+	if want := []Platform{{OS: "linux", Arch: "amd64"}}; !reflect.DeepEqual(got.SupportedPlatforms, want) {
+		t.Errorf("SupportedPlatforms = %v, want %v", got.SupportedPlatforms, want)
+	}
+	if got.Asset.ArchEmulation != nil {
+		t.Errorf("ArchEmulation = %v, want nil", got.Asset.ArchEmulation)
+	}
+	wantRules := []AssetRule{
+		{Arch: "x86_64"},
+		{When: PlatformCondition{Libc: "musl"}, Ext: ".musl.tar.gz"},
+	}
+	if !reflect.DeepEqual(got.Asset.Rules, wantRules) {
+		t.Errorf("Asset.Rules = %+v, want %+v", got.Asset.Rules, wantRules)
+	}
+	if len(got.Checksums.Rules) != 0 {
+		t.Errorf("Checksums.Rules = %+v, want none", got.Checksums.Rules)
+	}
+	if want := (BinDir{BinDirDefaultKey: "${HOME}/.local/bin"}); !reflect.DeepEqual(got.DefaultBinDir, want) {
+		t.Errorf("DefaultBinDir = %v, want %v", got.DefaultBinDir, want)
+	}
+
+	// The original spec is left untouched.
+	if len(s.Asset.Rules) != 3 || s.Asset.Rules[0].When.OS != "windows" || len(s.Checksums.Rules) != 1 || s.Asset.ArchEmulation == nil {
+		t.Errorf("ForPlatform() modified the spec: %+v", s)
+	}
+
+	got = s.ForPlatform(Platform{OS: "windows", Arch: "amd64"})
+	if want := (BinDir{BinDirDefaultKey: "${LOCALAPPDATA}/bin"}); !reflect.DeepEqual(got.DefaultBinDir, want) {
+		t.Errorf("DefaultBinDir = %v, want %v", got.DefaultBinDir, want)
+	}
+	if want := []ChecksumRule{{Template: "checksums_windows.txt"}}; !reflect.DeepEqual(got.Checksums.Rules, want) {
+		t.Errorf("Checksums.Rules = %+v, want %+v", got.Checksums.Rules, want)
+	}
+}
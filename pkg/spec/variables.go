@@ -0,0 +1,106 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Variable is a custom placeholder usable as ${KEY} in asset and checksum
+// templates. It is either a static string:
+//
+//	variables:
+//	  SUFFIX: -static
+//
+// or a mapping of the expanded Input to values:
+//
+//	variables:
+//	  TRIPLE:
+//	    input: ${OS}/${ARCH}
+//	    cases:
+//	      linux/amd64: x86_64-unknown-linux-musl
+//	      darwin/arm64: aarch64-apple-darwin
+//	    default: unknown
+type Variable struct {
+	Value   string            `yaml:"value,omitempty"`
+	Input   string            `yaml:"input,omitempty"`
+	Cases   map[string]string `yaml:"cases,omitempty"`
+	Default string            `yaml:"default,omitempty"`
+}
+
+// UnmarshalYAML accepts a plain string as a static variable.
+func (v *Variable) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		*v = Variable{Value: value}
+		return nil
+	}
+	type plain Variable
+	return unmarshal((*plain)(v))
+}
+
+// MarshalYAML writes static variables back as plain strings.
+func (v Variable) MarshalYAML() (interface{}, error) {
+	if v.Input == "" && len(v.Cases) == 0 && v.Default == "" {
+		return v.Value, nil
+	}
+	type plain Variable
+	return plain(v), nil
+}
+
+// Resolve returns the value of the variable. expand substitutes the built-in
+// placeholders in Input.
+func (v Variable) Resolve(expand func(string) string) string {
+	if v.Input == "" {
+		return v.Value
+	}
+	if value, ok := v.Cases[expand(v.Input)]; ok {
+		return value
+	}
+	return v.Default
+}
+
+// reservedVariables are placeholders set by the installer itself.
+var reservedVariables = map[string]bool{
+	"NAME": true, "REPO": true, "REPO_OWNER": true, "REPO_NAME": true,
+	"VERSION": true, "TAG": true, "OS": true, "ARCH": true, "EXT": true,
+	"LIBC": true, "ASSET_FILENAME": true, "TARGET_FILENAME": true,
+}
+
+var variableNameRe = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// ValidateVariables checks that variable names are usable as shell variables
+// and do not shadow built-in placeholders.
+func (s *InstallSpec) ValidateVariables() error {
+	for _, name := range s.VariableNames() {
+		if !variableNameRe.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q: must match %s", name, variableNameRe)
+		}
+		if reservedVariables[name] || strings.HasPrefix(name, "BINSTALLER_") {
+			return fmt.Errorf("variable name %q is reserved", name)
+		}
+	}
+	return nil
+}
+
+// VariableNames returns the names of the custom variables in sorted order.
+func (s *InstallSpec) VariableNames() []string {
+	names := make([]string, 0, len(s.Variables))
+	for name := range s.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveVariables returns the values of the custom variables keyed by their
+// ${NAME} placeholder. expand substitutes the built-in placeholders in
+// case-mapping inputs.
+func (s *InstallSpec) ResolveVariables(expand func(string) string) map[string]string {
+	values := make(map[string]string, len(s.Variables))
+	for name, v := range s.Variables {
+		values["${"+name+"}"] = v.Resolve(expand)
+	}
+	return values
+}
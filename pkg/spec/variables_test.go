@@ -0,0 +1,87 @@
+package spec
+
+import (
+	"strings"
+	"testing"
+
+	goyaml "github.com/goccy/go-yaml"
+	"gopkg.in/yaml.v3"
+)
+
+const variablesYAML = `
+variables:
+  SUFFIX: -static
+  TRIPLE:
+    input: ${OS}/${ARCH}
+    cases:
+      linux/amd64: x86_64-unknown-linux-musl
+      darwin/arm64: aarch64-apple-darwin
+    default: unknown
+`
+
+func TestVariablesUnmarshal(t *testing.T) {
+	for name, unmarshal := range map[string]func([]byte, interface{}) error{
+		"yaml.v3":       yaml.Unmarshal,
+		"goccy/go-yaml": goyaml.Unmarshal,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var s InstallSpec
+			if err := unmarshal([]byte(variablesYAML), &s); err != nil {
+				t.Fatalf("unmarshal error = %v", err)
+			}
+			if got := s.Variables["SUFFIX"].Value; got != "-static" {
+				t.Errorf("SUFFIX = %q, want %q", got, "-static")
+			}
+			if got := s.Variables["TRIPLE"].Cases["linux/amd64"]; got != "x86_64-unknown-linux-musl" {
+				t.Errorf("TRIPLE linux/amd64 = %q", got)
+			}
+		})
+	}
+}
+
+func TestResolveVariables(t *testing.T) {
+	var s InstallSpec
+	if err := yaml.Unmarshal([]byte(variablesYAML), &s); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		os, arch, want string
+	}{
+		{"linux", "amd64", "x86_64-unknown-linux-musl"},
+		{"darwin", "arm64", "aarch64-apple-darwin"},
+		{"windows", "amd64", "unknown"},
+	} {
+		expand := strings.NewReplacer("${OS}", tt.os, "${ARCH}", tt.arch).Replace
+		vars := s.ResolveVariables(expand)
+		if got := vars["${TRIPLE}"]; got != tt.want {
+			t.Errorf("TRIPLE for %s/%s = %q, want %q", tt.os, tt.arch, got, tt.want)
+		}
+		if got := vars["${SUFFIX}"]; got != "-static" {
+			t.Errorf("SUFFIX = %q, want %q", got, "-static")
+		}
+	}
+}
+
+func TestVariableMarshalStatic(t *testing.T) {
+	out, err := yaml.Marshal(map[string]Variable{"SUFFIX": {Value: "-static"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "SUFFIX: -static\n" {
+		t.Errorf("Marshal() = %q", out)
+	}
+}
+
+func TestValidateVariables(t *testing.T) {
+	for name, wantErr := range map[string]bool{
+		"MY_VAR":       false,
+		"my_var":       true,
+		"OS":           true,
+		"BINSTALLER_X": true,
+	} {
+		s := &InstallSpec{Variables: map[string]Variable{name: {Value: "x"}}}
+		if err := s.ValidateVariables(); (err != nil) != wantErr {
+			t.Errorf("ValidateVariables(%s) error = %v, wantErr %v", name, err, wantErr)
+		}
+	}
+}
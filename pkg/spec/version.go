@@ -0,0 +1,43 @@
+package spec
+
+import "strings"
+
+// Prefix returns the tag prefix, derived from TagTemplate when TagPrefix is
+// not set. It returns "" for a nil config.
+func (v *VersionConfig) Prefix() string {
+	if v == nil {
+		return ""
+	}
+	if v.TagPrefix != "" {
+		return v.TagPrefix
+	}
+	if i := strings.Index(v.TagTemplate, "${VERSION}"); i > 0 {
+		return v.TagTemplate[:i]
+	}
+	return ""
+}
+
+// IncludesPrereleases reports whether latest release lookup considers
+// prereleases. It returns false for a nil config.
+func (v *VersionConfig) IncludesPrereleases() bool {
+	return v != nil && v.Prerelease
+}
+
+// VersionFromTag returns the ${VERSION} value of a release tag: the tag
+// without the tag prefix and leading "v".
+func (s *InstallSpec) VersionFromTag(tag string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(tag, s.Version.Prefix()), "v")
+}
+
+// TagFromVersion returns the release tag for a user supplied version or tag.
+// Values already carrying the tag prefix are returned as is.
+func (s *InstallSpec) TagFromVersion(version string) string {
+	prefix := s.Version.Prefix()
+	if prefix == "" || strings.HasPrefix(version, prefix) {
+		return version
+	}
+	if s.Version.TagTemplate != "" {
+		return strings.ReplaceAll(s.Version.TagTemplate, "${VERSION}", strings.TrimPrefix(version, "v"))
+	}
+	return prefix + version
+}
@@ -0,0 +1,32 @@
+package spec
+
+import "testing"
+
+func TestTagPrefix(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     *VersionConfig
+		input       string
+		wantTag     string
+		wantVersion string
+	}{
+		{"no config", nil, "v1.2.3", "v1.2.3", "1.2.3"},
+		{"prefix bare version", &VersionConfig{TagPrefix: "cli/"}, "v1.2.3", "cli/v1.2.3", "1.2.3"},
+		{"prefix full tag", &VersionConfig{TagPrefix: "cli/"}, "cli/v1.2.3", "cli/v1.2.3", "1.2.3"},
+		{"template", &VersionConfig{TagTemplate: "cli/v${VERSION}"}, "1.2.3", "cli/v1.2.3", "1.2.3"},
+		{"template v version", &VersionConfig{TagTemplate: "cli/v${VERSION}"}, "v1.2.3", "cli/v1.2.3", "1.2.3"},
+		{"template full tag", &VersionConfig{TagTemplate: "cli/v${VERSION}"}, "cli/v1.2.3", "cli/v1.2.3", "1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &InstallSpec{Version: tt.version}
+			tag := s.TagFromVersion(tt.input)
+			if tag != tt.wantTag {
+				t.Errorf("TagFromVersion(%q) = %q, want %q", tt.input, tag, tt.wantTag)
+			}
+			if got := s.VersionFromTag(tag); got != tt.wantVersion {
+				t.Errorf("VersionFromTag(%q) = %q, want %q", tag, got, tt.wantVersion)
+			}
+		})
+	}
+}
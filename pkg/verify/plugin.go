@@ -0,0 +1,109 @@
+// Package verify runs additional verification steps against downloaded
+// release assets.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+// AssetInfo describes a downloaded asset passed to verifier plugins.
+// The JSON encoding is the payload written to the plugin's stdin.
+type AssetInfo struct {
+	Name          string `json:"name"`
+	Repo          string `json:"repo"`
+	Version       string `json:"version"`
+	Tag           string `json:"tag"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+	AssetFilename string `json:"asset_filename"`
+	AssetPath     string `json:"asset_path"`
+}
+
+// env returns the BINSTALLER_* environment variables for the asset. The same
+// variables are exported by generated installer scripts.
+func (a AssetInfo) env() []string {
+	return []string{
+		"BINSTALLER_NAME=" + a.Name,
+		"BINSTALLER_REPO=" + a.Repo,
+		"BINSTALLER_VERSION=" + a.Version,
+		"BINSTALLER_TAG=" + a.Tag,
+		"BINSTALLER_OS=" + a.OS,
+		"BINSTALLER_ARCH=" + a.Arch,
+		"BINSTALLER_ASSET_FILENAME=" + a.AssetFilename,
+		"BINSTALLER_ASSET_PATH=" + a.AssetPath,
+	}
+}
+
+// RunPlugins runs each verifier plugin against the asset in order and
+// returns the first failure. Plugins whose command is not installed are
+// skipped unless they are required.
+func RunPlugins(ctx context.Context, plugins []spec.VerifyPlugin, info AssetInfo) error {
+	payload, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset info: %w", err)
+	}
+	for _, p := range plugins {
+		name := p.Name
+		if name == "" {
+			name = p.Command
+		}
+		command := CommandName(p.Command)
+		if command == "" {
+			return fmt.Errorf("verifier plugin %q has no command", name)
+		}
+		if _, err := exec.LookPath(command); err != nil {
+			if p.Required == nil || *p.Required {
+				return fmt.Errorf("verifier plugin %q: command %q not found", name, command)
+			}
+			log.Warnf("Skipping verifier plugin %q: command %q not found", name, command)
+			continue
+		}
+
+		log.Infof("Running verifier plugin: %s", name)
+		// Run through sh so the command line behaves the same as in generated scripts.
+		cmd := exec.CommandContext(ctx, "sh", "-c", p.Command+` "$1"`, "sh", info.AssetPath)
+		cmd.Env = append(os.Environ(), info.env()...)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = os.Stderr
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("verifier plugin %q failed for %s: %w: %s", name, info.AssetFilename, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return nil
+}
+
+// CommandName returns the program run by the shell command line command,
+// skipping leading NAME=value environment assignments.
+func CommandName(command string) string {
+	for _, field := range strings.Fields(command) {
+		if !isEnvAssignment(field) {
+			return field
+		}
+	}
+	return ""
+}
+
+// isEnvAssignment reports whether the shell word is a NAME=value assignment.
+func isEnvAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !(i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestRunPlugins(t *testing.T) {
+	dir := t.TempDir()
+	asset := filepath.Join(dir, "tool.tar.gz")
+	if err := os.WriteFile(asset, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+	info := AssetInfo{Name: "tool", Version: "1.0.0", AssetFilename: "tool.tar.gz", AssetPath: asset}
+
+	plugins := []spec.VerifyPlugin{
+		{Name: "record", Command: `sh -c 'cat > ` + out + `; echo "$BINSTALLER_VERSION $1" >> ` + out + `' record`},
+		{Name: "missing", Command: "binstaller-no-such-command", Required: boolPtr(false)},
+	}
+	if err := RunPlugins(context.Background(), plugins, info); err != nil {
+		t.Fatalf("RunPlugins failed: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), `"asset_filename":"tool.tar.gz"`) {
+		t.Errorf("plugin stdin did not receive JSON payload: %s", got)
+	}
+	if !strings.Contains(string(got), "1.0.0 "+asset) {
+		t.Errorf("plugin did not receive env and asset path: %s", got)
+	}
+}
+
+func TestRunPlugins_Failure(t *testing.T) {
+	info := AssetInfo{AssetFilename: "tool.tar.gz", AssetPath: "/nonexistent"}
+	tests := []struct {
+		name   string
+		plugin spec.VerifyPlugin
+	}{
+		{"non-zero exit", spec.VerifyPlugin{Name: "fail", Command: "false"}},
+		{"required but missing", spec.VerifyPlugin{Name: "missing", Command: "binstaller-no-such-command"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RunPlugins(context.Background(), []spec.VerifyPlugin{tt.plugin}, info); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"clamscan --no-summary", "clamscan"},
+		{"CLAMAV_DB=/tmp/db clamscan", "clamscan"},
+		{"A=1 _B2=x  ./scan.sh -v", "./scan.sh"},
+		{"2A=1 scan", "2A=1"},
+		{"=x scan", "=x"},
+		{"A=1", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CommandName(tt.command); got != tt.want {
+			t.Errorf("CommandName(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Package winget renders winget manifests from an InstallSpec and its
+// embedded checksums, so that Windows package submissions can be automated.
+package winget
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ManifestVersion is the winget manifest schema version written.
+	ManifestVersion = "1.6.0"
+	defaultLocale   = "en-US"
+)
+
+// Options are the package metadata that is not part of an InstallSpec.
+type Options struct {
+	// PackageIdentifier defaults to "<Owner>.<Repo>" of the GitHub repository.
+	PackageIdentifier string
+	// Publisher defaults to the owner of the GitHub repository.
+	Publisher string
+	// License is required by winget, e.g. "MIT".
+	License string
+	// ShortDescription is required by winget.
+	ShortDescription string
+	// Homepage defaults to the GitHub repository URL.
+	Homepage string
+}
+
+// Manifest is one file of a multi-file winget manifest.
+type Manifest struct {
+	// Filename is the file name winget-pkgs expects, e.g.
+	// "Owner.Tool.installer.yaml".
+	Filename string
+	Content  []byte
+}
+
+// wingetArch maps architectures of the spec to winget architectures.
+var wingetArch = map[string]string{"amd64": "x64", "386": "x86", "arm64": "arm64", "arm": "arm"}
+
+type versionManifest struct {
+	PackageIdentifier string `yaml:"PackageIdentifier"`
+	PackageVersion    string `yaml:"PackageVersion"`
+	DefaultLocale     string `yaml:"DefaultLocale"`
+	ManifestType      string `yaml:"ManifestType"`
+	ManifestVersion   string `yaml:"ManifestVersion"`
+}
+
+type installerManifest struct {
+	PackageIdentifier string      `yaml:"PackageIdentifier"`
+	PackageVersion    string      `yaml:"PackageVersion"`
+	Installers        []installer `yaml:"Installers"`
+	ManifestType      string      `yaml:"ManifestType"`
+	ManifestVersion   string      `yaml:"ManifestVersion"`
+}
+
+type installer struct {
+	Architecture         string       `yaml:"Architecture"`
+	InstallerType        string       `yaml:"InstallerType"`
+	NestedInstallerType  string       `yaml:"NestedInstallerType,omitempty"`
+	NestedInstallerFiles []nestedFile `yaml:"NestedInstallerFiles,omitempty"`
+	Commands             []string     `yaml:"Commands,omitempty"`
+	InstallerURL         string       `yaml:"InstallerUrl"`
+	InstallerSha256      string       `yaml:"InstallerSha256"`
+}
+
+type nestedFile struct {
+	RelativeFilePath     string `yaml:"RelativeFilePath"`
+	PortableCommandAlias string `yaml:"PortableCommandAlias,omitempty"`
+}
+
+type localeManifest struct {
+	PackageIdentifier string `yaml:"PackageIdentifier"`
+	PackageVersion    string `yaml:"PackageVersion"`
+	PackageLocale     string `yaml:"PackageLocale"`
+	Publisher         string `yaml:"Publisher"`
+	PackageName       string `yaml:"PackageName"`
+	PackageURL        string `yaml:"PackageUrl"`
+	License           string `yaml:"License"`
+	ShortDescription  string `yaml:"ShortDescription"`
+	ManifestType      string `yaml:"ManifestType"`
+	ManifestVersion   string `yaml:"ManifestVersion"`
+}
+
+// Manifests returns the version, installer and default locale manifests of
+// version for the Windows assets. Raw executables are installed as portable
+// packages; zip archives as portable packages nested in the zip. Other
+// archive formats are not supported by winget and are skipped.
+func Manifests(installSpec *spec.InstallSpec, version string, assets []checksums.PlatformAsset, opts Options) ([]Manifest, error) {
+	if opts.License == "" || opts.ShortDescription == "" {
+		return nil, fmt.Errorf("winget manifests require a license and a short description")
+	}
+	owner, repo, _ := strings.Cut(installSpec.Repo, "/")
+	if opts.PackageIdentifier == "" {
+		opts.PackageIdentifier = capitalize(owner) + "." + capitalize(repo)
+	}
+	if opts.Publisher == "" {
+		opts.Publisher = owner
+	}
+	if opts.Homepage == "" {
+		opts.Homepage = installSpec.GitHubBase() + "/" + installSpec.Repo
+	}
+	packageVersion := installSpec.VersionFromTag(version)
+
+	var installers []installer
+	for _, a := range assets {
+		arch := wingetArch[strings.ToLower(a.Arch)]
+		if strings.ToLower(a.OS) != "windows" || arch == "" {
+			continue
+		}
+		if a.Algorithm != spec.SHA256 {
+			return nil, fmt.Errorf("winget requires sha256 checksums, but %s has a %s checksum", a.Filename, a.Algorithm)
+		}
+		i := installer{
+			Architecture:    arch,
+			InstallerURL:    a.URL,
+			InstallerSha256: strings.ToUpper(a.Hash),
+		}
+		switch {
+		case a.Raw:
+			i.InstallerType = "portable"
+			if len(a.Binaries) > 0 {
+				i.Commands = []string{a.Binaries[0].Name}
+			}
+		case strings.HasSuffix(strings.ToLower(a.Filename), ".zip"):
+			i.InstallerType = "zip"
+			i.NestedInstallerType = "portable"
+			for _, b := range a.Binaries {
+				i.NestedInstallerFiles = append(i.NestedInstallerFiles, nestedFile{
+					RelativeFilePath:     strings.ReplaceAll(spec.ExecutableName("windows", b.Path), "/", `\`),
+					PortableCommandAlias: b.Name,
+				})
+			}
+		default:
+			continue
+		}
+		installers = append(installers, i)
+	}
+	if len(installers) == 0 {
+		return nil, fmt.Errorf("no embedded checksums for Windows executables or zip archives")
+	}
+
+	manifests := []struct {
+		suffix string
+		schema string
+		value  any
+	}{
+		{"", "version", versionManifest{
+			PackageIdentifier: opts.PackageIdentifier,
+			PackageVersion:    packageVersion,
+			DefaultLocale:     defaultLocale,
+			ManifestType:      "version",
+			ManifestVersion:   ManifestVersion,
+		}},
+		{".installer", "installer", installerManifest{
+			PackageIdentifier: opts.PackageIdentifier,
+			PackageVersion:    packageVersion,
+			Installers:        installers,
+			ManifestType:      "installer",
+			ManifestVersion:   ManifestVersion,
+		}},
+		{".locale." + defaultLocale, "defaultLocale", localeManifest{
+			PackageIdentifier: opts.PackageIdentifier,
+			PackageVersion:    packageVersion,
+			PackageLocale:     defaultLocale,
+			Publisher:         opts.Publisher,
+			PackageName:       installSpec.Name,
+			PackageURL:        opts.Homepage,
+			License:           opts.License,
+			ShortDescription:  opts.ShortDescription,
+			ManifestType:      "defaultLocale",
+			ManifestVersion:   ManifestVersion,
+		}},
+	}
+	var files []Manifest
+	for _, m := range manifests {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "# Code generated by binst export. DO NOT EDIT.\n")
+		fmt.Fprintf(&buf, "# yaml-language-server: $schema=https://aka.ms/winget-manifest.%s.%s.schema.json\n\n", m.schema, ManifestVersion)
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(m.value); err != nil {
+			return nil, fmt.Errorf("failed to encode %s manifest: %w", m.schema, err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode %s manifest: %w", m.schema, err)
+		}
+		files = append(files, Manifest{Filename: opts.PackageIdentifier + m.suffix + ".yaml", Content: buf.Bytes()})
+	}
+	return files, nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
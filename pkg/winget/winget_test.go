@@ -0,0 +1,78 @@
+package winget
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/haya14busa/goinstaller/pkg/checksums"
+	"github.com/haya14busa/goinstaller/pkg/spec"
+)
+
+var testSpec = &spec.InstallSpec{Name: "tool", Repo: "owner/tool"}
+
+func testAssets() []checksums.PlatformAsset {
+	binaries := []spec.Binary{{Name: "tool", Path: "bin/tool"}}
+	return []checksums.PlatformAsset{
+		{OS: "linux", Arch: "amd64", Filename: "tool_linux_amd64.tar.gz", URL: "https://example.com/tool_linux_amd64.tar.gz", Hash: "aaa", Algorithm: spec.SHA256, Binaries: binaries},
+		{OS: "windows", Arch: "amd64", Filename: "tool_windows_amd64.zip", URL: "https://example.com/tool_windows_amd64.zip", Hash: "bbb", Algorithm: spec.SHA256, Binaries: binaries},
+		{OS: "windows", Arch: "arm64", Filename: "tool_windows_arm64.exe", URL: "https://example.com/tool_windows_arm64.exe", Hash: "ccc", Algorithm: spec.SHA256, Raw: true, Binaries: binaries},
+		{OS: "windows", Arch: "386", Filename: "tool_windows_386.tar.gz", URL: "https://example.com/tool_windows_386.tar.gz", Hash: "ddd", Algorithm: spec.SHA256, Binaries: binaries},
+	}
+}
+
+func TestManifests(t *testing.T) {
+	manifests, err := Manifests(testSpec, "v1.0.0", testAssets(), Options{License: "MIT", ShortDescription: "A tool"})
+	if err != nil {
+		t.Fatalf("Manifests() error = %v", err)
+	}
+	wantFiles := []string{"Owner.Tool.yaml", "Owner.Tool.installer.yaml", "Owner.Tool.locale.en-US.yaml"}
+	if len(manifests) != len(wantFiles) {
+		t.Fatalf("got %d manifests, want %d", len(manifests), len(wantFiles))
+	}
+	for i, m := range manifests {
+		if m.Filename != wantFiles[i] {
+			t.Errorf("manifest %d filename = %q, want %q", i, m.Filename, wantFiles[i])
+		}
+	}
+
+	wantInstaller := `# Code generated by binst export. DO NOT EDIT.
+# yaml-language-server: $schema=https://aka.ms/winget-manifest.installer.1.6.0.schema.json
+
+PackageIdentifier: Owner.Tool
+PackageVersion: 1.0.0
+Installers:
+  - Architecture: x64
+    InstallerType: zip
+    NestedInstallerType: portable
+    NestedInstallerFiles:
+      - RelativeFilePath: bin\tool.exe
+        PortableCommandAlias: tool
+    InstallerUrl: https://example.com/tool_windows_amd64.zip
+    InstallerSha256: BBB
+  - Architecture: arm64
+    InstallerType: portable
+    Commands:
+      - tool
+    InstallerUrl: https://example.com/tool_windows_arm64.exe
+    InstallerSha256: CCC
+ManifestType: installer
+ManifestVersion: 1.6.0
+`
+	if got := string(manifests[1].Content); got != wantInstaller {
+		t.Errorf("installer manifest =\n%s\nwant:\n%s", got, wantInstaller)
+	}
+	for _, want := range []string{"Publisher: owner\n", "PackageUrl: https://github.com/owner/tool\n", "License: MIT\n", "ShortDescription: A tool\n"} {
+		if !strings.Contains(string(manifests[2].Content), want) {
+			t.Errorf("locale manifest does not contain %q:\n%s", want, manifests[2].Content)
+		}
+	}
+}
+
+func TestManifestsErrors(t *testing.T) {
+	if _, err := Manifests(testSpec, "v1.0.0", testAssets(), Options{License: "MIT"}); err == nil {
+		t.Error("Manifests() error = nil, want error without a short description")
+	}
+	if _, err := Manifests(testSpec, "v1.0.0", testAssets()[:1], Options{License: "MIT", ShortDescription: "A tool"}); err == nil {
+		t.Error("Manifests() error = nil, want error without Windows assets")
+	}
+}
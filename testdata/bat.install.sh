@@ -7,12 +7,52 @@ usage() {
   cat <<EOF
 $this: download ${NAME} from ${REPO}
 
-Usage: $this [-b bindir] [-d] [tag]
+Usage: $this [-b bindir] [-d] [-n] [-o os] [-a arch] [-v version]
+          [--retries=N] [--timeout=SECONDS] [--progress] [--resume] [--print-url]
+          [--from-file=PATH] [--base-url=URL] [--require-checksum]
+          [--pre] [--force] [--add-to-path] [--sudo] [--json]
+          [--keep=DIR] [--extract-only=DIR] [tag]
   -b sets bindir or installation directory, Defaults to ./bin
   -d turns on debug logging
+  -n, --dry-run resolves the release and logs every action without
+     downloading or installing anything
+  -o sets the target OS instead of detecting it, e.g. linux (or \$OVERRIDE_OS)
+  -a sets the target architecture instead of detecting it, e.g. arm64
+     (or \$OVERRIDE_ARCH)
+  -v sets the tag or version range to install, like [tag]
+  --retries=N retries failed downloads N times with exponential backoff
+     (default: 3, or \$BINSTALLER_RETRIES)
+  --timeout=SECONDS aborts downloads taking longer than SECONDS
+     (default: 0, 0 for no limit, or \$BINSTALLER_TIMEOUT)
+  --progress shows download progress when run in a terminal
+     (or \$BINSTALLER_PROGRESS=1)
+  --resume resumes interrupted downloads on retry (or \$BINSTALLER_RESUME=1)
+  --print-url prints the resolved platform, version, asset URL, checksum URL
+     and expected hash as KEY=VALUE lines without downloading anything
+  --from-file=PATH installs the pre-downloaded release asset at PATH instead
+     of downloading it. It is verified like a downloaded asset, against the
+     embedded checksum if any, so pass the tag of the release it belongs to.
+  --base-url=URL downloads release files from a mirror of https://github.com
+     at URL, e.g. an Artifactory or Nexus proxy (or \$DOWNLOAD_BASE_URL).
+     Checksums are still verified. Pass a tag to avoid querying GitHub for
+     the latest release.
+  --require-checksum fails instead of falling back to the checksum file when
+     no checksum is embedded for the asset (or \$BINSTALLER_REQUIRE_CHECKSUM=1)
+  --pre considers prereleases when resolving the latest release
+     (or \$BINSTALLER_PRERELEASE=1)
+  --add-to-path appends bindir to the PATH of your shell's startup file if
+     it is missing (or \$BINSTALLER_ADD_TO_PATH=1)
+  --sudo installs with sudo if bindir is not writable, e.g. /usr/local/bin.
+     Only the final install step runs under sudo.
+  --json prints a JSON object describing the installation to stdout
+  --keep=DIR copies the verified release asset to DIR
+  --extract-only=DIR extracts the verified release asset to DIR instead of
+     installing it
    [tag] is a tag from
    https://github.com/sharkdp/bat/releases
    If tag is missing, then the latest will be used.
+   A version range like ^1.2, ~1.2.3 or 1.x installs the highest release
+   matching it.
 
  Generated by binstaller
   https://github.com/haya14busa/binstaller
@@ -150,21 +190,55 @@ http_download_curl() {
   local_file=$1
   source_url=$2
   header=$3
-  if [ -z "$header" ]; then
-    curl -fsSL -o "$local_file" "$source_url"
+  set -- -fSL --retry "${DOWNLOAD_RETRIES:-0}"
+  if [ -n "${DOWNLOAD_PROGRESS:-}" ]; then
+    set -- "$@" -#
   else
-    curl -fsSL -H "$header" -o "$local_file" "$source_url"
+    set -- "$@" -s
   fi
+  if [ -n "${DOWNLOAD_RESUME:-}" ]; then
+    set -- "$@" -C -
+  fi
+  if [ "${DOWNLOAD_TIMEOUT:-0}" != 0 ]; then
+    set -- "$@" --max-time "$DOWNLOAD_TIMEOUT"
+  fi
+  if [ -n "$header" ]; then
+    set -- "$@" -H "$header"
+  fi
+  curl "$@" -o "$local_file" "$source_url"
 }
 http_download_wget() {
   local_file=$1
   source_url=$2
   header=$3
-  if [ -z "$header" ]; then
-    wget -q -O "$local_file" "$source_url"
-  else
-    wget -q --header "$header" -O "$local_file" "$source_url"
+  set -- -q
+  if [ -n "${DOWNLOAD_PROGRESS:-}" ]; then
+    if wget --help 2>&1 | grep -q -- --show-progress; then
+      set -- "$@" --show-progress
+    else
+      set --
+    fi
+  fi
+  if [ -n "${DOWNLOAD_RESUME:-}" ]; then
+    set -- "$@" -c
   fi
+  if [ "${DOWNLOAD_TIMEOUT:-0}" != 0 ]; then
+    set -- "$@" -T "$DOWNLOAD_TIMEOUT"
+  fi
+  if [ -n "$header" ]; then
+    set -- "$@" --header "$header"
+  fi
+  attempt=0
+  delay=1
+  until wget "$@" -O "$local_file" "$source_url"; do
+    attempt=$((attempt + 1))
+    if [ "$attempt" -gt "${DOWNLOAD_RETRIES:-0}" ]; then
+      return 1
+    fi
+    log_debug "http_download_wget retrying $source_url in ${delay}s"
+    sleep "$delay"
+    delay=$((delay * 2))
+  done
 }
 http_download() {
   log_debug "http_download $2"
@@ -180,7 +254,13 @@ http_download() {
 }
 http_copy() {
   tmp=$(mktemp)
-  http_download "${tmp}" "$1" "$2" || return 1
+  progress=${DOWNLOAD_PROGRESS:-}
+  DOWNLOAD_PROGRESS=""
+  http_download "${tmp}" "$1" "$2" || {
+    DOWNLOAD_PROGRESS=$progress
+    return 1
+  }
+  DOWNLOAD_PROGRESS=$progress
   body=$(cat "$tmp")
   rm -f "${tmp}"
   echo "$body"
@@ -189,7 +269,7 @@ github_release() {
   owner_repo=$1
   version=$2
   test -z "$version" && version="latest"
-  giturl="https://github.com/${owner_repo}/releases/${version}"
+  giturl="${GITHUB_BASE_URL:-https://github.com}/${owner_repo}/releases/${version}"
   json=$(http_copy "$giturl" "Accept:application/json")
   test -z "$json" && return 1
   version=$(echo "$json" | tr -s '\n' ' ' | sed 's/.*"tag_name":"//' | sed 's/".*//')
@@ -228,24 +308,292 @@ hash_compute() {
 }
 
 
+log_warn() {
+  log_priority 4 || return 0
+  echoerr "$(log_prefix)" "$(log_tag 4)" "$@"
+}
+
+# Download the release asset named $3 of tag $2 of repository $1 to $4
+# through the GitHub API using GITHUB_TOKEN, which also works for private
+# repositories.
+github_api_download() {
+  owner_repo=$1
+  tag=$2
+  asset_name=$3
+  local_file=$4
+  release_json=$(http_copy "${GITHUB_API_URL}/repos/${owner_repo}/releases/tags/${tag}" "Authorization: Bearer ${GITHUB_TOKEN}") || return 1
+  asset_url=$(echo "$release_json" | awk -F'"' -v name="$asset_name" '
+    $2 == "url" && $4 ~ /\/releases\/assets\/[0-9]+$/ { url = $4 }
+    $2 == "name" && $4 == name && url != "" { print url; exit }')
+  test -z "$asset_url" && return 1
+  log_debug "github_api_download ${asset_url}"
+  if is_command curl; then
+    curl -fsSL --retry "${DOWNLOAD_RETRIES:-0}" -H "Authorization: Bearer ${GITHUB_TOKEN}" -H "Accept: application/octet-stream" -o "$local_file" "$asset_url"
+    return
+  elif is_command wget; then
+    if ! wget --help 2>&1 | grep -q -- --max-redirect; then
+      log_debug "github_api_download requires curl or a wget supporting --max-redirect, e.g. not BusyBox wget"
+      return 1
+    fi
+    # wget resends custom headers on redirect, which the storage backend
+    # rejects, so resolve the redirect first and download it without them.
+    location=$(wget -q -S --max-redirect=0 --header "Authorization: Bearer ${GITHUB_TOKEN}" --header "Accept: application/octet-stream" -O /dev/null "$asset_url" 2>&1 | sed -n 's/^ *Location: *//p' | tr -d '\r' | tail -n 1)
+    test -z "$location" && return 1
+    wget -q -O "$local_file" "$location"
+    return
+  fi
+  log_crit "github_api_download unable to find wget or curl"
+  return 1
+}
+
+# Report whether the URL $1 exists without downloading it.
+http_exists() {
+  if is_command curl; then
+    curl -fsIL -o /dev/null "$1" 2>/dev/null
+  elif is_command wget; then
+    wget -q --spider "$1" 2>/dev/null
+  else
+    return 1
+  fi
+}
+
+# Print the latest release tag of repository $1. With GITHUB_TOKEN, the
+# GitHub API is queried with the token so that the request counts against the
+# token's rate limit instead of the anonymous one.
+github_latest_release() {
+  if [ -z "${GITHUB_TOKEN:-}" ]; then
+    github_release "$1" latest
+    return
+  fi
+  json=$(http_copy "${GITHUB_API_URL}/repos/$1/releases/latest" "Authorization: Bearer ${GITHUB_TOKEN}")
+  test -z "$json" && return 1
+  tag=$(echo "$json" | tr -s '\n' ' ' | sed 's/.*"tag_name": *"//' | sed 's/".*//')
+  test -z "$tag" && return 1
+  echo "$tag"
+}
+
+# Print the published release tags of repository $1, newest first.
+# Prereleases are skipped unless $2 is non-empty. Only the first page of 100
+# releases is considered.
+github_releases() {
+  releases_url="${GITHUB_API_URL}/repos/$1/releases?per_page=100"
+  if [ -n "${GITHUB_TOKEN:-}" ]; then
+    json=$(http_copy "$releases_url" "Authorization: Bearer ${GITHUB_TOKEN}")
+  else
+    json=$(http_copy "$releases_url")
+  fi
+  test -z "$json" && return 1
+  echo "$json" | tr ',{' '\n\n' | awk -F'"' -v pre="${2:-}" '
+    $2 == "tag_name" { tag = $4; draft = 0 }
+    $2 == "draft" && $3 ~ /true/ { draft = 1 }
+    $2 == "prerelease" && tag != "" {
+      if ((pre != "" || $3 !~ /true/) && !draft) print tag
+      tag = ""
+    }'
+}
+
+# Print the newest published release tag of repository $1 starting with $2,
+# for monorepos that release several components from one repository.
+# Prereleases are considered if $3 is non-empty.
+github_release_with_prefix() {
+  tag=$(github_releases "$1" "${3:-}" | awk -v prefix="$2" 'prefix == "" || index($0, prefix) == 1 { print; exit }')
+  test -z "$tag" && return 1
+  echo "$tag"
+}
+
+# Report whether $1 is a version range rather than a tag: ^1.2, ~1.2.3,
+# 1.x or 1.2.*
+is_version_range() {
+  echo "$1" | grep -Eq '^[~^]|(^|[.])[xX*]([.]|$)'
+}
+
+# Print the highest release tag of repository $1 whose version matches the
+# range $2. Tags are stripped of the prefix $3 and a leading v before they
+# are compared; tags that are not plain MAJOR[.MINOR[.PATCH]] versions are
+# ignored. As with npm, ^ allows changes that do not modify the left-most
+# non-zero part and ~ allows patch-level changes.
+github_release_in_range() {
+  tag=$(github_releases "$1" | awk -v range="$2" -v prefix="$3" '
+    function cmp(a, b, i) {
+      for (i = 1; i <= 3; i++) if (a[i] != b[i]) return a[i] < b[i] ? -1 : 1
+      return 0
+    }
+    BEGIN {
+      op = substr(range, 1, 1)
+      if (op == "^" || op == "~") range = substr(range, 2)
+      else op = ""
+      sub(/^v/, "", range)
+      n = split(range, p, ".")
+      fixed = 0
+      for (i = 1; i <= 3; i++) {
+        if (i <= n && p[i] ~ /^[0-9]+$/) {
+          if (fixed == i - 1) fixed = i
+          lo[i] = p[i] + 0
+        } else {
+          lo[i] = 0
+        }
+      }
+      # Upper bound (exclusive): bump the last part that must stay fixed
+      keep = fixed
+      if (op == "~") keep = fixed >= 2 ? 2 : 1
+      if (op == "^") {
+        keep = 1
+        if (lo[1] == 0 && fixed >= 2) keep = 2
+        if (lo[1] == 0 && lo[2] == 0 && fixed >= 3) keep = 3
+      }
+      if (keep > fixed) keep = fixed
+      for (i = 1; i <= 3; i++) hi[i] = i <= keep ? lo[i] : 0
+      unbounded = keep == 0
+      if (!unbounded) hi[keep]++
+      if (op == "") for (i = 1; i <= 3; i++) if (i > fixed) lo[i] = 0
+    }
+    {
+      v = $0
+      if (prefix != "") {
+        if (index(v, prefix) != 1) next
+        v = substr(v, length(prefix) + 1)
+      }
+      sub(/^v/, "", v)
+      if (v !~ /^[0-9]+([.][0-9]+)?([.][0-9]+)?$/) next
+      m = split(v, q, ".")
+      for (i = 1; i <= 3; i++) x[i] = i <= m ? q[i] + 0 : 0
+      if (cmp(x, lo) < 0 || (!unbounded && cmp(x, hi) >= 0)) next
+      print x[1], x[2], x[3], $0
+    }' | sort -k1,1n -k2,2n -k3,3n | tail -n 1 | cut -d' ' -f4)
+  test -z "$tag" && return 1
+  echo "$tag"
+}
+
+# Print $1 with the .exe suffix executables need on Windows
+exe_name() {
+  case "$1" in
+  *.exe) echo "$1" ;;
+  *) echo "$1.exe" ;;
+  esac
+}
+
+# Print the bsdtar command, which is tar itself on macOS and the BSDs
+bsdtar_command() {
+  if is_command bsdtar; then
+    echo bsdtar
+  elif tar --version 2>/dev/null | grep -q bsdtar; then
+    echo tar
+  else
+    return 1
+  fi
+}
+
+# Extract the tarball $2 by piping it through the decompressor $1 (or cat)
+# into plain "tar -x -o -f -", which minimal tar implementations such as
+# BusyBox tar support, stripping $3 leading path components.
+untar_portable() {
+  decompress=$1
+  tarball=$2
+  strip_components=${3:-0}
+  set -- -x -o -f -
+  if [ "${strip_components}" -gt 0 ]; then
+    set -- "$@" --strip-components "${strip_components}"
+  fi
+  if [ "$decompress" = cat ]; then
+    tar "$@" <"${tarball}"
+  else
+    "$decompress" -dc "${tarball}" | tar "$@"
+  fi
+}
+
 untar() {
   tarball=$1
   strip_components=${2:-0} # default 0
+  if [ -n "${PORTABLE:-}" ]; then
+    case "${tarball}" in
+    *.tar.gz | *.tgz) untar_portable gzip "${tarball}" "${strip_components}"; return ;;
+    *.tar.xz) untar_portable xz "${tarball}" "${strip_components}"; return ;;
+    *.tar.bz2) untar_portable bzip2 "${tarball}" "${strip_components}"; return ;;
+    *.tar) untar_portable cat "${tarball}" "${strip_components}"; return ;;
+    esac
+  fi
   case "${tarball}" in
   *.tar.gz | *.tgz) tar --no-same-owner -xzf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar.xz) tar --no-same-owner -xJf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar.bz2) tar --no-same-owner -xjf "${tarball}" --strip-components "${strip_components}" ;;
   *.tar) tar --no-same-owner -xf "${tarball}" --strip-components "${strip_components}" ;;
   *.gz) gunzip "${tarball}" ;;
+  *.7z)
+    if is_command 7z; then
+      7z x -y "${tarball}" >/dev/null
+    elif is_command 7zz; then
+      7zz x -y "${tarball}" >/dev/null
+    elif is_command 7za; then
+      7za x -y "${tarball}" >/dev/null
+    else
+      log_err "7z, 7zz or 7za is required to extract ${tarball}"
+      return 1
+    fi
+    ;;
+  *.deb)
+    if is_command dpkg-deb; then
+      dpkg-deb -x "${tarball}" .
+    elif is_command ar; then
+      ar x "${tarball}"
+      for data in data.tar*; do
+        tar --no-same-owner -xf "${data}"
+      done
+    else
+      log_err "dpkg-deb or ar is required to extract ${tarball}"
+      return 1
+    fi
+    ;;
+  *.rpm)
+    if is_command rpm2cpio && is_command cpio; then
+      rpm2cpio "${tarball}" | cpio -idm --quiet
+    elif is_command bsdtar; then
+      bsdtar -xf "${tarball}"
+    else
+      log_err "rpm2cpio and cpio (or bsdtar) are required to extract ${tarball}"
+      return 1
+    fi
+    ;;
+  *.dmg)
+    if ! is_command hdiutil; then
+      log_err "hdiutil is required to extract ${tarball} (macOS only)"
+      return 1
+    fi
+    mount_point=$(mktemp -d)
+    hdiutil attach -nobrowse -readonly -noautoopen -mountpoint "${mount_point}" "${tarball}" >/dev/null
+    copy_status=0
+    cp -R "${mount_point}"/. . || copy_status=$?
+    hdiutil detach "${mount_point}" -quiet
+    rmdir "${mount_point}" 2>/dev/null || true
+    return "${copy_status}"
+    ;;
+  *.pkg)
+    if ! is_command pkgutil; then
+      log_err "pkgutil is required to extract ${tarball} (macOS only)"
+      return 1
+    fi
+    expand_dir=$(basename "${tarball%.pkg}")_expanded
+    pkgutil --expand "${tarball}" "${expand_dir}"
+    # Payloads are gzipped cpio archives, either at the top level (component
+    # package) or inside each component of a product archive.
+    for payload in "${expand_dir}"/Payload "${expand_dir}"/*.pkg/Payload; do
+      test -f "${payload}" || continue
+      gunzip -dc "${payload}" | cpio -idm --quiet
+    done
+    ;;
+  *.apk) tar --no-same-owner -xzf "${tarball}" 2>/dev/null ;;
   *.zip)
-    # unzip doesn't have a standard --strip-components
-    # Workaround: extract to a subdir and move contents up if stripping
-    if [ "$strip_components" -gt 0 ]; then
+    bsdtar=$(bsdtar_command) || bsdtar=""
+    if [ -n "$bsdtar" ] && { [ "$strip_components" -gt 0 ] || ! is_command unzip; }; then
+      # bsdtar reads zip files and, unlike unzip, strips components
+      "$bsdtar" --no-same-owner -xf "${tarball}" --strip-components "${strip_components}"
+    elif [ "$strip_components" -gt 0 ]; then
+      # unzip doesn't have a standard --strip-components
+      # Workaround: extract to a subdir and move contents up
       extract_dir=$(basename "${tarball%.zip}")_extracted
       unzip -q "${tarball}" -d "${extract_dir}"
       # Move contents of the *first* directory found inside extract_dir up
       # This assumes wrap_in_directory=true convention
-      first_subdir=$(find "${extract_dir}" -mindepth 1 -maxdepth 1 -type d -print -quit)
+      first_subdir=$(find "${extract_dir}" -mindepth 1 -maxdepth 1 -type d | head -n 1)
       if [ -n "$first_subdir" ]; then
         # Move all contents (* includes hidden files)
         mv "${first_subdir}"/* .
@@ -267,6 +615,170 @@ untar() {
   esac
 }
 
+# Print the object value of the first member named $1 of the JSON document
+# read from stdin, e.g. the first attestation bundle of an API response.
+json_object() {
+  tr -d '\n' | awk -v key="\"$1\"" '{
+    start = index($0, key)
+    if (start == 0) exit 1
+    s = substr($0, start + length(key))
+    sub(/^[ \t]*:[ \t]*/, "", s)
+    if (substr(s, 1, 1) != "{") exit 1
+    depth = 0
+    instr = 0
+    esc = 0
+    n = length(s)
+    for (i = 1; i <= n; i++) {
+      c = substr(s, i, 1)
+      if (instr) {
+        if (esc) esc = 0
+        else if (c == "\\") esc = 1
+        else if (c == "\"") instr = 0
+      } else if (c == "\"") {
+        instr = 1
+      } else if (c == "{") {
+        depth++
+      } else if (c == "}") {
+        depth--
+        if (depth == 0) {
+          print substr(s, 1, i)
+          exit 0
+        }
+      }
+    }
+    exit 1
+  }'
+}
+
+# Print $1 as a JSON string
+json_string() {
+  printf '"%s"' "$(printf '%s' "$1" | sed 's/\\/\\\\/g; s/"/\\"/g')"
+}
+
+# Check whether directory $1, or its nearest existing parent, is writable
+is_writable() {
+  dir="$1"
+  while [ ! -d "$dir" ]; do
+    dir=$(dirname "$dir")
+  done
+  test -w "$dir"
+}
+
+# Set SUDO so that as_installer can write to directory $1, using sudo only
+# if it is not writable and $2 is non-empty.
+prepare_install_dir() {
+  SUDO=""
+  is_writable "$1" && return 0
+  if [ -z "$2" ]; then
+    log_crit "$1 is not writable. Re-run with --sudo to install with sudo, or choose another directory with -b"
+    return 1
+  fi
+  if ! is_command sudo; then
+    log_crit "$1 is not writable and sudo is not installed"
+    return 1
+  fi
+  log_info "$1 is not writable, installing with sudo"
+  SUDO=sudo
+}
+
+# Run the install command $@, with sudo if prepare_install_dir enabled it
+as_installer() {
+  if [ -n "${SUDO:-}" ]; then
+    sudo "$@"
+  else
+    "$@"
+  fi
+}
+
+# Report whether process $1 is running. kill -0 fails for processes of
+# other users, e.g. an installer running with sudo, so /proc and ps are
+# checked too.
+is_running() {
+  kill -0 "$1" 2>/dev/null || [ -d "/proc/$1" ] || ps -p "$1" >/dev/null 2>&1
+}
+
+# Take the lock directory $1 so that concurrent installers on the same host
+# do not overwrite each other's files, waiting up to $2 seconds for the
+# current holder. mkdir is atomic, so only one installer can create it. A
+# lock whose process is gone, or which never recorded a process, is stale and
+# removed. Call release_lock when done.
+acquire_lock() {
+  lock=$1
+  waited=0
+  while ! as_installer mkdir "$lock" 2>/dev/null; do
+    owner=$(cat "$lock/pid" 2>/dev/null || true)
+    if [ -n "$owner" ] && ! is_running "$owner"; then
+      log_warn "Removing stale lock ${lock} of process ${owner}"
+      as_installer rm -rf "$lock"
+      continue
+    fi
+    if [ "$waited" -ge "$2" ]; then
+      if [ -z "$owner" ]; then
+        log_warn "Removing stale lock ${lock}"
+        as_installer rm -rf "$lock"
+        waited=0
+        continue
+      fi
+      log_crit "Timed out waiting for process ${owner} holding ${lock}"
+      return 1
+    fi
+    test "$waited" -eq 0 && log_info "Waiting for another installation holding ${lock}"
+    sleep 1
+    waited=$((waited + 1))
+  done
+  echo "$$" | as_installer tee "$lock/pid" >/dev/null
+  INSTALL_LOCK="$lock"
+}
+
+# Release the lock taken by acquire_lock, if any
+release_lock() {
+  if [ -n "${INSTALL_LOCK:-}" ]; then
+    as_installer rm -rf "$INSTALL_LOCK"
+    INSTALL_LOCK=""
+  fi
+}
+
+# Print the startup file of the user's shell
+shell_profile() {
+  case "${SHELL##*/}" in
+  zsh) echo "${ZDOTDIR:-$HOME}/.zshrc" ;;
+  bash) echo "${HOME}/.bashrc" ;;
+  fish) echo "${XDG_CONFIG_HOME:-$HOME/.config}/fish/config.fish" ;;
+  *) echo "${HOME}/.profile" ;;
+  esac
+}
+
+# Print the line of the shell profile adding directory $1 to PATH
+path_profile_line() {
+  case "${SHELL##*/}" in
+  fish) echo "fish_add_path \"$1\"" ;;
+  *) echo "export PATH=\"$1:\$PATH\"" ;;
+  esac
+}
+
+# Check whether directory $1 is in PATH. If it is not, append it to the
+# shell profile when $2 is non-empty, or print how to do so.
+check_path() {
+  dir=$(cd "$1" && pwd) || return 0
+  case ":${PATH}:" in
+  *":${dir}:"* | *":${dir}/:"*) return 0 ;;
+  esac
+  profile=$(shell_profile)
+  line=$(path_profile_line "$dir")
+  if [ -z "$2" ]; then
+    log_warn "${dir} is not in your PATH. To add it, run:"
+    log_priority 4 && echoerr "  echo '${line}' >>\"${profile}\""
+    return 0
+  fi
+  if [ -f "$profile" ] && grep -Fqx -- "$line" "$profile"; then
+    log_info "${dir} is already added to PATH in ${profile}; restart your shell to use it"
+    return 0
+  fi
+  mkdir -p "${profile%/*}"
+  printf '\n# Added by the %s installer\n%s\n' "$(log_prefix)" "$line" >>"$profile"
+  log_info "Added ${dir} to PATH in ${profile}; restart your shell to use it"
+}
+
 extract_hash() {
   TARGET=$1
   checksums=$2
@@ -275,7 +787,13 @@ extract_hash() {
     return 1
   fi
   BASENAME=${TARGET##*/}
-  grep -E "([[:space:]]|/|\*)${BASENAME}$" "${checksums}" 2>/dev/null | tr '\t' ' ' | cut -d ' ' -f 1
+  # Strip CRs so that checksum files with Windows line endings match
+  hash=$(tr -d '\r' 2>/dev/null <"${checksums}" | grep -E "([[:space:]]|/|\*)${BASENAME}$" | tr '\t' ' ' | cut -d ' ' -f 1)
+  if [ -z "$hash" ]; then
+    # BSD style: "SHA256 (file) = hash"
+    hash=$(sed -n -E "s/^[A-Za-z0-9-]+ \((.*\/)?${BASENAME}\) = ([0-9A-Fa-f]+)\r?$/\2/p" "${checksums}" 2>/dev/null)
+  fi
+  echo "$hash"
 }
 
 
@@ -291,12 +809,12 @@ hash_verify() {
     log_err "failed to calculate hash: ${TARGET_PATH}"
     return 1
   fi
-  # 1) “hash-only” line?
+  # 1) “hash-only” line, as in per-asset sidecar files (e.g. ${ASSET_FILENAME}.sha256)?
   if grep -i -E "^${got}[[:space:]]*$" "$SUMFILE" >/dev/null 2>&1; then
     return 0
   fi
   # 2) Check hash & file name match
-  want=$(extract_hash "${TARGET_PATH}" "${SUMFILE}")
+  want=$(extract_hash "${TARGET_PATH}" "${SUMFILE}" | tr 'A-F' 'a-f')
   if [ "$want" != "$got" ]; then
     log_err "hash_verify checksum for '$TARGET_PATH' did not verify ${want} vs ${got}"
     return 1
@@ -316,33 +834,114 @@ find_embedded_checksum() {
 
 parse_args() {
   BINDIR="${BINSTALLER_BIN:-${HOME}/.local/bin}"
-  while getopts "b:dqh?x" arg; do
+  DOWNLOAD_RETRIES="${BINSTALLER_RETRIES:-3}"
+  DOWNLOAD_TIMEOUT="${BINSTALLER_TIMEOUT:-0}"
+  DOWNLOAD_PROGRESS="${BINSTALLER_PROGRESS:-}"
+  DOWNLOAD_RESUME="${BINSTALLER_RESUME:-}"
+  LOCK_TIMEOUT="${BINSTALLER_LOCK_TIMEOUT:-300}"
+  PRINT_URL=""
+  DRY_RUN=""
+  FROM_FILE=""
+  DOWNLOAD_BASE_URL="${DOWNLOAD_BASE_URL:-}"
+  FORCE="${BINSTALLER_FORCE:-}"
+  ADD_TO_PATH="${BINSTALLER_ADD_TO_PATH:-}"
+  USE_SUDO=""
+  JSON_OUTPUT=""
+  PORTABLE="${BINSTALLER_PORTABLE:-}"
+  KEEP_DIR=""
+  EXTRACT_DIR=""
+  GO_INSTALL="${BINSTALLER_GO_INSTALL:-}"
+  PRERELEASE="${BINSTALLER_PRERELEASE:-}"
+  REQUIRE_CHECKSUM="${BINSTALLER_REQUIRE_CHECKSUM:-}"
+  OVERRIDE_OS="${OVERRIDE_OS:-${BINSTALLER_OS:-}}"
+  OVERRIDE_ARCH="${OVERRIDE_ARCH:-${BINSTALLER_ARCH:-}}"
+  VERSION_ARG=""
+  while getopts "b:dnqo:a:v:h?x-:" arg; do
     case "$arg" in
     b) BINDIR="$OPTARG" ;;
     d) log_set_priority 10 ;;
+    n) DRY_RUN=1 ;;
     q) log_set_priority 3 ;;
+    o) OVERRIDE_OS="$OPTARG" ;;
+    a) OVERRIDE_ARCH="$OPTARG" ;;
+    v) VERSION_ARG="$OPTARG" ;;
     h | \?) usage "$0" ;;
     x) set -x ;;
+    -)
+      # Long options: --name or --name=value
+      case "$OPTARG" in
+      retries=*) DOWNLOAD_RETRIES="${OPTARG#*=}" ;;
+      timeout=*) DOWNLOAD_TIMEOUT="${OPTARG#*=}" ;;
+      progress) DOWNLOAD_PROGRESS=1 ;;
+      resume) DOWNLOAD_RESUME=1 ;;
+      print-url) PRINT_URL=1 ;;
+      dry-run) DRY_RUN=1 ;;
+      from-file=*) FROM_FILE="${OPTARG#*=}" ;;
+      base-url=*) DOWNLOAD_BASE_URL="${OPTARG#*=}" ;;
+      require-checksum) REQUIRE_CHECKSUM=1 ;;
+      pre) PRERELEASE=1 ;;
+      force) FORCE=1 ;;
+      add-to-path) ADD_TO_PATH=1 ;;
+      sudo) USE_SUDO=1 ;;
+      json) JSON_OUTPUT=1 ;;
+      keep=*) KEEP_DIR="${OPTARG#*=}" ;;
+      extract-only=*) EXTRACT_DIR="${OPTARG#*=}" ;;
+      go-install) GO_INSTALL=1 ;;
+      from-file)
+        eval "FROM_FILE=\"\${$OPTIND:-}\""
+        OPTIND=$((OPTIND + 1))
+        ;;
+      *) usage "$0" ;;
+      esac
+      ;;
     esac
   done
   shift $((OPTIND - 1))
-  TAG="${1:-latest}"
+  if [ ! -t 2 ] || [ -n "${CI:-}" ]; then
+    # No progress bars in non-interactive environments
+    DOWNLOAD_PROGRESS=""
+  fi
+  if [ -n "$FROM_FILE" ] && [ ! -f "$FROM_FILE" ]; then
+    log_crit "--from-file: no such file '${FROM_FILE}'"
+    exit 1
+  fi
+  for n in "$DOWNLOAD_RETRIES" "$DOWNLOAD_TIMEOUT" "$LOCK_TIMEOUT"; do
+    case "$n" in
+    '' | *[!0-9]*)
+      log_crit "--retries, --timeout and \$BINSTALLER_LOCK_TIMEOUT must be non-negative integers, got '${n}'"
+      exit 1
+      ;;
+    esac
+  done
+  TAG="${1:-${VERSION_ARG:-latest}}"
 }
 
 tag_to_version() {
   if [ "$TAG" = "latest" ]; then
-    log_info "checking GitHub for latest tag"
-    REALTAG=$(github_release "${REPO}" "${TAG}") && true
+    if [ -n "$PRERELEASE" ]; then
+      log_info "checking GitHub for latest tag including prereleases"
+      REALTAG=$(github_release_with_prefix "${REPO}" '' 1) && true
+    else
+      log_info "checking GitHub for latest tag"
+      REALTAG=$(github_latest_release "${REPO}") && true
+    fi
     test -n "$REALTAG" || {
       log_crit "Could not determine latest tag for ${REPO}"
       exit 1
     }
+  elif is_version_range "$TAG"; then
+    log_info "checking GitHub for the highest tag matching ${TAG}"
+    REALTAG=$(github_release_in_range "${REPO}" "$TAG" '') && true
+    test -n "$REALTAG" || {
+      log_crit "Could not find a release of ${REPO} matching ${TAG}"
+      exit 1
+    }
   else
     # Assume TAG is a valid tag/version string
     REALTAG="$TAG"
   fi
   if test -z "$REALTAG"; then
-    log_crit "unable to find '${TAG}' - use 'latest' or see https://github.com/${REPO}/releases for details"
+    log_crit "unable to find '${TAG}' - use 'latest' or see ${GITHUB_BASE_URL}/${REPO}/releases for details"
     exit 1
   fi
   VERSION=${REALTAG#v} # Strip leading 'v'
@@ -352,6 +951,8 @@ tag_to_version() {
 
 
 
+
+
 resolve_asset_filename() {
   
   # --- Apply Rules ---
@@ -375,6 +976,119 @@ resolve_asset_filename() {
   if [ -z "${ASSET_FILENAME}" ]; then
     ASSET_FILENAME="${NAME}-v${VERSION}-${ARCH}-${OS}${EXT}"
   fi
+  if [ "${UNAME_OS}" = "windows" ] && [ -z "${EXT}" ]; then
+    # Raw binaries are published as executables on Windows
+    ASSET_FILENAME=$(exe_name "${ASSET_FILENAME}")
+    EXT=".exe"
+  fi
+}
+
+# Print the download URL of the release file $1. URLs under GITHUB_BASE_URL
+# are rewritten to DOWNLOAD_BASE_URL if set.
+release_url() (
+  ASSET_FILENAME="$1"
+  url="${GITHUB_BASE_URL}/${REPO}/releases/download/${TAG}/${ASSET_FILENAME}"
+  if [ -n "${DOWNLOAD_BASE_URL}" ]; then
+    case "$url" in
+    "${GITHUB_BASE_URL}"/*) url="${DOWNLOAD_BASE_URL%/}${url#"${GITHUB_BASE_URL}"}" ;;
+    esac
+  fi
+  echo "$url"
+)
+
+# Download the release file $2 to $1
+download_release_file() {
+  if [ -n "${GITHUB_TOKEN:-}" ] && [ -z "${DOWNLOAD_BASE_URL}" ]; then
+    # Use the API so that assets of private repositories can be downloaded
+    if github_api_download "${REPO}" "${TAG}" "$2" "$1"; then
+      return 0
+    fi
+    log_debug "GitHub API download of $2 failed, falling back to $(release_url "$2")"
+  fi
+  http_download "$1" "$(release_url "$2")"
+}
+
+# Download the checksum file to $1
+download_checksum_file() {
+  download_release_file "$1" "${CHECKSUM_FILENAME}"
+}
+
+# Download TMPDIR/$1 in the background with the download function $2, called
+# with the destination and the remaining arguments, so that it overlaps with
+# the asset download. Failures are ignored: files missing after
+# wait_prefetch are downloaded again when needed.
+prefetch() {
+  name="$1"
+  fetch="$2"
+  shift 2
+  (
+    "$fetch" "${TMPDIR}/.prefetch.${name}" "$@" >/dev/null 2>&1 &&
+      mv "${TMPDIR}/.prefetch.${name}" "${TMPDIR}/${name}"
+  ) &
+  PREFETCH_PIDS="${PREFETCH_PIDS} $!"
+}
+
+# Wait for the downloads started by prefetch
+wait_prefetch() {
+  for pid in ${PREFETCH_PIDS}; do
+    wait "$pid" || true
+  done
+  PREFETCH_PIDS=""
+}
+
+# Print what execute would download as KEY=VALUE lines for --print-url.
+# EXPECTED_HASH is empty unless the checksum is embedded, since checksum
+# files are not downloaded.
+print_resolved() {
+  echo "PLATFORM=${PLATFORM}"
+  echo "VERSION=${VERSION}"
+  echo "TAG=${TAG}"
+  echo "ASSET_FILENAME=${ASSET_FILENAME}"
+  echo "ASSET_URL=${ASSET_URL}"
+  echo "CHECKSUM_URL=${CHECKSUM_URL}"
+  echo "EXPECTED_HASH=${EMBEDDED_HASH}"
+}
+
+# Log the actions execute would take for --dry-run. Nothing is downloaded
+# and no files are written.
+dry_run() {
+  if [ -n "$FROM_FILE" ]; then
+    log_info "[dry-run] Would use local file ${FROM_FILE} as ${ASSET_FILENAME}"
+  else
+    log_info "[dry-run] Would download ${ASSET_URL}"
+  fi
+  if [ -n "$EMBEDDED_HASH" ]; then
+    log_info "[dry-run] Would verify ${ASSET_FILENAME} against embedded checksum ${EMBEDDED_HASH}"
+  elif [ -n "$CHECKSUM_URL" ]; then
+    log_info "[dry-run] Would download checksums from ${CHECKSUM_URL} and verify ${ASSET_FILENAME}"
+  else
+    log_info "[dry-run] Would skip checksum verification: no checksum found"
+  fi
+  if [ -n "${KEEP_DIR}" ]; then
+    log_info "[dry-run] Would copy ${ASSET_FILENAME} to ${KEEP_DIR}"
+  fi
+  if [ -n "${EXTRACT_DIR}" ]; then
+    log_info "[dry-run] Would extract ${ASSET_FILENAME} to ${EXTRACT_DIR} without installing"
+    return 0
+  fi
+  if [ -n "${EXT}" ] && [ "${EXT}" != ".exe" ]; then
+    log_info "[dry-run] Would extract ${ASSET_FILENAME}"
+  fi
+  if ! is_writable "${BINDIR}"; then
+    if [ -n "${USE_SUDO}" ]; then
+      log_info "[dry-run] Would install with sudo: ${BINDIR} is not writable"
+    else
+      log_warn "[dry-run] ${BINDIR} is not writable, installation would fail without --sudo"
+    fi
+  fi
+  BINARY_NAME='bat'
+  if [ "${UNAME_OS}" = "windows" ]; then
+    case "${BINARY_NAME}" in *.exe) ;; *) BINARY_NAME="${BINARY_NAME}.exe" ;; esac
+  fi
+  log_info "[dry-run] Would install ${BINARY_NAME} to ${BINDIR}/${BINARY_NAME}"
+  if [ -n "${ADD_TO_PATH}" ]; then
+    log_info "[dry-run] Would add ${BINDIR} to PATH in $(shell_profile) if missing"
+  fi
 }
 
 execute() {
@@ -382,26 +1096,54 @@ execute() {
   CHECKSUM_FILENAME=""
 
   # --- Construct URLs ---
-  GITHUB_DOWNLOAD="https://github.com/${REPO}/releases/download"
-  ASSET_URL="${GITHUB_DOWNLOAD}/${TAG}/${ASSET_FILENAME}"
+  ASSET_URL=$(release_url "${ASSET_FILENAME}")
   CHECKSUM_URL=""
   if [ -n "$CHECKSUM_FILENAME" ]; then
-    CHECKSUM_URL="${GITHUB_DOWNLOAD}/${TAG}/${CHECKSUM_FILENAME}"
+    CHECKSUM_URL=$(release_url "${CHECKSUM_FILENAME}")
+  fi
+
+  # Try to find embedded checksum first
+  EMBEDDED_HASH=$(find_embedded_checksum "$VERSION" "$ASSET_FILENAME")
+
+  if [ -n "$PRINT_URL" ]; then
+    print_resolved
+    return 0
+  fi
+  if [ -z "$EMBEDDED_HASH" ] && [ -n "$REQUIRE_CHECKSUM" ]; then
+    log_crit "No embedded checksum for ${ASSET_FILENAME} (version ${VERSION}) and a checksum is required"
+    return 1
+  fi
+  if [ -n "$DRY_RUN" ]; then
+    dry_run
+    return 0
   fi
 
   # --- Download and Verify ---
   TMPDIR=$(mktemp -d)
-  trap 'rm -rf -- "$TMPDIR"' EXIT HUP INT TERM
+  INSTALL_LOCK=""
+  trap 'release_lock; rm -rf -- "$TMPDIR"' EXIT HUP INT TERM
   log_debug "Downloading files into ${TMPDIR}"
-  log_info "Downloading ${ASSET_URL}"
-  http_download "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_URL}"
 
-  # Try to find embedded checksum first
-  EMBEDDED_HASH=$(find_embedded_checksum "$VERSION" "$ASSET_FILENAME")
+  # Fetch the small verification files while the asset downloads
+  PREFETCH_PIDS=""
+  if [ -z "$EMBEDDED_HASH" ] && [ -n "$CHECKSUM_URL" ]; then
+    prefetch "${CHECKSUM_FILENAME}" download_checksum_file
+  fi
+
+  if [ -n "$FROM_FILE" ]; then
+    log_info "Using local file ${FROM_FILE} as ${ASSET_FILENAME}"
+    cp "${FROM_FILE}" "${TMPDIR}/${ASSET_FILENAME}"
+  else
+    log_info "Downloading ${ASSET_URL}"
+    download_release_file "${TMPDIR}/${ASSET_FILENAME}" "${ASSET_FILENAME}"
+  fi
+  wait_prefetch
 
+  CHECKSUM_VERIFICATION=none
   if [ -n "$EMBEDDED_HASH" ]; then
     log_info "Using embedded checksum for verification"
-    
+    CHECKSUM_VERIFICATION=embedded
+
     # Verify using embedded hash
     got=$(hash_compute "${TMPDIR}/${ASSET_FILENAME}")
     if [ "$got" != "$EMBEDDED_HASH" ]; then
@@ -414,19 +1156,38 @@ execute() {
   elif [ -n "$CHECKSUM_URL" ]; then
     # Fall back to downloading checksum file
     log_info "Downloading checksums from ${CHECKSUM_URL}"
-    http_download "${TMPDIR}/${CHECKSUM_FILENAME}" "${CHECKSUM_URL}"
+    if [ ! -f "${TMPDIR}/${CHECKSUM_FILENAME}" ]; then
+      download_checksum_file "${TMPDIR}/${CHECKSUM_FILENAME}"
+    fi
     log_info "Verifying checksum ..."
     hash_verify "${TMPDIR}/${ASSET_FILENAME}" "${TMPDIR}/${CHECKSUM_FILENAME}"
+    CHECKSUM_VERIFICATION=checksum_file
   else
     log_info "No checksum found, skipping verification."
   fi
 
+  if [ -n "${KEEP_DIR}" ]; then
+    log_info "Copying ${ASSET_FILENAME} to ${KEEP_DIR}"
+    test ! -d "${KEEP_DIR}" && install -d "${KEEP_DIR}"
+    cp "${TMPDIR}/${ASSET_FILENAME}" "${KEEP_DIR}/"
+  fi
+  if [ -n "${EXTRACT_DIR}" ]; then
+    extract_only
+    return 0
+  fi
+
   if [ -z "${EXT}" ] || [ "${EXT}" = ".exe" ]; then
     log_debug "Target is raw binary"
   else
     log_info "Extracting ${ASSET_FILENAME}..."
     (cd "${TMPDIR}" && untar "${ASSET_FILENAME}" "${STRIP_COMPONENTS}")
   fi
+
+  prepare_install_dir "${BINDIR}" "${USE_SUDO}"
+  test ! -d "${BINDIR}" && as_installer install -d "${BINDIR}"
+  # Serialize installs into the same directory, e.g. by parallel CI jobs
+  acquire_lock "${BINDIR}/.${NAME}.lock" "${LOCK_TIMEOUT}"
+  INSTALLED_JSON=""
   BINARY_NAME='bat'
   if [ -z "${EXT}" ] || [ "${EXT}" = ".exe" ]; then
     BINARY_PATH="${TMPDIR}/${ASSET_FILENAME}"
@@ -435,8 +1196,10 @@ execute() {
   fi
 
   if [ "${UNAME_OS}" = "windows" ]; then
-    case "${BINARY_NAME}" in *.exe) ;; *) BINARY_NAME="${BINARY_NAME}.exe" ;; esac
-    case "${BINARY_PATH}" in *.exe) ;; *) BINARY_PATH="${BINARY_PATH}.exe" ;; esac
+    BINARY_NAME=$(exe_name "${BINARY_NAME}")
+    if [ -n "${EXT}" ] && [ "${EXT}" != ".exe" ]; then
+      BINARY_PATH=$(exe_name "${BINARY_PATH}")
+    fi
   fi
 
   if [ ! -f "${BINARY_PATH}" ]; then
@@ -452,15 +1215,47 @@ execute() {
 
   # Install the binary
   INSTALL_PATH="${BINDIR}/${BINARY_NAME}"
+  test ! -d "${BINDIR}" && as_installer install -d "${BINDIR}"
   log_info "Installing binary to ${INSTALL_PATH}"
-  test ! -d "${BINDIR}" && install -d "${BINDIR}"
-  install "${BINARY_PATH}" "${INSTALL_PATH}"
+  as_installer install "${BINARY_PATH}" "${INSTALL_PATH}"
+  INSTALLED_JSON="${INSTALLED_JSON:+${INSTALLED_JSON},}$(json_string "${INSTALL_PATH}")"
   log_info "${BINARY_NAME} installation complete!"
+  release_lock
+
+  check_path "${BINDIR}" "${ADD_TO_PATH}"
+
+  if [ -n "$JSON_OUTPUT" ]; then
+    print_json_result
+  fi
+}
+
+# Extract the verified release asset to EXTRACT_DIR for --extract-only
+extract_only() {
+  test ! -d "${EXTRACT_DIR}" && install -d "${EXTRACT_DIR}"
+  if [ -z "${EXT}" ] || [ "${EXT}" = ".exe" ]; then
+    log_info "Copying ${ASSET_FILENAME} to ${EXTRACT_DIR}"
+    cp "${TMPDIR}/${ASSET_FILENAME}" "${EXTRACT_DIR}/"
+  else
+    log_info "Extracting ${ASSET_FILENAME} to ${EXTRACT_DIR}"
+    (cd "${EXTRACT_DIR}" && untar "${TMPDIR}/${ASSET_FILENAME}" "${STRIP_COMPONENTS}")
+  fi
+}
+
+# Print the result of execute as a JSON object for --json
+print_json_result() {
+  printf '{"name":%s,"repo":%s,"version":%s,"tag":%s,"platform":%s,' \
+    "$(json_string "$NAME")" "$(json_string "$REPO")" "$(json_string "$VERSION")" "$(json_string "$TAG")" "$(json_string "$PLATFORM")"
+  printf '"asset":%s,"url":%s,"checksum":{"algorithm":"%s","hash":%s},' \
+    "$(json_string "$ASSET_FILENAME")" "$(json_string "$ASSET_URL")" \
+    "sha256" "$(json_string "$(hash_compute "${TMPDIR}/${ASSET_FILENAME}")")"
+  printf '"verification":"%s","installed":[%s]}\n' "$CHECKSUM_VERIFICATION" "$INSTALLED_JSON"
 }
 
 # --- Configuration  ---
 NAME='bat'
 REPO='sharkdp/bat'
+GITHUB_BASE_URL='https://github.com'
+GITHUB_API_URL='https://api.github.com'
 EXT='.tar.gz'
 
 # use in logging routines
@@ -471,12 +1266,13 @@ log_prefix() {
 parse_args "$@"
 
 # --- Determine target platform ---
-OS="${BINSTALLER_OS:-$(uname_os)}"
+OS="${OVERRIDE_OS:-$(uname_os)}"
 UNAME_OS="${OS}"
 
-ARCH="${BINSTALLER_ARCH:-$(uname_arch)}"
+ARCH="${OVERRIDE_ARCH:-$(uname_arch)}"
 UNAME_ARCH="${ARCH}"
-log_info "Detected Platform: ${OS}/${ARCH}"
+PLATFORM="${OS}/${ARCH}"
+log_info "Detected Platform: ${PLATFORM}"
 
 # --- Validate platform ---
 uname_os_check "$OS"